@@ -17,6 +17,16 @@ import (
 )
 
 func runDbCommand(command func(commandLine utils.CommandLine, sqlStore *sqlstore.SQLStore) error) func(context *cli.Context) error {
+	return dbCommand(command, false)
+}
+
+// runDbCommandWithoutMigrations is like runDbCommand, but does not run the database
+// migrations, for commands that must not change the database.
+func runDbCommandWithoutMigrations(command func(commandLine utils.CommandLine, sqlStore *sqlstore.SQLStore) error) func(context *cli.Context) error {
+	return dbCommand(command, true)
+}
+
+func dbCommand(command func(commandLine utils.CommandLine, sqlStore *sqlstore.SQLStore) error, skipMigrations bool) func(context *cli.Context) error {
 	return func(context *cli.Context) error {
 		cmd := &utils.ContextCommandLine{Context: context}
 		debug := cmd.Bool("debug")
@@ -35,6 +45,10 @@ func runDbCommand(command func(commandLine utils.CommandLine, sqlStore *sqlstore
 			cfg.LogConfigSources()
 		}
 
+		if skipMigrations {
+			cfg.Raw.Section("database").Key("skip_migrations").SetValue("true")
+		}
+
 		sqlStore, err := sqlstore.ProvideService(cfg, nil, bus.GetBus(), &migrations.OSSMigrations{})
 		if err != nil {
 			return errutil.Wrap("failed to initialize SQL store", err)
@@ -133,6 +147,18 @@ var adminCommands = []*cli.Command{
 				Usage:  "Migrates passwords from unsecured fields to secure_json_data field. Return ok unless there is an error. Safe to execute multiple times.",
 				Action: runDbCommand(datamigrations.EncryptDatasourcePasswords),
 			},
+			{
+				Name:   "alerting-dry-run",
+				Usage:  "Reports how the dashboard alerts and notification channels would be migrated to unified alerting, without changing anything.",
+				Action: runDbCommandWithoutMigrations(datamigrations.AlertingMigrationDryRun),
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "json",
+						Usage: "Output the report as JSON",
+						Value: false,
+					},
+				},
+			},
 		},
 	},
 }
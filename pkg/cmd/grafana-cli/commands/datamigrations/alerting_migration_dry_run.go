@@ -0,0 +1,109 @@
+package datamigrations
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/fatih/color"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrations/ualert"
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+// AlertingMigrationDryRun reports what the migration of the dashboard alerts and
+// notification channels to unified alerting would do, without changing the database.
+func AlertingMigrationDryRun(c utils.CommandLine, sqlStore *sqlstore.SQLStore) error {
+	report, err := alertingMigrationDryRun(sqlStore)
+	if err != nil {
+		return err
+	}
+
+	if c.Bool("json") {
+		out, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return errutil.Wrap("failed to marshal the report", err)
+		}
+		logger.Info(string(out))
+		return nil
+	}
+
+	var buf bytes.Buffer
+	writeAlertingMigrationReport(&buf, report)
+	logger.Info(buf.String())
+	return nil
+}
+
+func alertingMigrationDryRun(sqlStore *sqlstore.SQLStore) (*ualert.DryRunReport, error) {
+	var report *ualert.DryRunReport
+	err := sqlStore.WithDbSession(context.Background(), func(session *sqlstore.DBSession) error {
+		var err error
+		report, err = ualert.DryRun(session.Session, sqlStore.Dialect)
+		return err
+	})
+	if err != nil {
+		return nil, errutil.Wrap("failed to run the alerting migration", err)
+	}
+	return report, nil
+}
+
+func writeAlertingMigrationReport(w io.Writer, report *ualert.DryRunReport) {
+	failed, alertProblems := 0, 0
+	for _, a := range report.Alerts {
+		fmt.Fprintf(w, "Alert %d %q (org %d, dashboard %s, panel %d)\n", a.ID, a.Name, a.OrgID, a.DashboardUID, a.PanelID)
+		if a.Error != "" {
+			failed++
+			fmt.Fprintf(w, "  %s %s\n", color.RedString("✗"), a.Error)
+		}
+		if r := a.Rule; r != nil {
+			folder := fmt.Sprintf("%q", r.Folder)
+			if r.NewFolder {
+				folder += " (new)"
+			}
+			receiver := r.Receiver
+			if receiver == "" {
+				receiver = "none"
+			}
+			fmt.Fprintf(w, "  %s rule %q in folder %s, every %ds for %s, no data: %s, error: %s, contact point: %s\n",
+				color.GreenString("✔"), r.Title, folder, r.IntervalSeconds, r.For, r.NoDataState, r.ExecErrState, receiver)
+			if r.Silenced {
+				fmt.Fprintf(w, "  %s the alert is paused, the rule would be silenced\n", color.YellowString("!"))
+			}
+		}
+		if len(a.Problems) > 0 {
+			alertProblems++
+		}
+		for _, p := range a.Problems {
+			fmt.Fprintf(w, "  %s %s\n", color.YellowString("!"), p)
+		}
+	}
+
+	channelProblems := 0
+	for _, c := range report.Channels {
+		fmt.Fprintf(w, "Notification channel %d %q (org %d, type %s", c.ID, c.Name, c.OrgID, c.Type)
+		if c.IsDefault {
+			fmt.Fprint(w, ", default")
+		}
+		fmt.Fprintln(w, ")")
+		if len(c.Receivers) > 0 {
+			fmt.Fprintf(w, "  %s contact points: %s\n", color.GreenString("✔"), strings.Join(c.Receivers, ", "))
+		} else {
+			fmt.Fprintf(w, "  %s not migrated\n", color.RedString("✗"))
+		}
+		if len(c.Problems) > 0 {
+			channelProblems++
+		}
+		for _, p := range c.Problems {
+			fmt.Fprintf(w, "  %s %s\n", color.YellowString("!"), p)
+		}
+	}
+
+	fmt.Fprintf(w, "\n%d alerts: %d would fail the migration, %d with problems\n", len(report.Alerts), failed, alertProblems)
+	fmt.Fprintf(w, "%d notification channels: %d with problems\n", len(report.Channels), channelProblems)
+}
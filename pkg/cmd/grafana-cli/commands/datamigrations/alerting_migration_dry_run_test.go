@@ -0,0 +1,150 @@
+package datamigrations
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+func TestAlertingMigrationDryRun(t *testing.T) {
+	sqlStore := sqlstore.InitTestDB(t)
+
+	dsCmd := &models.AddDataSourceCommand{OrgId: 1, Name: "prometheus", Type: "prometheus", Access: models.DS_ACCESS_PROXY, Uid: "prom"}
+	require.NoError(t, sqlStore.AddDataSource(dsCmd))
+
+	for _, cmd := range []*models.CreateAlertNotificationCommand{
+		{OrgId: 1, Uid: "slack", Name: "Slack", Type: "slack", IsDefault: true, SendReminder: true, Frequency: "1h", Settings: simplejson.NewFromAny(map[string]interface{}{"url": "http://localhost"})},
+		{OrgId: 1, Uid: "email", Name: "Email", Type: "email", Settings: simplejson.NewFromAny(map[string]interface{}{"addresses": "alerts@example.com"})},
+		{OrgId: 1, Uid: "hipchat", Name: "HipChat", Type: "hipchat", Settings: simplejson.New()},
+		{OrgId: 1, Uid: "webhook", Name: "Webhook", Type: "webhook", Settings: simplejson.NewFromAny(map[string]interface{}{"url": "http://localhost"})},
+	} {
+		require.NoError(t, sqlStore.CreateAlertNotificationCommand(cmd))
+	}
+
+	dash, err := sqlStore.SaveDashboard(models.SaveDashboardCommand{
+		OrgId:     1,
+		Dashboard: simplejson.NewFromAny(map[string]interface{}{"title": "dashboard with alerts"}),
+	})
+	require.NoError(t, err)
+
+	alertSettings := func(dsID int64, reducer string, notifications string) *simplejson.Json {
+		settings, err := simplejson.NewJson([]byte(fmt.Sprintf(`{
+			"conditions": [{
+				"evaluator": {"params": [3], "type": "gt"},
+				"operator": {"type": "and"},
+				"query": {"params": ["A", "5m", "now"], "datasourceId": %d, "model": {"refId": "A", "expr": "up"}},
+				"reducer": {"type": %q}
+			}],
+			"noDataState": "no_data",
+			"executionErrorState": "alerting",
+			"notifications": %s
+		}`, dsID, reducer, notifications)))
+		require.NoError(t, err)
+		return settings
+	}
+	alerts := []*models.Alert{
+		{DashboardId: dash.Id, PanelId: 1, Name: "CPU", Frequency: 60, For: 5 * time.Minute, Settings: alertSettings(dsCmd.Result.Id, "avg", `[{"uid": "email"}]`)},
+		{DashboardId: dash.Id, PanelId: 2, Name: "Memory", Frequency: 15, State: models.AlertStatePaused, Settings: alertSettings(999, "unknown", `[{"uid": "hipchat"}, {"uid": "missing"}]`)},
+		{DashboardId: dash.Id, PanelId: 3, Name: "CPU", Frequency: 60, Settings: alertSettings(dsCmd.Result.Id, "max", `[]`)},
+		{DashboardId: 12345, PanelId: 1, Name: "Deleted dashboard", Frequency: 60, Settings: alertSettings(dsCmd.Result.Id, "avg", `[]`)},
+	}
+	err = sqlStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		for _, a := range alerts {
+			a.OrgId = 1
+			a.Created = time.Now()
+			a.Updated = time.Now()
+			a.NewStateDate = time.Now()
+			if _, err := sess.Insert(a); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	report, err := alertingMigrationDryRun(sqlStore)
+	require.NoError(t, err)
+
+	require.Len(t, report.Alerts, 4)
+
+	cpu := report.Alerts[0]
+	require.Equal(t, "CPU", cpu.Name)
+	require.Empty(t, cpu.Error)
+	require.Empty(t, cpu.Problems)
+	require.NotNil(t, cpu.Rule)
+	require.Equal(t, "CPU", cpu.Rule.Title)
+	require.Equal(t, "General Alerting", cpu.Rule.Folder)
+	require.True(t, cpu.Rule.NewFolder)
+	require.Equal(t, int64(60), cpu.Rule.IntervalSeconds)
+	require.Equal(t, "5m0s", cpu.Rule.For)
+	require.Equal(t, "NoData", cpu.Rule.NoDataState)
+	require.Equal(t, "Alerting", cpu.Rule.ExecErrState)
+	require.Equal(t, "autogen-contact-point-1", cpu.Rule.Receiver)
+
+	memory := report.Alerts[1]
+	require.Empty(t, memory.Error)
+	require.True(t, memory.Rule.Silenced)
+	require.Equal(t, int64(10), memory.Rule.IntervalSeconds)
+	require.Equal(t, "autogen-contact-point-2", memory.Rule.Receiver)
+	require.ElementsMatch(t, []string{
+		"the data source 999 of condition 1 does not exist",
+		`the reducer "unknown" of condition 1 is not supported`,
+		`the hipchat notification channel "HipChat" is discontinued and would not be notified`,
+		"the notification channel missing does not exist and would be ignored",
+		"the evaluation interval of 15s would be changed to 10s",
+	}, memory.Problems)
+
+	duplicate := report.Alerts[2]
+	require.Empty(t, duplicate.Error)
+	require.Len(t, duplicate.Problems, 1)
+	require.Contains(t, duplicate.Problems[0], `another alert in the folder "General Alerting" has the same name`)
+	require.NotEqual(t, "CPU", duplicate.Rule.Title)
+	require.Equal(t, "autogen-contact-point-default", duplicate.Rule.Receiver)
+
+	deleted := report.Alerts[3]
+	require.Nil(t, deleted.Rule)
+	require.Contains(t, deleted.Error, "not found")
+
+	require.Len(t, report.Channels, 4)
+	channels := make(map[string]struct {
+		receivers []string
+		problems  int
+	})
+	for _, c := range report.Channels {
+		channels[c.UID] = struct {
+			receivers []string
+			problems  int
+		}{c.Receivers, len(c.Problems)}
+	}
+	require.ElementsMatch(t, []string{"autogen-contact-point-default", "autogen-contact-point-1", "autogen-contact-point-2"}, channels["slack"].receivers)
+	require.Equal(t, 1, channels["slack"].problems)
+	require.Equal(t, []string{"autogen-contact-point-1"}, channels["email"].receivers)
+	require.Empty(t, channels["hipchat"].receivers)
+	require.Equal(t, 1, channels["hipchat"].problems)
+	require.Equal(t, []string{"autogen-unlinked-channel-recv"}, channels["webhook"].receivers)
+
+	// The dry run does not change the database.
+	err = sqlStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		rules, err := sess.Table("alert_rule").Count()
+		require.NoError(t, err)
+		require.Zero(t, rules)
+		folders, err := sess.Table("dashboard").Where("is_folder = ?", true).Count()
+		require.NoError(t, err)
+		require.Zero(t, folders)
+		return nil
+	})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	writeAlertingMigrationReport(&buf, report)
+	require.Contains(t, buf.String(), "4 alerts: 1 would fail the migration, 2 with problems")
+	require.Contains(t, buf.String(), "4 notification channels: 2 with problems")
+}
@@ -71,15 +71,17 @@ func (gm *MathCommand) Execute(ctx context.Context, vars mathexp.Vars) (mathexp.
 type ReduceCommand struct {
 	Reducer     string
 	VarToReduce string
+	Mode        mathexp.ReduceMode
 	refID       string
 }
 
 // NewReduceCommand creates a new ReduceCMD.
-func NewReduceCommand(refID, reducer, varToReduce string) *ReduceCommand {
+func NewReduceCommand(refID, reducer, varToReduce string, mode mathexp.ReduceMode) *ReduceCommand {
 	// TODO: validate reducer here, before execution
 	return &ReduceCommand{
 		Reducer:     reducer,
 		VarToReduce: varToReduce,
+		Mode:        mode,
 		refID:       refID,
 	}
 }
@@ -105,7 +107,26 @@ func UnmarshalReduceCommand(rn *rawNode) (*ReduceCommand, error) {
 		return nil, fmt.Errorf("expected reducer to be a string, got %T for refId %v", rawReducer, rn.RefID)
 	}
 
-	return NewReduceCommand(rn.RefID, redFunc, varToReduce), nil
+	mode := mathexp.ReduceModeStrict
+	if rawSettings, ok := rn.Query["settings"]; ok {
+		settings, ok := rawSettings.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected reduce settings to be an object, got %T for refId %v", rawSettings, rn.RefID)
+		}
+		if rawMode, ok := settings["mode"]; ok {
+			modeString, ok := rawMode.(string)
+			if !ok {
+				return nil, fmt.Errorf("expected reduce mode to be a string, got %T for refId %v", rawMode, rn.RefID)
+			}
+			var err error
+			mode, err = mathexp.ParseReduceMode(modeString)
+			if err != nil {
+				return nil, fmt.Errorf("invalid reduce settings for refId %v: %w", rn.RefID, err)
+			}
+		}
+	}
+
+	return NewReduceCommand(rn.RefID, redFunc, varToReduce, mode), nil
 }
 
 // NeedsVars returns the variable names (refIds) that are dependencies
@@ -123,7 +144,7 @@ func (gr *ReduceCommand) Execute(ctx context.Context, vars mathexp.Vars) (mathex
 		if !ok {
 			return newRes, fmt.Errorf("can only reduce type series, got type %v", val.Type())
 		}
-		num, err := series.Reduce(gr.refID, gr.Reducer)
+		num, err := series.Reduce(gr.refID, gr.Reducer, gr.Mode)
 		if err != nil {
 			return newRes, err
 		}
@@ -240,6 +261,10 @@ const (
 	TypeResample
 	// TypeClassicConditions is the CMDType for the classic condition operation.
 	TypeClassicConditions
+	// TypeThreshold is the CMDType for checking if a threshold has been crossed.
+	TypeThreshold
+	// TypeSQL is the CMDType for a SQL query over the results of other queries.
+	TypeSQL
 )
 
 func (gt CommandType) String() string {
@@ -252,6 +277,10 @@ func (gt CommandType) String() string {
 		return "resample"
 	case TypeClassicConditions:
 		return "classic_conditions"
+	case TypeThreshold:
+		return "threshold"
+	case TypeSQL:
+		return "sql"
 	default:
 		return "unknown"
 	}
@@ -268,6 +297,10 @@ func ParseCommandType(s string) (CommandType, error) {
 		return TypeResample, nil
 	case "classic_conditions":
 		return TypeClassicConditions, nil
+	case "threshold":
+		return TypeThreshold, nil
+	case "sql":
+		return TypeSQL, nil
 	default:
 		return TypeUnknown, fmt.Errorf("'%v' is not a recognized expression type", s)
 	}
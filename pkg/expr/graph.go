@@ -209,5 +209,28 @@ func buildGraphEdges(dp *simple.DirectedGraph, registry map[string]Node) error {
 			dp.SetEdge(edge)
 		}
 	}
+
+	// the results of a datasource node are kept as tables only when all the expressions
+	// using them are SQL expressions, the other expressions need time series
+	for _, node := range registry {
+		if dsNode, ok := node.(*DSNode); ok {
+			dsNode.sqlInput = onlySQLConsumers(dp, dsNode)
+		}
+	}
 	return nil
 }
+
+// onlySQLConsumers returns true if node is used, and only by SQL expressions.
+func onlySQLConsumers(dp *simple.DirectedGraph, node Node) bool {
+	consumers := dp.From(node.ID())
+	if consumers.Len() == 0 {
+		return false
+	}
+	for consumers.Next() {
+		cmdNode, ok := consumers.Node().(*CMDNode)
+		if !ok || cmdNode.CMDType != TypeSQL {
+			return false
+		}
+	}
+	return true
+}
@@ -1,7 +1,9 @@
 package mathexp
 
 import (
+	"fmt"
 	"math"
+	"time"
 
 	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
 )
@@ -17,6 +19,127 @@ var builtins = map[string]parse.Func{
 		VariantReturn: true,
 		F:             log,
 	},
+	"log10": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet},
+		VariantReturn: true,
+		F:             log10,
+	},
+	"log2": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet},
+		VariantReturn: true,
+		F:             log2,
+	},
+	"exp": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet},
+		VariantReturn: true,
+		F:             exp,
+	},
+	"sqrt": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet},
+		VariantReturn: true,
+		F:             sqrt,
+	},
+	"round": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet},
+		VariantReturn: true,
+		F:             round,
+	},
+	"ceil": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet},
+		VariantReturn: true,
+		F:             ceil,
+	},
+	"floor": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet},
+		VariantReturn: true,
+		F:             floor,
+	},
+	"sign": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet},
+		VariantReturn: true,
+		F:             sign,
+	},
+	"clamp": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeScalar, parse.TypeScalar},
+		VariantReturn: true,
+		F:             clamp,
+	},
+	"clamp_min": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeScalar},
+		VariantReturn: true,
+		F:             clampMin,
+	},
+	"clamp_max": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeScalar},
+		VariantReturn: true,
+		F:             clampMax,
+	},
+	"is_nan": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet},
+		VariantReturn: true,
+		F:             isNaN,
+	},
+	"is_inf": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet},
+		VariantReturn: true,
+		F:             isInf,
+	},
+	"is_null": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet},
+		VariantReturn: true,
+		F:             isNull,
+	},
+	"is_number": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet},
+		VariantReturn: true,
+		F:             isNumber,
+	},
+	"if": {
+		Args:              []parse.ReturnType{parse.TypeVariantSet, parse.TypeVariantSet, parse.TypeVariantSet},
+		VariantReturn:     true,
+		VariantReturnFunc: ifElseReturn,
+		F:                 ifElse,
+	},
+	"rate": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeString},
+		Return: parse.TypeSeriesSet,
+		F:      rate,
+	},
+	"increase": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeString},
+		Return: parse.TypeSeriesSet,
+		F:      increase,
+	},
+	"delta": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeString},
+		Return: parse.TypeSeriesSet,
+		F:      delta,
+	},
+	"deriv": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeString},
+		Return: parse.TypeSeriesSet,
+		F:      deriv,
+	},
+	"moving_avg": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeString},
+		Return: parse.TypeSeriesSet,
+		F:      movingAvg,
+	},
+	"shift": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeString},
+		Return: parse.TypeSeriesSet,
+		F:      shift,
+	},
+	"offset": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeString},
+		Return: parse.TypeSeriesSet,
+		F:      shift,
+	},
+	"cumulative_sum": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      cumulativeSum,
+	},
 	"nan": {
 		Return: parse.TypeScalar,
 		F:      nan,
@@ -57,6 +180,261 @@ func log(e *State, varSet Results) (Results, error) {
 	return newRes, nil
 }
 
+// log10 returns the base 10 logarithm value for each result in NumberSet, SeriesSet, or Scalar
+func log10(e *State, varSet Results) (Results, error) {
+	return perNullableFloatResults(e, varSet, nullPreserving(math.Log10))
+}
+
+// log2 returns the base 2 logarithm value for each result in NumberSet, SeriesSet, or Scalar
+func log2(e *State, varSet Results) (Results, error) {
+	return perNullableFloatResults(e, varSet, nullPreserving(math.Log2))
+}
+
+// exp returns e**x for each result in NumberSet, SeriesSet, or Scalar
+func exp(e *State, varSet Results) (Results, error) {
+	return perNullableFloatResults(e, varSet, nullPreserving(math.Exp))
+}
+
+// sqrt returns the square root for each result in NumberSet, SeriesSet, or Scalar
+func sqrt(e *State, varSet Results) (Results, error) {
+	return perNullableFloatResults(e, varSet, nullPreserving(math.Sqrt))
+}
+
+// round returns the nearest integer, rounding half away from zero, for each result
+// in NumberSet, SeriesSet, or Scalar
+func round(e *State, varSet Results) (Results, error) {
+	return perNullableFloatResults(e, varSet, nullPreserving(math.Round))
+}
+
+// ceil returns the least integer value greater than or equal to each result
+// in NumberSet, SeriesSet, or Scalar
+func ceil(e *State, varSet Results) (Results, error) {
+	return perNullableFloatResults(e, varSet, nullPreserving(math.Ceil))
+}
+
+// floor returns the greatest integer value less than or equal to each result
+// in NumberSet, SeriesSet, or Scalar
+func floor(e *State, varSet Results) (Results, error) {
+	return perNullableFloatResults(e, varSet, nullPreserving(math.Floor))
+}
+
+// sign returns -1, 0 or 1 depending on the sign of each result in NumberSet, SeriesSet, or Scalar
+func sign(e *State, varSet Results) (Results, error) {
+	return perNullableFloatResults(e, varSet, nullPreserving(func(f float64) float64 {
+		switch {
+		case f > 0:
+			return 1
+		case f < 0:
+			return -1
+		default:
+			return f // keeps 0, -0 and NaN as they are
+		}
+	}))
+}
+
+// clamp limits each result in NumberSet, SeriesSet, or Scalar to be between min and max
+func clamp(e *State, varSet, minSet, maxSet Results) (Results, error) {
+	min, err := scalarArg("clamp", minSet)
+	if err != nil {
+		return Results{}, err
+	}
+	max, err := scalarArg("clamp", maxSet)
+	if err != nil {
+		return Results{}, err
+	}
+	if min > max {
+		return Results{}, fmt.Errorf("clamp: min (%v) must not be greater than max (%v)", min, max)
+	}
+	return perNullableFloatResults(e, varSet, nullPreserving(func(f float64) float64 {
+		return math.Max(min, math.Min(max, f))
+	}))
+}
+
+// clampMin limits each result in NumberSet, SeriesSet, or Scalar to be no less than min
+func clampMin(e *State, varSet, minSet Results) (Results, error) {
+	min, err := scalarArg("clamp_min", minSet)
+	if err != nil {
+		return Results{}, err
+	}
+	return perNullableFloatResults(e, varSet, nullPreserving(func(f float64) float64 {
+		return math.Max(min, f)
+	}))
+}
+
+// clampMax limits each result in NumberSet, SeriesSet, or Scalar to be no greater than max
+func clampMax(e *State, varSet, maxSet Results) (Results, error) {
+	max, err := scalarArg("clamp_max", maxSet)
+	if err != nil {
+		return Results{}, err
+	}
+	return perNullableFloatResults(e, varSet, nullPreserving(func(f float64) float64 {
+		return math.Min(max, f)
+	}))
+}
+
+// isNaN returns 1 for each result in NumberSet, SeriesSet, or Scalar that is NaN, otherwise 0.
+// Null values are not NaN.
+func isNaN(e *State, varSet Results) (Results, error) {
+	return perNullableFloatResults(e, varSet, func(f *float64) *float64 {
+		return boolToFloatPointer(f != nil && math.IsNaN(*f))
+	})
+}
+
+// isInf returns 1 for each result in NumberSet, SeriesSet, or Scalar that is positive or negative infinity, otherwise 0.
+func isInf(e *State, varSet Results) (Results, error) {
+	return perNullableFloatResults(e, varSet, func(f *float64) *float64 {
+		return boolToFloatPointer(f != nil && math.IsInf(*f, 0))
+	})
+}
+
+// isNull returns 1 for each result in NumberSet, SeriesSet, or Scalar that is null, otherwise 0.
+// NaN values are not null.
+func isNull(e *State, varSet Results) (Results, error) {
+	return perNullableFloatResults(e, varSet, func(f *float64) *float64 {
+		return boolToFloatPointer(f == nil)
+	})
+}
+
+// isNumber returns 1 for each result in NumberSet, SeriesSet, or Scalar that is a real number
+// (not null, NaN or infinity), otherwise 0.
+func isNumber(e *State, varSet Results) (Results, error) {
+	return perNullableFloatResults(e, varSet, func(f *float64) *float64 {
+		return boolToFloatPointer(f != nil && !math.IsNaN(*f) && !math.IsInf(*f, 0))
+	})
+}
+
+// ifElse returns the value of thenSet where condSet is non-zero and the value of elseSet where
+// condSet is zero. If the condition is null or NaN, the result is null or NaN respectively.
+//
+// When condSet is a Scalar, the whole of thenSet or elseSet is returned. If the value that is
+// returned is a Scalar and the other one is not, the Scalar is used for every item of the other
+// one, so that the type of the result doesn't depend on the condition. Otherwise, thenSet and
+// elseSet must either hold a single value, which is used for every item in condSet, or hold
+// values with the same labels as the items in condSet. Series are matched by time. Items or
+// points that have no match are null.
+func ifElse(e *State, condSet, thenSet, elseSet Results) (Results, error) {
+	newRes := Results{}
+	for _, cond := range condSet.Values {
+		if cond.Type() == parse.TypeScalar {
+			f := cond.(Scalar).GetFloat64Value()
+			res, other := thenSet, elseSet
+			switch {
+			case f == nil || math.IsNaN(*f):
+				if isScalarResults(res) {
+					res = other
+				}
+				return perNullableFloatResults(e, res, func(*float64) *float64 { return f })
+			case *f == 0:
+				res, other = elseSet, thenSet
+			}
+			if !isScalarResults(res) || isScalarResults(other) {
+				return res, nil
+			}
+			// The scalar is used for every item of the other value, so that the result
+			// has the type of ifElseReturn whichever value is picked.
+			v := res.Values[0].(Scalar).GetFloat64Value()
+			return perNullableFloatResults(e, other, func(*float64) *float64 { return v })
+		}
+
+		thenLookup, err := newValueLookup(cond, thenSet)
+		if err != nil {
+			return newRes, err
+		}
+		elseLookup, err := newValueLookup(cond, elseSet)
+		if err != nil {
+			return newRes, err
+		}
+		pick := func(t time.Time, c *float64) *float64 {
+			switch {
+			case c == nil || math.IsNaN(*c):
+				return c
+			case *c != 0:
+				return thenLookup(t)
+			default:
+				return elseLookup(t)
+			}
+		}
+
+		switch c := cond.(type) {
+		case Number:
+			n := NewNumber(e.RefID, c.GetLabels())
+			n.SetValue(pick(time.Time{}, c.GetFloat64Value()))
+			newRes.Values = append(newRes.Values, n)
+		case Series:
+			s := NewSeries(e.RefID, c.GetLabels(), c.Len())
+			for i := 0; i < c.Len(); i++ {
+				t, f := c.GetPoint(i)
+				if err := s.SetPoint(i, t, pick(t, f)); err != nil {
+					return newRes, err
+				}
+			}
+			newRes.Values = append(newRes.Values, s)
+		default:
+			return newRes, fmt.Errorf("if: unsupported condition type %v", cond.Type())
+		}
+	}
+	return newRes, nil
+}
+
+// ifElseReturn returns the type of if. It is the type of the condition, unless the
+// condition is a Scalar, in which case it is the type of the then and else values.
+func ifElseReturn(args []parse.Node) parse.ReturnType {
+	if rt := args[0].Return(); rt != parse.TypeScalar {
+		return rt
+	}
+	if rt := args[1].Return(); rt != parse.TypeScalar {
+		return rt
+	}
+	return args[2].Return()
+}
+
+func isScalarResults(res Results) bool {
+	return len(res.Values) == 1 && res.Values[0].Type() == parse.TypeScalar
+}
+
+// valueLookup returns the value that corresponds to the time t.
+// Values that are not series ignore t.
+type valueLookup func(t time.Time) *float64
+
+// newValueLookup finds the value in res that matches the labels of cond, and returns
+// a valueLookup to retrieve its values.
+func newValueLookup(cond Value, res Results) (valueLookup, error) {
+	var match Value
+	if len(res.Values) == 1 {
+		match = res.Values[0]
+	} else {
+		for _, v := range res.Values {
+			if v.GetLabels().Equals(cond.GetLabels()) {
+				match = v
+				break
+			}
+		}
+	}
+
+	switch m := match.(type) {
+	case nil:
+		return func(time.Time) *float64 { return nil }, nil
+	case Scalar:
+		f := m.GetFloat64Value()
+		return func(time.Time) *float64 { return f }, nil
+	case Number:
+		f := m.GetFloat64Value()
+		return func(time.Time) *float64 { return f }, nil
+	case Series:
+		if cond.Type() != parse.TypeSeriesSet {
+			return nil, fmt.Errorf("if: can not select a series for a condition of type %v", cond.Type())
+		}
+		points := make(map[string]*float64, m.Len())
+		for i := 0; i < m.Len(); i++ {
+			t, f := m.GetPoint(i)
+			points[t.UTC().String()] = f
+		}
+		return func(t time.Time) *float64 { return points[t.UTC().String()] }, nil
+	default:
+		return nil, fmt.Errorf("if: unsupported value type %v", match.Type())
+	}
+}
+
 // nan returns a scalar nan value
 func nan(e *State) Results {
 	aNaN := math.NaN()
@@ -74,6 +452,74 @@ func null(e *State) Results {
 	return NewScalarResults(e.RefID, nil)
 }
 
+// scalarArg returns the float value of a Scalar function argument.
+func scalarArg(funcName string, res Results) (float64, error) {
+	if len(res.Values) != 1 || res.Values[0].Type() != parse.TypeScalar {
+		return 0, fmt.Errorf("%s: expected a single scalar argument", funcName)
+	}
+	f := res.Values[0].(Scalar).GetFloat64Value()
+	if f == nil {
+		return 0, fmt.Errorf("%s: scalar argument must not be null", funcName)
+	}
+	return *f, nil
+}
+
+// nullPreserving wraps floatF so that null values stay null.
+func nullPreserving(floatF func(x float64) float64) func(f *float64) *float64 {
+	return func(f *float64) *float64 {
+		if f == nil {
+			return nil
+		}
+		nF := floatF(*f)
+		return &nF
+	}
+}
+
+func boolToFloatPointer(b bool) *float64 {
+	f := 0.0
+	if b {
+		f = 1
+	}
+	return &f
+}
+
+// perNullableFloatResults calls perNullableFloat for each value in varSet.
+func perNullableFloatResults(e *State, varSet Results, floatF func(f *float64) *float64) (Results, error) {
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		newVal, err := perNullableFloat(e, res, floatF)
+		if err != nil {
+			return newRes, err
+		}
+		newRes.Values = append(newRes.Values, newVal)
+	}
+	return newRes, nil
+}
+
+// perNullableFloat is like perFloat, but floatF is also called for null values and may return null.
+func perNullableFloat(e *State, val Value, floatF func(f *float64) *float64) (Value, error) {
+	switch val.Type() {
+	case parse.TypeNumberSet:
+		n := NewNumber(e.RefID, val.GetLabels())
+		n.SetValue(floatF(val.(Number).GetFloat64Value()))
+		return n, nil
+	case parse.TypeScalar:
+		return NewScalar(e.RefID, floatF(val.(Scalar).GetFloat64Value())), nil
+	case parse.TypeSeriesSet:
+		resSeries := val.(Series)
+		newSeries := NewSeries(e.RefID, resSeries.GetLabels(), resSeries.Len())
+		for i := 0; i < resSeries.Len(); i++ {
+			t, f := resSeries.GetPoint(i)
+			if err := newSeries.SetPoint(i, t, floatF(f)); err != nil {
+				return newSeries, err
+			}
+		}
+		return newSeries, nil
+	default:
+		return nil, fmt.Errorf("can not apply a function to type %v", val.Type())
+	}
+}
+
 func perFloat(e *State, val Value, floatF func(x float64) float64) (Value, error) {
 	var newVal Value
 	switch val.Type() {
@@ -0,0 +1,212 @@
+package mathexp
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+)
+
+// timePoint is a non-null, non-NaN point of a Series.
+type timePoint struct {
+	t time.Time
+	f float64
+}
+
+// rate returns the per-second average rate of increase of each series in SeriesSet over the
+// trailing window. Decreases in value are treated as counter resets.
+func rate(e *State, varSet Results, rawWindow string) (Results, error) {
+	return perSeriesWindow(e, "rate", varSet, rawWindow, func(points []timePoint) *float64 {
+		if len(points) < 2 {
+			return nil
+		}
+		elapsed := points[len(points)-1].t.Sub(points[0].t).Seconds()
+		if elapsed == 0 {
+			return nil
+		}
+		f := counterIncrease(points) / elapsed
+		return &f
+	})
+}
+
+// increase returns the increase of each series in SeriesSet over the trailing window.
+// Decreases in value are treated as counter resets.
+func increase(e *State, varSet Results, rawWindow string) (Results, error) {
+	return perSeriesWindow(e, "increase", varSet, rawWindow, func(points []timePoint) *float64 {
+		if len(points) < 2 {
+			return nil
+		}
+		f := counterIncrease(points)
+		return &f
+	})
+}
+
+// delta returns the difference between the last and the first value of each series in SeriesSet
+// over the trailing window.
+func delta(e *State, varSet Results, rawWindow string) (Results, error) {
+	return perSeriesWindow(e, "delta", varSet, rawWindow, func(points []timePoint) *float64 {
+		if len(points) < 2 {
+			return nil
+		}
+		f := points[len(points)-1].f - points[0].f
+		return &f
+	})
+}
+
+// deriv returns the per-second derivative of each series in SeriesSet over the trailing window,
+// using simple linear regression.
+func deriv(e *State, varSet Results, rawWindow string) (Results, error) {
+	return perSeriesWindow(e, "deriv", varSet, rawWindow, func(points []timePoint) *float64 {
+		if len(points) < 2 {
+			return nil
+		}
+		// Times are relative to the first point to keep precision.
+		var sumX, sumY, sumXY, sumX2 float64
+		for _, p := range points {
+			x := p.t.Sub(points[0].t).Seconds()
+			sumX += x
+			sumY += p.f
+			sumXY += x * p.f
+			sumX2 += x * x
+		}
+		n := float64(len(points))
+		denominator := n*sumX2 - sumX*sumX
+		if denominator == 0 {
+			return nil
+		}
+		f := (n*sumXY - sumX*sumY) / denominator
+		return &f
+	})
+}
+
+// movingAvg returns the mean of each series in SeriesSet over the trailing window.
+func movingAvg(e *State, varSet Results, rawWindow string) (Results, error) {
+	return perSeriesWindow(e, "moving_avg", varSet, rawWindow, func(points []timePoint) *float64 {
+		if len(points) == 0 {
+			return nil
+		}
+		var sum float64
+		for _, p := range points {
+			sum += p.f
+		}
+		f := sum / float64(len(points))
+		return &f
+	})
+}
+
+// shift moves each point of each series in SeriesSet forward in time by the given duration.
+// A negative duration moves the points backward.
+func shift(e *State, varSet Results, rawDuration string) (Results, error) {
+	newRes := Results{}
+	d, err := gtime.ParseDuration(rawDuration)
+	if err != nil {
+		return newRes, fmt.Errorf("shift: failed to parse duration %q: %w", rawDuration, err)
+	}
+	for _, val := range varSet.Values {
+		s, ok := val.(Series)
+		if !ok {
+			return newRes, fmt.Errorf("shift: expected series, got type %v", val.Type())
+		}
+		newSeries := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		for i := 0; i < s.Len(); i++ {
+			t, f := s.GetPoint(i)
+			if err := newSeries.SetPoint(i, t.Add(d), f); err != nil {
+				return newRes, err
+			}
+		}
+		newRes.Values = append(newRes.Values, newSeries)
+	}
+	return newRes, nil
+}
+
+// cumulativeSum returns the running total of each series in SeriesSet. Null and NaN points
+// are kept as they are and do not contribute to the total.
+func cumulativeSum(e *State, varSet Results) (Results, error) {
+	newRes := Results{}
+	for _, val := range varSet.Values {
+		s, ok := val.(Series)
+		if !ok {
+			return newRes, fmt.Errorf("cumulative_sum: expected series, got type %v", val.Type())
+		}
+		s = sortedSeriesCopy(e.RefID, s)
+		newSeries := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		var sum float64
+		for i := 0; i < s.Len(); i++ {
+			t, f := s.GetPoint(i)
+			if f != nil && !math.IsNaN(*f) {
+				sum += *f
+				total := sum
+				f = &total
+			}
+			if err := newSeries.SetPoint(i, t, f); err != nil {
+				return newRes, err
+			}
+		}
+		newRes.Values = append(newRes.Values, newSeries)
+	}
+	return newRes, nil
+}
+
+// perSeriesWindow calls windowF for each point of each series in varSet with the non-null,
+// non-NaN points within the window that ends at, and includes, that point.
+func perSeriesWindow(e *State, funcName string, varSet Results, rawWindow string, windowF func(points []timePoint) *float64) (Results, error) {
+	newRes := Results{}
+	window, err := gtime.ParseDuration(rawWindow)
+	if err != nil {
+		return newRes, fmt.Errorf("%s: failed to parse window %q: %w", funcName, rawWindow, err)
+	}
+	if window <= 0 {
+		return newRes, fmt.Errorf("%s: window must be positive, got %v", funcName, window)
+	}
+	for _, val := range varSet.Values {
+		s, ok := val.(Series)
+		if !ok {
+			return newRes, fmt.Errorf("%s: expected series, got type %v", funcName, val.Type())
+		}
+		s = sortedSeriesCopy(e.RefID, s)
+
+		points := make([]timePoint, 0, s.Len())
+		newSeries := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		start := 0
+		for i := 0; i < s.Len(); i++ {
+			t, f := s.GetPoint(i)
+			if f != nil && !math.IsNaN(*f) {
+				points = append(points, timePoint{t: t, f: *f})
+			}
+			for start < len(points) && !points[start].t.After(t.Add(-window)) {
+				start++
+			}
+			if err := newSeries.SetPoint(i, t, windowF(points[start:])); err != nil {
+				return newRes, err
+			}
+		}
+		newRes.Values = append(newRes.Values, newSeries)
+	}
+	return newRes, nil
+}
+
+// counterIncrease returns the increase between the points, treating any
+// decrease as a counter reset to zero.
+func counterIncrease(points []timePoint) float64 {
+	var inc float64
+	for i := 1; i < len(points); i++ {
+		if points[i].f >= points[i-1].f {
+			inc += points[i].f - points[i-1].f
+		} else {
+			inc += points[i].f
+		}
+	}
+	return inc
+}
+
+// sortedSeriesCopy returns a copy of s sorted by time from oldest to newest.
+func sortedSeriesCopy(refID string, s Series) Series {
+	newSeries := NewSeries(refID, s.GetLabels(), s.Len())
+	for i := 0; i < s.Len(); i++ {
+		t, f := s.GetPoint(i)
+		_ = newSeries.SetPoint(i, t, f)
+	}
+	newSeries.SortByTime(false)
+	return newSeries
+}
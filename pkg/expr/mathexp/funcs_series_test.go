@@ -0,0 +1,260 @@
+package mathexp
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeriesFunc(t *testing.T) {
+	counter := Vars{
+		"A": Results{
+			[]Value{
+				makeSeries("", data.Labels{"job": "api"}, tp{
+					time.Unix(0, 0), float64Pointer(10),
+				}, tp{
+					time.Unix(10, 0), float64Pointer(20),
+				}, tp{
+					time.Unix(20, 0), float64Pointer(40),
+				}, tp{
+					time.Unix(30, 0), float64Pointer(5), // counter reset
+				}, tp{
+					time.Unix(40, 0), nil,
+				}),
+			},
+		},
+	}
+
+	var tests = []struct {
+		name      string
+		expr      string
+		vars      Vars
+		newErrIs  assert.ErrorAssertionFunc
+		execErrIs assert.ErrorAssertionFunc
+		results   Results
+	}{
+		{
+			name:      "increase handles counter resets",
+			expr:      `increase($A, "20s")`,
+			vars:      counter,
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			results: Results{
+				[]Value{
+					makeSeries("", data.Labels{"job": "api"}, tp{
+						time.Unix(0, 0), nil,
+					}, tp{
+						time.Unix(10, 0), float64Pointer(10),
+					}, tp{
+						time.Unix(20, 0), float64Pointer(20),
+					}, tp{
+						time.Unix(30, 0), float64Pointer(5),
+					}, tp{
+						time.Unix(40, 0), nil,
+					}),
+				},
+			},
+		},
+		{
+			name:      "rate is per second",
+			expr:      `rate($A, "30s")`,
+			vars:      counter,
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			results: Results{
+				[]Value{
+					makeSeries("", data.Labels{"job": "api"}, tp{
+						time.Unix(0, 0), nil,
+					}, tp{
+						time.Unix(10, 0), float64Pointer(1),
+					}, tp{
+						time.Unix(20, 0), float64Pointer(1.5),
+					}, tp{
+						time.Unix(30, 0), float64Pointer(1.25),
+					}, tp{
+						time.Unix(40, 0), float64Pointer(0.5),
+					}),
+				},
+			},
+		},
+		{
+			name:      "delta does not handle counter resets",
+			expr:      `delta($A, "20s")`,
+			vars:      counter,
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			results: Results{
+				[]Value{
+					makeSeries("", data.Labels{"job": "api"}, tp{
+						time.Unix(0, 0), nil,
+					}, tp{
+						time.Unix(10, 0), float64Pointer(10),
+					}, tp{
+						time.Unix(20, 0), float64Pointer(20),
+					}, tp{
+						time.Unix(30, 0), float64Pointer(-35),
+					}, tp{
+						time.Unix(40, 0), nil,
+					}),
+				},
+			},
+		},
+		{
+			name: "deriv of a line",
+			expr: `deriv($A, "1m")`,
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeSeries("", nil, tp{
+							time.Unix(0, 0), float64Pointer(0),
+						}, tp{
+							time.Unix(10, 0), float64Pointer(5),
+						}, tp{
+							time.Unix(20, 0), float64Pointer(10),
+						}),
+					},
+				},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			results: Results{
+				[]Value{
+					makeSeries("", nil, tp{
+						time.Unix(0, 0), nil,
+					}, tp{
+						time.Unix(10, 0), float64Pointer(0.5),
+					}, tp{
+						time.Unix(20, 0), float64Pointer(0.5),
+					}),
+				},
+			},
+		},
+		{
+			name:      "moving_avg skips null values",
+			expr:      `moving_avg($A, "20s")`,
+			vars:      counter,
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			results: Results{
+				[]Value{
+					makeSeries("", data.Labels{"job": "api"}, tp{
+						time.Unix(0, 0), float64Pointer(10),
+					}, tp{
+						time.Unix(10, 0), float64Pointer(15),
+					}, tp{
+						time.Unix(20, 0), float64Pointer(30),
+					}, tp{
+						time.Unix(30, 0), float64Pointer(22.5),
+					}, tp{
+						time.Unix(40, 0), float64Pointer(5),
+					}),
+				},
+			},
+		},
+		{
+			name: "shift moves points in time",
+			expr: `shift($A, "1h")`,
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeSeries("", nil, tp{
+							time.Unix(0, 0), float64Pointer(1),
+						}, tp{
+							time.Unix(10, 0), nil,
+						}),
+					},
+				},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			results: Results{
+				[]Value{
+					makeSeries("", nil, tp{
+						time.Unix(3600, 0), float64Pointer(1),
+					}, tp{
+						time.Unix(3610, 0), nil,
+					}),
+				},
+			},
+		},
+		{
+			name: "cumulative_sum keeps null and NaN points",
+			expr: `cumulative_sum($A)`,
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeSeries("", nil, tp{
+							time.Unix(0, 0), float64Pointer(1),
+						}, tp{
+							time.Unix(10, 0), nil,
+						}, tp{
+							time.Unix(20, 0), NaN,
+						}, tp{
+							time.Unix(30, 0), float64Pointer(2),
+						}),
+					},
+				},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			results: Results{
+				[]Value{
+					makeSeries("", nil, tp{
+						time.Unix(0, 0), float64Pointer(1),
+					}, tp{
+						time.Unix(10, 0), nil,
+					}, tp{
+						time.Unix(20, 0), NaN,
+					}, tp{
+						time.Unix(30, 0), float64Pointer(3),
+					}),
+				},
+			},
+		},
+		{
+			name:      "rate with invalid window - should error",
+			expr:      `rate($A, "abc")`,
+			vars:      counter,
+			newErrIs:  assert.NoError,
+			execErrIs: assert.Error,
+			results:   Results{},
+		},
+		{
+			name:      "rate on number - should error",
+			expr:      `rate($A, "1m")`,
+			vars:      Vars{"A": Results{[]Value{makeNumber("", nil, float64Pointer(1))}}},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.Error,
+			results:   Results{},
+		},
+		{
+			name:     "rate without window - should error",
+			expr:     `rate($A)`,
+			vars:     counter,
+			newErrIs: assert.Error,
+		},
+	}
+
+	opt := cmp.Comparer(func(x, y float64) bool {
+		return (math.IsNaN(x) && math.IsNaN(y)) || x == y
+	})
+	options := append([]cmp.Option{opt}, data.FrameTestCompareOptions()...)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			tt.newErrIs(t, err)
+			if e != nil {
+				res, err := e.Execute("", tt.vars)
+				tt.execErrIs(t, err)
+				if diff := cmp.Diff(tt.results, res, options...); diff != "" {
+					t.Errorf("Result mismatch (-want +got):\n%s", diff)
+				}
+			}
+		})
+	}
+}
@@ -1,10 +1,15 @@
 package mathexp
 
 import (
+	"math"
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestFunc(t *testing.T) {
@@ -74,6 +79,279 @@ func TestFunc(t *testing.T) {
 			vars:     Vars{},
 			newErrIs: assert.Error,
 		},
+		{
+			name:      "round on scalar",
+			expr:      "round(2.5)",
+			vars:      Vars{},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results:   Results{[]Value{NewScalar("", float64Pointer(3))}},
+		},
+		{
+			name: "ceil and floor on number",
+			expr: "ceil($A) + floor($A)",
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeNumber("", nil, float64Pointer(1.5)),
+					},
+				},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results:   Results{[]Value{makeNumber("", nil, float64Pointer(3))}},
+		},
+		{
+			name:      "sqrt and exp on scalar",
+			expr:      "sqrt(16) + exp(0)",
+			vars:      Vars{},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results:   Results{[]Value{NewScalar("", float64Pointer(5))}},
+		},
+		{
+			name:      "log10 and log2 on scalar",
+			expr:      "log10(100) + log2(8)",
+			vars:      Vars{},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results:   Results{[]Value{NewScalar("", float64Pointer(5))}},
+		},
+		{
+			name: "sign on series",
+			expr: "sign($A)",
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeSeries("", nil, tp{
+							time.Unix(5, 0), float64Pointer(-2),
+						}, tp{
+							time.Unix(10, 0), float64Pointer(0),
+						}, tp{
+							time.Unix(15, 0), float64Pointer(7),
+						}),
+					},
+				},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results: Results{
+				[]Value{
+					makeSeries("", nil, tp{
+						time.Unix(5, 0), float64Pointer(-1),
+					}, tp{
+						time.Unix(10, 0), float64Pointer(0),
+					}, tp{
+						time.Unix(15, 0), float64Pointer(1),
+					}),
+				},
+			},
+		},
+		{
+			name: "clamp on series",
+			expr: "clamp($A, 0, 10)",
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeSeries("", data.Labels{"host": "a"}, tp{
+							time.Unix(5, 0), float64Pointer(-2),
+						}, tp{
+							time.Unix(10, 0), float64Pointer(5),
+						}, tp{
+							time.Unix(15, 0), float64Pointer(12),
+						}),
+					},
+				},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results: Results{
+				[]Value{
+					makeSeries("", data.Labels{"host": "a"}, tp{
+						time.Unix(5, 0), float64Pointer(0),
+					}, tp{
+						time.Unix(10, 0), float64Pointer(5),
+					}, tp{
+						time.Unix(15, 0), float64Pointer(10),
+					}),
+				},
+			},
+		},
+		{
+			name: "clamp_min and clamp_max on number",
+			expr: "clamp_min($A, 0) + clamp_max($A, -5)",
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeNumber("", nil, float64Pointer(-2)),
+					},
+				},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results:   Results{[]Value{makeNumber("", nil, float64Pointer(-5))}},
+		},
+		{
+			name:      "clamp with min greater than max - should error",
+			expr:      "clamp(5, 10, 0)",
+			vars:      Vars{},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.Error,
+			resultIs:  assert.Equal,
+			results:   Results{},
+		},
+		{
+			name:     "clamp with series bound - should error",
+			expr:     "clamp($A, $A, 10)",
+			vars:     Vars{},
+			newErrIs: assert.Error,
+		},
+		{
+			name: "if on series selects between series and scalar",
+			expr: "if($A > 5, $A, 0)",
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeSeries("", nil, tp{
+							time.Unix(5, 0), float64Pointer(2),
+						}, tp{
+							time.Unix(10, 0), float64Pointer(7),
+						}, tp{
+							time.Unix(15, 0), nil,
+						}),
+					},
+				},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results: Results{
+				[]Value{
+					makeSeries("", nil, tp{
+						time.Unix(5, 0), float64Pointer(0),
+					}, tp{
+						time.Unix(10, 0), float64Pointer(7),
+					}, tp{
+						time.Unix(15, 0), nil,
+					}),
+				},
+			},
+		},
+		{
+			name: "if on numbers matches by labels",
+			expr: "if($A, $B, -1)",
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeNumber("", data.Labels{"host": "a"}, float64Pointer(1)),
+						makeNumber("", data.Labels{"host": "b"}, float64Pointer(0)),
+					},
+				},
+				"B": Results{
+					[]Value{
+						makeNumber("", data.Labels{"host": "b"}, float64Pointer(20)),
+						makeNumber("", data.Labels{"host": "a"}, float64Pointer(10)),
+					},
+				},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results: Results{
+				[]Value{
+					makeNumber("", data.Labels{"host": "a"}, float64Pointer(10)),
+					makeNumber("", data.Labels{"host": "b"}, float64Pointer(-1)),
+				},
+			},
+		},
+		{
+			name: "if with scalar condition returns whole result",
+			expr: "if(1, $A, 0)",
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeNumber("", data.Labels{"host": "a"}, float64Pointer(3)),
+					},
+				},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results: Results{
+				[]Value{
+					makeNumber("", data.Labels{"host": "a"}, float64Pointer(3)),
+				},
+			},
+		},
+		{
+			name: "if with scalar condition uses scalar for every item of the other value",
+			expr: "if(0, $A, 5)",
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeNumber("", data.Labels{"host": "a"}, float64Pointer(3)),
+					},
+				},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results: Results{
+				[]Value{
+					makeNumber("", data.Labels{"host": "a"}, float64Pointer(5)),
+				},
+			},
+		},
+		{
+			name: "if with null scalar condition returns null for every item",
+			expr: "if(null(), 1, $A)",
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeNumber("", data.Labels{"host": "a"}, float64Pointer(3)),
+					},
+				},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results: Results{
+				[]Value{
+					makeNumber("", data.Labels{"host": "a"}, nil),
+				},
+			},
+		},
+		{
+			name:     "if with wrong number of arguments - should error",
+			expr:     "if($A, 1)",
+			vars:     Vars{},
+			newErrIs: assert.Error,
+		},
+		{
+			name:     "func with empty argument - should error",
+			expr:     "clamp($A,,1)",
+			vars:     Vars{},
+			newErrIs: assert.Error,
+		},
+		{
+			name:     "func with trailing comma - should error",
+			expr:     "clamp_min($A, 1,)",
+			vars:     Vars{},
+			newErrIs: assert.Error,
+		},
+		{
+			name:     "func with missing comma - should error",
+			expr:     "clamp_min($A 1)",
+			vars:     Vars{},
+			newErrIs: assert.Error,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -87,3 +365,180 @@ func TestFunc(t *testing.T) {
 		})
 	}
 }
+
+func TestIfReturnType(t *testing.T) {
+	var tests = []struct {
+		expr string
+		rt   parse.ReturnType
+	}{
+		{"if($A, 1, 0)", parse.TypeSeriesSet},
+		{"if(1, $A, 0)", parse.TypeSeriesSet},
+		{"if(1, 0, $A)", parse.TypeSeriesSet},
+		{"if(1, 2, 3)", parse.TypeScalar},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			e, err := New(tt.expr)
+			require.NoError(t, err)
+			require.Equal(t, tt.rt, e.Tree.Root.Return())
+		})
+	}
+}
+
+func TestFuncNaNAndNull(t *testing.T) {
+	series := Vars{
+		"A": Results{
+			[]Value{
+				makeSeries("", nil, tp{
+					time.Unix(5, 0), float64Pointer(1.4),
+				}, tp{
+					time.Unix(10, 0), NaN,
+				}, tp{
+					time.Unix(15, 0), nil,
+				}, tp{
+					time.Unix(20, 0), float64Pointer(math.Inf(1)),
+				}),
+			},
+		},
+	}
+	var tests = []struct {
+		name    string
+		expr    string
+		vars    Vars
+		results Results
+	}{
+		{
+			name: "round keeps NaN and null",
+			expr: "round($A)",
+			vars: series,
+			results: Results{
+				[]Value{
+					makeSeries("", nil, tp{
+						time.Unix(5, 0), float64Pointer(1),
+					}, tp{
+						time.Unix(10, 0), NaN,
+					}, tp{
+						time.Unix(15, 0), nil,
+					}, tp{
+						time.Unix(20, 0), float64Pointer(math.Inf(1)),
+					}),
+				},
+			},
+		},
+		{
+			name: "is_nan",
+			expr: "is_nan($A)",
+			vars: series,
+			results: Results{
+				[]Value{
+					makeSeries("", nil, tp{
+						time.Unix(5, 0), float64Pointer(0),
+					}, tp{
+						time.Unix(10, 0), float64Pointer(1),
+					}, tp{
+						time.Unix(15, 0), float64Pointer(0),
+					}, tp{
+						time.Unix(20, 0), float64Pointer(0),
+					}),
+				},
+			},
+		},
+		{
+			name: "is_null",
+			expr: "is_null($A)",
+			vars: series,
+			results: Results{
+				[]Value{
+					makeSeries("", nil, tp{
+						time.Unix(5, 0), float64Pointer(0),
+					}, tp{
+						time.Unix(10, 0), float64Pointer(0),
+					}, tp{
+						time.Unix(15, 0), float64Pointer(1),
+					}, tp{
+						time.Unix(20, 0), float64Pointer(0),
+					}),
+				},
+			},
+		},
+		{
+			name: "is_inf",
+			expr: "is_inf($A)",
+			vars: series,
+			results: Results{
+				[]Value{
+					makeSeries("", nil, tp{
+						time.Unix(5, 0), float64Pointer(0),
+					}, tp{
+						time.Unix(10, 0), float64Pointer(0),
+					}, tp{
+						time.Unix(15, 0), float64Pointer(0),
+					}, tp{
+						time.Unix(20, 0), float64Pointer(1),
+					}),
+				},
+			},
+		},
+		{
+			name: "is_number",
+			expr: "is_number($A)",
+			vars: series,
+			results: Results{
+				[]Value{
+					makeSeries("", nil, tp{
+						time.Unix(5, 0), float64Pointer(1),
+					}, tp{
+						time.Unix(10, 0), float64Pointer(0),
+					}, tp{
+						time.Unix(15, 0), float64Pointer(0),
+					}, tp{
+						time.Unix(20, 0), float64Pointer(0),
+					}),
+				},
+			},
+		},
+		{
+			name: "if propagates NaN and null conditions",
+			expr: "if($A, 1, 0)",
+			vars: series,
+			results: Results{
+				[]Value{
+					makeSeries("", nil, tp{
+						time.Unix(5, 0), float64Pointer(1),
+					}, tp{
+						time.Unix(10, 0), NaN,
+					}, tp{
+						time.Unix(15, 0), nil,
+					}, tp{
+						time.Unix(20, 0), float64Pointer(1),
+					}),
+				},
+			},
+		},
+		{
+			name:    "is_null on null scalar",
+			expr:    "is_null(null())",
+			vars:    Vars{},
+			results: Results{[]Value{NewScalar("", float64Pointer(1))}},
+		},
+	}
+
+	// go-cmp instead of testify assert is used to compare results here
+	// because it supports an option for NaN equality.
+	opt := cmp.Comparer(func(x, y float64) bool {
+		return (math.IsNaN(x) && math.IsNaN(y)) || x == y
+	})
+	options := append([]cmp.Option{opt}, data.FrameTestCompareOptions()...)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			require.NoError(t, err)
+			res, err := e.Execute("", tt.vars)
+			require.NoError(t, err)
+			if diff := cmp.Diff(tt.results, res, options...); diff != "" {
+				t.Errorf("Result mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
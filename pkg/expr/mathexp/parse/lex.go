@@ -273,10 +273,12 @@ func lexSymbol(l *lexer) stateFn {
 	return lexItem
 }
 
+// lexFunc scans a function name. The first letter has already been
+// consumed, and the rest of the name may contain letters, digits and underscores.
 func lexFunc(l *lexer) stateFn {
 	for {
 		switch r := l.next(); {
-		case unicode.IsLetter(r):
+		case unicode.IsLetter(r), unicode.IsDigit(r), r == '_':
 			// absorb
 		default:
 			l.backup()
@@ -113,6 +113,35 @@ var lexTests = []lexTest{
 		{itemVar, 0, "$A"},
 		tEOF,
 	}},
+	{"func names with digits and underscores", "log10($A) is_nan($A)", []item{
+		{itemFunc, 0, "log10"},
+		{itemLeftParen, 0, "("},
+		{itemVar, 0, "$A"},
+		{itemRightParen, 0, ")"},
+		{itemFunc, 0, "is_nan"},
+		{itemLeftParen, 0, "("},
+		{itemVar, 0, "$A"},
+		{itemRightParen, 0, ")"},
+		tEOF,
+	}},
+	{"func with empty argument", "clamp($A,,1)", []item{
+		{itemFunc, 0, "clamp"},
+		{itemLeftParen, 0, "("},
+		{itemVar, 0, "$A"},
+		{itemComma, 0, ","},
+		{itemComma, 0, ","},
+		{itemNumber, 0, "1"},
+		{itemRightParen, 0, ")"},
+		tEOF,
+	}},
+	{"func with trailing comma", "abs($A,)", []item{
+		{itemFunc, 0, "abs"},
+		{itemLeftParen, 0, "("},
+		{itemVar, 0, "$A"},
+		{itemComma, 0, ","},
+		{itemRightParen, 0, ")"},
+		tEOF,
+	}},
 	// errors
 	{"unclosed quote", "\"", []item{
 		{itemError, 0, "unterminated string"},
@@ -9,6 +9,7 @@ package parse
 import (
 	"fmt"
 	"strconv"
+	"strings"
 )
 
 // A Node is an element in the parse tree. The interface is trivial.
@@ -298,6 +299,50 @@ type BinaryNode struct {
 	Args     [2]Node
 	Operator item
 	OpStr    string
+	// Matching is set when the operator has an on() or ignoring() modifier.
+	Matching *VectorMatching
+}
+
+// VectorMatchCardinality describes how many items on each side of a binary
+// operation can match each other.
+type VectorMatchCardinality int
+
+const (
+	// CardOneToOne matches each item on the left to at most one item on the right.
+	CardOneToOne VectorMatchCardinality = iota
+	// CardManyToOne matches many items on the left to one item on the right (group_left).
+	CardManyToOne
+	// CardOneToMany matches one item on the left to many items on the right (group_right).
+	CardOneToMany
+)
+
+// VectorMatching describes how the items of the two sets of a binary operation
+// are matched by their labels, as in PromQL.
+type VectorMatching struct {
+	Card VectorMatchCardinality
+	// MatchingLabels are the labels used to match items when On is true,
+	// or the labels ignored when matching items when On is false.
+	MatchingLabels []string
+	On             bool
+	// Include are the labels of the "one" side that are copied to the result
+	// of a group_left or group_right operation.
+	Include []string
+}
+
+// String returns the string representation of the VectorMatching.
+func (m *VectorMatching) String() string {
+	s := "ignoring"
+	if m.On {
+		s = "on"
+	}
+	s += "(" + strings.Join(m.MatchingLabels, ", ") + ")"
+	switch m.Card {
+	case CardManyToOne:
+		s += " group_left(" + strings.Join(m.Include, ", ") + ")"
+	case CardOneToMany:
+		s += " group_right(" + strings.Join(m.Include, ", ") + ")"
+	}
+	return s
 }
 
 func newBinary(operator item, arg1, arg2 Node) *BinaryNode {
@@ -306,16 +351,32 @@ func newBinary(operator item, arg1, arg2 Node) *BinaryNode {
 
 // String returns the string representation of the BinaryNode so it fulfills the Node interface.
 func (b *BinaryNode) String() string {
+	if b.Matching != nil {
+		return fmt.Sprintf("%s %s %s %s", b.Args[0], b.Operator.val, b.Matching, b.Args[1])
+	}
 	return fmt.Sprintf("%s %s %s", b.Args[0], b.Operator.val, b.Args[1])
 }
 
 // StringAST returns the string representation of abstract syntax tree of the BinaryNode so it fulfills the Node interface.
 func (b *BinaryNode) StringAST() string {
+	if b.Matching != nil {
+		return fmt.Sprintf("%s %s(%s, %s)", b.Operator.val, b.Matching, b.Args[0], b.Args[1])
+	}
 	return fmt.Sprintf("%s(%s, %s)", b.Operator.val, b.Args[0], b.Args[1])
 }
 
 // Check performs parse time checking on the BinaryNode so it fulfills the Node interface.
 func (b *BinaryNode) Check(t *Tree) error {
+	if b.Matching == nil || !b.Matching.On {
+		return nil
+	}
+	for _, include := range b.Matching.Include {
+		for _, label := range b.Matching.MatchingLabels {
+			if include == label {
+				return fmt.Errorf("parse: label %q must not occur in on() and in a group clause in %s", label, b)
+			}
+		}
+	}
 	return nil
 }
 
@@ -401,6 +462,8 @@ const (
 	TypeSeriesSet
 	// TypeVariantSet is a collection of the same type Number, Series, or Scalar.
 	TypeVariantSet
+	// TypeTableData is a table that is neither a number set nor a time series.
+	TypeTableData
 )
 
 // String returns a string representation of the ReturnType.
@@ -416,6 +479,8 @@ func (f ReturnType) String() string {
 		return "scalar"
 	case TypeVariantSet:
 		return "variant"
+	case TypeTableData:
+		return "tableData"
 	default:
 		return "unknown"
 	}
@@ -34,7 +34,10 @@ type Func struct {
 	Return        ReturnType
 	F             interface{}
 	VariantReturn bool
-	Check         func(*Tree, *FuncNode) error
+	// VariantReturnFunc returns the type of a VariantReturn function from its
+	// arguments. If it is nil, the type is the type of the first argument.
+	VariantReturnFunc func(args []Node) ReturnType
+	Check             func(*Tree, *FuncNode) error
 }
 
 // Parse returns a Tree, created by parsing the expression described in the
@@ -165,16 +168,18 @@ func (t *Tree) parse() {
 }
 
 /* Grammar:
-O -> A {"||" A}
-A -> C {"&&" C}
-C -> P {( "==" | "!=" | ">" | ">=" | "<" | "<=") P}
-P -> M {( "+" | "-" ) M}
-M -> E {( "*" | "/" ) F}
-E -> F {( "**" ) F}
+O -> A {"||" [Match] A}
+A -> C {"&&" [Match] C}
+C -> P {( "==" | "!=" | ">" | ">=" | "<" | "<=") [Match] P}
+P -> M {( "+" | "-" ) [Match] M}
+M -> E {( "*" | "/" ) [Match] F}
+E -> F {( "**" ) [Match] F}
 F -> v | "(" O ")" | "!" O | "-" O
 v -> number | func(..) | queryVar
 Func -> name "(" param {"," param} ")"
 param -> number | "string" | queryVar
+Match -> ("on" | "ignoring") Labels [("group_left" | "group_right") [Labels]]
+Labels -> "(" [label {"," label}] ")"
 */
 
 // expr:
@@ -185,7 +190,7 @@ func (t *Tree) O() Node {
 	for {
 		switch t.peek().typ {
 		case itemOr:
-			n = newBinary(t.next(), n, t.A())
+			n = t.binary(n, t.A)
 		default:
 			return n
 		}
@@ -198,7 +203,7 @@ func (t *Tree) A() Node {
 	for {
 		switch t.peek().typ {
 		case itemAnd:
-			n = newBinary(t.next(), n, t.C())
+			n = t.binary(n, t.C)
 		default:
 			return n
 		}
@@ -211,7 +216,7 @@ func (t *Tree) C() Node {
 	for {
 		switch t.peek().typ {
 		case itemEq, itemNotEq, itemGreater, itemGreaterEq, itemLess, itemLessEq:
-			n = newBinary(t.next(), n, t.P())
+			n = t.binary(n, t.P)
 		default:
 			return n
 		}
@@ -224,7 +229,7 @@ func (t *Tree) P() Node {
 	for {
 		switch t.peek().typ {
 		case itemPlus, itemMinus:
-			n = newBinary(t.next(), n, t.M())
+			n = t.binary(n, t.M)
 		default:
 			return n
 		}
@@ -237,7 +242,7 @@ func (t *Tree) M() Node {
 	for {
 		switch t.peek().typ {
 		case itemMult, itemDiv, itemMod:
-			n = newBinary(t.next(), n, t.E())
+			n = t.binary(n, t.E)
 		default:
 			return n
 		}
@@ -250,13 +255,83 @@ func (t *Tree) E() Node {
 	for {
 		switch t.peek().typ {
 		case itemPow:
-			n = newBinary(t.next(), n, t.F())
+			n = t.binary(n, t.F)
 		default:
 			return n
 		}
 	}
 }
 
+// binary consumes a binary operator and its optional vector matching modifiers,
+// and returns a BinaryNode of lhs and the node returned by rhs.
+func (t *Tree) binary(lhs Node, rhs func() Node) Node {
+	operator := t.next()
+	matching := t.vectorMatching()
+	b := newBinary(operator, lhs, rhs())
+	b.Matching = matching
+	return b
+}
+
+// vectorMatching is Match in the grammar. It returns nil if there is no
+// on() or ignoring() modifier.
+func (t *Tree) vectorMatching() *VectorMatching {
+	token := t.peek()
+	if token.typ != itemFunc || (token.val != "on" && token.val != "ignoring") {
+		return nil
+	}
+	t.next()
+	m := &VectorMatching{
+		Card:           CardOneToOne,
+		On:             token.val == "on",
+		MatchingLabels: t.labelList(token.val),
+	}
+
+	token = t.peek()
+	if token.typ != itemFunc || (token.val != "group_left" && token.val != "group_right") {
+		return m
+	}
+	t.next()
+	m.Card = CardManyToOne
+	if token.val == "group_right" {
+		m.Card = CardOneToMany
+	}
+	if t.peek().typ == itemLeftParen {
+		m.Include = t.labelList(token.val)
+	}
+	return m
+}
+
+// labelList is Labels in the grammar.
+func (t *Tree) labelList(context string) []string {
+	labels := []string{}
+	t.expect(itemLeftParen, context)
+	if t.peek().typ == itemRightParen {
+		t.next()
+		return labels
+	}
+	for {
+		switch token := t.next(); token.typ {
+		case itemFunc:
+			labels = append(labels, token.val)
+		case itemString:
+			s, err := strconv.Unquote(token.val)
+			if err != nil {
+				t.errorf("Unquoting error: %s", err)
+			}
+			labels = append(labels, s)
+		default:
+			t.unexpected(token, context)
+		}
+		switch token := t.next(); token.typ {
+		case itemComma:
+		case itemRightParen:
+			return labels
+		default:
+			t.unexpected(token, context)
+		}
+	}
+}
+
 // F is v | "(" O ")" | "!" O | "-" O in the grammar.
 func (t *Tree) F() Node {
 	switch token := t.peek(); token.typ {
@@ -314,22 +389,43 @@ func (t *Tree) Func() (f *FuncNode) {
 	}
 	f = newFunc(token.pos, token.val, funcv)
 	t.expect(itemLeftParen, "func")
+	// arg is whether an argument was parsed since the opening parenthesis or the last comma.
+	arg := false
 	for {
 		switch token = t.next(); token.typ {
 		default:
+			if arg {
+				t.unexpected(token, "func")
+			}
 			t.backup()
 			node := t.O()
 			f.append(node)
 			if len(f.Args) == 1 && f.F.VariantReturn {
 				f.F.Return = node.Return()
 			}
+			arg = true
 		case itemString:
+			if arg {
+				t.unexpected(token, "func")
+			}
 			s, err := strconv.Unquote(token.val)
 			if err != nil {
 				t.errorf("Unquoting error: %s", err)
 			}
 			f.append(newString(token.pos, token.val, s))
+			arg = true
+		case itemComma:
+			if !arg {
+				t.unexpected(token, "func")
+			}
+			arg = false
 		case itemRightParen:
+			if !arg && len(f.Args) > 0 {
+				t.unexpected(token, "func")
+			}
+			if f.F.VariantReturn && f.F.VariantReturnFunc != nil && len(f.Args) == len(f.F.Args) {
+				f.F.Return = f.F.VariantReturnFunc(f.Args)
+			}
 			return
 		}
 	}
@@ -3,10 +3,34 @@ package mathexp
 import (
 	"fmt"
 	"math"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 )
 
+// ReduceMode controls how null and NaN values are handled by Reduce.
+type ReduceMode string
+
+const (
+	// ReduceModeStrict passes null and NaN values to the reducer, so most
+	// reducers return NaN if the series contains any of them.
+	ReduceModeStrict ReduceMode = ""
+	// ReduceModeDropNN drops null and NaN values from the series before reducing it.
+	ReduceModeDropNN ReduceMode = "dropNN"
+)
+
+// ParseReduceMode returns a ReduceMode from its string representation.
+func ParseReduceMode(s string) (ReduceMode, error) {
+	switch ReduceMode(s) {
+	case ReduceModeStrict, ReduceModeDropNN:
+		return ReduceMode(s), nil
+	default:
+		return ReduceModeStrict, fmt.Errorf("reduce mode %q is not supported", s)
+	}
+}
+
 func Sum(fv *Float64Field) *float64 {
 	var sum float64
 	for i := 0; i < fv.Len(); i++ {
@@ -69,8 +93,117 @@ func Count(fv *Float64Field) *float64 {
 	return &f
 }
 
-// Reduce turns the Series into a Number based on the given reduction function
-func (s Series) Reduce(refID, rFunc string) (Number, error) {
+// Last returns the last value of the field, or NaN if the field is empty.
+func Last(fv *Float64Field) *float64 {
+	if fv.Len() == 0 {
+		nan := math.NaN()
+		return &nan
+	}
+	return fv.GetValue(fv.Len() - 1)
+}
+
+// Median returns the median of the field, or NaN if the field is empty or
+// contains null or NaN values.
+func Median(fv *Float64Field) *float64 {
+	return Percentile(fv, 50)
+}
+
+// Percentile returns the p-th percentile (0 <= p <= 100) of the field, interpolating
+// linearly between the closest ranks. It returns NaN if the field is empty or
+// contains null or NaN values.
+func Percentile(fv *Float64Field, p float64) *float64 {
+	values, ok := float64Values(fv)
+	if !ok || len(values) == 0 {
+		nan := math.NaN()
+		return &nan
+	}
+	sort.Float64s(values)
+	rank := p / 100 * float64(len(values)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	f := values[lower] + (values[upper]-values[lower])*(rank-float64(lower))
+	return &f
+}
+
+// Stddev returns the population standard deviation of the field, or NaN if the
+// field is empty or contains null or NaN values.
+func Stddev(fv *Float64Field) *float64 {
+	values, ok := float64Values(fv)
+	if !ok || len(values) == 0 {
+		nan := math.NaN()
+		return &nan
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+	var squares float64
+	for _, v := range values {
+		squares += (v - mean) * (v - mean)
+	}
+	f := math.Sqrt(squares / float64(len(values)))
+	return &f
+}
+
+// Range returns the difference between the maximum and minimum values of the field.
+func Range(fv *Float64Field) *float64 {
+	min, max := Min(fv), Max(fv)
+	f := *max - *min
+	return &f
+}
+
+// CountNonNull returns the number of values in the field that are neither null nor NaN.
+func CountNonNull(fv *Float64Field) *float64 {
+	var f float64
+	for i := 0; i < fv.Len(); i++ {
+		if v := fv.GetValue(i); v != nil && !math.IsNaN(*v) {
+			f++
+		}
+	}
+	return &f
+}
+
+// float64Values returns the values of the field. ok is false if any value is null or NaN.
+func float64Values(fv *Float64Field) (values []float64, ok bool) {
+	values = make([]float64, 0, fv.Len())
+	for i := 0; i < fv.Len(); i++ {
+		v := fv.GetValue(i)
+		if v == nil || math.IsNaN(*v) {
+			return nil, false
+		}
+		values = append(values, *v)
+	}
+	return values, true
+}
+
+// dropNN returns a copy of the field without null and NaN values.
+func dropNN(fv *Float64Field) *Float64Field {
+	values := make([]*float64, 0, fv.Len())
+	for i := 0; i < fv.Len(); i++ {
+		if v := fv.GetValue(i); v != nil && !math.IsNaN(*v) {
+			values = append(values, v)
+		}
+	}
+	ff := Float64Field(*data.NewField("", nil, values))
+	return &ff
+}
+
+// parsePercentile returns the percentile of a reduction function such as "p95" or "p99.9".
+func parsePercentile(rFunc string) (float64, bool) {
+	if !strings.HasPrefix(rFunc, "p") {
+		return 0, false
+	}
+	p, err := strconv.ParseFloat(strings.TrimPrefix(rFunc, "p"), 64)
+	if err != nil || math.IsNaN(p) || p < 0 || p > 100 {
+		return 0, false
+	}
+	return p, true
+}
+
+// Reduce turns the Series into a Number based on the given reduction function.
+// The mode controls how null and NaN values in the series are handled.
+func (s Series) Reduce(refID, rFunc string, mode ReduceMode) (Number, error) {
 	var l data.Labels
 	if s.GetLabels() != nil {
 		l = s.GetLabels().Copy()
@@ -79,6 +212,13 @@ func (s Series) Reduce(refID, rFunc string) (Number, error) {
 	var f *float64
 	fVec := s.Frame.Fields[seriesTypeValIdx]
 	floatField := Float64Field(*fVec)
+	switch mode {
+	case ReduceModeStrict:
+	case ReduceModeDropNN:
+		floatField = *dropNN(&floatField)
+	default:
+		return number, fmt.Errorf("reduce mode %q is not supported", mode)
+	}
 	switch rFunc {
 	case "sum":
 		f = Sum(&floatField)
@@ -90,8 +230,22 @@ func (s Series) Reduce(refID, rFunc string) (Number, error) {
 		f = Max(&floatField)
 	case "count":
 		f = Count(&floatField)
+	case "last":
+		f = Last(&floatField)
+	case "median":
+		f = Median(&floatField)
+	case "stddev":
+		f = Stddev(&floatField)
+	case "range":
+		f = Range(&floatField)
+	case "count_non_null":
+		f = CountNonNull(&floatField)
 	default:
-		return number, fmt.Errorf("reduction %v not implemented", rFunc)
+		p, ok := parsePercentile(rFunc)
+		if !ok {
+			return number, fmt.Errorf("reduction %v not implemented", rFunc)
+		}
+		f = Percentile(&floatField, p)
 	}
 	number.SetValue(f)
 
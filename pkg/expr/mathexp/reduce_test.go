@@ -30,10 +30,27 @@ var seriesEmpty = Vars{
 	},
 }
 
+var manySeries = Vars{
+	"A": Results{
+		[]Value{
+			makeSeries("temp", nil, tp{
+				time.Unix(5, 0), float64Pointer(4),
+			}, tp{
+				time.Unix(10, 0), float64Pointer(1),
+			}, tp{
+				time.Unix(15, 0), float64Pointer(3),
+			}, tp{
+				time.Unix(20, 0), float64Pointer(2),
+			}),
+		},
+	},
+}
+
 func TestSeriesReduce(t *testing.T) {
 	var tests = []struct {
 		name        string
 		red         string
+		mode        ReduceMode
 		vars        Vars
 		varToReduce string
 		errIs       require.ErrorAssertionFunc
@@ -191,6 +208,189 @@ func TestSeriesReduce(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:        "last series",
+			red:         "last",
+			varToReduce: "A",
+			vars:        aSeries,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results: Results{
+				[]Value{
+					makeNumber("", nil, float64Pointer(1)),
+				},
+			},
+		},
+		{
+			name:        "last series with a nil value",
+			red:         "last",
+			varToReduce: "A",
+			vars:        seriesWithNil,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results: Results{
+				[]Value{
+					makeNumber("", nil, nil),
+				},
+			},
+		},
+		{
+			name:        "last series with a nil value in dropNN mode",
+			red:         "last",
+			mode:        ReduceModeDropNN,
+			varToReduce: "A",
+			vars:        seriesWithNil,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results: Results{
+				[]Value{
+					makeNumber("", nil, float64Pointer(2)),
+				},
+			},
+		},
+		{
+			name:        "median series",
+			red:         "median",
+			varToReduce: "A",
+			vars:        manySeries,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results: Results{
+				[]Value{
+					makeNumber("", nil, float64Pointer(2.5)),
+				},
+			},
+		},
+		{
+			name:        "median series with a nil value",
+			red:         "median",
+			varToReduce: "A",
+			vars:        seriesWithNil,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results: Results{
+				[]Value{
+					makeNumber("", nil, NaN),
+				},
+			},
+		},
+		{
+			name:        "p75 series",
+			red:         "p75",
+			varToReduce: "A",
+			vars:        manySeries,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results: Results{
+				[]Value{
+					makeNumber("", nil, float64Pointer(3.25)),
+				},
+			},
+		},
+		{
+			name:        "p100 series",
+			red:         "p100",
+			varToReduce: "A",
+			vars:        manySeries,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results: Results{
+				[]Value{
+					makeNumber("", nil, float64Pointer(4)),
+				},
+			},
+		},
+		{
+			name:        "p101 reduction will error",
+			red:         "p101",
+			varToReduce: "A",
+			vars:        manySeries,
+			errIs:       require.Error,
+			resultsIs:   require.Equal,
+		},
+		{
+			name:        "pNaN reduction will error",
+			red:         "pNaN",
+			varToReduce: "A",
+			vars:        manySeries,
+			errIs:       require.Error,
+			resultsIs:   require.Equal,
+		},
+		{
+			name:        "stddev series",
+			red:         "stddev",
+			varToReduce: "A",
+			vars:        aSeries,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results: Results{
+				[]Value{
+					makeNumber("", nil, float64Pointer(0.5)),
+				},
+			},
+		},
+		{
+			name:        "stddev empty series",
+			red:         "stddev",
+			varToReduce: "A",
+			vars:        seriesEmpty,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results: Results{
+				[]Value{
+					makeNumber("", nil, NaN),
+				},
+			},
+		},
+		{
+			name:        "range series",
+			red:         "range",
+			varToReduce: "A",
+			vars:        manySeries,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results: Results{
+				[]Value{
+					makeNumber("", nil, float64Pointer(3)),
+				},
+			},
+		},
+		{
+			name:        "count_non_null series with a nil value",
+			red:         "count_non_null",
+			varToReduce: "A",
+			vars:        seriesWithNil,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results: Results{
+				[]Value{
+					makeNumber("", nil, float64Pointer(1)),
+				},
+			},
+		},
+		{
+			name:        "mean series with a nil value in dropNN mode",
+			red:         "mean",
+			mode:        ReduceModeDropNN,
+			varToReduce: "A",
+			vars:        seriesWithNil,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results: Results{
+				[]Value{
+					makeNumber("", nil, float64Pointer(2)),
+				},
+			},
+		},
+		{
+			name:        "unknown mode will error",
+			red:         "mean",
+			mode:        "foo",
+			varToReduce: "A",
+			vars:        aSeries,
+			errIs:       require.Error,
+			resultsIs:   require.Equal,
+		},
 		{
 			name:        "mean series with labels",
 			red:         "mean",
@@ -221,7 +421,7 @@ func TestSeriesReduce(t *testing.T) {
 			results := Results{}
 			seriesSet := tt.vars[tt.varToReduce]
 			for _, series := range seriesSet.Values {
-				ns, err := series.Value().(*Series).Reduce("", tt.red)
+				ns, err := series.Value().(*Series).Reduce("", tt.red, tt.mode)
 				tt.errIs(t, err)
 				if err != nil {
 					return
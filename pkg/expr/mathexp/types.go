@@ -23,7 +23,7 @@ func (vals Values) AsDataFrames(refID string) []*data.Frame {
 	return frames
 }
 
-// Value is the interface that holds different types such as a Scalar, Series, or Number.
+// Value is the interface that holds different types such as a Scalar, Series, Number, or TableData.
 // all Value implementations should be a *data.Frame
 type Value interface {
 	Type() parse.ReturnType
@@ -124,6 +124,35 @@ func (n Number) SetMeta(v interface{}) {
 	n.Frame.SetMeta(&data.FrameMeta{Custom: v})
 }
 
+// TableData holds a frame that is neither a number set nor a time series,
+// such as the table-shaped results of SQL data sources. It can only be used
+// as an input of the commands that work on tables, such as the SQL command.
+type TableData struct{ Frame *data.Frame }
+
+// Type returns the Value type and allows it to fulfill the Value interface.
+func (t TableData) Type() parse.ReturnType { return parse.TypeTableData }
+
+// Value returns the actual value allows it to fulfill the Value interface.
+func (t TableData) Value() interface{} { return &t }
+
+func (t TableData) GetLabels() data.Labels { return nil }
+
+func (t TableData) SetLabels(ls data.Labels) {}
+
+func (t TableData) GetMeta() interface{} {
+	if t.Frame.Meta == nil {
+		return nil
+	}
+	return t.Frame.Meta.Custom
+}
+
+func (t TableData) SetMeta(v interface{}) {
+	t.Frame.SetMeta(&data.FrameMeta{Custom: v})
+}
+
+// AsDataFrame returns the underlying *data.Frame.
+func (t TableData) AsDataFrame() *data.Frame { return t.Frame }
+
 // FloatField is a *float64 or a float64 data.Field with methods to always
 // get a *float64.
 type Float64Field data.Field
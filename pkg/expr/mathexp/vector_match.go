@@ -0,0 +1,126 @@
+package mathexp
+
+import (
+	"fmt"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
+)
+
+// matchUnion creates Union objects like union, but matches the items of the two
+// sets by the labels selected with the on() or ignoring() modifier of a binary
+// operation, following PromQL's vector matching rules.
+//
+// For one-to-one matching, each item may match at most one item on the other side,
+// and the labels of the result are the matching labels. For many-to-one (group_left)
+// and one-to-many (group_right) matching, the items of the "one" side must be unique,
+// and the labels of the result are the labels of the "many" side, with the included
+// labels copied from the "one" side. As in PromQL, the labels of the results must be
+// unique.
+//
+// Scalars have no labels to match on, so they are combined using union.
+func matchUnion(aResults, bResults Results, m *parse.VectorMatching) ([]*Union, error) {
+	if hasScalar(aResults) || hasScalar(bResults) {
+		return union(aResults, bResults), nil
+	}
+
+	many, one := aResults, bResults
+	manySide, oneSide := "left", "right"
+	if m.Card == parse.CardOneToMany {
+		many, one = bResults, aResults
+		manySide, oneSide = "right", "left"
+	}
+
+	oneBySignature := make(map[string]Value, len(one.Values))
+	for _, v := range one.Values {
+		sig := matchSignature(v.GetLabels(), m)
+		if _, ok := oneBySignature[sig]; ok {
+			return nil, fmt.Errorf("found duplicate items for the match group {%s} on the %s hand-side of the operation;many-to-many matching not allowed: matching labels must be unique on one side", sig, oneSide)
+		}
+		oneBySignature[sig] = v
+	}
+
+	unions := []*Union{}
+	matched := make(map[string]bool)
+	resultSignatures := make(map[string]bool)
+	for _, v := range many.Values {
+		sig := matchSignature(v.GetLabels(), m)
+		o, ok := oneBySignature[sig]
+		if !ok {
+			continue
+		}
+		if m.Card == parse.CardOneToOne {
+			if matched[sig] {
+				return nil, fmt.Errorf("found duplicate items for the match group {%s} on the %s hand-side of the operation, many-to-one matching must be explicit (group_left/group_right)", sig, manySide)
+			}
+			matched[sig] = true
+		}
+
+		u := &Union{
+			Labels: matchResultLabels(v.GetLabels(), o.GetLabels(), m),
+			A:      v,
+			B:      o,
+		}
+		if m.Card != parse.CardOneToOne {
+			resultSig := u.Labels.String()
+			if resultSignatures[resultSig] {
+				return nil, fmt.Errorf("multiple matches for labels {%s}: grouping labels must ensure unique matches", resultSig)
+			}
+			resultSignatures[resultSig] = true
+		}
+		if m.Card == parse.CardOneToMany {
+			u.A, u.B = o, v
+		}
+		unions = append(unions, u)
+	}
+	return unions, nil
+}
+
+// matchSignature returns a string that identifies the labels that are used to match items.
+func matchSignature(labels data.Labels, m *parse.VectorMatching) string {
+	return matchingLabels(labels, m).String()
+}
+
+// matchingLabels returns the subset of labels that are used to match items.
+func matchingLabels(labels data.Labels, m *parse.VectorMatching) data.Labels {
+	if m.On {
+		l := data.Labels{}
+		for _, name := range m.MatchingLabels {
+			if v, ok := labels[name]; ok {
+				l[name] = v
+			}
+		}
+		return l
+	}
+	l := labels.Copy()
+	for _, name := range m.MatchingLabels {
+		delete(l, name)
+	}
+	return l
+}
+
+// matchResultLabels returns the labels of the result of a binary operation
+// between the "many" and the "one" item.
+func matchResultLabels(many, one data.Labels, m *parse.VectorMatching) data.Labels {
+	if m.Card == parse.CardOneToOne {
+		return matchingLabels(many, m)
+	}
+	l := many.Copy()
+	for _, name := range m.Include {
+		if v, ok := one[name]; ok {
+			l[name] = v
+		} else {
+			delete(l, name)
+		}
+	}
+	return l
+}
+
+func hasScalar(res Results) bool {
+	for _, v := range res.Values {
+		if v.Type() == parse.TypeScalar {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,187 @@
+package mathexp
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVectorMatching(t *testing.T) {
+	perPod := Results{
+		[]Value{
+			makeNumber("", data.Labels{"namespace": "a", "pod": "a-1"}, float64Pointer(2)),
+			makeNumber("", data.Labels{"namespace": "a", "pod": "a-2"}, float64Pointer(6)),
+			makeNumber("", data.Labels{"namespace": "b", "pod": "b-1"}, float64Pointer(3)),
+		},
+	}
+	perNamespace := Results{
+		[]Value{
+			makeNumber("", data.Labels{"namespace": "a", "team": "red"}, float64Pointer(8)),
+			makeNumber("", data.Labels{"namespace": "b", "team": "blue"}, float64Pointer(12)),
+		},
+	}
+
+	var tests = []struct {
+		name      string
+		expr      string
+		vars      Vars
+		newErrIs  assert.ErrorAssertionFunc
+		execErrIs assert.ErrorAssertionFunc
+		resultIs  assert.ComparisonAssertionFunc
+		results   Results
+	}{
+		{
+			name:      "on() one-to-one keeps only the matching labels",
+			expr:      "$B - on(namespace) $C",
+			vars:      Vars{"B": perNamespace, "C": perNamespace},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results: Results{
+				[]Value{
+					makeNumber("", data.Labels{"namespace": "a"}, float64Pointer(0)),
+					makeNumber("", data.Labels{"namespace": "b"}, float64Pointer(0)),
+				},
+			},
+		},
+		{
+			name:      "ignoring() one-to-one drops the ignored labels",
+			expr:      "$B * ignoring(team) $C",
+			vars:      Vars{"B": perNamespace, "C": perNamespace},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results: Results{
+				[]Value{
+					makeNumber("", data.Labels{"namespace": "a"}, float64Pointer(64)),
+					makeNumber("", data.Labels{"namespace": "b"}, float64Pointer(144)),
+				},
+			},
+		},
+		{
+			name:      "one-to-one with many matches on one side - should error",
+			expr:      "$A / on(namespace) $B",
+			vars:      Vars{"A": perPod, "B": perNamespace},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.Error,
+			resultIs:  assert.Equal,
+			results:   Results{Values{}},
+		},
+		{
+			name:      "group_left matches many pods to one namespace and includes labels",
+			expr:      "$A / on(namespace) group_left(team) $B",
+			vars:      Vars{"A": perPod, "B": perNamespace},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results: Results{
+				[]Value{
+					makeNumber("", data.Labels{"namespace": "a", "pod": "a-1", "team": "red"}, float64Pointer(0.25)),
+					makeNumber("", data.Labels{"namespace": "a", "pod": "a-2", "team": "red"}, float64Pointer(0.75)),
+					makeNumber("", data.Labels{"namespace": "b", "pod": "b-1", "team": "blue"}, float64Pointer(0.25)),
+				},
+			},
+		},
+		{
+			name:      "group_right matches one namespace to many pods",
+			expr:      "$B - on(namespace) group_right $A",
+			vars:      Vars{"A": perPod, "B": perNamespace},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results: Results{
+				[]Value{
+					makeNumber("", data.Labels{"namespace": "a", "pod": "a-1"}, float64Pointer(6)),
+					makeNumber("", data.Labels{"namespace": "a", "pod": "a-2"}, float64Pointer(2)),
+					makeNumber("", data.Labels{"namespace": "b", "pod": "b-1"}, float64Pointer(9)),
+				},
+			},
+		},
+		{
+			name:      "group_left with duplicates on the one side - should error",
+			expr:      "$B / on(namespace) group_left $A",
+			vars:      Vars{"A": perPod, "B": perNamespace},
+			newErrIs:  assert.NoError,
+			execErrIs: errorContains("many-to-many matching not allowed: matching labels must be unique on one side"),
+			resultIs:  assert.Equal,
+			results:   Results{Values{}},
+		},
+		{
+			name:      "group_right with duplicates on the one side - should error",
+			expr:      "$A / on(namespace) group_right $B",
+			vars:      Vars{"A": perPod, "B": perNamespace},
+			newErrIs:  assert.NoError,
+			execErrIs: errorContains("many-to-many matching not allowed: matching labels must be unique on one side"),
+			resultIs:  assert.Equal,
+			results:   Results{Values{}},
+		},
+		{
+			name: "group_left with included labels that make the results identical - should error",
+			expr: "$A * on(namespace) group_left(pod) $B",
+			vars: Vars{"A": perPod, "B": Results{
+				[]Value{
+					makeNumber("", data.Labels{"namespace": "a", "pod": "a-0"}, float64Pointer(1)),
+					makeNumber("", data.Labels{"namespace": "b", "pod": "b-0"}, float64Pointer(1)),
+				},
+			}},
+			newErrIs:  assert.NoError,
+			execErrIs: errorContains("multiple matches for labels"),
+			resultIs:  assert.Equal,
+			results:   Results{Values{}},
+		},
+		{
+			name:      "scalars are combined without matching",
+			expr:      "$B > on(namespace) 10",
+			vars:      Vars{"B": perNamespace},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results: Results{
+				[]Value{
+					makeNumber("", data.Labels{"namespace": "a", "team": "red"}, float64Pointer(0)),
+					makeNumber("", data.Labels{"namespace": "b", "team": "blue"}, float64Pointer(1)),
+				},
+			},
+		},
+		{
+			name:     "label in on() and group_left() - should error",
+			expr:     "$A / on(namespace) group_left(namespace) $B",
+			vars:     Vars{"A": perPod, "B": perNamespace},
+			newErrIs: assert.Error,
+		},
+		{
+			name:     "missing label list - should error",
+			expr:     "$A / on $B",
+			vars:     Vars{"A": perPod, "B": perNamespace},
+			newErrIs: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			tt.newErrIs(t, err)
+			if e != nil {
+				res, err := e.Execute("", tt.vars)
+				tt.execErrIs(t, err)
+				tt.resultIs(t, tt.results, res)
+			}
+		})
+	}
+}
+
+func TestVectorMatchingString(t *testing.T) {
+	e, err := New(`$A / on(namespace, "app") group_left(team) $B`)
+	assert.NoError(t, err)
+	assert.Equal(t, "$A / on(namespace, app) group_left(team) $B", e.Tree.String())
+}
+
+// errorContains asserts that the error contains the given text.
+func errorContains(text string) assert.ErrorAssertionFunc {
+	return func(t assert.TestingT, err error, msgAndArgs ...interface{}) bool {
+		if !assert.Error(t, err, msgAndArgs...) {
+			return false
+		}
+		return assert.Contains(t, err.Error(), text, msgAndArgs...)
+	}
+}
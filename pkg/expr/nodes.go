@@ -115,6 +115,10 @@ func buildCMDNode(dp *simple.DirectedGraph, rn *rawNode) (*CMDNode, error) {
 		node.Command, err = UnmarshalResampleCommand(rn)
 	case TypeClassicConditions:
 		node.Command, err = classic.UnmarshalConditionsCmd(rn.Query, rn.RefID)
+	case TypeThreshold:
+		node.Command, err = UnmarshalThresholdCommand(rn)
+	case TypeSQL:
+		node.Command, err = UnmarshalSQLCommand(rn)
 	default:
 		return nil, fmt.Errorf("expression command type '%v' in '%v' not implemented", commandType, rn.RefID)
 	}
@@ -143,6 +147,10 @@ type DSNode struct {
 	intervalMS int64
 	maxDP      int64
 	request    Request
+
+	// sqlInput is true if the results are only used by SQL expressions, which can
+	// work on tables that are not time series.
+	sqlInput bool
 }
 
 // NodeType returns the data pipeline node type.
@@ -265,6 +273,11 @@ func (dn *DSNode) Execute(ctx context.Context, vars mathexp.Vars, s *Service) (m
 		}
 
 		for _, frame := range qr.Frames {
+			if dn.sqlInput && frame.TimeSeriesSchema().Type == data.TimeSeriesTypeNot {
+				logger.Debug("expression datasource query (tableData)", "query", refID)
+				vals = append(vals, mathexp.TableData{Frame: frame})
+				continue
+			}
 			logger.Debug("expression datasource query (seriesSet)", "query", refID)
 			series, err := WideToMany(frame)
 			if err != nil {
@@ -3,6 +3,7 @@ package expr
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"sort"
 	"testing"
 	"time"
@@ -103,3 +104,95 @@ func (me *mockEndpoint) DataQuery(ctx context.Context, ds *models.DataSource, qu
 func (me *mockEndpoint) HandleRequest(ctx context.Context, ds *models.DataSource, query plugins.DataQuery) (plugins.DataResponse, error) {
 	return me.DataQuery(ctx, ds, query)
 }
+
+// nolint:staticcheck // plugins.DataPlugin deprecated
+func TestServiceTableData(t *testing.T) {
+	tableDF := data.NewFrame("test",
+		data.NewField("host", nil, []string{"a", "b"}),
+		data.NewField("cpu", nil, []float64{10, 95}),
+		data.NewField("mem", nil, []float64{50, 60}))
+
+	s := Service{DataService: &mockEndpoint{Frames: []*data.Frame{tableDF}}}
+	bus.AddHandler("test", func(query *models.GetDataSourceQuery) error {
+		query.Result = &models.DataSource{Id: 1, OrgId: 1, Type: "test"}
+		return nil
+	})
+	dsQuery := Query{
+		RefID: "A",
+		JSON:  json.RawMessage(`{ "datasource": "test", "datasourceId": 1, "orgId": 1, "intervalMs": 1000, "maxDataPoints": 1000 }`),
+	}
+
+	// Tables are only accepted by SQL expressions, other expressions need time series.
+	pl, err := s.BuildPipeline(&Request{Queries: []Query{dsQuery, {
+		RefID: "B",
+		JSON:  json.RawMessage(`{ "datasource": "__expr__", "datasourceId": -100, "type": "math", "expression": "$A * 2" }`),
+	}}})
+	require.NoError(t, err)
+	_, err = s.ExecutePipeline(context.Background(), pl)
+	require.Error(t, err)
+
+	pl, err = s.BuildPipeline(&Request{Queries: []Query{dsQuery, {
+		RefID: "B",
+		JSON:  json.RawMessage(`{ "datasource": "__expr__", "datasourceId": -100, "type": "sql", "expression": "SELECT host, cpu FROM A WHERE cpu > 50" }`),
+	}}})
+	require.NoError(t, err)
+	res, err := s.ExecutePipeline(context.Background(), pl)
+	require.NoError(t, err)
+	require.Len(t, res.Responses["B"].Frames, 1)
+	require.Equal(t, 1, res.Responses["B"].Frames[0].Rows())
+}
+
+// nolint:staticcheck // plugins.DataPlugin deprecated
+func TestServiceTableDataMixedConsumers(t *testing.T) {
+	dsQuery := Query{
+		RefID: "A",
+		JSON:  json.RawMessage(`{ "datasource": "test", "datasourceId": 1, "orgId": 1, "intervalMs": 1000, "maxDataPoints": 1000 }`),
+	}
+	exprQuery := func(refID, exprType, expression string) Query {
+		return Query{
+			RefID: refID,
+			JSON:  json.RawMessage(fmt.Sprintf(`{ "datasource": "__expr__", "datasourceId": -100, "type": %q, "expression": %q }`, exprType, expression)),
+		}
+	}
+	bus.AddHandler("test", func(query *models.GetDataSourceQuery) error {
+		query.Result = &models.DataSource{Id: 1, OrgId: 1, Type: "test"}
+		return nil
+	})
+
+	t.Run("time series are used by both SQL and math expressions", func(t *testing.T) {
+		seriesDF := data.NewFrame("test",
+			data.NewField("time", nil, []time.Time{time.Unix(1, 0), time.Unix(2, 0)}),
+			data.NewField("cpu", nil, []float64{10, 95}))
+		s := Service{DataService: &mockEndpoint{Frames: []*data.Frame{seriesDF}}}
+
+		pl, err := s.BuildPipeline(&Request{Queries: []Query{
+			dsQuery,
+			exprQuery("B", "sql", "SELECT * FROM A WHERE value > 50"),
+			exprQuery("C", "math", "$A * 2"),
+		}})
+		require.NoError(t, err)
+		res, err := s.ExecutePipeline(context.Background(), pl)
+		require.NoError(t, err)
+		require.Len(t, res.Responses["B"].Frames, 1)
+		require.Equal(t, 1, res.Responses["B"].Frames[0].Rows())
+		require.Len(t, res.Responses["C"].Frames, 1)
+	})
+
+	t.Run("tables are not passed to math expressions", func(t *testing.T) {
+		tableDF := data.NewFrame("test",
+			data.NewField("host", nil, []string{"a", "b"}),
+			data.NewField("cpu", nil, []float64{10, 95}),
+			data.NewField("mem", nil, []float64{50, 60}))
+		s := Service{DataService: &mockEndpoint{Frames: []*data.Frame{tableDF}}}
+
+		pl, err := s.BuildPipeline(&Request{Queries: []Query{
+			dsQuery,
+			exprQuery("B", "sql", "SELECT host, cpu FROM A WHERE cpu > 50"),
+			exprQuery("C", "math", "$A * 2"),
+		}})
+		require.NoError(t, err)
+		_, err = s.ExecutePipeline(context.Background(), pl)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "input data must be a wide series")
+	})
+}
@@ -0,0 +1,298 @@
+package sql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Query is a parsed SELECT statement.
+type Query struct {
+	Distinct bool
+	Columns  []SelectItem
+	From     TableRef
+	Joins    []Join
+	Where    Expr
+	GroupBy  []Expr
+	Having   Expr
+	OrderBy  []OrderItem
+	// Limit is the maximum number of rows to return, or -1 if there is no limit.
+	Limit int
+}
+
+// Tables returns the names of the tables the query reads from, in the order
+// they first appear in the query.
+func (q *Query) Tables() []string {
+	names := []string{q.From.Name}
+	for _, j := range q.Joins {
+		found := false
+		for _, n := range names {
+			if n == j.Table.Name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			names = append(names, j.Table.Name)
+		}
+	}
+	return names
+}
+
+func (q *Query) String() string {
+	var sb strings.Builder
+	sb.WriteString("SELECT ")
+	if q.Distinct {
+		sb.WriteString("DISTINCT ")
+	}
+	for i, c := range q.Columns {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(c.String())
+	}
+	sb.WriteString(" FROM ")
+	sb.WriteString(q.From.String())
+	for _, j := range q.Joins {
+		sb.WriteString(" ")
+		sb.WriteString(j.String())
+	}
+	if q.Where != nil {
+		sb.WriteString(" WHERE ")
+		sb.WriteString(q.Where.String())
+	}
+	if len(q.GroupBy) > 0 {
+		sb.WriteString(" GROUP BY ")
+		sb.WriteString(joinExprs(q.GroupBy))
+	}
+	if q.Having != nil {
+		sb.WriteString(" HAVING ")
+		sb.WriteString(q.Having.String())
+	}
+	if len(q.OrderBy) > 0 {
+		sb.WriteString(" ORDER BY ")
+		for i, o := range q.OrderBy {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteString(o.Expr.String())
+			if o.Desc {
+				sb.WriteString(" DESC")
+			}
+		}
+	}
+	if q.Limit >= 0 {
+		sb.WriteString(" LIMIT ")
+		sb.WriteString(strconv.Itoa(q.Limit))
+	}
+	return sb.String()
+}
+
+// SelectItem is a column of the SELECT clause. It is either an expression
+// with an optional alias, or a star that selects all columns of all tables,
+// or of a single table if StarTable is set.
+type SelectItem struct {
+	Expr      Expr
+	Alias     string
+	Star      bool
+	StarTable string
+}
+
+func (s SelectItem) String() string {
+	switch {
+	case s.Star && s.StarTable != "":
+		return quoteIdent(s.StarTable) + ".*"
+	case s.Star:
+		return "*"
+	case s.Alias != "":
+		return s.Expr.String() + " AS " + quoteIdent(s.Alias)
+	}
+	return s.Expr.String()
+}
+
+// TableRef is a table in the FROM or JOIN clauses. The name of a table is the
+// RefID of the query or expression that it reads from.
+type TableRef struct {
+	Name  string
+	Alias string
+}
+
+// RefName is the name columns of the table are qualified with in the query.
+func (t TableRef) RefName() string {
+	if t.Alias != "" {
+		return t.Alias
+	}
+	return t.Name
+}
+
+func (t TableRef) String() string {
+	if t.Alias != "" {
+		return quoteIdent(t.Name) + " AS " + quoteIdent(t.Alias)
+	}
+	return quoteIdent(t.Name)
+}
+
+// JoinType is the type of a join.
+type JoinType int
+
+const (
+	// JoinInner only keeps the rows that match in both tables.
+	JoinInner JoinType = iota
+	// JoinLeft keeps all the rows of the left table, with null values for
+	// the columns of the right table when there is no match.
+	JoinLeft
+)
+
+// Join is a JOIN clause.
+type Join struct {
+	Type  JoinType
+	Table TableRef
+	On    Expr
+}
+
+func (j Join) String() string {
+	kw := "JOIN"
+	if j.Type == JoinLeft {
+		kw = "LEFT JOIN"
+	}
+	return fmt.Sprintf("%s %s ON %s", kw, j.Table, j.On)
+}
+
+// OrderItem is an expression of the ORDER BY clause.
+type OrderItem struct {
+	Expr Expr
+	Desc bool
+}
+
+// Expr is an expression in a query.
+type Expr interface {
+	String() string
+}
+
+// ColumnRef is a reference to a column, optionally qualified with the name or
+// alias of its table.
+type ColumnRef struct {
+	Table string
+	Name  string
+}
+
+func (c *ColumnRef) String() string {
+	if c.Table != "" {
+		return quoteIdent(c.Table) + "." + quoteIdent(c.Name)
+	}
+	return quoteIdent(c.Name)
+}
+
+// Literal is a constant value. The value is a float64, string, bool or nil.
+type Literal struct {
+	Value interface{}
+}
+
+func (l *Literal) String() string {
+	switch v := l.Value.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return "'" + strings.ReplaceAll(v, "'", "''") + "'"
+	case bool:
+		if v {
+			return "TRUE"
+		}
+		return "FALSE"
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	return fmt.Sprintf("%v", l.Value)
+}
+
+// BinaryExpr is an arithmetic, comparison or logical operation between two
+// expressions. Op is the upper case operator, such as "+", "<=", "AND" or "LIKE".
+type BinaryExpr struct {
+	Op  string
+	LHS Expr
+	RHS Expr
+}
+
+func (b *BinaryExpr) String() string {
+	return fmt.Sprintf("(%s %s %s)", b.LHS, b.Op, b.RHS)
+}
+
+// UnaryExpr is a negation ("-") or a logical not ("NOT").
+type UnaryExpr struct {
+	Op  string
+	Arg Expr
+}
+
+func (u *UnaryExpr) String() string {
+	if u.Op == "NOT" {
+		return fmt.Sprintf("(NOT %s)", u.Arg)
+	}
+	return fmt.Sprintf("(%s%s)", u.Op, u.Arg)
+}
+
+// IsNullExpr is an IS NULL or IS NOT NULL check.
+type IsNullExpr struct {
+	Arg Expr
+	Not bool
+}
+
+func (i *IsNullExpr) String() string {
+	if i.Not {
+		return fmt.Sprintf("(%s IS NOT NULL)", i.Arg)
+	}
+	return fmt.Sprintf("(%s IS NULL)", i.Arg)
+}
+
+// InExpr is an IN or NOT IN check against a list of expressions.
+type InExpr struct {
+	Arg  Expr
+	List []Expr
+	Not  bool
+}
+
+func (i *InExpr) String() string {
+	op := "IN"
+	if i.Not {
+		op = "NOT IN"
+	}
+	return fmt.Sprintf("(%s %s (%s))", i.Arg, op, joinExprs(i.List))
+}
+
+// FuncCall is a call of a scalar or an aggregate function. Name is upper case.
+// Star is set for COUNT(*).
+type FuncCall struct {
+	Name string
+	Args []Expr
+	Star bool
+}
+
+func (f *FuncCall) String() string {
+	if f.Star {
+		return f.Name + "(*)"
+	}
+	return f.Name + "(" + joinExprs(f.Args) + ")"
+}
+
+func joinExprs(exprs []Expr) string {
+	s := make([]string, len(exprs))
+	for i, e := range exprs {
+		s[i] = e.String()
+	}
+	return strings.Join(s, ", ")
+}
+
+// quoteIdent returns the identifier as is if it can be written without quotes,
+// otherwise it returns it double quoted.
+func quoteIdent(s string) string {
+	plain := s != "" && !isReserved(s)
+	for i, r := range s {
+		if (i == 0 && !isIdentStart(r)) || !isIdentChar(r) {
+			plain = false
+			break
+		}
+	}
+	if plain {
+		return s
+	}
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
@@ -0,0 +1,314 @@
+package sql
+
+import (
+	"fmt"
+	"math"
+)
+
+// column is a column of the rows a query works on. table is the name or the
+// alias of the table it comes from.
+type column struct {
+	table string
+	name  string
+}
+
+// scope is what an expression is evaluated against. It is either a single row,
+// or a group of rows when the query aggregates.
+type scope struct {
+	columns []column
+	row     []interface{}
+
+	// aggregate is set when the expression is evaluated for a group of rows.
+	// Column references are then only allowed inside aggregate functions or
+	// as part of a GROUP BY expression, which has the same value for all the
+	// rows of the group, so they are read from row, the first row of the group.
+	aggregate bool
+	group     [][]interface{}
+	groupBy   map[string]bool
+	groupCols map[int]bool
+
+	// outputs holds the values of the SELECT columns by alias, which can be
+	// referenced from HAVING and ORDER BY.
+	outputs map[string]interface{}
+}
+
+// rowScope returns a scope for the single row at row.
+func (s *scope) rowScope(row []interface{}) *scope {
+	return &scope{columns: s.columns, row: row}
+}
+
+// lookup returns the index of the column that ref refers to.
+func lookup(columns []column, ref *ColumnRef) (int, error) {
+	idx := -1
+	for i, c := range columns {
+		if c.name != ref.Name || (ref.Table != "" && c.table != ref.Table) {
+			continue
+		}
+		if idx >= 0 {
+			return 0, fmt.Errorf("column reference %s is ambiguous", ref)
+		}
+		idx = i
+	}
+	if idx < 0 {
+		return 0, fmt.Errorf("column %s does not exist", ref)
+	}
+	return idx, nil
+}
+
+func (s *scope) eval(e Expr) (interface{}, error) {
+	if s.aggregate && s.groupBy[e.String()] {
+		return s.rowScope(s.row).eval(e)
+	}
+
+	switch e := e.(type) {
+	case *Literal:
+		return e.Value, nil
+	case *ColumnRef:
+		if e.Table == "" && s.outputs != nil {
+			if v, ok := s.outputs[e.Name]; ok {
+				return v, nil
+			}
+		}
+		idx, err := lookup(s.columns, e)
+		if err != nil {
+			return nil, err
+		}
+		if s.aggregate && !s.groupCols[idx] {
+			return nil, fmt.Errorf("column %s must appear in the GROUP BY clause or be used in an aggregate function", e)
+		}
+		return s.row[idx], nil
+	case *UnaryExpr:
+		return s.evalUnary(e)
+	case *BinaryExpr:
+		return s.evalBinary(e)
+	case *IsNullExpr:
+		v, err := s.eval(e.Arg)
+		if err != nil {
+			return nil, err
+		}
+		return (v == nil) != e.Not, nil
+	case *InExpr:
+		return s.evalIn(e)
+	case *FuncCall:
+		if isAggregate(e.Name) {
+			return s.evalAggregate(e)
+		}
+		args := make([]interface{}, len(e.Args))
+		for i, a := range e.Args {
+			v, err := s.eval(a)
+			if err != nil {
+				return nil, err
+			}
+			args[i] = v
+		}
+		v, err := scalarFuncs[e.Name].f(args)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", e.Name, err)
+		}
+		return v, nil
+	}
+	return nil, fmt.Errorf("unexpected expression %s", e)
+}
+
+func (s *scope) evalUnary(e *UnaryExpr) (interface{}, error) {
+	v, err := s.eval(e.Arg)
+	if err != nil {
+		return nil, err
+	}
+	if e.Op == "NOT" {
+		b, err := asBool(v)
+		if err != nil || b == nil {
+			return nil, err
+		}
+		return !b.(bool), nil
+	}
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+	case float64:
+		return -v, nil
+	}
+	return nil, fmt.Errorf("can not negate a %s", typeName(v))
+}
+
+func (s *scope) evalBinary(e *BinaryExpr) (interface{}, error) {
+	a, err := s.eval(e.LHS)
+	if err != nil {
+		return nil, err
+	}
+	b, err := s.eval(e.RHS)
+	if err != nil {
+		return nil, err
+	}
+
+	switch e.Op {
+	case "AND", "OR":
+		return logical(e.Op, a, b)
+	}
+
+	if a == nil || b == nil {
+		return nil, nil
+	}
+
+	switch e.Op {
+	case "=", "!=", "<", "<=", ">", ">=":
+		c, err := compare(a, b)
+		if err != nil {
+			return nil, err
+		}
+		switch e.Op {
+		case "=":
+			return c == 0, nil
+		case "!=":
+			return c != 0, nil
+		case "<":
+			return c < 0, nil
+		case "<=":
+			return c <= 0, nil
+		case ">":
+			return c > 0, nil
+		}
+		return c >= 0, nil
+	case "LIKE", "NOT LIKE":
+		str, ok1 := a.(string)
+		pattern, ok2 := b.(string)
+		if !ok1 || !ok2 {
+			return nil, fmt.Errorf("%s expects strings but got a %s and a %s", e.Op, typeName(a), typeName(b))
+		}
+		re, err := likeToRegexp(pattern)
+		if err != nil {
+			return nil, err
+		}
+		return re.MatchString(str) != (e.Op == "NOT LIKE"), nil
+	}
+
+	x, ok1 := a.(float64)
+	y, ok2 := b.(float64)
+	if !ok1 || !ok2 {
+		return nil, fmt.Errorf("operator %s expects numbers but got a %s and a %s", e.Op, typeName(a), typeName(b))
+	}
+	switch e.Op {
+	case "+":
+		return x + y, nil
+	case "-":
+		return x - y, nil
+	case "*":
+		return x * y, nil
+	case "/":
+		if y == 0 {
+			return nil, nil
+		}
+		return x / y, nil
+	case "%":
+		if y == 0 {
+			return nil, nil
+		}
+		return math.Mod(x, y), nil
+	}
+	return nil, fmt.Errorf("unknown operator %s", e.Op)
+}
+
+// logical implements the three-valued AND and OR, where null is unknown.
+func logical(op string, a, b interface{}) (interface{}, error) {
+	x, err := asBool(a)
+	if err != nil {
+		return nil, err
+	}
+	y, err := asBool(b)
+	if err != nil {
+		return nil, err
+	}
+	// The result is known as soon as one of the operands decides it.
+	decisive := op == "OR"
+	if x == decisive || y == decisive {
+		return decisive, nil
+	}
+	if x == nil || y == nil {
+		return nil, nil
+	}
+	return !decisive, nil
+}
+
+func (s *scope) evalIn(e *InExpr) (interface{}, error) {
+	v, err := s.eval(e.Arg)
+	if err != nil || v == nil {
+		return nil, err
+	}
+	sawNull := false
+	for _, item := range e.List {
+		iv, err := s.eval(item)
+		if err != nil {
+			return nil, err
+		}
+		if iv == nil {
+			sawNull = true
+			continue
+		}
+		c, err := compare(v, iv)
+		if err != nil {
+			return nil, err
+		}
+		if c == 0 {
+			return !e.Not, nil
+		}
+	}
+	if sawNull {
+		return nil, nil
+	}
+	return e.Not, nil
+}
+
+func (s *scope) evalAggregate(e *FuncCall) (interface{}, error) {
+	if !s.aggregate {
+		return nil, fmt.Errorf("aggregate function %s is not allowed here", e.Name)
+	}
+	if e.Star {
+		return float64(len(s.group)), nil
+	}
+	values := make([]interface{}, 0, len(s.group))
+	for _, row := range s.group {
+		v, err := s.rowScope(row).eval(e.Args[0])
+		if err != nil {
+			return nil, err
+		}
+		if v != nil {
+			values = append(values, v)
+		}
+	}
+	v, err := aggregates[e.Name](values)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", e.Name, err)
+	}
+	return v, nil
+}
+
+// hasAggregate returns true if the expression calls an aggregate function.
+func hasAggregate(e Expr) bool {
+	switch e := e.(type) {
+	case *FuncCall:
+		if isAggregate(e.Name) {
+			return true
+		}
+		for _, a := range e.Args {
+			if hasAggregate(a) {
+				return true
+			}
+		}
+	case *UnaryExpr:
+		return hasAggregate(e.Arg)
+	case *BinaryExpr:
+		return hasAggregate(e.LHS) || hasAggregate(e.RHS)
+	case *IsNullExpr:
+		return hasAggregate(e.Arg)
+	case *InExpr:
+		if hasAggregate(e.Arg) {
+			return true
+		}
+		for _, item := range e.List {
+			if hasAggregate(item) {
+				return true
+			}
+		}
+	}
+	return false
+}
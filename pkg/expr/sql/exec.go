@@ -0,0 +1,412 @@
+package sql
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// table is a set of rows with named columns.
+type table struct {
+	columns []column
+	rows    [][]interface{}
+}
+
+// Execute runs the query against tables, which are the input frames by table
+// name, and returns the result as a frame.
+func (q *Query) Execute(tables map[string]*data.Frame) (*data.Frame, error) {
+	t, err := loadTable(tables, q.From)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{q.From.RefName(): true}
+	for _, j := range q.Joins {
+		if seen[j.Table.RefName()] {
+			return nil, fmt.Errorf("table name %q specified more than once", j.Table.RefName())
+		}
+		seen[j.Table.RefName()] = true
+		right, err := loadTable(tables, j.Table)
+		if err != nil {
+			return nil, err
+		}
+		if t, err = join(t, right, j); err != nil {
+			return nil, err
+		}
+	}
+
+	if q.Where != nil {
+		s := &scope{columns: t.columns}
+		rows := t.rows[:0:0]
+		for _, row := range t.rows {
+			ok, err := s.rowScope(row).condition(q.Where, "WHERE")
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				rows = append(rows, row)
+			}
+		}
+		t.rows = rows
+	}
+
+	names, outputs, err := q.project(t)
+	if err != nil {
+		return nil, err
+	}
+
+	if q.Distinct {
+		seen := make(map[string]bool, len(outputs))
+		distinct := outputs[:0:0]
+		for _, o := range outputs {
+			k := rowKey(o.values)
+			if !seen[k] {
+				seen[k] = true
+				distinct = append(distinct, o)
+			}
+		}
+		outputs = distinct
+	}
+
+	if len(q.OrderBy) > 0 {
+		if err := q.sort(names, outputs); err != nil {
+			return nil, err
+		}
+	}
+
+	if q.Limit >= 0 && len(outputs) > q.Limit {
+		outputs = outputs[:q.Limit]
+	}
+
+	return toFrame(names, outputs)
+}
+
+// loadTable reads the frame of ref into a table.
+func loadTable(tables map[string]*data.Frame, ref TableRef) (*table, error) {
+	frame, ok := tables[ref.Name]
+	if !ok || frame == nil {
+		return nil, fmt.Errorf("table %q does not exist", ref.Name)
+	}
+	t := &table{
+		columns: make([]column, len(frame.Fields)),
+		rows:    make([][]interface{}, frame.Rows()),
+	}
+	for i, f := range frame.Fields {
+		t.columns[i] = column{table: ref.RefName(), name: f.Name}
+	}
+	for rowIdx := range t.rows {
+		row := make([]interface{}, len(frame.Fields))
+		for i, f := range frame.Fields {
+			v, ok := f.ConcreteAt(rowIdx)
+			if !ok {
+				continue
+			}
+			nv, err := normalize(v)
+			if err != nil {
+				return nil, fmt.Errorf("column %q of table %q: %w", f.Name, ref.Name, err)
+			}
+			row[i] = nv
+		}
+		t.rows[rowIdx] = row
+	}
+	return t, nil
+}
+
+// join joins the rows of left and right for which the ON condition is true.
+func join(left, right *table, j Join) (*table, error) {
+	res := &table{columns: append(append([]column{}, left.columns...), right.columns...)}
+	s := &scope{columns: res.columns}
+	for _, l := range left.rows {
+		matched := false
+		for _, r := range right.rows {
+			row := append(append(make([]interface{}, 0, len(res.columns)), l...), r...)
+			ok, err := s.rowScope(row).condition(j.On, "ON")
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				matched = true
+				res.rows = append(res.rows, row)
+			}
+		}
+		if !matched && j.Type == JoinLeft {
+			row := append(make([]interface{}, 0, len(res.columns)), l...)
+			res.rows = append(res.rows, append(row, make([]interface{}, len(right.columns))...))
+		}
+	}
+	return res, nil
+}
+
+// condition evaluates a condition of the clause named clause.
+func (s *scope) condition(e Expr, clause string) (bool, error) {
+	v, err := s.eval(e)
+	if err != nil {
+		return false, err
+	}
+	ok, err := truthy(v)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", clause, err)
+	}
+	return ok, nil
+}
+
+// outputRow is a row of the result, with the scope it was computed in so that
+// ORDER BY can use expressions that are not part of the result.
+type outputRow struct {
+	values []interface{}
+	scope  *scope
+	keys   []interface{}
+}
+
+// project computes the SELECT columns for each row, or for each group of rows
+// if the query aggregates.
+func (q *Query) project(t *table) ([]string, []outputRow, error) {
+	aggregate := len(q.GroupBy) > 0 || (q.Having != nil && hasAggregate(q.Having))
+	for _, c := range q.Columns {
+		if !c.Star && hasAggregate(c.Expr) {
+			aggregate = true
+		}
+	}
+	for _, o := range q.OrderBy {
+		if hasAggregate(o.Expr) {
+			aggregate = true
+		}
+	}
+	if q.Having != nil && !aggregate {
+		return nil, nil, fmt.Errorf("HAVING requires GROUP BY or aggregate functions")
+	}
+
+	var names []string
+	var exprs []Expr
+	for _, c := range q.Columns {
+		if !c.Star {
+			names = append(names, outputName(c))
+			exprs = append(exprs, c.Expr)
+			continue
+		}
+		if aggregate {
+			return nil, nil, fmt.Errorf("* can not be used with GROUP BY or aggregate functions")
+		}
+		found := false
+		for _, col := range t.columns {
+			if c.StarTable == "" || c.StarTable == col.table {
+				found = true
+				names = append(names, col.name)
+				exprs = append(exprs, &ColumnRef{Table: col.table, Name: col.name})
+			}
+		}
+		if !found {
+			return nil, nil, fmt.Errorf("table %q does not exist", c.StarTable)
+		}
+	}
+
+	var scopes []*scope
+	if aggregate {
+		groups, err := q.groups(t)
+		if err != nil {
+			return nil, nil, err
+		}
+		groupBy := make(map[string]bool, len(q.GroupBy))
+		groupCols := map[int]bool{}
+		for _, g := range q.GroupBy {
+			groupBy[g.String()] = true
+			if ref, ok := g.(*ColumnRef); ok {
+				idx, err := lookup(t.columns, ref)
+				if err != nil {
+					return nil, nil, err
+				}
+				groupCols[idx] = true
+			}
+		}
+		for _, g := range groups {
+			row := make([]interface{}, len(t.columns))
+			if len(g) > 0 {
+				row = g[0]
+			}
+			scopes = append(scopes, &scope{columns: t.columns, row: row, aggregate: true, group: g, groupBy: groupBy, groupCols: groupCols})
+		}
+	} else {
+		for _, row := range t.rows {
+			scopes = append(scopes, &scope{columns: t.columns, row: row})
+		}
+	}
+
+	outputs := make([]outputRow, 0, len(scopes))
+	for _, s := range scopes {
+		values := make([]interface{}, len(exprs))
+		for i, e := range exprs {
+			v, err := s.eval(e)
+			if err != nil {
+				return nil, nil, err
+			}
+			values[i] = v
+		}
+		s.outputs = make(map[string]interface{}, len(q.Columns))
+		for i, c := range q.Columns {
+			if !c.Star && c.Alias != "" {
+				s.outputs[c.Alias] = values[i]
+			}
+		}
+		if q.Having != nil {
+			ok, err := s.condition(q.Having, "HAVING")
+			if err != nil {
+				return nil, nil, err
+			}
+			if !ok {
+				continue
+			}
+		}
+		outputs = append(outputs, outputRow{values: values, scope: s})
+	}
+	return names, outputs, nil
+}
+
+// groups splits the rows of t by the values of the GROUP BY expressions, in
+// the order the groups first appear. Without GROUP BY all the rows are in a
+// single group, even when there are no rows.
+func (q *Query) groups(t *table) ([][][]interface{}, error) {
+	if len(q.GroupBy) == 0 {
+		return [][][]interface{}{t.rows}, nil
+	}
+	var groups [][][]interface{}
+	index := map[string]int{}
+	s := &scope{columns: t.columns}
+	for _, row := range t.rows {
+		values := make([]interface{}, len(q.GroupBy))
+		for i, g := range q.GroupBy {
+			if hasAggregate(g) {
+				return nil, fmt.Errorf("aggregate functions are not allowed in GROUP BY")
+			}
+			v, err := s.rowScope(row).eval(g)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = v
+		}
+		k := rowKey(values)
+		idx, ok := index[k]
+		if !ok {
+			idx = len(groups)
+			index[k] = idx
+			groups = append(groups, nil)
+		}
+		groups[idx] = append(groups[idx], row)
+	}
+	return groups, nil
+}
+
+// outputName returns the name of the result column of a select item.
+func outputName(c SelectItem) string {
+	if c.Alias != "" {
+		return c.Alias
+	}
+	if ref, ok := c.Expr.(*ColumnRef); ok {
+		return ref.Name
+	}
+	return c.Expr.String()
+}
+
+// sort sorts the rows by the ORDER BY expressions. An expression that is an
+// integer literal refers to a result column by position, starting at 1.
+func (q *Query) sort(names []string, rows []outputRow) error {
+	for i := range rows {
+		rows[i].keys = make([]interface{}, len(q.OrderBy))
+		for j, o := range q.OrderBy {
+			if lit, ok := o.Expr.(*Literal); ok {
+				pos, ok := lit.Value.(float64)
+				if !ok || pos != float64(int(pos)) || pos < 1 || int(pos) > len(names) {
+					return fmt.Errorf("ORDER BY position %s is not in the select list", lit)
+				}
+				rows[i].keys[j] = rows[i].values[int(pos)-1]
+				continue
+			}
+			v, err := rows[i].scope.eval(o.Expr)
+			if err != nil {
+				return err
+			}
+			rows[i].keys[j] = v
+		}
+	}
+
+	var sortErr error
+	sort.SliceStable(rows, func(a, b int) bool {
+		for j, o := range q.OrderBy {
+			c, err := compareNullsFirst(rows[a].keys[j], rows[b].keys[j])
+			if err != nil {
+				sortErr = fmt.Errorf("ORDER BY: %w", err)
+				return false
+			}
+			if c == 0 {
+				continue
+			}
+			if o.Desc {
+				return c > 0
+			}
+			return c < 0
+		}
+		return false
+	})
+	return sortErr
+}
+
+// toFrame converts the result rows to a frame. The type of each column is
+// the type of its non-null values, which must all be the same.
+func toFrame(names []string, rows []outputRow) (*data.Frame, error) {
+	frame := data.NewFrame("")
+	for i, name := range names {
+		var colType interface{}
+		for _, r := range rows {
+			v := r.values[i]
+			if v == nil {
+				continue
+			}
+			if colType == nil {
+				colType = v
+				continue
+			}
+			if typeName(v) != typeName(colType) {
+				return nil, fmt.Errorf("column %q has values of different types: %s and %s", name, typeName(colType), typeName(v))
+			}
+		}
+
+		var field *data.Field
+		switch colType.(type) {
+		case string:
+			vals := make([]*string, len(rows))
+			for j, r := range rows {
+				if v, ok := r.values[i].(string); ok {
+					vals[j] = &v
+				}
+			}
+			field = data.NewField(name, nil, vals)
+		case bool:
+			vals := make([]*bool, len(rows))
+			for j, r := range rows {
+				if v, ok := r.values[i].(bool); ok {
+					vals[j] = &v
+				}
+			}
+			field = data.NewField(name, nil, vals)
+		case time.Time:
+			vals := make([]*time.Time, len(rows))
+			for j, r := range rows {
+				if v, ok := r.values[i].(time.Time); ok {
+					vals[j] = &v
+				}
+			}
+			field = data.NewField(name, nil, vals)
+		default:
+			vals := make([]*float64, len(rows))
+			for j, r := range rows {
+				if v, ok := r.values[i].(float64); ok {
+					vals[j] = &v
+				}
+			}
+			field = data.NewField(name, nil, vals)
+		}
+		frame.Fields = append(frame.Fields, field)
+	}
+	return frame, nil
+}
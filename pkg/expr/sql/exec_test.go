@@ -0,0 +1,205 @@
+package sql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func strPtr(s string) *string     { return &s }
+func floatPtr(f float64) *float64 { return &f }
+func boolPtr(b bool) *bool        { return &b }
+
+var testTables = map[string]*data.Frame{
+	"A": data.NewFrame("",
+		data.NewField("host", nil, []string{"a", "a", "b", "c"}),
+		data.NewField("dc", nil, []*string{strPtr("east"), strPtr("east"), strPtr("west"), nil}),
+		data.NewField("value", nil, []*float64{floatPtr(1), floatPtr(3), floatPtr(10), nil}),
+	),
+	"B": data.NewFrame("",
+		data.NewField("host", nil, []string{"a", "b", "d"}),
+		data.NewField("max", nil, []int64{2, 5, 1}),
+	),
+	"T": data.NewFrame("",
+		data.NewField("time", nil, []time.Time{time.Unix(20, 0), time.Unix(10, 0)}),
+		data.NewField("up", nil, []bool{true, false}),
+	),
+}
+
+func TestExecute(t *testing.T) {
+	var tests = []struct {
+		name   string
+		query  string
+		errIs  assert.ErrorAssertionFunc
+		result *data.Frame
+	}{
+		{
+			name:  "select star",
+			query: "SELECT * FROM B",
+			errIs: assert.NoError,
+			result: data.NewFrame("",
+				data.NewField("host", nil, []*string{strPtr("a"), strPtr("b"), strPtr("d")}),
+				data.NewField("max", nil, []*float64{floatPtr(2), floatPtr(5), floatPtr(1)}),
+			),
+		},
+		{
+			name:  "where filters out rows where the condition is null",
+			query: "SELECT host, value * 2 AS double FROM A WHERE value > 2 OR dc = 'nowhere'",
+			errIs: assert.NoError,
+			result: data.NewFrame("",
+				data.NewField("host", nil, []*string{strPtr("a"), strPtr("b")}),
+				data.NewField("double", nil, []*float64{floatPtr(6), floatPtr(20)}),
+			),
+		},
+		{
+			name:  "is null, in and like",
+			query: "SELECT host FROM A WHERE dc IS NULL OR (host IN ('b', 'x') AND dc LIKE 'w_s%')",
+			errIs: assert.NoError,
+			result: data.NewFrame("",
+				data.NewField("host", nil, []*string{strPtr("b"), strPtr("c")}),
+			),
+		},
+		{
+			name:  "group by with aggregates",
+			query: "SELECT dc, COUNT(*) AS rows, COUNT(value), SUM(value), AVG(value), MIN(host), MAX(value) FROM A GROUP BY dc",
+			errIs: assert.NoError,
+			result: data.NewFrame("",
+				data.NewField("dc", nil, []*string{strPtr("east"), strPtr("west"), nil}),
+				data.NewField("rows", nil, []*float64{floatPtr(2), floatPtr(1), floatPtr(1)}),
+				data.NewField("COUNT(value)", nil, []*float64{floatPtr(2), floatPtr(1), floatPtr(0)}),
+				data.NewField("SUM(value)", nil, []*float64{floatPtr(4), floatPtr(10), nil}),
+				data.NewField("AVG(value)", nil, []*float64{floatPtr(2), floatPtr(10), nil}),
+				data.NewField("MIN(host)", nil, []*string{strPtr("a"), strPtr("b"), strPtr("c")}),
+				data.NewField("MAX(value)", nil, []*float64{floatPtr(3), floatPtr(10), nil}),
+			),
+		},
+		{
+			name:  "having and order by an alias",
+			query: "SELECT host, SUM(value) AS total FROM A GROUP BY host HAVING total IS NOT NULL ORDER BY total DESC",
+			errIs: assert.NoError,
+			result: data.NewFrame("",
+				data.NewField("host", nil, []*string{strPtr("b"), strPtr("a")}),
+				data.NewField("total", nil, []*float64{floatPtr(10), floatPtr(4)}),
+			),
+		},
+		{
+			name:  "aggregate without group by on no rows",
+			query: "SELECT COUNT(*), SUM(value) FROM A WHERE value > 100",
+			errIs: assert.NoError,
+			result: data.NewFrame("",
+				data.NewField("COUNT(*)", nil, []*float64{floatPtr(0)}),
+				data.NewField("SUM(value)", nil, []*float64{nil}),
+			),
+		},
+		{
+			name:  "inner join",
+			query: "SELECT a.host, a.value, b.max, a.value > b.max AS exceeded FROM A a JOIN B b ON a.host = b.host",
+			errIs: assert.NoError,
+			result: data.NewFrame("",
+				data.NewField("host", nil, []*string{strPtr("a"), strPtr("a"), strPtr("b")}),
+				data.NewField("value", nil, []*float64{floatPtr(1), floatPtr(3), floatPtr(10)}),
+				data.NewField("max", nil, []*float64{floatPtr(2), floatPtr(2), floatPtr(5)}),
+				data.NewField("exceeded", nil, []*bool{boolPtr(false), boolPtr(true), boolPtr(true)}),
+			),
+		},
+		{
+			name:  "left join keeps unmatched rows",
+			query: "SELECT DISTINCT A.host, COALESCE(B.max, -1) AS max FROM A LEFT JOIN B ON A.host = B.host ORDER BY 2, 1",
+			errIs: assert.NoError,
+			result: data.NewFrame("",
+				data.NewField("host", nil, []*string{strPtr("c"), strPtr("a"), strPtr("b")}),
+				data.NewField("max", nil, []*float64{floatPtr(-1), floatPtr(2), floatPtr(5)}),
+			),
+		},
+		{
+			name:  "join and group by",
+			query: "SELECT a.host, MAX(a.value) - MIN(b.max) AS diff FROM A a JOIN B b ON a.host = b.host GROUP BY a.host",
+			errIs: assert.NoError,
+			result: data.NewFrame("",
+				data.NewField("host", nil, []*string{strPtr("a"), strPtr("b")}),
+				data.NewField("diff", nil, []*float64{floatPtr(1), floatPtr(5)}),
+			),
+		},
+		{
+			name:  "order by an expression that is not selected and limit",
+			query: "SELECT up FROM T ORDER BY time LIMIT 1",
+			errIs: assert.NoError,
+			result: data.NewFrame("",
+				data.NewField("up", nil, []*bool{boolPtr(false)}),
+			),
+		},
+		{
+			name:  "time columns",
+			query: "SELECT MAX(time) AS last FROM T",
+			errIs: assert.NoError,
+			result: data.NewFrame("",
+				data.NewField("last", nil, []*time.Time{timePtr(time.Unix(20, 0))}),
+			),
+		},
+		{
+			name:  "scalar functions",
+			query: "SELECT UPPER(host), ROUND(value / 3, 2), ABS(-value) FROM A WHERE host = 'b'",
+			errIs: assert.NoError,
+			result: data.NewFrame("",
+				data.NewField("UPPER(host)", nil, []*string{strPtr("B")}),
+				data.NewField("ROUND((value / 3), 2)", nil, []*float64{floatPtr(3.33)}),
+				data.NewField("ABS((-value))", nil, []*float64{floatPtr(10)}),
+			),
+		},
+		{
+			name:  "unknown table",
+			query: "SELECT * FROM X",
+			errIs: assert.Error,
+		},
+		{
+			name:  "unknown column",
+			query: "SELECT nope FROM A",
+			errIs: assert.Error,
+		},
+		{
+			name:  "ambiguous column",
+			query: "SELECT host FROM A JOIN B ON A.host = B.host",
+			errIs: assert.Error,
+		},
+		{
+			name:  "column not in group by",
+			query: "SELECT host, COUNT(*) FROM A GROUP BY dc",
+			errIs: assert.Error,
+		},
+		{
+			name:  "aggregate in where",
+			query: "SELECT host FROM A WHERE SUM(value) > 1",
+			errIs: assert.Error,
+		},
+		{
+			name:  "comparing different types",
+			query: "SELECT host FROM A WHERE host > 1",
+			errIs: assert.Error,
+		},
+		{
+			name:  "column with values of different types",
+			query: "SELECT COALESCE(value, host) FROM A",
+			errIs: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := Parse(tt.query)
+			require.NoError(t, err)
+			res, err := q.Execute(testTables)
+			tt.errIs(t, err)
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tt.result, res, data.FrameTestCompareOptions()...); diff != "" {
+				t.Errorf("Result mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func timePtr(t time.Time) *time.Time { return &t }
@@ -0,0 +1,165 @@
+package sql
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// scalarFunc is a function that is applied to the values of a single row.
+type scalarFunc struct {
+	minArgs int
+	maxArgs int // -1 for no limit
+	f       func(args []interface{}) (interface{}, error)
+}
+
+var scalarFuncs = map[string]scalarFunc{
+	"ABS":      {1, 1, numberFunc(math.Abs)},
+	"CEIL":     {1, 1, numberFunc(math.Ceil)},
+	"FLOOR":    {1, 1, numberFunc(math.Floor)},
+	"ROUND":    {1, 2, round},
+	"LOWER":    {1, 1, stringFunc(strings.ToLower)},
+	"UPPER":    {1, 1, stringFunc(strings.ToUpper)},
+	"COALESCE": {1, -1, coalesce},
+}
+
+// aggregateFunc is a function that is applied to the values of all the rows of
+// a group. Null values are removed before the function is called.
+type aggregateFunc func(values []interface{}) (interface{}, error)
+
+var aggregates = map[string]aggregateFunc{
+	"COUNT": count,
+	"SUM":   sum,
+	"AVG":   avg,
+	"MIN":   minMax(-1),
+	"MAX":   minMax(1),
+}
+
+func isAggregate(name string) bool {
+	_, ok := aggregates[name]
+	return ok
+}
+
+// checkArgs checks the number of arguments of a function call.
+func checkArgs(call *FuncCall) error {
+	if call.Star {
+		return nil
+	}
+	if isAggregate(call.Name) {
+		if len(call.Args) != 1 {
+			return fmt.Errorf("function %s expects 1 argument but got %d", call.Name, len(call.Args))
+		}
+		return nil
+	}
+	f := scalarFuncs[call.Name]
+	if len(call.Args) < f.minArgs || (f.maxArgs >= 0 && len(call.Args) > f.maxArgs) {
+		if f.minArgs == f.maxArgs {
+			return fmt.Errorf("function %s expects %d argument(s) but got %d", call.Name, f.minArgs, len(call.Args))
+		}
+		return fmt.Errorf("function %s expects at least %d argument(s) but got %d", call.Name, f.minArgs, len(call.Args))
+	}
+	return nil
+}
+
+func numberFunc(f func(float64) float64) func([]interface{}) (interface{}, error) {
+	return func(args []interface{}) (interface{}, error) {
+		switch v := args[0].(type) {
+		case nil:
+			return nil, nil
+		case float64:
+			return f(v), nil
+		}
+		return nil, fmt.Errorf("expected a number but got a %s", typeName(args[0]))
+	}
+}
+
+func stringFunc(f func(string) string) func([]interface{}) (interface{}, error) {
+	return func(args []interface{}) (interface{}, error) {
+		switch v := args[0].(type) {
+		case nil:
+			return nil, nil
+		case string:
+			return f(v), nil
+		}
+		return nil, fmt.Errorf("expected a string but got a %s", typeName(args[0]))
+	}
+}
+
+// round rounds a number half away from zero to the given number of decimal
+// places, which defaults to 0.
+func round(args []interface{}) (interface{}, error) {
+	places := 0.0
+	if len(args) == 2 {
+		p, ok := args[1].(float64)
+		if !ok {
+			return nil, fmt.Errorf("expected the number of decimal places to be a number but got a %s", typeName(args[1]))
+		}
+		places = math.Trunc(p)
+	}
+	switch v := args[0].(type) {
+	case nil:
+		return nil, nil
+	case float64:
+		pow := math.Pow(10, places)
+		return math.Round(v*pow) / pow, nil
+	}
+	return nil, fmt.Errorf("expected a number but got a %s", typeName(args[0]))
+}
+
+// coalesce returns its first non-null argument.
+func coalesce(args []interface{}) (interface{}, error) {
+	for _, a := range args {
+		if a != nil {
+			return a, nil
+		}
+	}
+	return nil, nil
+}
+
+func count(values []interface{}) (interface{}, error) {
+	return float64(len(values)), nil
+}
+
+func sum(values []interface{}) (interface{}, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	total := 0.0
+	for _, v := range values {
+		f, ok := v.(float64)
+		if !ok {
+			return nil, fmt.Errorf("SUM expects numbers but got a %s", typeName(v))
+		}
+		total += f
+	}
+	return total, nil
+}
+
+func avg(values []interface{}) (interface{}, error) {
+	total, err := sum(values)
+	if err != nil || total == nil {
+		return total, err
+	}
+	return total.(float64) / float64(len(values)), nil
+}
+
+// minMax returns MIN when sign is -1, and MAX when sign is 1.
+func minMax(sign int) aggregateFunc {
+	return func(values []interface{}) (interface{}, error) {
+		var res interface{}
+		for _, v := range values {
+			if res == nil {
+				res = v
+				continue
+			}
+			c, err := compare(v, res)
+			if err != nil {
+				return nil, err
+			}
+			if c == sign {
+				res = v
+			}
+		}
+		return res, nil
+	}
+}
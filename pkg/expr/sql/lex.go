@@ -0,0 +1,211 @@
+package sql
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// tokenType identifies the type of lexical tokens.
+type tokenType int
+
+const (
+	tokenEOF tokenType = iota
+	tokenIdent
+	tokenNumber
+	tokenString
+	tokenComma
+	tokenDot
+	tokenLeftParen
+	tokenRightParen
+	tokenStar
+	tokenPlus
+	tokenMinus
+	tokenSlash
+	tokenPercent
+	tokenEq
+	tokenNotEq
+	tokenLess
+	tokenLessEq
+	tokenGreater
+	tokenGreaterEq
+)
+
+// token is a lexical token of a query. Quoted identifiers are tokenIdent
+// tokens with quoted set, so they are never treated as keywords.
+type token struct {
+	typ    tokenType
+	val    string
+	pos    int
+	quoted bool
+}
+
+func (t token) String() string {
+	switch t.typ {
+	case tokenEOF:
+		return "end of query"
+	case tokenString:
+		return fmt.Sprintf("'%s'", t.val)
+	}
+	return fmt.Sprintf("%q", t.val)
+}
+
+// isKeyword returns true if the token is the unquoted keyword kw.
+func (t token) isKeyword(kw string) bool {
+	return t.typ == tokenIdent && !t.quoted && strings.EqualFold(t.val, kw)
+}
+
+// lex splits a query into tokens. The last token is always tokenEOF.
+func lex(query string) ([]token, error) {
+	var tokens []token
+	runes := []rune(query)
+	for pos := 0; pos < len(runes); {
+		r := runes[pos]
+		switch {
+		case unicode.IsSpace(r):
+			pos++
+		case r == '-' && pos+1 < len(runes) && runes[pos+1] == '-':
+			// A comment runs to the end of the line.
+			for pos < len(runes) && runes[pos] != '\n' {
+				pos++
+			}
+		case isIdentStart(r):
+			start := pos
+			for pos < len(runes) && isIdentChar(runes[pos]) {
+				pos++
+			}
+			tokens = append(tokens, token{typ: tokenIdent, val: string(runes[start:pos]), pos: start})
+		case unicode.IsDigit(r) || (r == '.' && pos+1 < len(runes) && unicode.IsDigit(runes[pos+1])):
+			start := pos
+			pos = scanNumber(runes, pos)
+			tokens = append(tokens, token{typ: tokenNumber, val: string(runes[start:pos]), pos: start})
+		case r == '\'':
+			val, end, err := scanQuoted(runes, pos, '\'')
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{typ: tokenString, val: val, pos: pos})
+			pos = end
+		case r == '"' || r == '`':
+			val, end, err := scanQuoted(runes, pos, r)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{typ: tokenIdent, val: val, pos: pos, quoted: true})
+			pos = end
+		default:
+			typ, width := operator(runes, pos)
+			if width == 0 {
+				return nil, fmt.Errorf("unexpected character %q at position %d", r, pos)
+			}
+			tokens = append(tokens, token{typ: typ, val: string(runes[pos : pos+width]), pos: pos})
+			pos += width
+		}
+	}
+	return append(tokens, token{typ: tokenEOF, pos: len(runes)}), nil
+}
+
+func isIdentStart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r)
+}
+
+func isIdentChar(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// scanNumber returns the position after the number that starts at pos.
+func scanNumber(runes []rune, pos int) int {
+	for pos < len(runes) && unicode.IsDigit(runes[pos]) {
+		pos++
+	}
+	if pos < len(runes) && runes[pos] == '.' {
+		pos++
+		for pos < len(runes) && unicode.IsDigit(runes[pos]) {
+			pos++
+		}
+	}
+	if pos < len(runes) && (runes[pos] == 'e' || runes[pos] == 'E') {
+		exp := pos + 1
+		if exp < len(runes) && (runes[exp] == '+' || runes[exp] == '-') {
+			exp++
+		}
+		if exp < len(runes) && unicode.IsDigit(runes[exp]) {
+			pos = exp
+			for pos < len(runes) && unicode.IsDigit(runes[pos]) {
+				pos++
+			}
+		}
+	}
+	return pos
+}
+
+// scanQuoted reads a string quoted with q that starts at pos. A doubled quote
+// character inside the string stands for the character itself.
+func scanQuoted(runes []rune, pos int, q rune) (string, int, error) {
+	var sb strings.Builder
+	for i := pos + 1; i < len(runes); i++ {
+		if runes[i] != q {
+			sb.WriteRune(runes[i])
+			continue
+		}
+		if i+1 < len(runes) && runes[i+1] == q {
+			sb.WriteRune(q)
+			i++
+			continue
+		}
+		return sb.String(), i + 1, nil
+	}
+	return "", 0, fmt.Errorf("unterminated quoted string starting at position %d", pos)
+}
+
+// operator returns the type and width of the operator at pos, and a width of
+// 0 if there is no operator at pos.
+func operator(runes []rune, pos int) (tokenType, int) {
+	next := rune(0)
+	if pos+1 < len(runes) {
+		next = runes[pos+1]
+	}
+	switch runes[pos] {
+	case ',':
+		return tokenComma, 1
+	case '.':
+		return tokenDot, 1
+	case '(':
+		return tokenLeftParen, 1
+	case ')':
+		return tokenRightParen, 1
+	case '*':
+		return tokenStar, 1
+	case '+':
+		return tokenPlus, 1
+	case '-':
+		return tokenMinus, 1
+	case '/':
+		return tokenSlash, 1
+	case '%':
+		return tokenPercent, 1
+	case '=':
+		if next == '=' {
+			return tokenEq, 2
+		}
+		return tokenEq, 1
+	case '!':
+		if next == '=' {
+			return tokenNotEq, 2
+		}
+	case '<':
+		switch next {
+		case '=':
+			return tokenLessEq, 2
+		case '>':
+			return tokenNotEq, 2
+		}
+		return tokenLess, 1
+	case '>':
+		if next == '=' {
+			return tokenGreaterEq, 2
+		}
+		return tokenGreater, 1
+	}
+	return tokenEOF, 0
+}
@@ -0,0 +1,549 @@
+package sql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// reserved are the keywords that can not be used as unquoted identifiers.
+var reserved = map[string]bool{
+	"SELECT": true, "DISTINCT": true, "FROM": true, "WHERE": true, "GROUP": true,
+	"BY": true, "HAVING": true, "ORDER": true, "ASC": true, "DESC": true,
+	"LIMIT": true, "JOIN": true, "INNER": true, "LEFT": true, "OUTER": true,
+	"ON": true, "AS": true, "AND": true, "OR": true, "NOT": true, "IS": true,
+	"NULL": true, "IN": true, "LIKE": true, "TRUE": true, "FALSE": true,
+}
+
+func isReserved(s string) bool {
+	return reserved[strings.ToUpper(s)]
+}
+
+// Parse parses a query of the restricted SQL dialect:
+//
+//	SELECT [DISTINCT] select_item [, ...]
+//	FROM table [[AS] alias]
+//	[[INNER | LEFT [OUTER]] JOIN table [[AS] alias] ON condition ...]
+//	[WHERE condition]
+//	[GROUP BY expression [, ...]]
+//	[HAVING condition]
+//	[ORDER BY expression [ASC | DESC] [, ...]]
+//	[LIMIT count]
+func Parse(query string) (*Query, error) {
+	tokens, err := lex(query)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	q, err := p.query()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.typ != tokenEOF {
+		return nil, p.unexpected(t, "end of query")
+	}
+	return q, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.typ != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// acceptKeyword consumes the next token if it is the keyword kw.
+func (p *parser) acceptKeyword(kw string) bool {
+	if p.peek().isKeyword(kw) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expectKeyword(kw string) error {
+	if t := p.peek(); !t.isKeyword(kw) {
+		return p.unexpected(t, kw)
+	}
+	p.pos++
+	return nil
+}
+
+// accept consumes the next token if it has type typ.
+func (p *parser) accept(typ tokenType) bool {
+	if p.peek().typ == typ {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(typ tokenType, what string) error {
+	if t := p.peek(); t.typ != typ {
+		return p.unexpected(t, what)
+	}
+	p.pos++
+	return nil
+}
+
+func (p *parser) unexpected(t token, expected string) error {
+	return fmt.Errorf("unexpected %v at position %d, expected %s", t, t.pos, expected)
+}
+
+// ident reads an identifier that is not a reserved keyword.
+func (p *parser) ident(what string) (string, error) {
+	t := p.peek()
+	if t.typ != tokenIdent || (!t.quoted && isReserved(t.val)) {
+		return "", p.unexpected(t, what)
+	}
+	p.pos++
+	return t.val, nil
+}
+
+// isAlias returns true if the next token can be an alias without AS.
+func (p *parser) isAlias() bool {
+	t := p.peek()
+	return t.typ == tokenIdent && (t.quoted || !isReserved(t.val))
+}
+
+func (p *parser) query() (*Query, error) {
+	if err := p.expectKeyword("SELECT"); err != nil {
+		return nil, err
+	}
+	q := &Query{Limit: -1}
+	q.Distinct = p.acceptKeyword("DISTINCT")
+
+	for {
+		item, err := p.selectItem()
+		if err != nil {
+			return nil, err
+		}
+		q.Columns = append(q.Columns, item)
+		if !p.accept(tokenComma) {
+			break
+		}
+	}
+
+	if err := p.expectKeyword("FROM"); err != nil {
+		return nil, err
+	}
+	from, err := p.tableRef()
+	if err != nil {
+		return nil, err
+	}
+	q.From = from
+
+	for {
+		join, ok, err := p.join()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+		q.Joins = append(q.Joins, join)
+	}
+
+	if p.acceptKeyword("WHERE") {
+		if q.Where, err = p.expr(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.acceptKeyword("GROUP") {
+		if err := p.expectKeyword("BY"); err != nil {
+			return nil, err
+		}
+		if q.GroupBy, err = p.exprList(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.acceptKeyword("HAVING") {
+		if q.Having, err = p.expr(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.acceptKeyword("ORDER") {
+		if err := p.expectKeyword("BY"); err != nil {
+			return nil, err
+		}
+		for {
+			e, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			item := OrderItem{Expr: e}
+			if p.acceptKeyword("DESC") {
+				item.Desc = true
+			} else {
+				p.acceptKeyword("ASC")
+			}
+			q.OrderBy = append(q.OrderBy, item)
+			if !p.accept(tokenComma) {
+				break
+			}
+		}
+	}
+
+	if p.acceptKeyword("LIMIT") {
+		t := p.peek()
+		if t.typ != tokenNumber {
+			return nil, p.unexpected(t, "number of rows")
+		}
+		limit, err := strconv.Atoi(t.val)
+		if err != nil || limit < 0 {
+			return nil, fmt.Errorf("invalid limit %q at position %d", t.val, t.pos)
+		}
+		p.pos++
+		q.Limit = limit
+	}
+
+	return q, nil
+}
+
+func (p *parser) selectItem() (SelectItem, error) {
+	if p.accept(tokenStar) {
+		return SelectItem{Star: true}, nil
+	}
+	// table.*
+	if t := p.peek(); t.typ == tokenIdent && p.tokens[p.pos+1].typ == tokenDot && p.tokens[p.pos+2].typ == tokenStar {
+		table, err := p.ident("table name")
+		if err != nil {
+			return SelectItem{}, err
+		}
+		p.pos += 2
+		return SelectItem{Star: true, StarTable: table}, nil
+	}
+
+	e, err := p.expr()
+	if err != nil {
+		return SelectItem{}, err
+	}
+	item := SelectItem{Expr: e}
+	if p.acceptKeyword("AS") || p.isAlias() {
+		if item.Alias, err = p.ident("column alias"); err != nil {
+			return SelectItem{}, err
+		}
+	}
+	return item, nil
+}
+
+func (p *parser) tableRef() (TableRef, error) {
+	name, err := p.ident("table name")
+	if err != nil {
+		return TableRef{}, err
+	}
+	ref := TableRef{Name: name}
+	if p.acceptKeyword("AS") || p.isAlias() {
+		if ref.Alias, err = p.ident("table alias"); err != nil {
+			return TableRef{}, err
+		}
+	}
+	return ref, nil
+}
+
+// join reads a JOIN clause. It returns false if the next tokens are not a join.
+func (p *parser) join() (Join, bool, error) {
+	var j Join
+	switch {
+	case p.acceptKeyword("INNER"):
+		j.Type = JoinInner
+	case p.acceptKeyword("LEFT"):
+		j.Type = JoinLeft
+		p.acceptKeyword("OUTER")
+	case p.peek().isKeyword("JOIN"):
+		j.Type = JoinInner
+	default:
+		return j, false, nil
+	}
+	if err := p.expectKeyword("JOIN"); err != nil {
+		return j, false, err
+	}
+	table, err := p.tableRef()
+	if err != nil {
+		return j, false, err
+	}
+	j.Table = table
+	if err := p.expectKeyword("ON"); err != nil {
+		return j, false, err
+	}
+	if j.On, err = p.expr(); err != nil {
+		return j, false, err
+	}
+	return j, true, nil
+}
+
+func (p *parser) exprList() ([]Expr, error) {
+	var list []Expr
+	for {
+		e, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, e)
+		if !p.accept(tokenComma) {
+			return list, nil
+		}
+	}
+}
+
+// The expression grammar, from the lowest to the highest precedence:
+//
+//	expr       = and {"OR" and}
+//	and        = not {"AND" not}
+//	not        = "NOT" not | comparison
+//	comparison = additive [compare_op additive | "IS" ["NOT"] "NULL"
+//	             | ["NOT"] "IN" "(" expr_list ")" | ["NOT"] "LIKE" additive]
+//	additive   = multiplicative {("+" | "-") multiplicative}
+//	multiplicative = unary {("*" | "/" | "%") unary}
+//	unary      = "-" unary | primary
+//	primary    = number | string | "TRUE" | "FALSE" | "NULL" | "(" expr ")"
+//	             | name "(" ["*" | expr_list] ")" | [table "."] column
+func (p *parser) expr() (Expr, error) {
+	lhs, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptKeyword("OR") {
+		rhs, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		lhs = &BinaryExpr{Op: "OR", LHS: lhs, RHS: rhs}
+	}
+	return lhs, nil
+}
+
+func (p *parser) and() (Expr, error) {
+	lhs, err := p.not()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptKeyword("AND") {
+		rhs, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		lhs = &BinaryExpr{Op: "AND", LHS: lhs, RHS: rhs}
+	}
+	return lhs, nil
+}
+
+func (p *parser) not() (Expr, error) {
+	if p.acceptKeyword("NOT") {
+		arg, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		return &UnaryExpr{Op: "NOT", Arg: arg}, nil
+	}
+	return p.comparison()
+}
+
+var compareOps = map[tokenType]string{
+	tokenEq:        "=",
+	tokenNotEq:     "!=",
+	tokenLess:      "<",
+	tokenLessEq:    "<=",
+	tokenGreater:   ">",
+	tokenGreaterEq: ">=",
+}
+
+func (p *parser) comparison() (Expr, error) {
+	lhs, err := p.additive()
+	if err != nil {
+		return nil, err
+	}
+
+	if op, ok := compareOps[p.peek().typ]; ok {
+		p.pos++
+		rhs, err := p.additive()
+		if err != nil {
+			return nil, err
+		}
+		return &BinaryExpr{Op: op, LHS: lhs, RHS: rhs}, nil
+	}
+
+	if p.acceptKeyword("IS") {
+		not := p.acceptKeyword("NOT")
+		if err := p.expectKeyword("NULL"); err != nil {
+			return nil, err
+		}
+		return &IsNullExpr{Arg: lhs, Not: not}, nil
+	}
+
+	not := false
+	if t := p.peek(); t.isKeyword("NOT") {
+		if n := p.tokens[p.pos+1]; n.isKeyword("IN") || n.isKeyword("LIKE") {
+			p.pos++
+			not = true
+		}
+	}
+	switch {
+	case p.acceptKeyword("IN"):
+		if err := p.expect(tokenLeftParen, "("); err != nil {
+			return nil, err
+		}
+		list, err := p.exprList()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenRightParen, ")"); err != nil {
+			return nil, err
+		}
+		return &InExpr{Arg: lhs, List: list, Not: not}, nil
+	case p.acceptKeyword("LIKE"):
+		rhs, err := p.additive()
+		if err != nil {
+			return nil, err
+		}
+		op := "LIKE"
+		if not {
+			op = "NOT LIKE"
+		}
+		return &BinaryExpr{Op: op, LHS: lhs, RHS: rhs}, nil
+	}
+	return lhs, nil
+}
+
+func (p *parser) additive() (Expr, error) {
+	lhs, err := p.multiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		if t.typ != tokenPlus && t.typ != tokenMinus {
+			return lhs, nil
+		}
+		p.pos++
+		rhs, err := p.multiplicative()
+		if err != nil {
+			return nil, err
+		}
+		lhs = &BinaryExpr{Op: t.val, LHS: lhs, RHS: rhs}
+	}
+}
+
+func (p *parser) multiplicative() (Expr, error) {
+	lhs, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		if t.typ != tokenStar && t.typ != tokenSlash && t.typ != tokenPercent {
+			return lhs, nil
+		}
+		p.pos++
+		rhs, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		lhs = &BinaryExpr{Op: t.val, LHS: lhs, RHS: rhs}
+	}
+}
+
+func (p *parser) unary() (Expr, error) {
+	if p.accept(tokenMinus) {
+		arg, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &UnaryExpr{Op: "-", Arg: arg}, nil
+	}
+	return p.primary()
+}
+
+func (p *parser) primary() (Expr, error) {
+	t := p.peek()
+	switch {
+	case t.typ == tokenNumber:
+		p.pos++
+		f, err := strconv.ParseFloat(t.val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at position %d", t.val, t.pos)
+		}
+		return &Literal{Value: f}, nil
+	case t.typ == tokenString:
+		p.pos++
+		return &Literal{Value: t.val}, nil
+	case t.isKeyword("TRUE"):
+		p.pos++
+		return &Literal{Value: true}, nil
+	case t.isKeyword("FALSE"):
+		p.pos++
+		return &Literal{Value: false}, nil
+	case t.isKeyword("NULL"):
+		p.pos++
+		return &Literal{Value: nil}, nil
+	case t.typ == tokenLeftParen:
+		p.pos++
+		e, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenRightParen, ")"); err != nil {
+			return nil, err
+		}
+		return e, nil
+	case t.typ == tokenIdent && !t.quoted && p.tokens[p.pos+1].typ == tokenLeftParen:
+		return p.funcCall()
+	}
+
+	name, err := p.ident("expression")
+	if err != nil {
+		return nil, err
+	}
+	if !p.accept(tokenDot) {
+		return &ColumnRef{Name: name}, nil
+	}
+	// Keywords can be used as column names after a table qualifier.
+	column := p.peek()
+	if column.typ != tokenIdent {
+		return nil, p.unexpected(column, "column name")
+	}
+	p.pos++
+	return &ColumnRef{Table: name, Name: column.val}, nil
+}
+
+func (p *parser) funcCall() (Expr, error) {
+	t := p.next()
+	name := strings.ToUpper(t.val)
+	if _, ok := aggregates[name]; !ok {
+		if _, ok := scalarFuncs[name]; !ok {
+			return nil, fmt.Errorf("unknown function %q at position %d", t.val, t.pos)
+		}
+	}
+	p.pos++ // (
+
+	call := &FuncCall{Name: name}
+	if name == "COUNT" && p.accept(tokenStar) {
+		call.Star = true
+	} else if p.peek().typ != tokenRightParen {
+		args, err := p.exprList()
+		if err != nil {
+			return nil, err
+		}
+		call.Args = args
+	}
+	if err := p.expect(tokenRightParen, ")"); err != nil {
+		return nil, err
+	}
+	if err := checkArgs(call); err != nil {
+		return nil, fmt.Errorf("%v at position %d", err, t.pos)
+	}
+	return call, nil
+}
@@ -0,0 +1,128 @@
+package sql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	var tests = []struct {
+		name      string
+		query     string
+		errIs     assert.ErrorAssertionFunc
+		canonical string
+		tables    []string
+	}{
+		{
+			name:      "select star",
+			query:     "SELECT * FROM A",
+			errIs:     assert.NoError,
+			canonical: "SELECT * FROM A",
+			tables:    []string{"A"},
+		},
+		{
+			name:      "keywords are case insensitive",
+			query:     "select distinct host as h from A a where value > 1 order by h desc limit 10",
+			errIs:     assert.NoError,
+			canonical: "SELECT DISTINCT host AS h FROM A AS a WHERE (value > 1) ORDER BY h DESC LIMIT 10",
+			tables:    []string{"A"},
+		},
+		{
+			name:      "group by with aggregates and having",
+			query:     "SELECT host, AVG(value) avg_value, count(*) FROM A GROUP BY host HAVING COUNT(*) >= 2",
+			errIs:     assert.NoError,
+			canonical: "SELECT host, AVG(value) AS avg_value, COUNT(*) FROM A GROUP BY host HAVING (COUNT(*) >= 2)",
+			tables:    []string{"A"},
+		},
+		{
+			name:      "joins",
+			query:     "SELECT a.host, b.* FROM A a JOIN B AS b ON a.host = b.host LEFT OUTER JOIN C ON C.host = a.host",
+			errIs:     assert.NoError,
+			canonical: "SELECT a.host, b.* FROM A AS a JOIN B AS b ON (a.host = b.host) LEFT JOIN C ON (C.host = a.host)",
+			tables:    []string{"A", "B", "C"},
+		},
+		{
+			name:      "self join reads the table once",
+			query:     "SELECT x.value FROM A x INNER JOIN A y ON x.host = y.host",
+			errIs:     assert.NoError,
+			canonical: "SELECT x.value FROM A AS x JOIN A AS y ON (x.host = y.host)",
+			tables:    []string{"A"},
+		},
+		{
+			name:      "operator precedence",
+			query:     "SELECT 1 + 2 * -3 FROM A WHERE NOT a = 1 OR b < 2 AND c IS NOT NULL",
+			errIs:     assert.NoError,
+			canonical: "SELECT (1 + (2 * (-3))) FROM A WHERE ((NOT (a = 1)) OR ((b < 2) AND (c IS NOT NULL)))",
+			tables:    []string{"A"},
+		},
+		{
+			name:      "in, like and literals",
+			query:     "SELECT * FROM A WHERE host NOT IN ('a', 'it''s') AND name LIKE 'web%' AND up = TRUE AND v <> NULL",
+			errIs:     assert.NoError,
+			canonical: "SELECT * FROM A WHERE ((((host NOT IN ('a', 'it''s')) AND (name LIKE 'web%')) AND (up = TRUE)) AND (v != NULL))",
+			tables:    []string{"A"},
+		},
+		{
+			name:      "quoted identifiers",
+			query:     `SELECT "from", ` + "`my col`" + ` FROM "A"`,
+			errIs:     assert.NoError,
+			canonical: `SELECT "from", "my col" FROM A`,
+			tables:    []string{"A"},
+		},
+		{
+			name:      "comments are ignored",
+			query:     "SELECT value -- the value\nFROM A",
+			errIs:     assert.NoError,
+			canonical: "SELECT value FROM A",
+			tables:    []string{"A"},
+		},
+		{
+			name:  "missing from",
+			query: "SELECT value",
+			errIs: assert.Error,
+		},
+		{
+			name:  "unknown function",
+			query: "SELECT nope(value) FROM A",
+			errIs: assert.Error,
+		},
+		{
+			name:  "wrong number of arguments",
+			query: "SELECT SUM(a, b) FROM A",
+			errIs: assert.Error,
+		},
+		{
+			name:  "reserved word as column",
+			query: "SELECT from FROM A",
+			errIs: assert.Error,
+		},
+		{
+			name:  "trailing tokens",
+			query: "SELECT value FROM A B C",
+			errIs: assert.Error,
+		},
+		{
+			name:  "unterminated string",
+			query: "SELECT * FROM A WHERE host = 'a",
+			errIs: assert.Error,
+		},
+		{
+			name:  "unsupported statement",
+			query: "DELETE FROM A",
+			errIs: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := Parse(tt.query)
+			tt.errIs(t, err)
+			if err != nil {
+				return
+			}
+			require.Equal(t, tt.canonical, q.String())
+			require.Equal(t, tt.tables, q.Tables())
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package sql
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Values in a query are nil (NULL), float64, string, bool or time.Time.
+
+// normalize converts a value read from a data.Field to one of the value types
+// of a query.
+func normalize(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case nil, float64, string, bool, time.Time:
+		return v, nil
+	case float32:
+		return float64(v), nil
+	case int8:
+		return float64(v), nil
+	case int16:
+		return float64(v), nil
+	case int32:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case uint8:
+		return float64(v), nil
+	case uint16:
+		return float64(v), nil
+	case uint32:
+		return float64(v), nil
+	case uint64:
+		return float64(v), nil
+	}
+	return nil, fmt.Errorf("unsupported value type %T", v)
+}
+
+func typeName(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case time.Time:
+		return "time"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// compare returns -1, 0 or 1 if a is less than, equal to or greater than b.
+// Both values must be non-nil and of the same type.
+func compare(a, b interface{}) (int, error) {
+	switch a := a.(type) {
+	case float64:
+		if b, ok := b.(float64); ok {
+			switch {
+			case a < b:
+				return -1, nil
+			case a > b:
+				return 1, nil
+			}
+			return 0, nil
+		}
+	case string:
+		if b, ok := b.(string); ok {
+			return strings.Compare(a, b), nil
+		}
+	case bool:
+		if b, ok := b.(bool); ok {
+			switch {
+			case a == b:
+				return 0, nil
+			case !a:
+				return -1, nil
+			}
+			return 1, nil
+		}
+	case time.Time:
+		if b, ok := b.(time.Time); ok {
+			switch {
+			case a.Before(b):
+				return -1, nil
+			case a.After(b):
+				return 1, nil
+			}
+			return 0, nil
+		}
+	}
+	return 0, fmt.Errorf("can not compare %s with %s", typeName(a), typeName(b))
+}
+
+// compareNullsFirst is like compare but accepts nil values, which are less
+// than all other values. It is used to sort rows.
+func compareNullsFirst(a, b interface{}) (int, error) {
+	switch {
+	case a == nil && b == nil:
+		return 0, nil
+	case a == nil:
+		return -1, nil
+	case b == nil:
+		return 1, nil
+	}
+	return compare(a, b)
+}
+
+// truthy returns true if v is the boolean true or a non-zero number. Null
+// values are never true, so rows for which a condition is null are filtered out.
+func truthy(v interface{}) (bool, error) {
+	switch v := v.(type) {
+	case nil:
+		return false, nil
+	case bool:
+		return v, nil
+	case float64:
+		return v != 0 && !math.IsNaN(v), nil
+	}
+	return false, fmt.Errorf("expected a boolean condition but got a %s", typeName(v))
+}
+
+// asBool converts a value to a boolean for the logical operators, keeping nil
+// as the unknown value.
+func asBool(v interface{}) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	b, err := truthy(v)
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// valueKey returns a string that is equal for equal values, and is used to
+// group rows and to remove duplicate rows.
+func valueKey(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "n"
+	case float64:
+		return "f" + strconv.FormatFloat(v, 'g', -1, 64)
+	case string:
+		return "s" + strconv.Quote(v)
+	case bool:
+		return "b" + strconv.FormatBool(v)
+	case time.Time:
+		return "t" + strconv.FormatInt(v.UnixNano(), 10)
+	}
+	return fmt.Sprintf("?%v", v)
+}
+
+func rowKey(values []interface{}) string {
+	keys := make([]string, len(values))
+	for i, v := range values {
+		keys[i] = valueKey(v)
+	}
+	return strings.Join(keys, ",")
+}
+
+// likeToRegexp converts a LIKE pattern, where % matches any sequence of
+// characters and _ matches a single character, to a regular expression.
+func likeToRegexp(pattern string) (*regexp.Regexp, error) {
+	var sb strings.Builder
+	sb.WriteString("(?s)^")
+	for _, r := range pattern {
+		switch r {
+		case '%':
+			sb.WriteString(".*")
+		case '_':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.Compile(sb.String())
+}
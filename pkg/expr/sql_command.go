@@ -0,0 +1,260 @@
+package expr
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/expr/sql"
+)
+
+// SQLCommand is an expression command that runs a query of a restricted SQL
+// dialect over the results of other queries and expressions, where each table
+// is the RefID of a query or expression.
+type SQLCommand struct {
+	RawQuery string
+	Query    *sql.Query
+	refID    string
+}
+
+// NewSQLCommand creates a new SQLCommand. It will return an error
+// if there is an error parsing the query.
+func NewSQLCommand(refID, rawQuery string) (*SQLCommand, error) {
+	q, err := sql.Parse(rawQuery)
+	if err != nil {
+		return nil, err
+	}
+	return &SQLCommand{
+		RawQuery: rawQuery,
+		Query:    q,
+		refID:    refID,
+	}, nil
+}
+
+// UnmarshalSQLCommand creates a SQLCommand from Grafana's frontend query.
+func UnmarshalSQLCommand(rn *rawNode) (*SQLCommand, error) {
+	rawQuery, ok := rn.Query["expression"]
+	if !ok {
+		return nil, fmt.Errorf("sql command for refId %v is missing a query", rn.RefID)
+	}
+	queryString, ok := rawQuery.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected sql command for refId %v query to be a string, got %T", rn.RefID, rawQuery)
+	}
+
+	cmd, err := NewSQLCommand(rn.RefID, queryString)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sql command in '%v': %v", rn.RefID, err)
+	}
+	return cmd, nil
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (sc *SQLCommand) NeedsVars() []string {
+	return sc.Query.Tables()
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (sc *SQLCommand) Execute(ctx context.Context, vars mathexp.Vars) (mathexp.Results, error) {
+	tables := make(map[string]*data.Frame)
+	for _, name := range sc.Query.Tables() {
+		res, ok := vars[name]
+		if !ok {
+			return mathexp.Results{}, fmt.Errorf("no results for table %q", name)
+		}
+		frame, err := resultsToTable(res)
+		if err != nil {
+			return mathexp.Results{}, fmt.Errorf("table %q: %w", name, err)
+		}
+		tables[name] = frame
+	}
+
+	frame, err := sc.Query.Execute(tables)
+	if err != nil {
+		return mathexp.Results{}, err
+	}
+	return tableToResults(sc.refID, frame)
+}
+
+// resultsToTable converts the results of a query or an expression to a table.
+// Table data is used as is. Numbers and series become a table with a column
+// for each label, a "time" column for series, and a "value" column.
+func resultsToTable(res mathexp.Results) (*data.Frame, error) {
+	if len(res.Values) == 1 {
+		if t, ok := res.Values[0].(mathexp.TableData); ok {
+			return t.Frame, nil
+		}
+	}
+
+	var valueType string
+	labelSet := map[string]struct{}{}
+	for _, v := range res.Values {
+		var t string
+		switch v.(type) {
+		case mathexp.Number, mathexp.Scalar:
+			t = "numbers"
+		case mathexp.Series:
+			t = "series"
+		default:
+			return nil, fmt.Errorf("can not use more than one frame of table data as a table, or mix it with other types")
+		}
+		if valueType != "" && valueType != t {
+			return nil, fmt.Errorf("can not mix numbers and series in a table")
+		}
+		valueType = t
+		for k := range v.GetLabels() {
+			labelSet[k] = struct{}{}
+		}
+	}
+
+	labelKeys := make([]string, 0, len(labelSet))
+	for k := range labelSet {
+		labelKeys = append(labelKeys, k)
+	}
+	sort.Strings(labelKeys)
+
+	var labelValues [][]*string
+	var times []time.Time
+	var values []*float64
+	addRow := func(labels data.Labels, t *time.Time, f *float64) {
+		for i, k := range labelKeys {
+			if lv, ok := labels[k]; ok {
+				labelValues[i] = append(labelValues[i], &lv)
+			} else {
+				labelValues[i] = append(labelValues[i], nil)
+			}
+		}
+		if t != nil {
+			times = append(times, *t)
+		}
+		values = append(values, f)
+	}
+
+	labelValues = make([][]*string, len(labelKeys))
+	for _, v := range res.Values {
+		switch v := v.(type) {
+		case mathexp.Number:
+			addRow(v.GetLabels(), nil, v.GetFloat64Value())
+		case mathexp.Scalar:
+			addRow(nil, nil, v.GetFloat64Value())
+		case mathexp.Series:
+			for i := 0; i < v.Len(); i++ {
+				t, f := v.GetPoint(i)
+				addRow(v.GetLabels(), &t, f)
+			}
+		}
+	}
+
+	frame := data.NewFrame("")
+	for i, k := range labelKeys {
+		frame.Fields = append(frame.Fields, data.NewField(k, nil, labelValues[i]))
+	}
+	if valueType == "series" {
+		frame.Fields = append(frame.Fields, data.NewField("time", nil, times))
+	}
+	frame.Fields = append(frame.Fields, data.NewField("value", nil, values))
+	return frame, nil
+}
+
+// tableToResults converts the result of a SQL query. Boolean columns are
+// converted to 1 and 0 first, so that conditions can be used by alerting. A
+// table with a time column, a single numeric column and otherwise string
+// columns becomes a series for each distinct combination of the string
+// columns, which are its labels. A table with a single numeric column and
+// otherwise string columns becomes a set of labelled numbers. Any other table
+// is returned as table data.
+func tableToResults(refID string, frame *data.Frame) (mathexp.Results, error) {
+	frame.RefID = refID
+	for i, field := range frame.Fields {
+		if field.Type() != data.FieldTypeNullableBool {
+			continue
+		}
+		vals := make([]*float64, field.Len())
+		for j := 0; j < field.Len(); j++ {
+			if b := field.At(j).(*bool); b != nil {
+				f := 0.0
+				if *b {
+					f = 1
+				}
+				vals[j] = &f
+			}
+		}
+		frame.Fields[i] = data.NewField(field.Name, field.Labels, vals)
+	}
+
+	timeIdx := -1
+	numericIdx := -1
+	var stringIdxs []int
+	for i, field := range frame.Fields {
+		switch fType := field.Type(); {
+		case fType.Time() && timeIdx < 0:
+			timeIdx = i
+		case fType.Numeric() && numericIdx < 0:
+			numericIdx = i
+		case fType == data.FieldTypeString || fType == data.FieldTypeNullableString:
+			stringIdxs = append(stringIdxs, i)
+		default:
+			return mathexp.Results{Values: mathexp.Values{mathexp.TableData{Frame: frame}}}, nil
+		}
+	}
+	if numericIdx < 0 {
+		return mathexp.Results{Values: mathexp.Values{mathexp.TableData{Frame: frame}}}, nil
+	}
+
+	if timeIdx < 0 {
+		numbers, err := extractNumberSet(frame)
+		if err != nil {
+			return mathexp.Results{}, err
+		}
+		vals := make([]mathexp.Value, len(numbers))
+		for i, n := range numbers {
+			vals[i] = n
+		}
+		return mathexp.Results{Values: vals}, nil
+	}
+
+	var series []mathexp.Series
+	index := map[string]int{}
+	for row := 0; row < frame.Rows(); row++ {
+		t, ok := frame.Fields[timeIdx].ConcreteAt(row)
+		if !ok {
+			continue
+		}
+		labels := data.Labels{}
+		for _, i := range stringIdxs {
+			if lv, ok := frame.Fields[i].ConcreteAt(row); ok {
+				labels[frame.Fields[i].Name] = lv.(string)
+			}
+		}
+		key := labels.String()
+		idx, ok := index[key]
+		if !ok {
+			idx = len(series)
+			index[key] = idx
+			series = append(series, mathexp.NewSeries(refID, labels, 0))
+		}
+		var f *float64
+		if _, ok := frame.Fields[numericIdx].ConcreteAt(row); ok {
+			v, err := frame.FloatAt(numericIdx, row)
+			if err != nil {
+				return mathexp.Results{}, err
+			}
+			f = &v
+		}
+		if err := series[idx].AppendPoint(series[idx].Len(), t.(time.Time), f); err != nil {
+			return mathexp.Results{}, err
+		}
+	}
+
+	vals := make([]mathexp.Value, len(series))
+	for i, s := range series {
+		s.SortByTime(false)
+		vals[i] = s
+	}
+	return mathexp.Results{Values: vals}, nil
+}
@@ -0,0 +1,168 @@
+package expr
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnmarshalSQLCommand(t *testing.T) {
+	cmd, err := UnmarshalSQLCommand(&rawNode{
+		RefID: "C",
+		Query: map[string]interface{}{
+			"expression": "SELECT A.host, A.value - B.value AS diff FROM A JOIN B ON A.host = B.host",
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"A", "B"}, cmd.NeedsVars())
+
+	_, err = UnmarshalSQLCommand(&rawNode{
+		RefID: "C",
+		Query: map[string]interface{}{"expression": "SELECT FROM A"},
+	})
+	require.Error(t, err)
+
+	_, err = UnmarshalSQLCommand(&rawNode{RefID: "C", Query: map[string]interface{}{}})
+	require.Error(t, err)
+}
+
+func TestSQLCommandExecute(t *testing.T) {
+	number := func(labels data.Labels, f float64) mathexp.Number {
+		n := mathexp.NewNumber("", labels)
+		n.SetValue(&f)
+		return n
+	}
+	table := mathexp.TableData{Frame: data.NewFrame("",
+		data.NewField("host", nil, []string{"a", "b", "c"}),
+		data.NewField("cpu", nil, []float64{10, 95, 80}),
+		data.NewField("mem", nil, []float64{50, 60, 99}),
+	)}
+	series := mathexp.NewSeries("A", data.Labels{"host": "a"}, 2)
+	require.NoError(t, series.SetPoint(0, time.Unix(10, 0), fp(1)))
+	require.NoError(t, series.SetPoint(1, time.Unix(20, 0), fp(5)))
+
+	var tests = []struct {
+		name     string
+		query    string
+		vars     mathexp.Vars
+		errIs    assert.ErrorAssertionFunc
+		expected mathexp.Results
+	}{
+		{
+			name:  "table data to numbers",
+			query: "SELECT host, cpu FROM A WHERE cpu > 50 OR mem > 90",
+			vars:  mathexp.Vars{"A": mathexp.Results{Values: mathexp.Values{table}}},
+			errIs: assert.NoError,
+			expected: mathexp.Results{Values: mathexp.Values{
+				number(data.Labels{"host": "b"}, 95),
+				number(data.Labels{"host": "c"}, 80),
+			}},
+		},
+		{
+			name:  "conditions become 1 and 0",
+			query: "SELECT host, cpu > 90 OR mem > 90 AS firing FROM A",
+			vars:  mathexp.Vars{"A": mathexp.Results{Values: mathexp.Values{table}}},
+			errIs: assert.NoError,
+			expected: mathexp.Results{Values: mathexp.Values{
+				number(data.Labels{"host": "a"}, 0),
+				number(data.Labels{"host": "b"}, 1),
+				number(data.Labels{"host": "c"}, 1),
+			}},
+		},
+		{
+			name:  "numbers are joined by labels",
+			query: "SELECT A.host, A.value / B.value AS ratio FROM A JOIN B ON A.host = B.host",
+			vars: mathexp.Vars{
+				"A": mathexp.Results{Values: mathexp.Values{
+					number(data.Labels{"host": "a"}, 1),
+					number(data.Labels{"host": "b"}, 6),
+				}},
+				"B": mathexp.Results{Values: mathexp.Values{
+					number(data.Labels{"host": "b"}, 3),
+				}},
+			},
+			errIs: assert.NoError,
+			expected: mathexp.Results{Values: mathexp.Values{
+				number(data.Labels{"host": "b"}, 2),
+			}},
+		},
+		{
+			name:  "series are grouped into numbers",
+			query: "SELECT host, MAX(value) FROM A GROUP BY host",
+			vars:  mathexp.Vars{"A": mathexp.Results{Values: mathexp.Values{series}}},
+			errIs: assert.NoError,
+			expected: mathexp.Results{Values: mathexp.Values{
+				number(data.Labels{"host": "a"}, 5),
+			}},
+		},
+		{
+			name:  "tables with a time column become series",
+			query: "SELECT time, host, value * 2 FROM A ORDER BY time DESC",
+			vars:  mathexp.Vars{"A": mathexp.Results{Values: mathexp.Values{series}}},
+			errIs: assert.NoError,
+			expected: mathexp.Results{Values: mathexp.Values{
+				func() mathexp.Series {
+					s := mathexp.NewSeries("B", data.Labels{"host": "a"}, 2)
+					_ = s.SetPoint(0, time.Unix(10, 0), fp(2))
+					_ = s.SetPoint(1, time.Unix(20, 0), fp(10))
+					return s
+				}(),
+			}},
+		},
+		{
+			name:  "other tables are returned as table data",
+			query: "SELECT host, cpu, mem FROM A WHERE host = 'a'",
+			vars:  mathexp.Vars{"A": mathexp.Results{Values: mathexp.Values{table}}},
+			errIs: assert.NoError,
+			expected: mathexp.Results{Values: mathexp.Values{
+				mathexp.TableData{Frame: &data.Frame{
+					RefID: "B",
+					Fields: []*data.Field{
+						data.NewField("host", nil, []*string{stringPointer("a")}),
+						data.NewField("cpu", nil, []*float64{fp(10)}),
+						data.NewField("mem", nil, []*float64{fp(50)}),
+					},
+				}},
+			}},
+		},
+		{
+			name:  "numbers and series can not be mixed",
+			query: "SELECT * FROM A",
+			vars: mathexp.Vars{"A": mathexp.Results{Values: mathexp.Values{
+				series,
+				number(data.Labels{"host": "a"}, 1),
+			}}},
+			errIs: assert.Error,
+		},
+		{
+			name:  "missing table",
+			query: "SELECT * FROM A JOIN C ON A.host = C.host",
+			vars:  mathexp.Vars{"A": mathexp.Results{Values: mathexp.Values{table}}},
+			errIs: assert.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, err := NewSQLCommand("B", tt.query)
+			require.NoError(t, err)
+
+			res, err := cmd.Execute(context.Background(), tt.vars)
+			tt.errIs(t, err)
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tt.expected, res, data.FrameTestCompareOptions()...); diff != "" {
+				t.Errorf("Result mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func stringPointer(s string) *string { return &s }
@@ -0,0 +1,162 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+// ThresholdCommand is an expression command that checks if each number, or each
+// point of each series, crosses a threshold. It returns 1 where the threshold is
+// crossed and 0 where it is not. Null and NaN values are kept as they are.
+type ThresholdCommand struct {
+	ReferenceVar  string
+	ThresholdFunc string
+	Conditions    []float64
+	refID         string
+}
+
+const (
+	// ThresholdIsAbove is true if the value is greater than the threshold.
+	ThresholdIsAbove = "gt"
+	// ThresholdIsBelow is true if the value is less than the threshold.
+	ThresholdIsBelow = "lt"
+	// ThresholdIsWithinRange is true if the value is strictly between the two thresholds.
+	ThresholdIsWithinRange = "within_range"
+	// ThresholdIsOutsideRange is true if the value is strictly outside of the two thresholds.
+	ThresholdIsOutsideRange = "outside_range"
+)
+
+// ThresholdConditionJSON is the JSON model for the condition of a threshold command.
+// The evaluator is the same as the evaluator of the classic conditions.
+type ThresholdConditionJSON struct {
+	Evaluator ThresholdEvalJSON `json:"evaluator"`
+}
+
+// ThresholdEvalJSON is the JSON model for a threshold evaluator.
+type ThresholdEvalJSON struct {
+	Params []float64 `json:"params"`
+	Type   string    `json:"type"` // e.g. "gt"
+}
+
+// NewThresholdCommand creates a new ThresholdCommand. It will return an error
+// if the threshold function is unknown or has the wrong number of arguments.
+func NewThresholdCommand(refID, referenceVar, thresholdFunc string, conditions []float64) (*ThresholdCommand, error) {
+	switch thresholdFunc {
+	case ThresholdIsAbove, ThresholdIsBelow:
+		if len(conditions) < 1 {
+			return nil, fmt.Errorf("incorrect number of arguments for threshold function %q: got %v but need 1", thresholdFunc, len(conditions))
+		}
+	case ThresholdIsWithinRange, ThresholdIsOutsideRange:
+		if len(conditions) < 2 {
+			return nil, fmt.Errorf("incorrect number of arguments for threshold function %q: got %v but need 2", thresholdFunc, len(conditions))
+		}
+	default:
+		return nil, fmt.Errorf("expected threshold function to be one of %s, %s, %s or %s, got %q",
+			ThresholdIsAbove, ThresholdIsBelow, ThresholdIsWithinRange, ThresholdIsOutsideRange, thresholdFunc)
+	}
+
+	return &ThresholdCommand{
+		ReferenceVar:  referenceVar,
+		ThresholdFunc: thresholdFunc,
+		Conditions:    conditions,
+		refID:         refID,
+	}, nil
+}
+
+// UnmarshalThresholdCommand creates a ThresholdCommand from Grafana's frontend query.
+func UnmarshalThresholdCommand(rn *rawNode) (*ThresholdCommand, error) {
+	rawVar, ok := rn.Query["expression"]
+	if !ok {
+		return nil, fmt.Errorf("no variable specified to reference for refId %v", rn.RefID)
+	}
+	referenceVar, ok := rawVar.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected threshold variable to be a string, got %T for refId %v", rawVar, rn.RefID)
+	}
+	referenceVar = strings.TrimPrefix(referenceVar, "$")
+
+	jsonFromM, err := json.Marshal(rn.Query["conditions"])
+	if err != nil {
+		return nil, fmt.Errorf("failed to remarshal threshold expression body: %w", err)
+	}
+	var conditions []ThresholdConditionJSON
+	if err = json.Unmarshal(jsonFromM, &conditions); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal remarshaled threshold expression body: %w", err)
+	}
+	if len(conditions) != 1 {
+		return nil, fmt.Errorf("threshold expression for refId %v must have exactly one condition, got %v", rn.RefID, len(conditions))
+	}
+
+	evaluator := conditions[0].Evaluator
+	cmd, err := NewThresholdCommand(rn.RefID, referenceVar, evaluator.Type, evaluator.Params)
+	if err != nil {
+		return nil, fmt.Errorf("invalid threshold command for refId %v: %w", rn.RefID, err)
+	}
+	return cmd, nil
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (tc *ThresholdCommand) NeedsVars() []string {
+	return []string{tc.ReferenceVar}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (tc *ThresholdCommand) Execute(ctx context.Context, vars mathexp.Vars) (mathexp.Results, error) {
+	newRes := mathexp.Results{}
+	for _, val := range vars[tc.ReferenceVar].Values {
+		switch v := val.(type) {
+		case mathexp.Number:
+			num := mathexp.NewNumber(tc.refID, v.GetLabels())
+			num.SetValue(tc.evaluate(v.GetFloat64Value()))
+			newRes.Values = append(newRes.Values, num)
+		case mathexp.Series:
+			series := mathexp.NewSeries(tc.refID, v.GetLabels(), v.Len())
+			for i := 0; i < v.Len(); i++ {
+				t, f := v.GetPoint(i)
+				if err := series.SetPoint(i, t, tc.evaluate(f)); err != nil {
+					return newRes, err
+				}
+			}
+			newRes.Values = append(newRes.Values, series)
+		case mathexp.Scalar:
+			newRes.Values = append(newRes.Values, mathexp.NewScalar(tc.refID, tc.evaluate(v.GetFloat64Value())))
+		default:
+			return newRes, fmt.Errorf("can not apply a threshold to type %v", val.Type())
+		}
+	}
+	return newRes, nil
+}
+
+// evaluate returns 1 if f crosses the threshold, and 0 otherwise.
+// Null and NaN are returned as they are.
+func (tc *ThresholdCommand) evaluate(f *float64) *float64 {
+	if f == nil || math.IsNaN(*f) {
+		return f
+	}
+	v := *f
+	var crossed bool
+	switch tc.ThresholdFunc {
+	case ThresholdIsAbove:
+		crossed = v > tc.Conditions[0]
+	case ThresholdIsBelow:
+		crossed = v < tc.Conditions[0]
+	case ThresholdIsWithinRange:
+		lower, upper := tc.Conditions[0], tc.Conditions[1]
+		crossed = (lower < v && upper > v) || (upper < v && lower > v)
+	case ThresholdIsOutsideRange:
+		lower, upper := tc.Conditions[0], tc.Conditions[1]
+		crossed = (upper < v && lower < v) || (upper > v && lower > v)
+	}
+	r := 0.0
+	if crossed {
+		r = 1
+	}
+	return &r
+}
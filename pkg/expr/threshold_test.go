@@ -0,0 +1,122 @@
+package expr
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewThresholdCommand(t *testing.T) {
+	type testCase struct {
+		fn      string
+		args    []float64
+		wantErr bool
+	}
+
+	cases := []testCase{
+		{fn: "gt", args: []float64{0}},
+		{fn: "lt", args: []float64{0}},
+		{fn: "within_range", args: []float64{0, 1}},
+		{fn: "outside_range", args: []float64{0, 1}},
+		{fn: "gt", args: []float64{}, wantErr: true},
+		{fn: "within_range", args: []float64{0}, wantErr: true},
+		{fn: "eq", args: []float64{0}, wantErr: true},
+	}
+
+	for _, tc := range cases {
+		cmd, err := NewThresholdCommand("B", "A", tc.fn, tc.args)
+		if tc.wantErr {
+			require.Error(t, err)
+			continue
+		}
+		require.NoError(t, err)
+		require.Equal(t, []string{"A"}, cmd.NeedsVars())
+	}
+}
+
+func TestUnmarshalThresholdCommand(t *testing.T) {
+	rn := &rawNode{
+		RefID: "B",
+		Query: map[string]interface{}{
+			"type":       "threshold",
+			"expression": "$A",
+			"conditions": []interface{}{
+				map[string]interface{}{
+					"evaluator": map[string]interface{}{
+						"type":   "within_range",
+						"params": []interface{}{10, 20},
+					},
+				},
+			},
+		},
+	}
+
+	cmd, err := UnmarshalThresholdCommand(rn)
+	require.NoError(t, err)
+	require.Equal(t, "A", cmd.ReferenceVar)
+	require.Equal(t, ThresholdIsWithinRange, cmd.ThresholdFunc)
+	require.Equal(t, []float64{10, 20}, cmd.Conditions)
+
+	rn.Query["conditions"] = []interface{}{}
+	_, err = UnmarshalThresholdCommand(rn)
+	require.Error(t, err)
+}
+
+func TestThresholdCommandExecute(t *testing.T) {
+	f := func(v float64) *float64 { return &v }
+	nan := math.NaN()
+
+	number := func(labels data.Labels, v *float64) mathexp.Number {
+		n := mathexp.NewNumber("B", labels)
+		n.SetValue(v)
+		return n
+	}
+
+	t.Run("numbers keep labels and null values", func(t *testing.T) {
+		cmd, err := NewThresholdCommand("B", "A", ThresholdIsAbove, []float64{80})
+		require.NoError(t, err)
+
+		vars := mathexp.Vars{
+			"A": mathexp.Results{Values: mathexp.Values{
+				number(data.Labels{"host": "a"}, f(90)),
+				number(data.Labels{"host": "b"}, f(80)),
+				number(data.Labels{"host": "c"}, nil),
+			}},
+		}
+		res, err := cmd.Execute(context.Background(), vars)
+		require.NoError(t, err)
+		require.Equal(t, mathexp.Values{
+			number(data.Labels{"host": "a"}, f(1)),
+			number(data.Labels{"host": "b"}, f(0)),
+			number(data.Labels{"host": "c"}, nil),
+		}, res.Values)
+	})
+
+	t.Run("series are evaluated per point", func(t *testing.T) {
+		cmd, err := NewThresholdCommand("B", "A", ThresholdIsOutsideRange, []float64{10, 0})
+		require.NoError(t, err)
+
+		series := mathexp.NewSeries("A", data.Labels{"host": "a"}, 4)
+		require.NoError(t, series.SetPoint(0, time.Unix(0, 0), f(-1)))
+		require.NoError(t, series.SetPoint(1, time.Unix(10, 0), f(5)))
+		require.NoError(t, series.SetPoint(2, time.Unix(20, 0), f(11)))
+		require.NoError(t, series.SetPoint(3, time.Unix(30, 0), &nan))
+
+		res, err := cmd.Execute(context.Background(), mathexp.Vars{"A": mathexp.Results{Values: mathexp.Values{series}}})
+		require.NoError(t, err)
+		require.Len(t, res.Values, 1)
+
+		out := res.Values[0].(mathexp.Series)
+		require.Equal(t, data.Labels{"host": "a"}, out.GetLabels())
+		require.Equal(t, 4, out.Len())
+		require.Equal(t, 1.0, *out.GetValue(0))
+		require.Equal(t, 0.0, *out.GetValue(1))
+		require.Equal(t, 1.0, *out.GetValue(2))
+		require.True(t, math.IsNaN(*out.GetValue(3)))
+	})
+}
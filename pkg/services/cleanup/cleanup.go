@@ -15,15 +15,23 @@ import (
 	"github.com/grafana/grafana/pkg/infra/serverlock"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/annotations"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
+// AlertingStore is the database interface for the expired data of the unified alerting.
+type AlertingStore interface {
+	DeleteExpiredAlertStateHistory(ctx context.Context, cmd *ngmodels.DeleteExpiredAlertStateHistoryCommand) error
+	DeleteExpiredNotificationDeliveries(ctx context.Context, cmd *ngmodels.DeleteExpiredNotificationDeliveriesCommand) error
+}
+
 func ProvideService(cfg *setting.Cfg, serverLockService *serverlock.ServerLockService,
-	shortURLService shorturls.Service) *CleanUpService {
+	shortURLService shorturls.Service, alertingStore AlertingStore) *CleanUpService {
 	s := &CleanUpService{
 		Cfg:               cfg,
 		ServerLockService: serverLockService,
 		ShortURLService:   shortURLService,
+		AlertingStore:     alertingStore,
 		log:               log.New("cleanup"),
 	}
 	return s
@@ -34,6 +42,7 @@ type CleanUpService struct {
 	Cfg               *setting.Cfg
 	ServerLockService *serverlock.ServerLockService
 	ShortURLService   shorturls.Service
+	AlertingStore     AlertingStore
 }
 
 func (srv *CleanUpService) Run(ctx context.Context) error {
@@ -52,6 +61,8 @@ func (srv *CleanUpService) Run(ctx context.Context) error {
 			srv.cleanUpOldAnnotations(ctxWithTimeout)
 			srv.expireOldUserInvites()
 			srv.deleteStaleShortURLs()
+			srv.deleteExpiredAlertStateHistory(ctxWithTimeout)
+			srv.deleteExpiredNotificationDeliveries(ctxWithTimeout)
 			err := srv.ServerLockService.LockAndExecute(ctx, "delete old login attempts",
 				time.Minute*10, func(context.Context) {
 					srv.deleteOldLoginAttempts()
@@ -181,3 +192,35 @@ func (srv *CleanUpService) deleteStaleShortURLs() {
 		srv.log.Debug("Deleted short urls", "rows affected", cmd.NumDeleted)
 	}
 }
+
+func (srv *CleanUpService) deleteExpiredAlertStateHistory(ctx context.Context) {
+	retention := srv.Cfg.UnifiedAlerting.StateHistoryRetention
+	if retention <= 0 {
+		return
+	}
+
+	cmd := ngmodels.DeleteExpiredAlertStateHistoryCommand{
+		OlderThan: time.Now().Add(-retention),
+	}
+	if err := srv.AlertingStore.DeleteExpiredAlertStateHistory(ctx, &cmd); err != nil {
+		srv.log.Error("Problem deleting expired alert state history", "error", err.Error())
+	} else {
+		srv.log.Debug("Deleted expired alert state history", "rows affected", cmd.DeletedRows)
+	}
+}
+
+func (srv *CleanUpService) deleteExpiredNotificationDeliveries(ctx context.Context) {
+	retention := srv.Cfg.UnifiedAlerting.NotificationLogRetention
+	if retention <= 0 {
+		return
+	}
+
+	cmd := ngmodels.DeleteExpiredNotificationDeliveriesCommand{
+		OlderThan: time.Now().Add(-retention),
+	}
+	if err := srv.AlertingStore.DeleteExpiredNotificationDeliveries(ctx, &cmd); err != nil {
+		srv.log.Error("Problem deleting expired notification deliveries", "error", err.Error())
+	} else {
+		srv.log.Debug("Deleted expired notification deliveries", "rows affected", cmd.DeletedRows)
+	}
+}
@@ -0,0 +1,57 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/services/live/pipeline"
+)
+
+const (
+	pipelineKVNamespace      = "live.pipeline"
+	fileStorageImportedKVKey = "file_storage_imported"
+)
+
+// ImportFileStorage copies the channel rules and remote write backends of the
+// files used before the pipeline configuration was stored in the database. The
+// import is only done once, the rules and backends already in the database are
+// kept. Missing files are considered empty.
+func (s *PipelineStorage) ImportFileStorage(ctx context.Context, file *pipeline.FileStorage, orgIDs []int64) error {
+	kv := kvstore.WithNamespace(kvstore.ProvideService(s.store), 0, pipelineKVNamespace)
+	_, imported, err := kv.Get(ctx, fileStorageImportedKVKey)
+	if err != nil {
+		return err
+	}
+	if imported {
+		return nil
+	}
+
+	for _, orgID := range orgIDs {
+		backends, err := file.ListRemoteWriteBackends(ctx, orgID)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("can't read remote write backends of org %d: %w", orgID, err)
+		}
+		for _, backend := range backends {
+			_, err := s.CreateRemoteWriteBackend(ctx, orgID, backend)
+			if err != nil && !errors.Is(err, pipeline.ErrRemoteWriteBackendExists) {
+				return fmt.Errorf("can't import remote write backend %s of org %d: %w", backend.UID, orgID, err)
+			}
+		}
+
+		rules, err := file.ListChannelRules(ctx, orgID)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("can't read channel rules of org %d: %w", orgID, err)
+		}
+		for _, rule := range rules {
+			_, err := s.CreateChannelRule(ctx, orgID, rule)
+			if err != nil && !errors.Is(err, pipeline.ErrChannelRuleExists) {
+				return fmt.Errorf("can't import channel rule %s of org %d: %w", rule.Pattern, orgID, err)
+			}
+		}
+	}
+
+	return kv.Set(ctx, fileStorageImportedKVKey, "true")
+}
@@ -0,0 +1,404 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/encryption"
+	"github.com/grafana/grafana/pkg/services/live/pipeline"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+type channelRule struct {
+	Id       int64
+	OrgId    int64
+	Version  int64
+	Pattern  string
+	Settings string
+
+	Created time.Time
+	Updated time.Time
+}
+
+func (r *channelRule) TableName() string {
+	return "live_channel_rule"
+}
+
+type remoteWriteBackend struct {
+	Id             int64
+	OrgId          int64
+	Version        int64
+	Uid            string
+	Settings       string
+	SecureSettings map[string][]byte
+
+	Created time.Time
+	Updated time.Time
+}
+
+func (b *remoteWriteBackend) TableName() string {
+	return "live_remote_write_backend"
+}
+
+// PipelineStorage keeps Live pipeline channel rules and remote write backends
+// in the Grafana database, so they are shared by all Grafana instances. It
+// implements pipeline.RuleStorage.
+type PipelineStorage struct {
+	store             *sqlstore.SQLStore
+	encryptionService encryption.Service
+}
+
+func NewPipelineStorage(store *sqlstore.SQLStore, encryptionService encryption.Service) *PipelineStorage {
+	return &PipelineStorage{store: store, encryptionService: encryptionService}
+}
+
+func (s *PipelineStorage) ListChannelRules(ctx context.Context, orgID int64) ([]pipeline.ChannelRule, error) {
+	var rules []pipeline.ChannelRule
+	err := s.store.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		var err error
+		rules, err = listChannelRules(sess, orgID)
+		return err
+	})
+	return rules, err
+}
+
+func listChannelRules(sess *sqlstore.DBSession, orgID int64) ([]pipeline.ChannelRule, error) {
+	var rows []*channelRule
+	if err := sess.Where("org_id=?", orgID).Asc("pattern").Find(&rows); err != nil {
+		return nil, err
+	}
+	rules := make([]pipeline.ChannelRule, 0, len(rows))
+	for _, row := range rows {
+		rule, err := row.toChannelRule()
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func (r *channelRule) toChannelRule() (pipeline.ChannelRule, error) {
+	rule := pipeline.ChannelRule{
+		OrgId:   r.OrgId,
+		Pattern: r.Pattern,
+		Version: r.Version,
+	}
+	if err := json.Unmarshal([]byte(r.Settings), &rule.Settings); err != nil {
+		return rule, fmt.Errorf("can't unmarshal settings of channel rule %s: %w", r.Pattern, err)
+	}
+	return rule, nil
+}
+
+// checkChannelRule checks that the rule is valid and does not conflict with the
+// other rules of the organization.
+func checkChannelRule(sess *sqlstore.DBSession, orgID int64, rule pipeline.ChannelRule) error {
+	ok, reason := rule.Valid()
+	if !ok {
+		return fmt.Errorf("%w: %s", pipeline.ErrInvalidChannelRule, reason)
+	}
+	existingRules, err := listChannelRules(sess, orgID)
+	if err != nil {
+		return err
+	}
+	rule.OrgId = orgID
+	rules := []pipeline.ChannelRule{rule}
+	for _, r := range existingRules {
+		if r.Pattern != rule.Pattern {
+			rules = append(rules, r)
+		}
+	}
+	ok, reason = pipeline.CheckRulesValid(orgID, rules)
+	if !ok {
+		return fmt.Errorf("%w: %s", pipeline.ErrInvalidChannelRule, reason)
+	}
+	return nil
+}
+
+func (s *PipelineStorage) CreateChannelRule(ctx context.Context, orgID int64, rule pipeline.ChannelRule) (pipeline.ChannelRule, error) {
+	err := s.store.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		exists, err := sess.Where("org_id=? AND pattern=?", orgID, rule.Pattern).Exist(&channelRule{})
+		if err != nil {
+			return err
+		}
+		if exists {
+			return fmt.Errorf("%w: %s", pipeline.ErrChannelRuleExists, rule.Pattern)
+		}
+		if err := checkChannelRule(sess, orgID, rule); err != nil {
+			return err
+		}
+		settings, err := json.Marshal(rule.Settings)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		_, err = sess.Insert(&channelRule{
+			OrgId:    orgID,
+			Version:  1,
+			Pattern:  rule.Pattern,
+			Settings: string(settings),
+			Created:  now,
+			Updated:  now,
+		})
+		return err
+	})
+	rule.OrgId = orgID
+	rule.Version = 1
+	return rule, err
+}
+
+// UpdateChannelRule updates the rule with the same pattern, or creates it if there is
+// no such rule.
+func (s *PipelineStorage) UpdateChannelRule(ctx context.Context, orgID int64, rule pipeline.ChannelRule) (pipeline.ChannelRule, error) {
+	var existing channelRule
+	var exists bool
+	err := s.store.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		var err error
+		exists, err = sess.Where("org_id=? AND pattern=?", orgID, rule.Pattern).Get(&existing)
+		if err != nil || !exists {
+			return err
+		}
+		if rule.Version != 0 && rule.Version != existing.Version {
+			return pipeline.ErrVersionConflict
+		}
+		if err := checkChannelRule(sess, orgID, rule); err != nil {
+			return err
+		}
+		settings, err := json.Marshal(rule.Settings)
+		if err != nil {
+			return err
+		}
+		updated, err := sess.Where("id=? AND version=?", existing.Id, existing.Version).
+			Cols("version", "settings", "updated").
+			Update(&channelRule{
+				Version:  existing.Version + 1,
+				Settings: string(settings),
+				Updated:  time.Now(),
+			})
+		if err != nil {
+			return err
+		}
+		if updated == 0 {
+			return pipeline.ErrVersionConflict
+		}
+		return nil
+	})
+	if err != nil {
+		return rule, err
+	}
+	if !exists {
+		return s.CreateChannelRule(ctx, orgID, rule)
+	}
+	rule.OrgId = orgID
+	rule.Version = existing.Version + 1
+	return rule, nil
+}
+
+func (s *PipelineStorage) DeleteChannelRule(ctx context.Context, orgID int64, pattern string) error {
+	return s.store.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		deleted, err := sess.Where("org_id=? AND pattern=?", orgID, pattern).Delete(&channelRule{})
+		if err != nil {
+			return err
+		}
+		if deleted == 0 {
+			return pipeline.ErrChannelRuleNotFound
+		}
+		return nil
+	})
+}
+
+func (s *PipelineStorage) ListRemoteWriteBackends(ctx context.Context, orgID int64) ([]pipeline.RemoteWriteBackend, error) {
+	var rows []*remoteWriteBackend
+	err := s.store.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.Where("org_id=?", orgID).Asc("uid").Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+	backends := make([]pipeline.RemoteWriteBackend, 0, len(rows))
+	for _, row := range rows {
+		backend, err := s.toRemoteWriteBackend(ctx, row)
+		if err != nil {
+			return nil, err
+		}
+		backends = append(backends, backend)
+	}
+	return backends, nil
+}
+
+func (s *PipelineStorage) toRemoteWriteBackend(ctx context.Context, row *remoteWriteBackend) (pipeline.RemoteWriteBackend, error) {
+	backend := pipeline.RemoteWriteBackend{
+		OrgId:    row.OrgId,
+		UID:      row.Uid,
+		Version:  row.Version,
+		Settings: &pipeline.RemoteWriteConfig{},
+	}
+	if err := json.Unmarshal([]byte(row.Settings), backend.Settings); err != nil {
+		return backend, fmt.Errorf("can't unmarshal settings of remote write backend %s: %w", row.Uid, err)
+	}
+	secureSettings, err := s.encryptionService.DecryptJsonData(ctx, row.SecureSettings, setting.SecretKey)
+	if err != nil {
+		return backend, fmt.Errorf("can't decrypt secure settings of remote write backend %s: %w", row.Uid, err)
+	}
+	backend.Settings.Password = secureSettings["password"]
+	return backend, nil
+}
+
+// remoteWriteBackendRow returns the settings of a backend without secrets, and the
+// encrypted secrets.
+func (s *PipelineStorage) remoteWriteBackendRow(ctx context.Context, backend pipeline.RemoteWriteBackend) (string, map[string][]byte, error) {
+	if backend.Settings == nil {
+		return "", nil, fmt.Errorf("remote write backend settings required")
+	}
+	settings := *backend.Settings
+	secureSettings := map[string]string{}
+	if settings.Password != "" {
+		secureSettings["password"] = settings.Password
+	}
+	settings.Password = ""
+	settingsJSON, err := json.Marshal(settings)
+	if err != nil {
+		return "", nil, err
+	}
+	encrypted, err := s.encryptionService.EncryptJsonData(ctx, secureSettings, setting.SecretKey)
+	if err != nil {
+		return "", nil, err
+	}
+	return string(settingsJSON), encrypted, nil
+}
+
+// CreateRemoteWriteBackend creates a backend, an UID is generated if not set.
+func (s *PipelineStorage) CreateRemoteWriteBackend(ctx context.Context, orgID int64, backend pipeline.RemoteWriteBackend) (pipeline.RemoteWriteBackend, error) {
+	if backend.UID == "" {
+		backend.UID = util.GenerateShortUID()
+	}
+	settings, secureSettings, err := s.remoteWriteBackendRow(ctx, backend)
+	if err != nil {
+		return backend, err
+	}
+	err = s.store.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		exists, err := sess.Where("org_id=? AND uid=?", orgID, backend.UID).Exist(&remoteWriteBackend{})
+		if err != nil {
+			return err
+		}
+		if exists {
+			return fmt.Errorf("%w: %s", pipeline.ErrRemoteWriteBackendExists, backend.UID)
+		}
+		now := time.Now()
+		_, err = sess.Insert(&remoteWriteBackend{
+			OrgId:          orgID,
+			Version:        1,
+			Uid:            backend.UID,
+			Settings:       settings,
+			SecureSettings: secureSettings,
+			Created:        now,
+			Updated:        now,
+		})
+		return err
+	})
+	backend.OrgId = orgID
+	backend.Version = 1
+	return backend, err
+}
+
+// UpdateRemoteWriteBackend updates the backend with the same UID, or creates it if
+// there is no such backend. The password is kept if not set.
+func (s *PipelineStorage) UpdateRemoteWriteBackend(ctx context.Context, orgID int64, backend pipeline.RemoteWriteBackend) (pipeline.RemoteWriteBackend, error) {
+	if backend.UID == "" {
+		return s.CreateRemoteWriteBackend(ctx, orgID, backend)
+	}
+	settings, secureSettings, err := s.remoteWriteBackendRow(ctx, backend)
+	if err != nil {
+		return backend, err
+	}
+	var existing remoteWriteBackend
+	var exists bool
+	err = s.store.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		var err error
+		exists, err = sess.Where("org_id=? AND uid=?", orgID, backend.UID).Get(&existing)
+		if err != nil || !exists {
+			return err
+		}
+		if backend.Version != 0 && backend.Version != existing.Version {
+			return pipeline.ErrVersionConflict
+		}
+		if backend.Settings.Password == "" {
+			secureSettings = existing.SecureSettings
+		}
+		updated, err := sess.Where("id=? AND version=?", existing.Id, existing.Version).
+			Cols("version", "settings", "secure_settings", "updated").
+			Update(&remoteWriteBackend{
+				Version:        existing.Version + 1,
+				Settings:       settings,
+				SecureSettings: secureSettings,
+				Updated:        time.Now(),
+			})
+		if err != nil {
+			return err
+		}
+		if updated == 0 {
+			return pipeline.ErrVersionConflict
+		}
+		return nil
+	})
+	if err != nil {
+		return backend, err
+	}
+	if !exists {
+		return s.CreateRemoteWriteBackend(ctx, orgID, backend)
+	}
+	backend.OrgId = orgID
+	backend.Version = existing.Version + 1
+	return backend, nil
+}
+
+func (s *PipelineStorage) DeleteRemoteWriteBackend(ctx context.Context, orgID int64, uid string) error {
+	return s.store.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		// Channel rules outputting to the backend would fail to build.
+		rules, err := listChannelRules(sess, orgID)
+		if err != nil {
+			return err
+		}
+		for _, rule := range rules {
+			for _, out := range rule.Settings.FrameOutputters {
+				if usesRemoteWriteBackend(out, uid) {
+					return fmt.Errorf("%w: %s", pipeline.ErrRemoteWriteBackendInUse, rule.Pattern)
+				}
+			}
+		}
+		deleted, err := sess.Where("org_id=? AND uid=?", orgID, uid).Delete(&remoteWriteBackend{})
+		if err != nil {
+			return err
+		}
+		if deleted == 0 {
+			return pipeline.ErrRemoteWriteBackendNotFound
+		}
+		return nil
+	})
+}
+
+// usesRemoteWriteBackend reports whether the frame outputter, or one of the
+// outputters nested in it, writes to the remote write backend with the uid.
+func usesRemoteWriteBackend(out *pipeline.FrameOutputterConfig, uid string) bool {
+	if out == nil {
+		return false
+	}
+	if out.RemoteWriteOutputConfig != nil && out.RemoteWriteOutputConfig.UID == uid {
+		return true
+	}
+	if out.MultipleOutputterConfig != nil {
+		for i := range out.MultipleOutputterConfig.Outputters {
+			if usesRemoteWriteBackend(&out.MultipleOutputterConfig.Outputters[i], uid) {
+				return true
+			}
+		}
+	}
+	if out.ConditionalOutputConfig != nil {
+		return usesRemoteWriteBackend(out.ConditionalOutputConfig.Outputter, uid)
+	}
+	return false
+}
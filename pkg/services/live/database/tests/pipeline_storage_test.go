@@ -0,0 +1,194 @@
+//go:build integration
+// +build integration
+
+package tests
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/services/live/pipeline"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPipelineStorage_ChannelRules(t *testing.T) {
+	storage := SetupTestPipelineStorage(t)
+	ctx := context.Background()
+
+	rules, err := storage.ListChannelRules(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, rules, 0)
+
+	rule := pipeline.ChannelRule{
+		Pattern: "stream/test/:name",
+		Settings: pipeline.ChannelRuleSettings{
+			Converter: &pipeline.ConverterConfig{Type: pipeline.ConverterTypeJsonAuto},
+		},
+	}
+	created, err := storage.CreateChannelRule(ctx, 1, rule)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), created.Version)
+
+	_, err = storage.CreateChannelRule(ctx, 1, rule)
+	require.ErrorIs(t, err, pipeline.ErrChannelRuleExists)
+
+	_, err = storage.CreateChannelRule(ctx, 1, pipeline.ChannelRule{Pattern: "stream/test/:other"})
+	require.ErrorIs(t, err, pipeline.ErrInvalidChannelRule)
+
+	// Rules are scoped to organizations.
+	_, err = storage.CreateChannelRule(ctx, 2, rule)
+	require.NoError(t, err)
+
+	rules, err = storage.ListChannelRules(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	require.Equal(t, rule.Pattern, rules[0].Pattern)
+	require.Equal(t, pipeline.ConverterTypeJsonAuto, rules[0].Settings.Converter.Type)
+
+	rule.Version = 1
+	rule.Settings.Converter = &pipeline.ConverterConfig{Type: pipeline.ConverterTypeInfluxAuto}
+	updated, err := storage.UpdateChannelRule(ctx, 1, rule)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), updated.Version)
+
+	// The rule was changed since version 1 was read.
+	_, err = storage.UpdateChannelRule(ctx, 1, rule)
+	require.ErrorIs(t, err, pipeline.ErrVersionConflict)
+
+	rules, err = storage.ListChannelRules(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	require.Equal(t, int64(2), rules[0].Version)
+	require.Equal(t, pipeline.ConverterTypeInfluxAuto, rules[0].Settings.Converter.Type)
+
+	err = storage.DeleteChannelRule(ctx, 1, rule.Pattern)
+	require.NoError(t, err)
+	err = storage.DeleteChannelRule(ctx, 1, rule.Pattern)
+	require.ErrorIs(t, err, pipeline.ErrChannelRuleNotFound)
+
+	rules, err = storage.ListChannelRules(ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+}
+
+func TestPipelineStorage_RemoteWriteBackends(t *testing.T) {
+	storage := SetupTestPipelineStorage(t)
+	ctx := context.Background()
+
+	created, err := storage.CreateRemoteWriteBackend(ctx, 1, pipeline.RemoteWriteBackend{
+		Settings: &pipeline.RemoteWriteConfig{
+			Endpoint: "http://localhost:9090/api/v1/write",
+			User:     "user",
+			Password: "secret",
+		},
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, created.UID)
+
+	backends, err := storage.ListRemoteWriteBackends(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, backends, 1)
+	require.Equal(t, created.UID, backends[0].UID)
+	require.Equal(t, "secret", backends[0].Settings.Password)
+
+	// The password is kept when not set.
+	_, err = storage.UpdateRemoteWriteBackend(ctx, 1, pipeline.RemoteWriteBackend{
+		UID:     created.UID,
+		Version: 1,
+		Settings: &pipeline.RemoteWriteConfig{
+			Endpoint: "http://localhost:9091/api/v1/write",
+			User:     "user",
+		},
+	})
+	require.NoError(t, err)
+
+	backends, err = storage.ListRemoteWriteBackends(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, backends, 1)
+	require.Equal(t, int64(2), backends[0].Version)
+	require.Equal(t, "http://localhost:9091/api/v1/write", backends[0].Settings.Endpoint)
+	require.Equal(t, "secret", backends[0].Settings.Password)
+
+	backends, err = storage.ListRemoteWriteBackends(ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, backends, 0)
+
+	err = storage.DeleteRemoteWriteBackend(ctx, 2, created.UID)
+	require.ErrorIs(t, err, pipeline.ErrRemoteWriteBackendNotFound)
+
+	// The backend can't be deleted while a channel rule outputs to it.
+	rule := pipeline.ChannelRule{
+		Pattern: "stream/test/:name",
+		Settings: pipeline.ChannelRuleSettings{
+			Converter: &pipeline.ConverterConfig{Type: pipeline.ConverterTypeJsonAuto},
+			FrameOutputters: []*pipeline.FrameOutputterConfig{{
+				Type: pipeline.FrameOutputTypeConditional,
+				ConditionalOutputConfig: &pipeline.ConditionalOutputConfig{
+					Condition: &pipeline.FrameConditionCheckerConfig{
+						Type: pipeline.FrameConditionCheckerTypeNumberCompare,
+						NumberCompareConditionConfig: &pipeline.NumberCompareFrameConditionConfig{
+							FieldName: "value",
+							Op:        pipeline.NumberCompareOpGt,
+							Value:     10,
+						},
+					},
+					Outputter: &pipeline.FrameOutputterConfig{
+						Type:                    pipeline.FrameOutputTypeRemoteWrite,
+						RemoteWriteOutputConfig: &pipeline.RemoteWriteOutputConfig{UID: created.UID},
+					},
+				},
+			}},
+		},
+	}
+	_, err = storage.CreateChannelRule(ctx, 1, rule)
+	require.NoError(t, err)
+	err = storage.DeleteRemoteWriteBackend(ctx, 1, created.UID)
+	require.ErrorIs(t, err, pipeline.ErrRemoteWriteBackendInUse)
+
+	backends, err = storage.ListRemoteWriteBackends(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, backends, 1)
+
+	err = storage.DeleteChannelRule(ctx, 1, rule.Pattern)
+	require.NoError(t, err)
+	err = storage.DeleteRemoteWriteBackend(ctx, 1, created.UID)
+	require.NoError(t, err)
+}
+
+func TestPipelineStorage_ImportFileStorage(t *testing.T) {
+	storage := SetupTestPipelineStorage(t)
+	ctx := context.Background()
+
+	dataPath := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dataPath, "pipeline"), 0700))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dataPath, "pipeline", "live-channel-rules.json"),
+		[]byte(`{"rules": [{"pattern": "stream/test/:name", "settings": {"converter": {"type": "jsonAuto"}}}]}`), 0600))
+	fileStorage := &pipeline.FileStorage{DataPath: dataPath}
+
+	// The remote write backends file is missing.
+	err := storage.ImportFileStorage(ctx, fileStorage, []int64{1, 2})
+	require.NoError(t, err)
+
+	rules, err := storage.ListChannelRules(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	require.Equal(t, "stream/test/:name", rules[0].Pattern)
+	require.Equal(t, pipeline.ConverterTypeJsonAuto, rules[0].Settings.Converter.Type)
+
+	// The rules of the file belong to the main organization.
+	rules, err = storage.ListChannelRules(ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, rules, 0)
+
+	// The files are only imported once.
+	require.NoError(t, storage.DeleteChannelRule(ctx, 1, "stream/test/:name"))
+	err = storage.ImportFileStorage(ctx, fileStorage, []int64{1, 2})
+	require.NoError(t, err)
+	rules, err = storage.ListChannelRules(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, rules, 0)
+}
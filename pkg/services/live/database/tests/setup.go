@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/services/encryption/ossencryption"
 	"github.com/grafana/grafana/pkg/services/live/database"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 )
@@ -16,3 +17,9 @@ func SetupTestStorage(t *testing.T) *database.Storage {
 	localCache := localcache.New(time.Hour, time.Hour)
 	return database.NewStorage(sqlStore, localCache)
 }
+
+// SetupTestPipelineStorage initializes a pipeline storage to used by the integration tests.
+func SetupTestPipelineStorage(t *testing.T) *database.PipelineStorage {
+	sqlStore := sqlstore.InitTestDB(t)
+	return database.NewPipelineStorage(sqlStore, ossencryption.ProvideService())
+}
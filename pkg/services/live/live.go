@@ -29,6 +29,7 @@ import (
 	"github.com/grafana/grafana/pkg/plugins/manager"
 	"github.com/grafana/grafana/pkg/plugins/plugincontext"
 	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/encryption"
 	"github.com/grafana/grafana/pkg/services/live/database"
 	"github.com/grafana/grafana/pkg/services/live/features"
 	"github.com/grafana/grafana/pkg/services/live/livecontext"
@@ -62,7 +63,7 @@ type CoreGrafanaScope struct {
 
 func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, routeRegister routing.RouteRegister,
 	logsService *cloudwatch.LogsService, pluginManager *manager.PluginManager, cacheService *localcache.CacheService,
-	dataSourceCache datasources.CacheService, sqlStore *sqlstore.SQLStore,
+	dataSourceCache datasources.CacheService, sqlStore *sqlstore.SQLStore, encryptionService encryption.Service,
 	usageStatsService usagestats.Service) (*GrafanaLive, error) {
 	g := &GrafanaLive{
 		Cfg:                   cfg,
@@ -147,6 +148,10 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 	channelLocalPublisher := liveplugin.NewChannelLocalPublisher(node, nil)
 
 	var managedStreamRunner *managedstream.Runner
+	managedStreamHistory := managedstream.HistoryConfig{
+		Size:   g.Cfg.LiveManagedStreamHistorySize,
+		MaxAge: g.Cfg.LiveManagedStreamHistoryMaxAge,
+	}
 	if g.IsHA() {
 		redisClient := redis.NewClient(&redis.Options{
 			Addr: g.Cfg.LiveHAEngineAddress,
@@ -158,19 +163,20 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 		managedStreamRunner = managedstream.NewRunner(
 			g.Publish,
 			channelLocalPublisher,
-			managedstream.NewRedisFrameCache(redisClient),
+			managedstream.NewRedisFrameCache(redisClient, managedStreamHistory),
 		)
 	} else {
 		managedStreamRunner = managedstream.NewRunner(
 			g.Publish,
 			channelLocalPublisher,
-			managedstream.NewMemoryFrameCache(),
+			managedstream.NewMemoryFrameCache(managedStreamHistory),
 		)
 	}
 
 	g.ManagedStreamRunner = managedStreamRunner
 	if enabled := g.Cfg.FeatureToggles["live-pipeline"]; enabled {
 		var builder pipeline.RuleBuilder
+		var pipelineStorage *database.PipelineStorage
 		if os.Getenv("GF_LIVE_DEV_BUILDER") != "" {
 			builder = &pipeline.DevRuleBuilder{
 				Node:                 node,
@@ -179,15 +185,14 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 				ChannelHandlerGetter: g,
 			}
 		} else {
-			storage := &pipeline.FileStorage{
-				DataPath: cfg.DataPath,
-			}
-			g.channelRuleStorage = storage
+			pipelineStorage = database.NewPipelineStorage(sqlStore, encryptionService)
+			g.channelRuleStorage = pipelineStorage
 			builder = &pipeline.StorageRuleBuilder{
 				Node:                 node,
 				ManagedStream:        g.ManagedStreamRunner,
 				FrameStorage:         pipeline.NewFrameStorage(),
-				RuleStorage:          storage,
+				AggregateStorage:     pipeline.NewAggregateStorage(),
+				RuleStorage:          pipelineStorage,
 				ChannelHandlerGetter: g,
 			}
 		}
@@ -201,6 +206,17 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 		if err != nil {
 			return nil, fmt.Errorf("can't get org list: %w", err)
 		}
+		if pipelineStorage != nil {
+			orgIDs := make([]int64, 0, len(query.Result))
+			for _, org := range query.Result {
+				orgIDs = append(orgIDs, org.Id)
+			}
+			// The pipeline configuration used to be stored in files of the data directory.
+			err := pipelineStorage.ImportFileStorage(context.Background(), &pipeline.FileStorage{DataPath: cfg.DataPath}, orgIDs)
+			if err != nil {
+				logger.Error("Error importing the pipeline configuration files", "error", err)
+			}
+		}
 		for _, org := range query.Result {
 			_, _, err := channelRuleGetter.Get(org.Id, "")
 			if err != nil {
@@ -1016,6 +1032,18 @@ func (s *DryRunRuleStorage) DeleteChannelRule(_ context.Context, _ int64, _ stri
 	return errors.New("not implemented by dry run rule storage")
 }
 
+func (s *DryRunRuleStorage) CreateRemoteWriteBackend(_ context.Context, _ int64, _ pipeline.RemoteWriteBackend) (pipeline.RemoteWriteBackend, error) {
+	return pipeline.RemoteWriteBackend{}, errors.New("not implemented by dry run rule storage")
+}
+
+func (s *DryRunRuleStorage) UpdateRemoteWriteBackend(_ context.Context, _ int64, _ pipeline.RemoteWriteBackend) (pipeline.RemoteWriteBackend, error) {
+	return pipeline.RemoteWriteBackend{}, errors.New("not implemented by dry run rule storage")
+}
+
+func (s *DryRunRuleStorage) DeleteRemoteWriteBackend(_ context.Context, _ int64, _ string) error {
+	return errors.New("not implemented by dry run rule storage")
+}
+
 func (s *DryRunRuleStorage) ListRemoteWriteBackends(_ context.Context, _ int64) ([]pipeline.RemoteWriteBackend, error) {
 	return nil, nil
 }
@@ -1042,6 +1070,7 @@ func (g *GrafanaLive) HandlePipelineConvertTestHTTP(c *models.ReqContext) respon
 		Node:                 g.node,
 		ManagedStream:        g.ManagedStreamRunner,
 		FrameStorage:         pipeline.NewFrameStorage(),
+		AggregateStorage:     pipeline.NewAggregateStorage(),
 		RuleStorage:          storage,
 		ChannelHandlerGetter: g,
 	}
@@ -1082,7 +1111,7 @@ func (g *GrafanaLive) HandleChannelRulesPostHTTP(c *models.ReqContext) response.
 	}
 	result, err := g.channelRuleStorage.CreateChannelRule(c.Req.Context(), c.OrgId, rule)
 	if err != nil {
-		return response.Error(http.StatusInternalServerError, "Failed to create channel rule", err)
+		return ruleStorageErrorResponse("Failed to create channel rule", err)
 	}
 	return response.JSON(http.StatusOK, util.DynMap{
 		"rule": result,
@@ -1105,7 +1134,7 @@ func (g *GrafanaLive) HandleChannelRulesPutHTTP(c *models.ReqContext) response.R
 	}
 	rule, err = g.channelRuleStorage.UpdateChannelRule(c.Req.Context(), c.OrgId, rule)
 	if err != nil {
-		return response.Error(http.StatusInternalServerError, "Failed to update channel rule", err)
+		return ruleStorageErrorResponse("Failed to update channel rule", err)
 	}
 	return response.JSON(http.StatusOK, util.DynMap{
 		"rule": rule,
@@ -1128,7 +1157,7 @@ func (g *GrafanaLive) HandleChannelRulesDeleteHTTP(c *models.ReqContext) respons
 	}
 	err = g.channelRuleStorage.DeleteChannelRule(c.Req.Context(), c.OrgId, rule.Pattern)
 	if err != nil {
-		return response.Error(http.StatusInternalServerError, "Failed to delete channel rule", err)
+		return ruleStorageErrorResponse("Failed to delete channel rule", err)
 	}
 	return response.JSON(http.StatusOK, util.DynMap{})
 }
@@ -1148,13 +1177,110 @@ func (g *GrafanaLive) HandlePipelineEntitiesListHTTP(_ *models.ReqContext) respo
 func (g *GrafanaLive) HandleRemoteWriteBackendsListHTTP(c *models.ReqContext) response.Response {
 	result, err := g.channelRuleStorage.ListRemoteWriteBackends(c.Req.Context(), c.OrgId)
 	if err != nil {
-		return response.Error(http.StatusInternalServerError, "Failed to get channel rules", err)
+		return response.Error(http.StatusInternalServerError, "Failed to get remote write backends", err)
+	}
+	for i := range result {
+		result[i] = withoutPassword(result[i])
 	}
 	return response.JSON(http.StatusOK, util.DynMap{
 		"remoteWriteBackends": result,
 	})
 }
 
+// HandleRemoteWriteBackendsPostHTTP ...
+func (g *GrafanaLive) HandleRemoteWriteBackendsPostHTTP(c *models.ReqContext) response.Response {
+	body, err := ioutil.ReadAll(c.Req.Body)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Error reading body", err)
+	}
+	var backend pipeline.RemoteWriteBackend
+	err = json.Unmarshal(body, &backend)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Error decoding remote write backend", err)
+	}
+	if backend.Settings == nil {
+		return response.Error(http.StatusBadRequest, "Remote write backend settings required", nil)
+	}
+	result, err := g.channelRuleStorage.CreateRemoteWriteBackend(c.Req.Context(), c.OrgId, backend)
+	if err != nil {
+		return ruleStorageErrorResponse("Failed to create remote write backend", err)
+	}
+	return response.JSON(http.StatusOK, util.DynMap{
+		"remoteWriteBackend": withoutPassword(result),
+	})
+}
+
+// HandleRemoteWriteBackendsPutHTTP ...
+func (g *GrafanaLive) HandleRemoteWriteBackendsPutHTTP(c *models.ReqContext) response.Response {
+	body, err := ioutil.ReadAll(c.Req.Body)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Error reading body", err)
+	}
+	var backend pipeline.RemoteWriteBackend
+	err = json.Unmarshal(body, &backend)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Error decoding remote write backend", err)
+	}
+	if backend.UID == "" {
+		return response.Error(http.StatusBadRequest, "Remote write backend uid required", nil)
+	}
+	if backend.Settings == nil {
+		return response.Error(http.StatusBadRequest, "Remote write backend settings required", nil)
+	}
+	result, err := g.channelRuleStorage.UpdateRemoteWriteBackend(c.Req.Context(), c.OrgId, backend)
+	if err != nil {
+		return ruleStorageErrorResponse("Failed to update remote write backend", err)
+	}
+	return response.JSON(http.StatusOK, util.DynMap{
+		"remoteWriteBackend": withoutPassword(result),
+	})
+}
+
+// HandleRemoteWriteBackendsDeleteHTTP ...
+func (g *GrafanaLive) HandleRemoteWriteBackendsDeleteHTTP(c *models.ReqContext) response.Response {
+	body, err := ioutil.ReadAll(c.Req.Body)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Error reading body", err)
+	}
+	var backend pipeline.RemoteWriteBackend
+	err = json.Unmarshal(body, &backend)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Error decoding remote write backend", err)
+	}
+	if backend.UID == "" {
+		return response.Error(http.StatusBadRequest, "Remote write backend uid required", nil)
+	}
+	err = g.channelRuleStorage.DeleteRemoteWriteBackend(c.Req.Context(), c.OrgId, backend.UID)
+	if err != nil {
+		return ruleStorageErrorResponse("Failed to delete remote write backend", err)
+	}
+	return response.JSON(http.StatusOK, util.DynMap{})
+}
+
+// withoutPassword returns a copy of the backend without the password, which must
+// not be sent to clients.
+func withoutPassword(backend pipeline.RemoteWriteBackend) pipeline.RemoteWriteBackend {
+	if backend.Settings != nil {
+		settings := *backend.Settings
+		settings.Password = ""
+		backend.Settings = &settings
+	}
+	return backend
+}
+
+func ruleStorageErrorResponse(message string, err error) response.Response {
+	switch {
+	case errors.Is(err, pipeline.ErrChannelRuleNotFound), errors.Is(err, pipeline.ErrRemoteWriteBackendNotFound):
+		return response.Error(http.StatusNotFound, message, err)
+	case errors.Is(err, pipeline.ErrChannelRuleExists), errors.Is(err, pipeline.ErrRemoteWriteBackendExists),
+		errors.Is(err, pipeline.ErrRemoteWriteBackendInUse), errors.Is(err, pipeline.ErrVersionConflict):
+		return response.Error(http.StatusConflict, message, err)
+	case errors.Is(err, pipeline.ErrInvalidChannelRule):
+		return response.Error(http.StatusBadRequest, message, err)
+	}
+	return response.Error(http.StatusInternalServerError, message, err)
+}
+
 // Write to the standard log15 logger
 func handleLog(msg centrifuge.LogEntry) {
 	arr := make([]interface{}, 0)
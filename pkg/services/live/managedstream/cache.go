@@ -12,6 +12,9 @@ type FrameCache interface {
 	GetActiveChannels(orgID int64) (map[string]json.RawMessage, error)
 	// GetFrame returns full JSON frame for a channel in org.
 	GetFrame(orgID int64, channel string) (json.RawMessage, bool, error)
+	// GetHistory returns the JSON frames kept in the history of a channel in org, oldest first.
+	// The history only has frames with the schema of the last frame.
+	GetHistory(orgID int64, channel string) ([]json.RawMessage, error)
 	// Update updates frame cache and returns true if schema changed.
 	Update(orgID int64, channel string, frameJson data.FrameJSONCache) (bool, error)
 }
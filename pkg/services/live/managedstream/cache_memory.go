@@ -9,14 +9,18 @@ import (
 
 // MemoryFrameCache ...
 type MemoryFrameCache struct {
-	mu     sync.RWMutex
-	frames map[int64]map[string]data.FrameJSONCache
+	mu        sync.RWMutex
+	frames    map[int64]map[string]data.FrameJSONCache
+	history   HistoryConfig
+	histories map[int64]map[string]*frameHistory
 }
 
 // NewMemoryFrameCache ...
-func NewMemoryFrameCache() *MemoryFrameCache {
+func NewMemoryFrameCache(history HistoryConfig) *MemoryFrameCache {
 	return &MemoryFrameCache{
-		frames: map[int64]map[string]data.FrameJSONCache{},
+		frames:    map[int64]map[string]data.FrameJSONCache{},
+		history:   history,
+		histories: map[int64]map[string]*frameHistory{},
 	}
 }
 
@@ -41,6 +45,16 @@ func (c *MemoryFrameCache) GetFrame(orgID int64, channel string) (json.RawMessag
 	return cachedFrame.Bytes(data.IncludeAll), ok, nil
 }
 
+func (c *MemoryFrameCache) GetHistory(orgID int64, channel string) ([]json.RawMessage, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	h, ok := c.histories[orgID][channel]
+	if !ok {
+		return nil, nil
+	}
+	return h.since(c.history.minTime()), nil
+}
+
 func (c *MemoryFrameCache) Update(orgID int64, channel string, jsonFrame data.FrameJSONCache) (bool, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -50,5 +64,22 @@ func (c *MemoryFrameCache) Update(orgID int64, channel string, jsonFrame data.Fr
 	cachedJsonFrame, exists := c.frames[orgID][channel]
 	schemaUpdated := !exists || !cachedJsonFrame.SameSchema(&jsonFrame)
 	c.frames[orgID][channel] = jsonFrame
+	if c.history.Enabled() {
+		c.updateHistory(orgID, channel, jsonFrame, schemaUpdated)
+	}
 	return schemaUpdated, nil
 }
+
+func (c *MemoryFrameCache) updateHistory(orgID int64, channel string, jsonFrame data.FrameJSONCache, schemaUpdated bool) {
+	if _, ok := c.histories[orgID]; !ok {
+		c.histories[orgID] = map[string]*frameHistory{}
+	}
+	h, ok := c.histories[orgID][channel]
+	if !ok {
+		h = newFrameHistory(c.history.Size)
+		c.histories[orgID][channel] = h
+	} else if schemaUpdated {
+		h.reset()
+	}
+	h.push(historyEntry{Time: timeNow(), Frame: jsonFrame.Bytes(data.IncludeAll)})
+}
@@ -2,7 +2,9 @@ package managedstream
 
 import (
 	"encoding/json"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 
@@ -62,8 +64,63 @@ func testFrameCache(t *testing.T, c FrameCache) {
 	require.NotEqual(t, string(channels["test"]), string(schema))
 }
 
+func testFrameCacheHistory(t *testing.T, c FrameCache) {
+	now := time.Now()
+	timeNow = func() time.Time { return now }
+	t.Cleanup(func() {
+		timeNow = time.Now
+	})
+
+	// Use a new channel, Redis may keep the history of previous runs.
+	channel := fmt.Sprintf("history_%d", now.UnixNano())
+	update := func(field string, value int64) {
+		frameJsonCache, err := data.FrameToJSONCache(data.NewFrame("hello", data.NewField(field, nil, []int64{value})))
+		require.NoError(t, err)
+		_, err = c.Update(1, channel, frameJsonCache)
+		require.NoError(t, err)
+	}
+	values := func() []int64 {
+		history, err := c.GetHistory(1, channel)
+		require.NoError(t, err)
+		values := make([]int64, 0, len(history))
+		for _, frameJSON := range history {
+			var f data.Frame
+			require.NoError(t, json.Unmarshal(frameJSON, &f))
+			values = append(values, f.Fields[0].At(0).(int64))
+		}
+		return values
+	}
+
+	// Only the last frames are kept.
+	for i := int64(1); i <= 4; i++ {
+		update("value", i)
+	}
+	require.Equal(t, []int64{2, 3, 4}, values())
+
+	// Other orgs have their own history.
+	history, err := c.GetHistory(2, channel)
+	require.NoError(t, err)
+	require.Empty(t, history)
+
+	// Old frames are dropped.
+	now = now.Add(30 * time.Second)
+	update("value", 5)
+	now = now.Add(45 * time.Second)
+	require.Equal(t, []int64{5}, values())
+
+	// The history is reset when the schema changes.
+	update("other", 6)
+	require.Equal(t, []int64{6}, values())
+}
+
 func TestMemoryFrameCache(t *testing.T) {
-	c := NewMemoryFrameCache()
+	c := NewMemoryFrameCache(HistoryConfig{})
 	require.NotNil(t, c)
 	testFrameCache(t, c)
 }
+
+func TestMemoryFrameCacheHistory(t *testing.T) {
+	c := NewMemoryFrameCache(HistoryConfig{Size: 3, MaxAge: time.Minute})
+	require.NotNil(t, c)
+	testFrameCacheHistory(t, c)
+}
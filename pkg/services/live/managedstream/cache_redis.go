@@ -18,13 +18,15 @@ type RedisFrameCache struct {
 	mu          sync.RWMutex
 	redisClient *redis.Client
 	frames      map[int64]map[string]data.FrameJSONCache
+	history     HistoryConfig
 }
 
 // NewRedisFrameCache ...
-func NewRedisFrameCache(redisClient *redis.Client) *RedisFrameCache {
+func NewRedisFrameCache(redisClient *redis.Client, history HistoryConfig) *RedisFrameCache {
 	return &RedisFrameCache{
 		frames:      map[int64]map[string]data.FrameJSONCache{},
 		redisClient: redisClient,
+		history:     history,
 	}
 }
 
@@ -55,6 +57,29 @@ func (c *RedisFrameCache) GetFrame(orgID int64, channel string) (json.RawMessage
 	return json.RawMessage(result["frame"]), true, nil
 }
 
+func (c *RedisFrameCache) GetHistory(orgID int64, channel string) ([]json.RawMessage, error) {
+	if !c.history.Enabled() {
+		return nil, nil
+	}
+	key := getHistoryKey(orgchannel.PrependOrgID(orgID, channel))
+	result, err := c.redisClient.LRange(context.TODO(), key, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	minTime := c.history.minTime()
+	frames := make([]json.RawMessage, 0, len(result))
+	for _, item := range result {
+		var e historyEntry
+		if err := json.Unmarshal([]byte(item), &e); err != nil {
+			return nil, err
+		}
+		if e.Time.After(minTime) {
+			frames = append(frames, e.Frame)
+		}
+	}
+	return frames, nil
+}
+
 const (
 	frameCacheTTL = 7 * 24 * time.Hour
 )
@@ -67,48 +92,85 @@ func (c *RedisFrameCache) Update(orgID int64, channel string, jsonFrame data.Fra
 	c.frames[orgID][channel] = jsonFrame
 	c.mu.Unlock()
 
+	channelID := orgchannel.PrependOrgID(orgID, channel)
 	stringSchema := string(jsonFrame.Bytes(data.IncludeSchemaOnly))
+	frameJSON := jsonFrame.Bytes(data.IncludeAll)
 
-	key := getCacheKey(orgchannel.PrependOrgID(orgID, channel))
-
-	pipe := c.redisClient.TxPipeline()
-	defer func() { _ = pipe.Close() }()
-
-	ctx := context.TODO()
-
-	pipe.HGetAll(ctx, key)
-	pipe.HMSet(ctx, key, map[string]string{
-		"schema": stringSchema,
-		"frame":  string(jsonFrame.Bytes(data.IncludeAll)),
-	})
-	pipe.Expire(ctx, key, frameCacheTTL)
-
-	replies, err := pipe.Exec(ctx)
-	if err != nil {
-		return false, err
-	}
-	if len(replies) == 0 {
-		return false, errors.New("no replies in response")
-	}
-	reply := replies[0]
-
-	if reply.Err() != nil {
-		return false, err
-	}
-
-	if mapReply, ok := reply.(*redis.StringStringMapCmd); ok {
-		result, err := mapReply.Result()
+	var historyEntryJSON []byte
+	if c.history.Enabled() {
+		var err error
+		historyEntryJSON, err = json.Marshal(historyEntry{Time: timeNow(), Frame: frameJSON})
 		if err != nil {
 			return false, err
 		}
-		if len(result) == 0 {
-			return true, nil
+	}
+
+	var schemaUpdated bool
+	var err error
+	for i := 0; i < updateMaxAttempts; i++ {
+		schemaUpdated, err = c.update(channelID, stringSchema, string(frameJSON), historyEntryJSON)
+		if !errors.Is(err, redis.TxFailedErr) {
+			break
 		}
-		return result["schema"] != stringSchema, nil
 	}
-	return true, nil
+	return schemaUpdated, err
+}
+
+// updateMaxAttempts is the number of attempts to update a channel, as the update
+// fails when another Grafana instance updated the channel at the same time.
+const updateMaxAttempts = 3
+
+// update saves the last frame of a channel and appends it to the history of the channel,
+// which is reset when the schema changed, in a single transaction. It returns true if the
+// schema changed.
+func (c *RedisFrameCache) update(channelID string, stringSchema string, stringFrame string, historyEntryJSON []byte) (bool, error) {
+	key := getCacheKey(channelID)
+	historyKey := getHistoryKey(channelID)
+	ctx := context.TODO()
+
+	var schemaUpdated bool
+	// the transaction fails if the frame was updated since its schema was read
+	err := c.redisClient.Watch(ctx, func(tx *redis.Tx) error {
+		previousSchema, err := tx.HGet(ctx, key, "schema").Result()
+		switch {
+		case errors.Is(err, redis.Nil):
+			schemaUpdated = true
+		case err != nil:
+			return err
+		default:
+			schemaUpdated = previousSchema != stringSchema
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.HMSet(ctx, key, map[string]string{
+				"schema": stringSchema,
+				"frame":  stringFrame,
+			})
+			pipe.Expire(ctx, key, frameCacheTTL)
+			if historyEntryJSON == nil {
+				return nil
+			}
+			ttl := frameCacheTTL
+			if c.history.MaxAge > 0 {
+				ttl = c.history.MaxAge
+			}
+			if schemaUpdated {
+				pipe.Del(ctx, historyKey)
+			}
+			pipe.RPush(ctx, historyKey, historyEntryJSON)
+			pipe.LTrim(ctx, historyKey, int64(-c.history.Size), -1)
+			pipe.Expire(ctx, historyKey, ttl)
+			return nil
+		})
+		return err
+	}, key)
+	return schemaUpdated, err
 }
 
 func getCacheKey(channelID string) string {
 	return "gf_live.managed_stream." + channelID
 }
+
+func getHistoryKey(channelID string) string {
+	return "gf_live.managed_stream.history." + channelID
+}
@@ -5,6 +5,7 @@ package managedstream
 
 import (
 	"testing"
+	"time"
 
 	"github.com/go-redis/redis/v8"
 	"github.com/stretchr/testify/require"
@@ -14,7 +15,16 @@ func TestRedisCacheStorage(t *testing.T) {
 	redisClient := redis.NewClient(&redis.Options{
 		Addr: "localhost:6379",
 	})
-	c := NewRedisFrameCache(redisClient)
+	c := NewRedisFrameCache(redisClient, HistoryConfig{})
 	require.NotNil(t, c)
 	testFrameCache(t, c)
 }
+
+func TestRedisCacheStorageHistory(t *testing.T) {
+	redisClient := redis.NewClient(&redis.Options{
+		Addr: "localhost:6379",
+	})
+	c := NewRedisFrameCache(redisClient, HistoryConfig{Size: 3, MaxAge: time.Minute})
+	require.NotNil(t, c)
+	testFrameCacheHistory(t, c)
+}
@@ -0,0 +1,119 @@
+package managedstream
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// timeNow makes it possible to test the age of the history.
+var timeNow = time.Now
+
+// HistoryConfig bounds the history of frames kept for each managed channel.
+type HistoryConfig struct {
+	// Size is the maximum number of frames kept. 0 disables the history.
+	Size int
+	// MaxAge is the maximum age of the frames kept. 0 means no limit.
+	MaxAge time.Duration
+}
+
+// Enabled returns true if frames are kept in the history.
+func (c HistoryConfig) Enabled() bool {
+	return c.Size > 0
+}
+
+// minTime returns the time of the oldest frame to keep.
+func (c HistoryConfig) minTime() time.Time {
+	if c.MaxAge <= 0 {
+		return time.Time{}
+	}
+	return timeNow().Add(-c.MaxAge)
+}
+
+type historyEntry struct {
+	Time  time.Time       `json:"time"`
+	Frame json.RawMessage `json:"frame"`
+}
+
+// frameHistory is a ring buffer of the last frames pushed to a channel.
+type frameHistory struct {
+	entries []historyEntry
+	head    int // Index of the oldest entry.
+	len     int
+}
+
+func newFrameHistory(size int) *frameHistory {
+	return &frameHistory{entries: make([]historyEntry, size)}
+}
+
+func (h *frameHistory) push(e historyEntry) {
+	if h.len < len(h.entries) {
+		h.entries[(h.head+h.len)%len(h.entries)] = e
+		h.len++
+		return
+	}
+	h.entries[h.head] = e
+	h.head = (h.head + 1) % len(h.entries)
+}
+
+func (h *frameHistory) reset() {
+	for i := range h.entries {
+		h.entries[i] = historyEntry{}
+	}
+	h.head, h.len = 0, 0
+}
+
+// since returns the frames pushed after t, oldest first.
+func (h *frameHistory) since(t time.Time) []json.RawMessage {
+	frames := make([]json.RawMessage, 0, h.len)
+	for i := 0; i < h.len; i++ {
+		e := h.entries[(h.head+i)%len(h.entries)]
+		if e.Time.After(t) {
+			frames = append(frames, e.Frame)
+		}
+	}
+	return frames
+}
+
+// mergeFrames concatenates the rows of JSON frames into a single JSON frame.
+// Frames with a schema different from the first one are skipped.
+func mergeFrames(frames []json.RawMessage) (json.RawMessage, error) {
+	if len(frames) == 1 {
+		return frames[0], nil
+	}
+	var merged *data.Frame
+	for _, frameJSON := range frames {
+		var frame data.Frame
+		if err := json.Unmarshal(frameJSON, &frame); err != nil {
+			return nil, fmt.Errorf("error unmarshalling frame: %w", err)
+		}
+		if merged == nil {
+			merged = &frame
+			continue
+		}
+		if !sameFieldTypes(merged, &frame) {
+			continue
+		}
+		for i := 0; i < frame.Rows(); i++ {
+			merged.AppendRow(frame.RowCopy(i)...)
+		}
+	}
+	if merged == nil {
+		return nil, nil
+	}
+	return data.FrameToJSON(merged, data.IncludeAll)
+}
+
+func sameFieldTypes(a, b *data.Frame) bool {
+	if len(a.Fields) != len(b.Fields) {
+		return false
+	}
+	for i := range a.Fields {
+		if a.Fields[i].Name != b.Fields[i].Name || a.Fields[i].Type() != b.Fields[i].Type() {
+			return false
+		}
+	}
+	return true
+}
@@ -236,6 +236,19 @@ func (s *NamespaceStream) GetHandlerForPath(_ string) (models.ChannelHandler, er
 
 func (s *NamespaceStream) OnSubscribe(_ context.Context, u *models.SignedInUser, e models.SubscribeEvent) (models.SubscribeReply, backend.SubscribeStreamStatus, error) {
 	reply := models.SubscribeReply{}
+	// Send the recent history so that subscribers do not start empty, or at least the last frame.
+	history, err := s.frameCache.GetHistory(u.OrgId, e.Channel)
+	if err != nil {
+		return reply, 0, err
+	}
+	if len(history) > 0 {
+		frameJSON, err := mergeFrames(history)
+		if err != nil {
+			return reply, 0, err
+		}
+		reply.Data = frameJSON
+		return reply, backend.SubscribeStreamStatusOK, nil
+	}
 	frameJSON, ok, err := s.frameCache.GetFrame(u.OrgId, e.Channel)
 	if err != nil {
 		return reply, 0, err
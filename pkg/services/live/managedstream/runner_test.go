@@ -1,11 +1,15 @@
 package managedstream
 
 import (
+	"context"
+	"encoding/json"
 	"testing"
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/models"
 )
 
 type testPublisher struct {
@@ -18,13 +22,13 @@ func (p *testPublisher) publish(_ int64, _ string, _ []byte) error {
 
 func TestNewManagedStream(t *testing.T) {
 	publisher := &testPublisher{t: t}
-	c := NewNamespaceStream(1, "stream", "a", publisher.publish, nil, NewMemoryFrameCache())
+	c := NewNamespaceStream(1, "stream", "a", publisher.publish, nil, NewMemoryFrameCache(HistoryConfig{}))
 	require.NotNil(t, c)
 }
 
 func TestManagedStreamMinuteRate(t *testing.T) {
 	publisher := &testPublisher{t: t}
-	c := NewNamespaceStream(1, "stream", "a", publisher.publish, nil, NewMemoryFrameCache())
+	c := NewNamespaceStream(1, "stream", "a", publisher.publish, nil, NewMemoryFrameCache(HistoryConfig{}))
 	require.NotNil(t, c)
 
 	c.incRate("test1", time.Now().Unix())
@@ -46,7 +50,7 @@ func TestManagedStreamMinuteRate(t *testing.T) {
 
 func TestGetManagedStreams(t *testing.T) {
 	publisher := &testPublisher{t: t}
-	frameCache := NewMemoryFrameCache()
+	frameCache := NewMemoryFrameCache(HistoryConfig{})
 	runner := NewRunner(publisher.publish, nil, frameCache)
 	s1, err := runner.GetOrCreateStream(1, "stream", "test1")
 	require.NoError(t, err)
@@ -82,3 +86,44 @@ func TestGetManagedStreams(t *testing.T) {
 	require.NoError(t, err)
 	require.Len(t, managedChannels, 6) // Not affected by other org.
 }
+
+func TestNamespaceStreamOnSubscribe(t *testing.T) {
+	publisher := &testPublisher{t: t}
+	user := &models.SignedInUser{OrgId: 1}
+	event := models.SubscribeEvent{Channel: "stream/a/cpu"}
+
+	subscribe := func(t *testing.T, s *NamespaceStream) *data.Frame {
+		t.Helper()
+		reply, _, err := s.OnSubscribe(context.Background(), user, event)
+		require.NoError(t, err)
+		var frame data.Frame
+		require.NoError(t, json.Unmarshal(reply.Data, &frame))
+		return &frame
+	}
+	push := func(t *testing.T, s *NamespaceStream, values ...float64) {
+		t.Helper()
+		for _, v := range values {
+			err := s.Push("cpu", data.NewFrame("cpu",
+				data.NewField("time", nil, []time.Time{time.Now()}),
+				data.NewField("value", nil, []float64{v}),
+			))
+			require.NoError(t, err)
+		}
+	}
+
+	t.Run("without history the last frame is sent", func(t *testing.T) {
+		s := NewNamespaceStream(1, "stream", "a", publisher.publish, nil, NewMemoryFrameCache(HistoryConfig{}))
+		push(t, s, 1, 2, 3)
+		frame := subscribe(t, s)
+		require.Equal(t, 1, frame.Rows())
+		require.Equal(t, 3.0, frame.Fields[1].At(0))
+	})
+
+	t.Run("with history the recent frames are merged", func(t *testing.T) {
+		s := NewNamespaceStream(1, "stream", "a", publisher.publish, nil, NewMemoryFrameCache(HistoryConfig{Size: 3}))
+		push(t, s, 1, 2, 3, 4)
+		frame := subscribe(t, s)
+		require.Equal(t, 3, frame.Rows())
+		require.Equal(t, []interface{}{2.0, 3.0, 4.0}, []interface{}{frame.Fields[1].At(0), frame.Fields[1].At(1), frame.Fields[1].At(2)})
+	})
+}
@@ -2,6 +2,7 @@ package pipeline
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/grafana/grafana/pkg/models"
@@ -23,10 +24,15 @@ type ConverterConfig struct {
 }
 
 type FrameProcessorConfig struct {
-	Type                      string                          `json:"type"`
-	DropFieldsProcessorConfig *DropFieldsFrameProcessorConfig `json:"dropFields,omitempty"`
-	KeepFieldsProcessorConfig *KeepFieldsFrameProcessorConfig `json:"keepFields,omitempty"`
-	MultipleProcessorConfig   *MultipleFrameProcessorConfig   `json:"multiple,omitempty"`
+	Type                         string                             `json:"type"`
+	DropFieldsProcessorConfig    *DropFieldsFrameProcessorConfig    `json:"dropFields,omitempty"`
+	KeepFieldsProcessorConfig    *KeepFieldsFrameProcessorConfig    `json:"keepFields,omitempty"`
+	MultipleProcessorConfig      *MultipleFrameProcessorConfig      `json:"multiple,omitempty"`
+	AggregateProcessorConfig     *AggregateFrameProcessorConfig     `json:"aggregate,omitempty"`
+	RenameFieldsProcessorConfig  *RenameFieldsFrameProcessorConfig  `json:"renameFields,omitempty"`
+	FieldConfigProcessorConfig   *FieldConfigFrameProcessorConfig   `json:"fieldConfig,omitempty"`
+	CastFieldsProcessorConfig    *CastFieldsFrameProcessorConfig    `json:"castFields,omitempty"`
+	ComputeFieldsProcessorConfig *ComputeFieldsFrameProcessorConfig `json:"computeFields,omitempty"`
 }
 
 type MultipleFrameProcessorConfig struct {
@@ -96,8 +102,11 @@ type ChannelRuleSettings struct {
 }
 
 type ChannelRule struct {
-	OrgId    int64               `json:"-"`
-	Pattern  string              `json:"pattern"`
+	OrgId   int64  `json:"-"`
+	Pattern string `json:"pattern"`
+	// Version is incremented on each update of the rule. Updating a rule with
+	// a version different from the stored one fails, unless it is 0.
+	Version  int64               `json:"version,omitempty"`
 	Settings ChannelRuleSettings `json:"settings"`
 }
 
@@ -145,8 +154,11 @@ func typeRegistered(entityType string, registry []EntityInfo) bool {
 }
 
 type RemoteWriteBackend struct {
-	OrgId    int64              `json:"-"`
-	UID      string             `json:"uid"`
+	OrgId int64  `json:"-"`
+	UID   string `json:"uid"`
+	// Version is incremented on each update of the backend. Updating a backend
+	// with a version different from the stored one fails, unless it is 0.
+	Version  int64              `json:"version,omitempty"`
 	Settings *RemoteWriteConfig `json:"settings"`
 }
 
@@ -158,7 +170,9 @@ type ChannelRules struct {
 	Rules []ChannelRule `json:"rules"`
 }
 
-func checkRulesValid(orgID int64, rules []ChannelRule) (ok bool, reason string) {
+// CheckRulesValid checks that the patterns of the rules of an organization
+// do not conflict with each other.
+func CheckRulesValid(orgID int64, rules []ChannelRule) (ok bool, reason string) {
 	t := tree.New()
 	defer func() {
 		if r := recover(); r != nil {
@@ -192,8 +206,21 @@ type FrameConditionCheckerConfig struct {
 	NumberCompareConditionConfig   *NumberCompareFrameConditionConfig   `json:"numberCompare,omitempty"`
 }
 
+var (
+	ErrInvalidChannelRule         = errors.New("invalid channel rule")
+	ErrChannelRuleNotFound        = errors.New("channel rule not found")
+	ErrChannelRuleExists          = errors.New("pattern already exists in org")
+	ErrRemoteWriteBackendNotFound = errors.New("remote write backend not found")
+	ErrRemoteWriteBackendExists   = errors.New("remote write backend uid already exists in org")
+	ErrRemoteWriteBackendInUse    = errors.New("remote write backend is used by a channel rule")
+	ErrVersionConflict            = errors.New("version conflict, it was changed since it was read")
+)
+
 type RuleStorage interface {
 	ListRemoteWriteBackends(_ context.Context, orgID int64) ([]RemoteWriteBackend, error)
+	CreateRemoteWriteBackend(_ context.Context, orgID int64, backend RemoteWriteBackend) (RemoteWriteBackend, error)
+	UpdateRemoteWriteBackend(_ context.Context, orgID int64, backend RemoteWriteBackend) (RemoteWriteBackend, error)
+	DeleteRemoteWriteBackend(_ context.Context, orgID int64, uid string) error
 	ListChannelRules(_ context.Context, orgID int64) ([]ChannelRule, error)
 	CreateChannelRule(_ context.Context, orgID int64, rule ChannelRule) (ChannelRule, error)
 	UpdateChannelRule(_ context.Context, orgID int64, rule ChannelRule) (ChannelRule, error)
@@ -204,6 +231,7 @@ type StorageRuleBuilder struct {
 	Node                 *centrifuge.Node
 	ManagedStream        *managedstream.Runner
 	FrameStorage         *FrameStorage
+	AggregateStorage     *AggregateStorage
 	RuleStorage          RuleStorage
 	ChannelHandlerGetter ChannelHandlerGetter
 }
@@ -284,6 +312,31 @@ func (f *StorageRuleBuilder) extractFrameProcessor(config *FrameProcessorConfig)
 			return nil, missingConfiguration
 		}
 		return NewKeepFieldsFrameProcessor(*config.KeepFieldsProcessorConfig), nil
+	case FrameProcessorTypeAggregate:
+		if config.AggregateProcessorConfig == nil {
+			return nil, missingConfiguration
+		}
+		return NewAggregateFrameProcessor(f.AggregateStorage, *config.AggregateProcessorConfig)
+	case FrameProcessorTypeRenameFields:
+		if config.RenameFieldsProcessorConfig == nil {
+			return nil, missingConfiguration
+		}
+		return NewRenameFieldsFrameProcessor(*config.RenameFieldsProcessorConfig), nil
+	case FrameProcessorTypeFieldConfig:
+		if config.FieldConfigProcessorConfig == nil {
+			return nil, missingConfiguration
+		}
+		return NewFieldConfigFrameProcessor(*config.FieldConfigProcessorConfig), nil
+	case FrameProcessorTypeCastFields:
+		if config.CastFieldsProcessorConfig == nil {
+			return nil, missingConfiguration
+		}
+		return NewCastFieldsFrameProcessor(*config.CastFieldsProcessorConfig)
+	case FrameProcessorTypeComputeFields:
+		if config.ComputeFieldsProcessorConfig == nil {
+			return nil, missingConfiguration
+		}
+		return NewComputeFieldsFrameProcessor(*config.ComputeFieldsProcessorConfig)
 	case FrameProcessorTypeMultiple:
 		if config.MultipleProcessorConfig == nil {
 			return nil, missingConfiguration
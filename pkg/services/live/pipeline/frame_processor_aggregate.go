@@ -0,0 +1,434 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/live/orgchannel"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// AggregateFunction reduces the values of a field in a window to a single value.
+type AggregateFunction string
+
+const (
+	AggregateFunctionAvg   AggregateFunction = "avg"
+	AggregateFunctionMin   AggregateFunction = "min"
+	AggregateFunctionMax   AggregateFunction = "max"
+	AggregateFunctionSum   AggregateFunction = "sum"
+	AggregateFunctionCount AggregateFunction = "count"
+	AggregateFunctionLast  AggregateFunction = "last"
+)
+
+type AggregateFieldConfig struct {
+	// Name of the field to aggregate.
+	Name string `json:"name"`
+	// Functions to apply to the values of the field. Each function results in
+	// a field named <name>_<function> in the aggregated frame.
+	Functions []AggregateFunction `json:"functions"`
+}
+
+type AggregateFrameProcessorConfig struct {
+	// WindowMilliseconds is the duration of the windows.
+	WindowMilliseconds int64 `json:"windowMilliseconds"`
+	// EveryMilliseconds is the duration between the ends of two consecutive windows.
+	// When it is shorter than WindowMilliseconds windows slide and overlap. If not
+	// set then it is equal to WindowMilliseconds, i.e. windows are tumbling.
+	EveryMilliseconds int64 `json:"everyMilliseconds,omitempty"`
+	// TimeField is the name of the time field. If not set then the first time field
+	// of the frame is used.
+	TimeField string `json:"timeField,omitempty"`
+	// GroupBy is the list of labels to group rows by. Labels are taken from the labels
+	// column (see influxAuto converter labels_column frame format) or from the labels
+	// of the aggregated fields. If not set then rows are grouped by all their labels.
+	GroupBy []string `json:"groupBy,omitempty"`
+	// Fields to aggregate. Other fields are dropped.
+	Fields []AggregateFieldConfig `json:"fields"`
+}
+
+// AggregateFrameProcessor downsamples frames by aggregating the values of fields over
+// time windows. Frames are held back until a row newer than the current window arrives,
+// then a frame with one row per group for each window closed is returned. The time of
+// the aggregated rows is the end of their window. Rows older than the current window
+// are dropped.
+//
+// The processor stops frame processing while the window is open so it is usually
+// used in a rule of a separate channel, frames being sent there with redirect output.
+type AggregateFrameProcessor struct {
+	storage *AggregateStorage
+	config  AggregateFrameProcessorConfig
+	window  time.Duration
+	every   time.Duration
+}
+
+func NewAggregateFrameProcessor(storage *AggregateStorage, config AggregateFrameProcessorConfig) (*AggregateFrameProcessor, error) {
+	if config.WindowMilliseconds <= 0 {
+		return nil, errors.New("window must be positive")
+	}
+	if config.EveryMilliseconds < 0 {
+		return nil, errors.New("every must be positive")
+	}
+	if len(config.Fields) == 0 {
+		return nil, errors.New("no fields to aggregate")
+	}
+	for _, f := range config.Fields {
+		if len(f.Functions) == 0 {
+			return nil, fmt.Errorf("no functions for field %s", f.Name)
+		}
+		for _, fn := range f.Functions {
+			switch fn {
+			case AggregateFunctionAvg, AggregateFunctionMin, AggregateFunctionMax,
+				AggregateFunctionSum, AggregateFunctionCount, AggregateFunctionLast:
+			default:
+				return nil, fmt.Errorf("unknown aggregate function: %s", fn)
+			}
+		}
+	}
+	window := time.Duration(config.WindowMilliseconds) * time.Millisecond
+	every := window
+	if config.EveryMilliseconds > 0 {
+		every = time.Duration(config.EveryMilliseconds) * time.Millisecond
+	}
+	return &AggregateFrameProcessor{
+		storage: storage,
+		config:  config,
+		window:  window,
+		every:   every,
+	}, nil
+}
+
+const FrameProcessorTypeAggregate = "aggregate"
+
+func (p *AggregateFrameProcessor) Type() string {
+	return FrameProcessorTypeAggregate
+}
+
+func (p *AggregateFrameProcessor) ProcessFrame(_ context.Context, vars Vars, frame *data.Frame) (*data.Frame, error) {
+	timeField := p.timeField(frame)
+	if timeField == nil {
+		return nil, errors.New("time field not found")
+	}
+
+	fields := make([]*data.Field, len(p.config.Fields))
+	fieldTypes := make([]data.FieldType, len(p.config.Fields))
+	for i, fc := range p.config.Fields {
+		for _, f := range frame.Fields {
+			if f.Name == fc.Name {
+				fields[i] = f
+				fieldTypes[i] = f.Type()
+				break
+			}
+		}
+		if fields[i] == nil {
+			return nil, fmt.Errorf("field not found: %s", fc.Name)
+		}
+	}
+
+	var labelsField *data.Field
+	for _, f := range frame.Fields {
+		if f.Name == "labels" && f.Type() == data.FieldTypeString {
+			labelsField = f
+			break
+		}
+	}
+	var fieldLabels data.Labels
+	for _, f := range fields {
+		if len(f.Labels) > 0 {
+			fieldLabels = f.Labels
+			break
+		}
+	}
+
+	state := p.storage.get(vars.OrgID, vars.Channel, p.config)
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	if !reflect.DeepEqual(state.fieldTypes, fieldTypes) {
+		// Field types changed, start over.
+		state.reset()
+		state.fieldTypes = fieldTypes
+	}
+
+	out := p.newFrame(frame.Name, fieldTypes, labelsField != nil || len(fieldLabels) > 0 || len(p.config.GroupBy) > 0)
+
+	for i := 0; i < frame.Rows(); i++ {
+		t, ok := timeAt(timeField, i)
+		if !ok {
+			continue
+		}
+		p.closeWindows(state, t, out)
+		if t.Before(state.windowEnd.Add(-p.window)) {
+			// Too late for the current window.
+			continue
+		}
+		labels := fieldLabels
+		if labelsField != nil {
+			var err error
+			labels, err = data.LabelsFromString(labelsField.At(i).(string))
+			if err != nil {
+				return nil, fmt.Errorf("error parsing labels: %w", err)
+			}
+		}
+		row := aggregateRow{
+			time:   t,
+			values: make([]interface{}, len(fields)),
+			floats: make([]*float64, len(fields)),
+		}
+		for j, f := range fields {
+			if _, ok := f.ConcreteAt(i); !ok {
+				continue
+			}
+			row.values[j] = f.CopyAt(i)
+			if f.Type().Numeric() {
+				row.floats[j], _ = f.NullableFloatAt(i)
+			}
+		}
+		state.add(p.groupLabels(labels), row)
+	}
+
+	if out.Rows() == 0 {
+		return nil, nil
+	}
+	return out, nil
+}
+
+func (p *AggregateFrameProcessor) timeField(frame *data.Frame) *data.Field {
+	for _, f := range frame.Fields {
+		if !f.Type().Time() {
+			continue
+		}
+		if p.config.TimeField == "" || f.Name == p.config.TimeField {
+			return f
+		}
+	}
+	return nil
+}
+
+func timeAt(f *data.Field, i int) (time.Time, bool) {
+	switch v := f.At(i).(type) {
+	case time.Time:
+		return v, true
+	case *time.Time:
+		if v == nil {
+			return time.Time{}, false
+		}
+		return *v, true
+	}
+	return time.Time{}, false
+}
+
+func (p *AggregateFrameProcessor) groupLabels(labels data.Labels) data.Labels {
+	if len(p.config.GroupBy) == 0 {
+		return labels
+	}
+	group := data.Labels{}
+	for _, name := range p.config.GroupBy {
+		if v, ok := labels[name]; ok {
+			group[name] = v
+		}
+	}
+	return group
+}
+
+func (p *AggregateFrameProcessor) newFrame(name string, fieldTypes []data.FieldType, withLabels bool) *data.Frame {
+	timeField := data.NewFieldFromFieldType(data.FieldTypeTime, 0)
+	timeField.Name = "time"
+	frame := data.NewFrame(name, timeField)
+	if withLabels {
+		frame.Fields = append(frame.Fields, data.NewField("labels", nil, []string{}))
+	}
+	for i, fc := range p.config.Fields {
+		for _, fn := range fc.Functions {
+			var f *data.Field
+			switch fn {
+			case AggregateFunctionCount:
+				f = data.NewFieldFromFieldType(data.FieldTypeFloat64, 0)
+			case AggregateFunctionLast:
+				f = data.NewFieldFromFieldType(fieldTypes[i], 0)
+			default:
+				f = data.NewFieldFromFieldType(data.FieldTypeNullableFloat64, 0)
+			}
+			f.Name = fc.Name + "_" + string(fn)
+			frame.Fields = append(frame.Fields, f)
+		}
+	}
+	return frame
+}
+
+// closeWindows appends to the frame the aggregated rows of the windows which end
+// before t.
+func (p *AggregateFrameProcessor) closeWindows(state *aggregateState, t time.Time, frame *data.Frame) {
+	if state.windowEnd.IsZero() {
+		state.windowEnd = t.Truncate(p.every).Add(p.every)
+		return
+	}
+	for !t.Before(state.windowEnd) {
+		p.appendWindow(state, state.windowEnd.Add(-p.window), state.windowEnd, frame)
+		state.windowEnd = state.windowEnd.Add(p.every)
+		state.prune(state.windowEnd.Add(-p.window))
+		if len(state.groups) == 0 {
+			// No need to go through the windows without rows.
+			if end := t.Truncate(p.every).Add(p.every); end.After(state.windowEnd) {
+				state.windowEnd = end
+			}
+		}
+	}
+}
+
+func (p *AggregateFrameProcessor) appendWindow(state *aggregateState, start, end time.Time, frame *data.Frame) {
+	keys := make([]string, 0, len(state.groups))
+	for key := range state.groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		group := state.groups[key]
+		var rows []aggregateRow
+		for _, row := range group.rows {
+			if !row.time.Before(start) && row.time.Before(end) {
+				rows = append(rows, row)
+			}
+		}
+		if len(rows) == 0 {
+			continue
+		}
+		values := []interface{}{end}
+		if len(frame.Fields) > 1 && frame.Fields[1].Name == "labels" {
+			values = append(values, key)
+		}
+		for i, fc := range p.config.Fields {
+			for _, fn := range fc.Functions {
+				values = append(values, aggregate(fn, rows, i))
+			}
+		}
+		frame.AppendRow(values...)
+	}
+}
+
+func aggregate(fn AggregateFunction, rows []aggregateRow, i int) interface{} {
+	switch fn {
+	case AggregateFunctionCount:
+		var count float64
+		for _, row := range rows {
+			if row.values[i] != nil {
+				count++
+			}
+		}
+		return count
+	case AggregateFunctionLast:
+		return rows[len(rows)-1].values[i]
+	}
+
+	var result *float64
+	var count float64
+	for _, row := range rows {
+		v := row.floats[i]
+		if v == nil || math.IsNaN(*v) {
+			continue
+		}
+		count++
+		if result == nil {
+			r := *v
+			result = &r
+			continue
+		}
+		switch fn {
+		case AggregateFunctionMin:
+			*result = math.Min(*result, *v)
+		case AggregateFunctionMax:
+			*result = math.Max(*result, *v)
+		default:
+			*result += *v
+		}
+	}
+	if fn == AggregateFunctionAvg && result != nil {
+		*result /= count
+	}
+	return result
+}
+
+type aggregateRow struct {
+	time   time.Time
+	values []interface{} // nil for null values.
+	floats []*float64    // nil for null and not numeric values.
+}
+
+type aggregateGroup struct {
+	rows []aggregateRow
+}
+
+type aggregateState struct {
+	mu         sync.Mutex
+	config     AggregateFrameProcessorConfig
+	fieldTypes []data.FieldType
+	windowEnd  time.Time // End of the oldest open window.
+	groups     map[string]*aggregateGroup
+}
+
+func (s *aggregateState) reset() {
+	s.windowEnd = time.Time{}
+	s.groups = map[string]*aggregateGroup{}
+}
+
+func (s *aggregateState) add(labels data.Labels, row aggregateRow) {
+	key := labels.String()
+	group, ok := s.groups[key]
+	if !ok {
+		group = &aggregateGroup{}
+		s.groups[key] = group
+	}
+	group.rows = append(group.rows, row)
+}
+
+// prune removes the rows older than t.
+func (s *aggregateState) prune(t time.Time) {
+	for key, group := range s.groups {
+		rows := group.rows[:0]
+		for _, row := range group.rows {
+			if !row.time.Before(t) {
+				rows = append(rows, row)
+			}
+		}
+		if len(rows) == 0 {
+			delete(s.groups, key)
+			continue
+		}
+		group.rows = rows
+	}
+}
+
+// AggregateStorage keeps the state of aggregation windows of channels in memory,
+// so it survives rebuilding channel rules. Not usable in HA setup.
+type AggregateStorage struct {
+	mu     sync.Mutex
+	states map[string]*aggregateState
+}
+
+func NewAggregateStorage() *AggregateStorage {
+	return &AggregateStorage{
+		states: map[string]*aggregateState{},
+	}
+}
+
+// get returns the state of the channel windows. The state is reset when the
+// configuration of the processor changes.
+func (s *AggregateStorage) get(orgID int64, channel string, config AggregateFrameProcessorConfig) *aggregateState {
+	key := orgchannel.PrependOrgID(orgID, channel)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state, ok := s.states[key]
+	if !ok || !reflect.DeepEqual(state.config, config) {
+		state = &aggregateState{config: config}
+		state.reset()
+		s.states[key] = state
+	}
+	return state
+}
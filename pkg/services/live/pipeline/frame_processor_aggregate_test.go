@@ -0,0 +1,133 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregateFrameProcessor_Config(t *testing.T) {
+	_, err := NewAggregateFrameProcessor(NewAggregateStorage(), AggregateFrameProcessorConfig{
+		Fields: []AggregateFieldConfig{{Name: "value", Functions: []AggregateFunction{AggregateFunctionAvg}}},
+	})
+	require.Error(t, err)
+
+	_, err = NewAggregateFrameProcessor(NewAggregateStorage(), AggregateFrameProcessorConfig{
+		WindowMilliseconds: 1000,
+	})
+	require.Error(t, err)
+
+	_, err = NewAggregateFrameProcessor(NewAggregateStorage(), AggregateFrameProcessorConfig{
+		WindowMilliseconds: 1000,
+		Fields:             []AggregateFieldConfig{{Name: "value", Functions: []AggregateFunction{"median"}}},
+	})
+	require.Error(t, err)
+}
+
+func TestAggregateFrameProcessor_Tumbling(t *testing.T) {
+	p, err := NewAggregateFrameProcessor(NewAggregateStorage(), AggregateFrameProcessorConfig{
+		WindowMilliseconds: 1000,
+		Fields: []AggregateFieldConfig{
+			{Name: "value", Functions: []AggregateFunction{
+				AggregateFunctionAvg, AggregateFunctionMin, AggregateFunctionMax,
+				AggregateFunctionSum, AggregateFunctionCount, AggregateFunctionLast,
+			}},
+		},
+	})
+	require.NoError(t, err)
+
+	start := time.Unix(100, 0)
+	newFrame := func(offsets []time.Duration, values []*float64) *data.Frame {
+		times := make([]time.Time, len(offsets))
+		for i, o := range offsets {
+			times[i] = start.Add(o)
+		}
+		return data.NewFrame("test", data.NewField("time", nil, times), data.NewField("value", nil, values))
+	}
+	v := func(f float64) *float64 { return &f }
+
+	vars := Vars{OrgID: 1, Channel: "stream/test/aggregate"}
+
+	// Rows of the first window are held back.
+	frame, err := p.ProcessFrame(context.Background(), vars, newFrame(
+		[]time.Duration{0, 200 * time.Millisecond, 400 * time.Millisecond},
+		[]*float64{v(1), v(3), nil},
+	))
+	require.NoError(t, err)
+	require.Nil(t, frame)
+
+	// The first window is closed by a row of the second window, the third window is
+	// empty and skipped.
+	frame, err = p.ProcessFrame(context.Background(), vars, newFrame(
+		[]time.Duration{600 * time.Millisecond, 1100 * time.Millisecond, 3100 * time.Millisecond},
+		[]*float64{v(5), v(2), v(7)},
+	))
+	require.NoError(t, err)
+	require.NotNil(t, frame)
+	require.Equal(t, 2, frame.Rows())
+
+	require.Equal(t, []interface{}{start.Add(time.Second), v(3), v(1), v(5), v(9), 3.0, v(5)}, frame.RowCopy(0))
+	require.Equal(t, []interface{}{start.Add(2 * time.Second), v(2), v(2), v(2), v(2), 1.0, v(2)}, frame.RowCopy(1))
+
+	// Rows older than the current window are dropped.
+	frame, err = p.ProcessFrame(context.Background(), vars, newFrame(
+		[]time.Duration{500 * time.Millisecond, 4000 * time.Millisecond},
+		[]*float64{v(100), v(1)},
+	))
+	require.NoError(t, err)
+	require.NotNil(t, frame)
+	require.Equal(t, 1, frame.Rows())
+	require.Equal(t, []interface{}{start.Add(4 * time.Second), v(7), v(7), v(7), v(7), 1.0, v(7)}, frame.RowCopy(0))
+}
+
+func TestAggregateFrameProcessor_SlidingGroupBy(t *testing.T) {
+	storage := NewAggregateStorage()
+	config := AggregateFrameProcessorConfig{
+		WindowMilliseconds: 2000,
+		EveryMilliseconds:  1000,
+		GroupBy:            []string{"host"},
+		Fields: []AggregateFieldConfig{
+			{Name: "usage", Functions: []AggregateFunction{AggregateFunctionMax}},
+		},
+	}
+	p, err := NewAggregateFrameProcessor(storage, config)
+	require.NoError(t, err)
+
+	start := time.Unix(100, 0)
+	frame := data.NewFrame("cpu",
+		data.NewField("labels", nil, []string{"cpu=0, host=a", "cpu=1, host=a", "cpu=0, host=b", "cpu=0, host=a", "cpu=0, host=a"}),
+		data.NewField("time", nil, []time.Time{start, start, start.Add(500 * time.Millisecond), start.Add(1500 * time.Millisecond), start.Add(2000 * time.Millisecond)}),
+		data.NewField("usage", nil, []float64{10, 30, 20, 5, 1}),
+	)
+
+	vars := Vars{OrgID: 1, Channel: "stream/test/sliding"}
+	out, err := p.ProcessFrame(context.Background(), vars, frame)
+	require.NoError(t, err)
+	require.NotNil(t, out)
+	require.Equal(t, 4, out.Rows())
+
+	v := func(f float64) *float64 { return &f }
+	// Window [99s, 101s).
+	require.Equal(t, []interface{}{start.Add(time.Second), "host=a", v(30)}, out.RowCopy(0))
+	require.Equal(t, []interface{}{start.Add(time.Second), "host=b", v(20)}, out.RowCopy(1))
+	// Window [100s, 102s).
+	require.Equal(t, []interface{}{start.Add(2 * time.Second), "host=a", v(30)}, out.RowCopy(2))
+	require.Equal(t, []interface{}{start.Add(2 * time.Second), "host=b", v(20)}, out.RowCopy(3))
+
+	// The state is kept by the storage when the processor is built again.
+	p, err = NewAggregateFrameProcessor(storage, config)
+	require.NoError(t, err)
+	out, err = p.ProcessFrame(context.Background(), vars, data.NewFrame("cpu",
+		data.NewField("labels", nil, []string{"cpu=0, host=b"}),
+		data.NewField("time", nil, []time.Time{start.Add(3000 * time.Millisecond)}),
+		data.NewField("usage", nil, []float64{0}),
+	))
+	require.NoError(t, err)
+	require.NotNil(t, out)
+	// Window [101s, 103s), there are no rows of host b in it.
+	require.Equal(t, 1, out.Rows())
+	require.Equal(t, []interface{}{start.Add(3 * time.Second), "host=a", v(5)}, out.RowCopy(0))
+}
@@ -0,0 +1,190 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+type CastField struct {
+	// Name of the field to cast.
+	Name string `json:"name"`
+	// Type to cast the field to. Supported types are float64, int64, string, bool,
+	// time and their nullable variants.
+	Type data.FieldType `json:"type"`
+}
+
+type CastFieldsFrameProcessorConfig struct {
+	Fields []CastField `json:"fields"`
+}
+
+// CastFieldsFrameProcessor can change the type of fields of a data.Frame, for example
+// to parse numbers sent as strings.
+type CastFieldsFrameProcessor struct {
+	config CastFieldsFrameProcessorConfig
+}
+
+func NewCastFieldsFrameProcessor(config CastFieldsFrameProcessorConfig) (*CastFieldsFrameProcessor, error) {
+	for _, f := range config.Fields {
+		if err := checkFieldType(f.Type); err != nil {
+			return nil, fmt.Errorf("field %s: %w", f.Name, err)
+		}
+	}
+	return &CastFieldsFrameProcessor{config: config}, nil
+}
+
+const FrameProcessorTypeCastFields = "castFields"
+
+func (p *CastFieldsFrameProcessor) Type() string {
+	return FrameProcessorTypeCastFields
+}
+
+func (p *CastFieldsFrameProcessor) ProcessFrame(_ context.Context, _ Vars, frame *data.Frame) (*data.Frame, error) {
+	for _, c := range p.config.Fields {
+		for i, field := range frame.Fields {
+			if field.Name != c.Name || field.Type() == c.Type {
+				continue
+			}
+			casted, err := castField(field, c.Type)
+			if err != nil {
+				return nil, fmt.Errorf("error casting field %s: %w", field.Name, err)
+			}
+			frame.Fields[i] = casted
+		}
+	}
+	return frame, nil
+}
+
+func castField(field *data.Field, fieldType data.FieldType) (*data.Field, error) {
+	casted := data.NewFieldFromFieldType(fieldType, field.Len())
+	casted.Name = field.Name
+	casted.Labels = field.Labels
+	casted.Config = field.Config
+	for i := 0; i < field.Len(); i++ {
+		v, ok := field.ConcreteAt(i)
+		if !ok {
+			if !fieldType.Nullable() {
+				return nil, fmt.Errorf("null value at row %d", i)
+			}
+			continue
+		}
+		cv, err := castValue(v, fieldType)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", i, err)
+		}
+		casted.SetConcrete(i, cv)
+	}
+	return casted, nil
+}
+
+// checkFieldType returns an error if values can not be cast to the field type.
+func checkFieldType(fieldType data.FieldType) error {
+	if fieldType == data.FieldTypeUnknown {
+		return errors.New("missing field type")
+	}
+	switch fieldType.NonNullableType() {
+	case data.FieldTypeFloat64, data.FieldTypeInt64, data.FieldTypeString, data.FieldTypeBool, data.FieldTypeTime:
+		return nil
+	}
+	return fmt.Errorf("unsupported field type: %s", fieldType)
+}
+
+// castValue converts a non-pointer value to the non-pointer type of values of fieldType.
+// Times are converted from and to numbers as milliseconds since Unix epoch, and from
+// and to strings in RFC3339 format.
+func castValue(v interface{}, fieldType data.FieldType) (interface{}, error) {
+	switch fieldType.NonNullableType() {
+	case data.FieldTypeFloat64:
+		return toFloat64(v)
+	case data.FieldTypeInt64:
+		if s, ok := v.(string); ok {
+			if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+				return i, nil
+			}
+		}
+		f, err := toFloat64(v)
+		if err != nil {
+			return nil, err
+		}
+		return int64(f), nil
+	case data.FieldTypeString:
+		switch v := v.(type) {
+		case string:
+			return v, nil
+		case time.Time:
+			return v.Format(time.RFC3339Nano), nil
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64), nil
+		case float32:
+			return strconv.FormatFloat(float64(v), 'f', -1, 32), nil
+		default:
+			return fmt.Sprint(v), nil
+		}
+	case data.FieldTypeBool:
+		switch v := v.(type) {
+		case bool:
+			return v, nil
+		case string:
+			return strconv.ParseBool(v)
+		default:
+			f, err := toFloat64(v)
+			if err != nil {
+				return nil, err
+			}
+			return f != 0, nil
+		}
+	case data.FieldTypeTime:
+		switch v := v.(type) {
+		case time.Time:
+			return v, nil
+		case string:
+			return time.Parse(time.RFC3339Nano, v)
+		default:
+			f, err := toFloat64(v)
+			if err != nil {
+				return nil, err
+			}
+			return time.Unix(0, int64(f*float64(time.Millisecond))), nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported field type: %s", fieldType)
+}
+
+func toFloat64(v interface{}) (float64, error) {
+	switch v := v.(type) {
+	case float64:
+		return v, nil
+	case float32:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case int32:
+		return float64(v), nil
+	case int16:
+		return float64(v), nil
+	case int8:
+		return float64(v), nil
+	case uint64:
+		return float64(v), nil
+	case uint32:
+		return float64(v), nil
+	case uint16:
+		return float64(v), nil
+	case uint8:
+		return float64(v), nil
+	case string:
+		return strconv.ParseFloat(v, 64)
+	case bool:
+		if v {
+			return 1, nil
+		}
+		return 0, nil
+	case time.Time:
+		return float64(v.UnixNano()) / float64(time.Millisecond), nil
+	}
+	return 0, fmt.Errorf("can not convert %T to a number", v)
+}
@@ -0,0 +1,68 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCastFieldsFrameProcessor(t *testing.T) {
+	p, err := NewCastFieldsFrameProcessor(CastFieldsFrameProcessorConfig{
+		Fields: []CastField{
+			{Name: "value", Type: data.FieldTypeNullableFloat64},
+			{Name: "count", Type: data.FieldTypeInt64},
+			{Name: "on", Type: data.FieldTypeNullableString},
+			{Name: "ts", Type: data.FieldTypeTime},
+		},
+	})
+	require.NoError(t, err)
+
+	s := func(s string) *string { return &s }
+	frame := data.NewFrame("test",
+		data.NewField("value", data.Labels{"host": "a"}, []*string{s("1.5"), nil}),
+		data.NewField("count", nil, []float64{2.7, 3}),
+		data.NewField("on", nil, []bool{true, false}),
+		data.NewField("ts", nil, []float64{1000, 1500}),
+	)
+	frame, err = p.ProcessFrame(context.Background(), Vars{}, frame)
+	require.NoError(t, err)
+
+	f := func(f float64) *float64 { return &f }
+	require.Equal(t, data.FieldTypeNullableFloat64, frame.Fields[0].Type())
+	require.Equal(t, data.Labels{"host": "a"}, frame.Fields[0].Labels)
+	require.Equal(t, []interface{}{f(1.5), int64(2), s("true"), time.Unix(1, 0)}, frame.RowCopy(0))
+	require.Equal(t, []interface{}{(*float64)(nil), int64(3), s("false"), time.Unix(1, 500000000)}, frame.RowCopy(1))
+}
+
+func TestCastFieldsFrameProcessor_Error(t *testing.T) {
+	p, err := NewCastFieldsFrameProcessor(CastFieldsFrameProcessorConfig{
+		Fields: []CastField{{Name: "value", Type: data.FieldTypeFloat64}},
+	})
+	require.NoError(t, err)
+
+	s := func(s string) *string { return &s }
+	_, err = p.ProcessFrame(context.Background(), Vars{}, data.NewFrame("test",
+		data.NewField("value", nil, []*string{s("1.5"), nil}),
+	))
+	require.Error(t, err)
+
+	_, err = p.ProcessFrame(context.Background(), Vars{}, data.NewFrame("test",
+		data.NewField("value", nil, []string{"not a number"}),
+	))
+	require.Error(t, err)
+}
+
+func TestNewCastFieldsFrameProcessor_UnsupportedType(t *testing.T) {
+	_, err := NewCastFieldsFrameProcessor(CastFieldsFrameProcessorConfig{
+		Fields: []CastField{{Name: "value"}},
+	})
+	require.Error(t, err)
+
+	_, err = NewCastFieldsFrameProcessor(CastFieldsFrameProcessorConfig{
+		Fields: []CastField{{Name: "value", Type: data.FieldTypeNullableUint8}},
+	})
+	require.Error(t, err)
+}
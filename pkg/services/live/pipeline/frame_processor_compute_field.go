@@ -0,0 +1,134 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dop251/goja"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+type ComputedField struct {
+	// Name of the field. If the frame already has a field with that name then
+	// the field is replaced.
+	Name string `json:"name"`
+	// Type of the field. Supported types are float64, int64, string, bool,
+	// time and their nullable variants.
+	Type data.FieldType `json:"type"`
+	// Expression is a Goja script computing the value of the field for each row.
+	// The values of the row are available in x by field name, for example:
+	// x.temperature * 9 / 5 + 32. Times are passed as milliseconds since Unix epoch.
+	// Fields computed before are available too.
+	Expression string            `json:"expression"`
+	Config     *data.FieldConfig `json:"config,omitempty"`
+}
+
+type ComputeFieldsFrameProcessorConfig struct {
+	Fields []ComputedField `json:"fields"`
+}
+
+// ComputeFieldsFrameProcessor can add fields computed from the values of other
+// fields to a data.Frame.
+type ComputeFieldsFrameProcessor struct {
+	config   ComputeFieldsFrameProcessorConfig
+	programs []*goja.Program
+}
+
+func NewComputeFieldsFrameProcessor(config ComputeFieldsFrameProcessorConfig) (*ComputeFieldsFrameProcessor, error) {
+	programs := make([]*goja.Program, len(config.Fields))
+	for i, f := range config.Fields {
+		if err := checkFieldType(f.Type); err != nil {
+			return nil, fmt.Errorf("field %s: %w", f.Name, err)
+		}
+		p, err := compileScript(f.Name, f.Expression)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: invalid expression: %w", f.Name, err)
+		}
+		programs[i] = p
+	}
+	return &ComputeFieldsFrameProcessor{config: config, programs: programs}, nil
+}
+
+const FrameProcessorTypeComputeFields = "computeFields"
+
+func (p *ComputeFieldsFrameProcessor) Type() string {
+	return FrameProcessorTypeComputeFields
+}
+
+func (p *ComputeFieldsFrameProcessor) ProcessFrame(_ context.Context, _ Vars, frame *data.Frame) (*data.Frame, error) {
+	rowLen, err := frame.RowLen()
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make([]*data.Field, len(p.config.Fields))
+	for i, c := range p.config.Fields {
+		fields[i] = data.NewFieldFromFieldType(c.Type, rowLen)
+		fields[i].Name = c.Name
+		fields[i].Config = c.Config
+	}
+
+	// the expressions of all rows share the time a single script run is given for each row
+	r := newRuntime()
+	err = r.withTimeout(time.Duration(rowLen)*scriptTimeout, func() error {
+		for row := 0; row < rowLen; row++ {
+			values := make(map[string]interface{}, len(frame.Fields)+len(fields))
+			for _, f := range frame.Fields {
+				values[f.Name] = scriptValue(f, row)
+			}
+			if err := r.setVar("x", values); err != nil {
+				return err
+			}
+			for i, c := range p.config.Fields {
+				v, err := r.getProgramValue(p.programs[i])
+				if err != nil {
+					return fmt.Errorf("error computing field %s: %w", c.Name, err)
+				}
+				if v == nil {
+					if !c.Type.Nullable() {
+						return fmt.Errorf("error computing field %s: null value at row %d", c.Name, row)
+					}
+				} else {
+					cv, err := castValue(v, c.Type)
+					if err != nil {
+						return fmt.Errorf("error computing field %s: %w", c.Name, err)
+					}
+					fields[i].SetConcrete(row, cv)
+				}
+				values[c.Name] = scriptValue(fields[i], row)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, field := range fields {
+		replaced := false
+		for i, f := range frame.Fields {
+			if f.Name == field.Name {
+				frame.Fields[i] = field
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			frame.Fields = append(frame.Fields, field)
+		}
+	}
+	return frame, nil
+}
+
+// scriptValue returns the value of a field passed to scripts.
+func scriptValue(f *data.Field, row int) interface{} {
+	v, ok := f.ConcreteAt(row)
+	if !ok {
+		return nil
+	}
+	if t, ok := v.(time.Time); ok {
+		return t.UnixNano() / int64(time.Millisecond)
+	}
+	return v
+}
@@ -0,0 +1,75 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeFieldsFrameProcessor(t *testing.T) {
+	p, err := NewComputeFieldsFrameProcessor(ComputeFieldsFrameProcessorConfig{
+		Fields: []ComputedField{
+			{Name: "fahrenheit", Type: data.FieldTypeNullableFloat64, Expression: "x.celsius === null ? null : x.celsius * 9 / 5 + 32"},
+			{Name: "hot", Type: data.FieldTypeNullableBool, Expression: "x.fahrenheit > 80"},
+			{Name: "status", Type: data.FieldTypeNullableString, Expression: "x.status.toUpperCase()"},
+			{Name: "seconds", Type: data.FieldTypeFloat64, Expression: "x.time / 1000"},
+		},
+	})
+	require.NoError(t, err)
+
+	f := func(f float64) *float64 { return &f }
+	frame := data.NewFrame("test",
+		data.NewField("time", nil, []time.Time{time.Unix(10, 0), time.Unix(20, 0)}),
+		data.NewField("celsius", nil, []*float64{f(30), nil}),
+		data.NewField("status", nil, []string{"ok", "down"}),
+	)
+	frame, err = p.ProcessFrame(context.Background(), Vars{}, frame)
+	require.NoError(t, err)
+	require.Len(t, frame.Fields, 6)
+
+	b := func(b bool) *bool { return &b }
+	s := func(s string) *string { return &s }
+	require.Equal(t, []interface{}{time.Unix(10, 0), f(30), s("OK"), f(86), b(true), 10.0}, frame.RowCopy(0))
+	require.Equal(t, []interface{}{time.Unix(20, 0), (*float64)(nil), s("DOWN"), (*float64)(nil), b(false), 20.0}, frame.RowCopy(1))
+}
+
+func TestComputeFieldsFrameProcessor_Error(t *testing.T) {
+	p, err := NewComputeFieldsFrameProcessor(ComputeFieldsFrameProcessorConfig{
+		Fields: []ComputedField{
+			{Name: "value", Type: data.FieldTypeFloat64, Expression: "x.missing"},
+		},
+	})
+	require.NoError(t, err)
+	_, err = p.ProcessFrame(context.Background(), Vars{}, data.NewFrame("test",
+		data.NewField("value", nil, []float64{1}),
+	))
+	require.Error(t, err)
+}
+
+func TestComputeFieldsFrameProcessor_Timeout(t *testing.T) {
+	p, err := NewComputeFieldsFrameProcessor(ComputeFieldsFrameProcessorConfig{
+		Fields: []ComputedField{
+			{Name: "value", Type: data.FieldTypeFloat64, Expression: "while (true) {}"},
+		},
+	})
+	require.NoError(t, err)
+	_, err = p.ProcessFrame(context.Background(), Vars{}, data.NewFrame("test",
+		data.NewField("value", nil, []float64{1}),
+	))
+	require.Error(t, err)
+}
+
+func TestNewComputeFieldsFrameProcessor_InvalidConfig(t *testing.T) {
+	_, err := NewComputeFieldsFrameProcessor(ComputeFieldsFrameProcessorConfig{
+		Fields: []ComputedField{{Name: "value", Expression: "x.value * 2"}},
+	})
+	require.Error(t, err)
+
+	_, err = NewComputeFieldsFrameProcessor(ComputeFieldsFrameProcessorConfig{
+		Fields: []ComputedField{{Name: "value", Type: data.FieldTypeFloat64, Expression: "x.value *"}},
+	})
+	require.Error(t, err)
+}
@@ -0,0 +1,73 @@
+package pipeline
+
+import (
+	"context"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// FieldConfigSetting is a field config to set on a field. Only the properties
+// which are set replace the ones of the field.
+type FieldConfigSetting struct {
+	// Name of the field.
+	Name        string                 `json:"name"`
+	DisplayName string                 `json:"displayName,omitempty"`
+	Unit        string                 `json:"unit,omitempty"`
+	Min         *float64               `json:"min,omitempty"`
+	Max         *float64               `json:"max,omitempty"`
+	Thresholds  *data.ThresholdsConfig `json:"thresholds,omitempty"`
+}
+
+type FieldConfigFrameProcessorConfig struct {
+	Fields []FieldConfigSetting `json:"fields"`
+}
+
+// FieldConfigFrameProcessor can set display name, unit, min/max and thresholds
+// of fields of a data.Frame.
+type FieldConfigFrameProcessor struct {
+	config FieldConfigFrameProcessorConfig
+}
+
+func NewFieldConfigFrameProcessor(config FieldConfigFrameProcessorConfig) *FieldConfigFrameProcessor {
+	return &FieldConfigFrameProcessor{config: config}
+}
+
+const FrameProcessorTypeFieldConfig = "fieldConfig"
+
+func (p *FieldConfigFrameProcessor) Type() string {
+	return FrameProcessorTypeFieldConfig
+}
+
+func (p *FieldConfigFrameProcessor) ProcessFrame(_ context.Context, _ Vars, frame *data.Frame) (*data.Frame, error) {
+	for _, s := range p.config.Fields {
+		for _, field := range frame.Fields {
+			if field.Name != s.Name {
+				continue
+			}
+			// Config can be shared with other frames, e.g. set by a converter.
+			var config data.FieldConfig
+			if field.Config != nil {
+				config = *field.Config
+			}
+			if s.DisplayName != "" {
+				config.DisplayName = s.DisplayName
+			}
+			if s.Unit != "" {
+				config.Unit = s.Unit
+			}
+			if s.Min != nil {
+				min := data.ConfFloat64(*s.Min)
+				config.Min = &min
+			}
+			if s.Max != nil {
+				max := data.ConfFloat64(*s.Max)
+				config.Max = &max
+			}
+			if s.Thresholds != nil {
+				config.Thresholds = s.Thresholds
+			}
+			field.Config = &config
+		}
+	}
+	return frame, nil
+}
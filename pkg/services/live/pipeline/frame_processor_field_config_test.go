@@ -0,0 +1,37 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFieldConfigFrameProcessor(t *testing.T) {
+	min, max := 0.0, 100.0
+	p := NewFieldConfigFrameProcessor(FieldConfigFrameProcessorConfig{
+		Fields: []FieldConfigSetting{
+			{Name: "cpu", DisplayName: "CPU", Unit: "percent", Min: &min, Max: &max},
+		},
+	})
+
+	config := &data.FieldConfig{Unit: "none", Decimals: new(uint16)}
+	frame := data.NewFrame("test",
+		data.NewField("cpu", nil, []float64{1}).SetConfig(config),
+		data.NewField("memory", nil, []float64{1}),
+	)
+	frame, err := p.ProcessFrame(context.Background(), Vars{}, frame)
+	require.NoError(t, err)
+
+	cpuConfig := frame.Fields[0].Config
+	require.Equal(t, "CPU", cpuConfig.DisplayName)
+	require.Equal(t, "percent", cpuConfig.Unit)
+	require.Equal(t, data.ConfFloat64(0), *cpuConfig.Min)
+	require.Equal(t, data.ConfFloat64(100), *cpuConfig.Max)
+	require.NotNil(t, cpuConfig.Decimals)
+	require.Nil(t, frame.Fields[1].Config)
+
+	// The original config is not changed.
+	require.Equal(t, "none", config.Unit)
+}
@@ -10,10 +10,7 @@ import (
 )
 
 func getRuntime(payload []byte) (*gojaRuntime, error) {
-	vm := goja.New()
-	vm.SetMaxCallStackSize(64)
-	vm.SetParserOptions(parser.WithDisableSourceMaps)
-	r := &gojaRuntime{vm}
+	r := newRuntime()
 	err := r.init(payload)
 	if err != nil {
 		return nil, err
@@ -21,6 +18,26 @@ func getRuntime(payload []byte) (*gojaRuntime, error) {
 	return r, nil
 }
 
+// scriptTimeout is the maximum duration of a script run.
+const scriptTimeout = 100 * time.Millisecond
+
+// compileScript parses and compiles a script once, so that it can be run by runtimes
+// with getProgramValue without being parsed at each run.
+func compileScript(name string, script string) (*goja.Program, error) {
+	ast, err := goja.Parse(name, script, parser.WithDisableSourceMaps)
+	if err != nil {
+		return nil, err
+	}
+	return goja.CompileAST(ast, false)
+}
+
+func newRuntime() *gojaRuntime {
+	vm := goja.New()
+	vm.SetMaxCallStackSize(64)
+	vm.SetParserOptions(parser.WithDisableSourceMaps)
+	return &gojaRuntime{vm}
+}
+
 type gojaRuntime struct {
 	vm *goja.Runtime
 }
@@ -35,13 +52,18 @@ func (r *gojaRuntime) init(payload []byte) error {
 	return err
 }
 
+// setVar makes a Go value available to scripts under the name.
+func (r *gojaRuntime) setVar(name string, value interface{}) error {
+	return r.vm.Set(name, value)
+}
+
 func (r *gojaRuntime) runString(script string) (goja.Value, error) {
 	doneCh := make(chan struct{})
 	go func() {
 		select {
 		case <-doneCh:
 			return
-		case <-time.After(100 * time.Millisecond):
+		case <-time.After(scriptTimeout):
 			// Some ideas to prevent misuse of scripts:
 			// * parse/validate scripts on save
 			// * block scripts after several timeouts in a row
@@ -55,6 +77,16 @@ func (r *gojaRuntime) runString(script string) (goja.Value, error) {
 	return r.vm.RunString(script)
 }
 
+// withTimeout interrupts the programs run by fn once the timeout is elapsed. Unlike
+// runString it starts a single timer for all of them.
+func (r *gojaRuntime) withTimeout(timeout time.Duration, fn func() error) error {
+	timer := time.AfterFunc(timeout, func() {
+		r.vm.Interrupt(errors.New("timeout"))
+	})
+	defer timer.Stop()
+	return fn()
+}
+
 func (r *gojaRuntime) getBool(script string) (bool, error) {
 	v, err := r.runString(script)
 	if err != nil {
@@ -95,3 +127,16 @@ func (r *gojaRuntime) getFloat64(script string) (float64, error) {
 		return 0, fmt.Errorf("unexpected return value: %T", exported)
 	}
 }
+
+// getProgramValue returns the exported value of the result of a compiled script, nil
+// if the result is null or undefined. It must be called within withTimeout.
+func (r *gojaRuntime) getProgramValue(p *goja.Program) (interface{}, error) {
+	v, err := r.vm.RunProgram(p)
+	if err != nil {
+		return nil, err
+	}
+	if goja.IsNull(v) || goja.IsUndefined(v) {
+		return nil, nil
+	}
+	return v.Export(), nil
+}
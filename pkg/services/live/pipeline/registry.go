@@ -77,6 +77,31 @@ var FrameProcessorsRegistry = []EntityInfo{
 		Description: "list the fields that should be removed",
 		Example:     DropFieldsFrameProcessorConfig{},
 	},
+	{
+		Type:        FrameProcessorTypeAggregate,
+		Description: "aggregate field values over time windows",
+		Example:     AggregateFrameProcessorConfig{},
+	},
+	{
+		Type:        FrameProcessorTypeRenameFields,
+		Description: "rename fields",
+		Example:     RenameFieldsFrameProcessorConfig{},
+	},
+	{
+		Type:        FrameProcessorTypeFieldConfig,
+		Description: "set display name, unit, min/max and thresholds of fields",
+		Example:     FieldConfigFrameProcessorConfig{},
+	},
+	{
+		Type:        FrameProcessorTypeCastFields,
+		Description: "change the type of fields",
+		Example:     CastFieldsFrameProcessorConfig{},
+	},
+	{
+		Type:        FrameProcessorTypeComputeFields,
+		Description: "add fields computed from other fields with a script",
+		Example:     ComputeFieldsFrameProcessorConfig{},
+	},
 }
 
 var DataOutputsRegistry = []EntityInfo{
@@ -3,7 +3,6 @@ package pipeline
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -16,14 +15,9 @@ type FileStorage struct {
 }
 
 func (f *FileStorage) ListRemoteWriteBackends(_ context.Context, orgID int64) ([]RemoteWriteBackend, error) {
-	backendBytes, err := ioutil.ReadFile(filepath.Join(f.DataPath, "pipeline", "remote-write-backends.json"))
+	remoteWriteBackends, err := f.readRemoteWriteBackends()
 	if err != nil {
-		return nil, fmt.Errorf("can't read ./pipeline/remote-write-backends.json file: %w", err)
-	}
-	var remoteWriteBackends RemoteWriteBackends
-	err = json.Unmarshal(backendBytes, &remoteWriteBackends)
-	if err != nil {
-		return nil, fmt.Errorf("can't unmarshal remote-write-backends.json data: %w", err)
+		return nil, err
 	}
 	var backends []RemoteWriteBackend
 	for _, b := range remoteWriteBackends.Backends {
@@ -55,11 +49,11 @@ func (f *FileStorage) CreateChannelRule(_ context.Context, orgID int64, rule Cha
 	}
 	ok, reason := rule.Valid()
 	if !ok {
-		return rule, fmt.Errorf("invalid channel rule: %s", reason)
+		return rule, fmt.Errorf("%w: %s", ErrInvalidChannelRule, reason)
 	}
 	for _, existingRule := range channelRules.Rules {
 		if patternMatch(orgID, rule.Pattern, existingRule) {
-			return rule, fmt.Errorf("pattern already exists in org: %s", rule.Pattern)
+			return rule, fmt.Errorf("%w: %s", ErrChannelRuleExists, rule.Pattern)
 		}
 	}
 	channelRules.Rules = append(channelRules.Rules, rule)
@@ -79,7 +73,7 @@ func (f *FileStorage) UpdateChannelRule(ctx context.Context, orgID int64, rule C
 
 	ok, reason := rule.Valid()
 	if !ok {
-		return rule, fmt.Errorf("invalid channel rule: %s", reason)
+		return rule, fmt.Errorf("%w: %s", ErrInvalidChannelRule, reason)
 	}
 
 	index := -1
@@ -125,9 +119,9 @@ func (f *FileStorage) readRules() (ChannelRules, error) {
 }
 
 func (f *FileStorage) saveChannelRules(orgID int64, rules ChannelRules) error {
-	ok, reason := checkRulesValid(orgID, rules.Rules)
+	ok, reason := CheckRulesValid(orgID, rules.Rules)
 	if !ok {
-		return errors.New(reason)
+		return fmt.Errorf("%w: %s", ErrInvalidChannelRule, reason)
 	}
 	ruleFile := f.ruleFilePath()
 	// Safe to ignore gosec warning G304.
@@ -163,8 +157,95 @@ func (f *FileStorage) DeleteChannelRule(_ context.Context, orgID int64, pattern
 	if index > -1 {
 		channelRules.Rules = removeChannelRuleByIndex(channelRules.Rules, index)
 	} else {
-		return fmt.Errorf("rule not found")
+		return ErrChannelRuleNotFound
 	}
 
 	return f.saveChannelRules(orgID, channelRules)
 }
+
+func (f *FileStorage) remoteWriteFilePath() string {
+	return filepath.Join(f.DataPath, "pipeline", "remote-write-backends.json")
+}
+
+func (f *FileStorage) readRemoteWriteBackends() (RemoteWriteBackends, error) {
+	// Safe to ignore gosec warning G304.
+	// nolint:gosec
+	backendBytes, err := ioutil.ReadFile(f.remoteWriteFilePath())
+	if err != nil {
+		return RemoteWriteBackends{}, fmt.Errorf("can't read ./pipeline/remote-write-backends.json file: %w", err)
+	}
+	var remoteWriteBackends RemoteWriteBackends
+	err = json.Unmarshal(backendBytes, &remoteWriteBackends)
+	if err != nil {
+		return RemoteWriteBackends{}, fmt.Errorf("can't unmarshal remote-write-backends.json data: %w", err)
+	}
+	return remoteWriteBackends, nil
+}
+
+func (f *FileStorage) saveRemoteWriteBackends(backends RemoteWriteBackends) error {
+	// Safe to ignore gosec warning G304.
+	// nolint:gosec
+	file, err := os.OpenFile(f.remoteWriteFilePath(), os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("can't open remote write backends file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+	enc := json.NewEncoder(file)
+	enc.SetIndent("", "  ")
+	err = enc.Encode(backends)
+	if err != nil {
+		return fmt.Errorf("can't save remote write backends to file: %w", err)
+	}
+	return nil
+}
+
+func uidMatch(orgID int64, uid string, existingBackend RemoteWriteBackend) bool {
+	return uid == existingBackend.UID && (existingBackend.OrgId == orgID || (existingBackend.OrgId == 0 && orgID == 1))
+}
+
+func (f *FileStorage) CreateRemoteWriteBackend(_ context.Context, orgID int64, backend RemoteWriteBackend) (RemoteWriteBackend, error) {
+	remoteWriteBackends, err := f.readRemoteWriteBackends()
+	if err != nil {
+		return backend, err
+	}
+	if backend.UID == "" {
+		return backend, fmt.Errorf("remote write backend uid required")
+	}
+	for _, existingBackend := range remoteWriteBackends.Backends {
+		if uidMatch(orgID, backend.UID, existingBackend) {
+			return backend, fmt.Errorf("%w: %s", ErrRemoteWriteBackendExists, backend.UID)
+		}
+	}
+	backend.OrgId = orgID
+	remoteWriteBackends.Backends = append(remoteWriteBackends.Backends, backend)
+	return backend, f.saveRemoteWriteBackends(remoteWriteBackends)
+}
+
+func (f *FileStorage) UpdateRemoteWriteBackend(ctx context.Context, orgID int64, backend RemoteWriteBackend) (RemoteWriteBackend, error) {
+	remoteWriteBackends, err := f.readRemoteWriteBackends()
+	if err != nil {
+		return backend, err
+	}
+	for i, existingBackend := range remoteWriteBackends.Backends {
+		if uidMatch(orgID, backend.UID, existingBackend) {
+			backend.OrgId = existingBackend.OrgId
+			remoteWriteBackends.Backends[i] = backend
+			return backend, f.saveRemoteWriteBackends(remoteWriteBackends)
+		}
+	}
+	return f.CreateRemoteWriteBackend(ctx, orgID, backend)
+}
+
+func (f *FileStorage) DeleteRemoteWriteBackend(_ context.Context, orgID int64, uid string) error {
+	remoteWriteBackends, err := f.readRemoteWriteBackends()
+	if err != nil {
+		return err
+	}
+	for i, existingBackend := range remoteWriteBackends.Backends {
+		if uidMatch(orgID, uid, existingBackend) {
+			remoteWriteBackends.Backends = append(remoteWriteBackends.Backends[:i], remoteWriteBackends.Backends[i+1:]...)
+			return f.saveRemoteWriteBackends(remoteWriteBackends)
+		}
+	}
+	return ErrRemoteWriteBackendNotFound
+}
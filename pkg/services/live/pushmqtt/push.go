@@ -0,0 +1,179 @@
+package pushmqtt
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/live"
+	"github.com/grafana/grafana/pkg/setting"
+
+	liveDto "github.com/grafana/grafana-plugin-sdk-go/live"
+)
+
+var (
+	logger = log.New("live.push_mqtt")
+)
+
+const disconnectQuiesceMilliseconds = 250
+
+func ProvideService(cfg *setting.Cfg, live *live.GrafanaLive) (*Gateway, error) {
+	router, err := newTopicRouter(cfg.LiveMQTTTopicRules)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Live MQTT topic rules: %w", err)
+	}
+	return &Gateway{
+		Cfg:         cfg,
+		GrafanaLive: live,
+		router:      router,
+	}, nil
+}
+
+// Gateway subscribes to the topics of an MQTT broker and pushes the received
+// messages to the Live pipeline. Messages are converted to frames by the
+// converter of the rule of the channel their topic is mapped to.
+type Gateway struct {
+	Cfg         *setting.Cfg
+	GrafanaLive *live.GrafanaLive
+
+	router *topicRouter
+}
+
+// IsDisabled returns true if no MQTT broker is configured. The Live pipeline
+// is required to process the messages.
+func (g *Gateway) IsDisabled() bool {
+	return g.Cfg.LiveMQTTBrokerURL == "" || len(g.router.Filters()) == 0 || !g.Cfg.FeatureToggles["live-pipeline"]
+}
+
+// Run Gateway.
+func (g *Gateway) Run(ctx context.Context) error {
+	logger.Info("Live MQTT Gateway initialization", "broker", g.Cfg.LiveMQTTBrokerURL, "clientID", g.Cfg.LiveMQTTClientID)
+
+	opts, err := g.clientOptions(ctx)
+	if err != nil {
+		return err
+	}
+
+	client := mqtt.NewClient(opts)
+	// With connect retry the token completes once connected, the client keeps
+	// trying in the background until then.
+	client.Connect()
+	defer client.Disconnect(disconnectQuiesceMilliseconds)
+
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (g *Gateway) clientOptions(ctx context.Context) (*mqtt.ClientOptions, error) {
+	tlsConfig, err := g.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	return mqtt.NewClientOptions().
+		AddBroker(g.Cfg.LiveMQTTBrokerURL).
+		SetClientID(g.Cfg.LiveMQTTClientID).
+		SetUsername(g.Cfg.LiveMQTTUsername).
+		SetPassword(g.Cfg.LiveMQTTPassword).
+		SetTLSConfig(tlsConfig).
+		SetAutoReconnect(true).
+		SetConnectRetry(true).
+		SetConnectRetryInterval(10 * time.Second).
+		SetDefaultPublishHandler(func(_ mqtt.Client, msg mqtt.Message) {
+			g.handleMessage(ctx, msg)
+		}).
+		SetOnConnectHandler(g.subscribe).
+		SetConnectionLostHandler(func(_ mqtt.Client, err error) {
+			logger.Warn("Lost connection to MQTT broker", "error", err)
+		}), nil
+}
+
+// tlsConfig returns the TLS configuration of the connection to the broker,
+// used with the ssl and wss schemes.
+func (g *Gateway) tlsConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: g.Cfg.LiveMQTTTLSSkipVerify,
+	}
+	if g.Cfg.LiveMQTTTLSCACertPath != "" {
+		// nolint:gosec
+		// We can ignore the gosec G304 warning on this one because the path comes from the Grafana configuration.
+		pem, err := ioutil.ReadFile(g.Cfg.LiveMQTTTLSCACertPath)
+		if err != nil {
+			return nil, fmt.Errorf("could not read MQTT CA certificate %q: %w", g.Cfg.LiveMQTTTLSCACertPath, err)
+		}
+		rootCertPool := x509.NewCertPool()
+		if ok := rootCertPool.AppendCertsFromPEM(pem); !ok {
+			return nil, fmt.Errorf("no certificate found in MQTT CA certificate %q", g.Cfg.LiveMQTTTLSCACertPath)
+		}
+		tlsConfig.RootCAs = rootCertPool
+	}
+	if g.Cfg.LiveMQTTTLSClientCertPath != "" {
+		cert, err := tls.LoadX509KeyPair(g.Cfg.LiveMQTTTLSClientCertPath, g.Cfg.LiveMQTTTLSClientKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("could not load MQTT client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// subscribe is called on each connection to the broker, subscriptions are lost
+// on reconnection since the session is clean.
+func (g *Gateway) subscribe(client mqtt.Client) {
+	filters := g.subscriptionFilters()
+	// Messages of all topics are handled by the default publish handler.
+	token := client.SubscribeMultiple(filters, nil)
+	go func() {
+		token.Wait()
+		if err := token.Error(); err != nil {
+			logger.Error("Error subscribing to MQTT topics", "error", err)
+			return
+		}
+		logger.Info("Subscribed to MQTT topics", "filters", filters)
+	}()
+}
+
+// subscriptionFilters returns the topic filters to subscribe to, with their QoS.
+func (g *Gateway) subscriptionFilters() map[string]byte {
+	filters := make(map[string]byte, len(g.router.Filters()))
+	for _, filter := range g.router.Filters() {
+		if g.Cfg.LiveMQTTSharedGroup != "" {
+			filter = "$share/" + g.Cfg.LiveMQTTSharedGroup + "/" + filter
+		}
+		filters[filter] = g.Cfg.LiveMQTTQoS
+	}
+	return filters
+}
+
+func (g *Gateway) handleMessage(ctx context.Context, msg mqtt.Message) {
+	channelID, ok := g.router.Channel(msg.Topic())
+	if !ok {
+		logger.Debug("No channel for MQTT topic", "topic", msg.Topic())
+		return
+	}
+	logger.Debug("Live channel push request",
+		"protocol", "mqtt",
+		"topic", msg.Topic(),
+		"channel", channelID,
+		"bodyLength", len(msg.Payload()),
+	)
+
+	ruleFound, err := g.GrafanaLive.Pipeline.ProcessInput(ctx, g.Cfg.LiveMQTTOrgID, channelID, msg.Payload())
+	if err != nil {
+		if errors.Is(err, liveDto.ErrInvalidChannelID) {
+			logger.Error("Invalid channel for MQTT topic", "topic", msg.Topic(), "channel", channelID)
+		} else {
+			logger.Error("Pipeline input processing error", "error", err, "topic", msg.Topic(), "channel", channelID)
+		}
+		return
+	}
+	if !ruleFound {
+		logger.Error("No conversion rule for a channel", "topic", msg.Topic(), "channel", channelID)
+	}
+}
@@ -0,0 +1,92 @@
+package pushmqtt
+
+import (
+	"context"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func newTestGateway(t *testing.T, cfg *setting.Cfg) *Gateway {
+	t.Helper()
+	g, err := ProvideService(cfg, nil)
+	require.NoError(t, err)
+	return g
+}
+
+func TestGateway_ClientOptions(t *testing.T) {
+	g := newTestGateway(t, &setting.Cfg{
+		LiveMQTTBrokerURL: "ssl://localhost:8883",
+		LiveMQTTClientID:  "grafana-1",
+		LiveMQTTUsername:  "user",
+		LiveMQTTPassword:  "password",
+		LiveMQTTTopicRules: []setting.LiveMQTTTopicRule{
+			{Topic: "devices/:device", Channel: "stream/devices/:device"},
+		},
+	})
+
+	opts, err := g.clientOptions(context.Background())
+	require.NoError(t, err)
+	require.Len(t, opts.Servers, 1)
+	require.Equal(t, "ssl://localhost:8883", opts.Servers[0].String())
+	require.Equal(t, "grafana-1", opts.ClientID)
+	require.Equal(t, "user", opts.Username)
+	require.Equal(t, "password", opts.Password)
+	require.NotNil(t, opts.TLSConfig)
+	require.False(t, opts.TLSConfig.InsecureSkipVerify)
+	require.Nil(t, opts.TLSConfig.RootCAs)
+}
+
+func TestGateway_TLSConfig(t *testing.T) {
+	t.Run("CA certificate", func(t *testing.T) {
+		srv := httptest.NewTLSServer(http.NotFoundHandler())
+		defer srv.Close()
+
+		caCertPath := filepath.Join(t.TempDir(), "ca.pem")
+		caCert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+		require.NoError(t, ioutil.WriteFile(caCertPath, caCert, 0600))
+
+		g := newTestGateway(t, &setting.Cfg{LiveMQTTTLSCACertPath: caCertPath, LiveMQTTTLSSkipVerify: true})
+		tlsConfig, err := g.tlsConfig()
+		require.NoError(t, err)
+		require.NotNil(t, tlsConfig.RootCAs)
+		require.True(t, tlsConfig.InsecureSkipVerify)
+	})
+
+	t.Run("missing CA certificate", func(t *testing.T) {
+		g := newTestGateway(t, &setting.Cfg{LiveMQTTTLSCACertPath: filepath.Join(t.TempDir(), "missing.pem")})
+		_, err := g.tlsConfig()
+		require.Error(t, err)
+	})
+
+	t.Run("invalid client certificate", func(t *testing.T) {
+		dir := t.TempDir()
+		certPath, keyPath := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+		require.NoError(t, ioutil.WriteFile(certPath, []byte("invalid"), 0600))
+		require.NoError(t, ioutil.WriteFile(keyPath, []byte("invalid"), 0600))
+
+		g := newTestGateway(t, &setting.Cfg{LiveMQTTTLSClientCertPath: certPath, LiveMQTTTLSClientKeyPath: keyPath})
+		_, err := g.tlsConfig()
+		require.Error(t, err)
+	})
+}
+
+func TestGateway_SubscriptionFilters(t *testing.T) {
+	rules := []setting.LiveMQTTTopicRule{
+		{Topic: "devices/:device", Channel: "stream/devices/:device"},
+		{Topic: "sensors/*path", Channel: "stream/sensors/*path"},
+	}
+
+	g := newTestGateway(t, &setting.Cfg{LiveMQTTQoS: 1, LiveMQTTTopicRules: rules})
+	require.Equal(t, map[string]byte{"devices/+": 1, "sensors/#": 1}, g.subscriptionFilters())
+
+	g = newTestGateway(t, &setting.Cfg{LiveMQTTSharedGroup: "grafana", LiveMQTTTopicRules: rules})
+	require.Equal(t, map[string]byte{"$share/grafana/devices/+": 0, "$share/grafana/sensors/#": 0}, g.subscriptionFilters())
+}
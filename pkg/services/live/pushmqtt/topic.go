@@ -0,0 +1,108 @@
+package pushmqtt
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/services/live/pipeline/pattern"
+	"github.com/grafana/grafana/pkg/services/live/pipeline/tree"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// topicRouter maps MQTT topics to Live channels according to topic rules. Topic
+// patterns use the syntax of channel rule patterns: :name matches a single topic
+// level and *name matches all remaining levels.
+type topicRouter struct {
+	tree      *tree.Node
+	filters   []string
+	maxParams int
+}
+
+func newTopicRouter(rules []setting.LiveMQTTTopicRule) (r *topicRouter, err error) {
+	r = &topicRouter{tree: tree.New()}
+	defer func() {
+		if rec := recover(); rec != nil {
+			r, err = nil, fmt.Errorf("%v", rec)
+		}
+	}()
+	for _, rule := range rules {
+		if ok, reason := pattern.Valid(rule.Topic); !ok {
+			return nil, fmt.Errorf("invalid topic pattern %s: %s", rule.Topic, reason)
+		}
+		if ok, reason := pattern.Valid(rule.Channel); !ok {
+			return nil, fmt.Errorf("invalid channel pattern %s: %s", rule.Channel, reason)
+		}
+		params := patternParams(rule.Topic)
+		for _, p := range patternParams(rule.Channel) {
+			if !contains(params, p) {
+				return nil, fmt.Errorf("parameter %s of channel pattern %s not found in topic pattern %s", p, rule.Channel, rule.Topic)
+			}
+		}
+		if len(params) > r.maxParams {
+			r.maxParams = len(params)
+		}
+		r.tree.AddRoute("/"+rule.Topic, rule.Channel)
+		r.filters = append(r.filters, topicFilter(rule.Topic))
+	}
+	return r, nil
+}
+
+// Filters returns the MQTT topic filters to subscribe to.
+func (r *topicRouter) Filters() []string {
+	return r.filters
+}
+
+// Channel returns the channel to push the messages of topic to.
+func (r *topicRouter) Channel(topic string) (string, bool) {
+	ps := make(tree.Params, 0, r.maxParams)
+	nodeValue := r.tree.GetValue("/"+topic, &ps, false)
+	if nodeValue.Handler == nil {
+		return "", false
+	}
+	segments := strings.Split(nodeValue.Handler.(string), "/")
+	for i, s := range segments {
+		if !isParam(s) {
+			continue
+		}
+		value, _ := ps.Get(s[1:])
+		segments[i] = strings.TrimPrefix(value, "/")
+	}
+	return strings.Join(segments, "/"), true
+}
+
+// topicFilter converts a topic pattern to an MQTT topic filter.
+func topicFilter(topicPattern string) string {
+	levels := strings.Split(topicPattern, "/")
+	for i, l := range levels {
+		switch {
+		case strings.HasPrefix(l, ":"):
+			levels[i] = "+"
+		case strings.HasPrefix(l, "*"):
+			levels[i] = "#"
+		}
+	}
+	return strings.Join(levels, "/")
+}
+
+func patternParams(p string) []string {
+	var params []string
+	for _, s := range strings.Split(p, "/") {
+		if isParam(s) {
+			params = append(params, s[1:])
+		}
+	}
+	return params
+}
+
+func isParam(segment string) bool {
+	return strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*")
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package pushmqtt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestTopicRouter(t *testing.T) {
+	r, err := newTopicRouter([]setting.LiveMQTTTopicRule{
+		{Topic: "devices/:device/telemetry", Channel: "stream/devices/:device"},
+		{Topic: "devices/:device/status", Channel: "stream/status/all"},
+		{Topic: "sensors/*path", Channel: "stream/sensors/*path"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"devices/+/telemetry", "devices/+/status", "sensors/#"}, r.Filters())
+
+	channel, ok := r.Channel("devices/boiler/telemetry")
+	require.True(t, ok)
+	require.Equal(t, "stream/devices/boiler", channel)
+
+	channel, ok = r.Channel("devices/boiler/status")
+	require.True(t, ok)
+	require.Equal(t, "stream/status/all", channel)
+
+	channel, ok = r.Channel("sensors/floor1/room2")
+	require.True(t, ok)
+	require.Equal(t, "stream/sensors/floor1/room2", channel)
+
+	_, ok = r.Channel("devices/boiler")
+	require.False(t, ok)
+	_, ok = r.Channel("other")
+	require.False(t, ok)
+}
+
+func TestTopicRouter_Invalid(t *testing.T) {
+	_, err := newTopicRouter([]setting.LiveMQTTTopicRule{
+		{Topic: "devices/:device", Channel: "stream/devices/:name"},
+	})
+	require.Error(t, err)
+
+	_, err = newTopicRouter([]setting.LiveMQTTTopicRule{
+		{Topic: "/devices/:device", Channel: "stream/devices/:device"},
+	})
+	require.Error(t, err)
+
+	_, err = newTopicRouter([]setting.LiveMQTTTopicRule{
+		{Topic: "devices/:device", Channel: "stream/devices/:device"},
+		{Topic: "devices/:id", Channel: "stream/ids/:id"},
+	})
+	require.Error(t, err)
+}
@@ -3,6 +3,7 @@ package remotewrite
 import (
 	"fmt"
 	"hash/fnv"
+	"sort"
 	"strings"
 	"time"
 
@@ -190,6 +191,88 @@ func TimeSeriesFromFramesLabelsColumn(frames ...*data.Frame) []prompb.TimeSeries
 	return promTimeSeriesBatch
 }
 
+// TimeSeriesFromFramesWithName converts frames to slice of Prometheus TimeSeries
+// all having the given metric name. The labels of each field are merged with
+// extraLabels, which take precedence. Samples of frames without time field, such
+// as reduced numbers, are timestamped with defaultTime.
+func TimeSeriesFromFramesWithName(name string, extraLabels map[string]string, defaultTime time.Time, frames ...*data.Frame) []prompb.TimeSeries {
+	metricName, ok := sanitizeMetricName(name)
+	if !ok {
+		return nil
+	}
+
+	var entries = make(map[metricKey]prompb.TimeSeries)
+	var keys []metricKey // sorted keys.
+
+	for _, frame := range frames {
+		timeFieldIndex, hasTime := timeFieldIndex(frame)
+
+		for _, field := range frame.Fields {
+			if !field.Type().Numeric() {
+				continue
+			}
+
+			fieldLabels := make(map[string]string, len(field.Labels)+len(extraLabels))
+			for k, v := range field.Labels {
+				fieldLabels[k] = v
+			}
+			for k, v := range extraLabels {
+				fieldLabels[k] = v
+			}
+			labels := createLabels(fieldLabels)
+			sort.Slice(labels, func(i, j int) bool {
+				return labels[i].Name < labels[j].Name
+			})
+			labels = append(labels, prompb.Label{
+				Name:  "__name__",
+				Value: metricName,
+			})
+			key := makeMetricKey(metricName, labels)
+
+			promTimeSeries, ok := entries[key]
+			if !ok {
+				promTimeSeries = prompb.TimeSeries{Labels: labels}
+				keys = append(keys, key)
+			}
+
+			for i := 0; i < field.Len(); i++ {
+				val, ok := field.ConcreteAt(i)
+				if !ok {
+					continue
+				}
+				value, ok := sampleValue(val)
+				if !ok {
+					continue
+				}
+				tm := defaultTime
+				if hasTime {
+					t, ok := frame.Fields[timeFieldIndex].ConcreteAt(i)
+					if !ok {
+						continue
+					}
+					tm = t.(time.Time)
+				}
+				promTimeSeries.Samples = append(promTimeSeries.Samples, prompb.Sample{
+					// Timestamp is int milliseconds for remote write.
+					Timestamp: toSampleTime(tm),
+					Value:     value,
+				})
+			}
+			entries[key] = promTimeSeries
+		}
+	}
+
+	var promTimeSeriesBatch = make([]prompb.TimeSeries, 0, len(entries))
+	for _, key := range keys {
+		if len(entries[key].Samples) == 0 {
+			continue
+		}
+		promTimeSeriesBatch = append(promTimeSeriesBatch, entries[key])
+	}
+
+	return promTimeSeriesBatch
+}
+
 func timeFieldIndex(frame *data.Frame) (int, bool) {
 	timeFieldIndex := -1
 	for i, field := range frame.Fields {
@@ -85,3 +85,46 @@ func TestSerialize(t *testing.T) {
 	_, err := Serialize(frame)
 	require.NoError(t, err)
 }
+
+func TestTsFromFramesWithName(t *testing.T) {
+	now := time.Now()
+	t1 := now.Add(-time.Minute)
+	t2 := now.Add(-time.Second)
+	reduced := data.NewFrame("",
+		data.NewField("B", map[string]string{"instance": "a", "job": "node"}, []*float64{func() *float64 { v := 1.5; return &v }()}),
+	)
+	noValue := data.NewFrame("",
+		data.NewField("B", map[string]string{"instance": "b"}, []*float64{nil}),
+	)
+	series := data.NewFrame("",
+		data.NewField("time", nil, []time.Time{t1, t2}),
+		data.NewField("B", map[string]string{"instance": "c"}, []float64{3.0, 4.0}),
+	)
+	ts := TimeSeriesFromFramesWithName("job:cpu:rate5m", map[string]string{"job": "recorded"}, now, reduced, noValue, series)
+	require.Len(t, ts, 2)
+
+	require.Len(t, ts[0].Samples, 1)
+	require.Equal(t, toSampleTime(now), ts[0].Samples[0].Timestamp)
+	require.Equal(t, 1.5, ts[0].Samples[0].Value)
+	require.Len(t, ts[0].Labels, 3)
+	require.Equal(t, "instance", ts[0].Labels[0].Name)
+	require.Equal(t, "a", ts[0].Labels[0].Value)
+	require.Equal(t, "job", ts[0].Labels[1].Name)
+	require.Equal(t, "recorded", ts[0].Labels[1].Value)
+	require.Equal(t, "__name__", ts[0].Labels[2].Name)
+	require.Equal(t, "job:cpu:rate5m", ts[0].Labels[2].Value)
+
+	require.Len(t, ts[1].Samples, 2)
+	require.Equal(t, toSampleTime(t1), ts[1].Samples[0].Timestamp)
+	require.Equal(t, toSampleTime(t2), ts[1].Samples[1].Timestamp)
+	require.Equal(t, 3.0, ts[1].Samples[0].Value)
+	require.Equal(t, 4.0, ts[1].Samples[1].Value)
+}
+
+func TestTsFromFramesWithInvalidName(t *testing.T) {
+	frame := data.NewFrame("",
+		data.NewField("B", nil, []float64{1.0}),
+	)
+	ts := TimeSeriesFromFramesWithName("---", nil, time.Now(), frame)
+	require.Len(t, ts, 0)
+}
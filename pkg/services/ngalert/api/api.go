@@ -47,6 +47,7 @@ type Alertmanager interface {
 
 	// Testing
 	TestReceivers(ctx context.Context, c apimodels.TestReceiversConfigParams) (*notifier.TestReceiversResult, error)
+	TestRoutes(c apimodels.TestRoutesConfig) (apimodels.TestRoutesResult, error)
 }
 
 // API handlers.
@@ -59,6 +60,7 @@ type API struct {
 	Schedule             schedule.ScheduleService
 	RuleStore            store.RuleStore
 	InstanceStore        store.InstanceStore
+	StateHistoryStore    store.StateHistoryStore
 	AlertingStore        store.AlertingStore
 	AdminConfigStore     store.AdminConfigurationStore
 	DataProxy            *datasourceproxy.DataSourceProxyService
@@ -86,11 +88,12 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 		NewLotexProm(proxy, logger),
 		PrometheusSrv{log: logger, manager: api.StateManager, store: api.RuleStore},
 	), m)
+	ruler := RulerSrv{DatasourceCache: api.DatasourceCache, QuotaService: api.QuotaService, manager: api.StateManager, store: api.RuleStore, log: logger}
 	// Register endpoints for proxying to Cortex Ruler-compatible backends.
 	api.RegisterRulerApiEndpoints(NewForkedRuler(
 		api.DatasourceCache,
 		NewLotexRuler(proxy, logger),
-		RulerSrv{DatasourceCache: api.DatasourceCache, QuotaService: api.QuotaService, manager: api.StateManager, store: api.RuleStore, log: logger},
+		ruler,
 	), m)
 	api.RegisterTestingApiEndpoints(TestingApiSrv{
 		AlertingProxy:   proxy,
@@ -104,4 +107,10 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 		log:       logger,
 		scheduler: api.Schedule,
 	}, m)
+	api.RegisterHistoryApiEndpoints(HistorySrv{
+		store:             api.StateHistoryStore,
+		notificationStore: api.AlertingStore,
+		log:               logger,
+	}, m)
+	api.RegisterConvertApiEndpoints(ConvertSrv{ruler: ruler}, m)
 }
@@ -442,6 +442,27 @@ func contextWithTimeoutFromRequest(ctx context.Context, r *http.Request, default
 	return ctx, cancelFunc, nil
 }
 
+func (srv AlertmanagerSrv) RoutePostTestRoutes(c *models.ReqContext, body apimodels.TestRoutesConfig) response.Response {
+	if !c.HasUserRole(models.ROLE_EDITOR) {
+		return accessForbiddenResp()
+	}
+
+	am, errResp := srv.AlertmanagerFor(c.OrgId)
+	if errResp != nil {
+		return errResp
+	}
+
+	result, err := am.TestRoutes(body)
+	if err != nil {
+		if errors.Is(err, notifier.ErrNoLabels) || errors.Is(err, notifier.ErrNoRoute) {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to test routes")
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
+
 func newTestReceiversResult(r *notifier.TestReceiversResult) apimodels.TestReceiversResult {
 	v := apimodels.TestReceiversResult{
 		Receivers: make([]apimodels.TestReceiverResult, len(r.Receivers)),
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/prom"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// ConvertSrv imports and exports Grafana managed rules as Prometheus rules files.
+type ConvertSrv struct {
+	ruler RulerSrv
+}
+
+func (srv ConvertSrv) RoutePostConvertPrometheusRules(c *models.ReqContext) response.Response {
+	namespaceTitle := web.Params(c.Req)[":Namespace"]
+	namespace, err := srv.ruler.store.GetNamespaceByTitle(c.Req.Context(), namespaceTitle, c.SignedInUser.OrgId, c.SignedInUser, true)
+	if err != nil {
+		return toNamespaceErrorResponse(err)
+	}
+
+	datasourceUID := c.Query("datasourceUID")
+	if datasourceUID == "" {
+		return ErrResp(http.StatusBadRequest, errors.New("datasourceUID must be set"), "")
+	}
+	ds, err := srv.ruler.DatasourceCache.GetDatasourceByUID(datasourceUID, c.SignedInUser, c.SkipCache)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "failed to get datasource %s", datasourceUID)
+	}
+	if ds.Type != models.DS_PROMETHEUS {
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("datasource %s is of type %s", datasourceUID, ds.Type), "rules can only be imported for Prometheus datasources")
+	}
+
+	var file apimodels.PrometheusRulesFile
+	if err := yaml.NewDecoder(c.Req.Body).Decode(&file); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = errors.New("the rules file is empty")
+		}
+		return ErrResp(http.StatusBadRequest, err, "failed to parse the rules file")
+	}
+
+	q := ngmodels.ListNamespaceAlertRulesQuery{
+		OrgID:        c.SignedInUser.OrgId,
+		NamespaceUID: namespace.Uid,
+	}
+	if err := srv.ruler.store.GetNamespaceAlertRules(&q); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get the rules of the folder")
+	}
+
+	groups, err := prom.ToGrafanaRuleGroups(file, ds.Uid, q.Result)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "failed to convert the rules file")
+	}
+
+	// all the rule groups are validated before they are saved together, so that the rules file
+	// is imported entirely or not at all
+	cmds := make([]store.UpdateRuleGroupCmd, 0, len(groups))
+	alertRuleUIDs := make(map[string]struct{})
+	for _, group := range groups {
+		uids, resp := srv.ruler.validateAlertRuleGroup(c, group)
+		if resp != nil {
+			return resp
+		}
+		for uid := range uids {
+			alertRuleUIDs[uid] = struct{}{}
+		}
+		cmds = append(cmds, store.UpdateRuleGroupCmd{
+			OrgID:           c.SignedInUser.OrgId,
+			NamespaceUID:    namespace.Uid,
+			RuleGroupConfig: group,
+		})
+	}
+
+	if err := srv.ruler.store.UpdateRuleGroups(cmds); err != nil {
+		return toUpdateRuleGroupErrorResponse(err)
+	}
+
+	for uid := range alertRuleUIDs {
+		srv.ruler.manager.RemoveByRuleUID(c.OrgId, uid)
+	}
+
+	return response.JSON(http.StatusAccepted, util.DynMap{"message": "rules file imported successfully"})
+}
+
+func (srv ConvertSrv) RouteGetConvertPrometheusRules(c *models.ReqContext) response.Response {
+	namespaceTitle := web.Params(c.Req)[":Namespace"]
+	namespace, err := srv.ruler.store.GetNamespaceByTitle(c.Req.Context(), namespaceTitle, c.SignedInUser.OrgId, c.SignedInUser, false)
+	if err != nil {
+		return toNamespaceErrorResponse(err)
+	}
+
+	q := ngmodels.ListNamespaceAlertRulesQuery{
+		OrgID:        c.SignedInUser.OrgId,
+		NamespaceUID: namespace.Uid,
+	}
+	if err := srv.ruler.store.GetNamespaceAlertRules(&q); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get rules")
+	}
+
+	file, skipped := prom.FromAlertRules(q.Result)
+
+	yml, err := yaml.Marshal(file)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to marshal the rules file")
+	}
+
+	// the rules that cannot be converted are reported in comments, so that the file remains valid
+	if len(skipped) > 0 {
+		var b bytes.Buffer
+		for _, r := range skipped {
+			reason := strings.ReplaceAll(r.Err.Error(), "\n", " ")
+			fmt.Fprintf(&b, "# skipped rule %q of rule group %q: %s\n", r.Title, r.RuleGroup, reason)
+		}
+		yml = append(b.Bytes(), yml...)
+	}
+	return response.CreateNormalResponse(http.Header{"Content-Type": []string{"application/yaml"}}, yml, http.StatusOK)
+}
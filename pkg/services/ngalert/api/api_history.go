@@ -0,0 +1,135 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+const (
+	defaultStateHistoryLimit           = 100
+	defaultNotificationDeliveriesLimit = 100
+)
+
+type HistorySrv struct {
+	store             store.StateHistoryStore
+	notificationStore store.NotificationDeliveryStore
+	log               log.Logger
+}
+
+func (srv HistorySrv) RouteGetStateHistory(c *models.ReqContext) response.Response {
+	query := ngmodels.ListAlertStateHistoryQuery{
+		RuleOrgID: c.OrgId,
+		RuleUID:   c.Query("ruleUID"),
+		Limit:     c.QueryInt("limit"),
+	}
+	if query.Limit <= 0 {
+		query.Limit = defaultStateHistoryLimit
+	}
+	if from := c.QueryInt64("from"); from > 0 {
+		query.From = time.Unix(0, from*int64(time.Millisecond))
+	}
+	if to := c.QueryInt64("to"); to > 0 {
+		query.To = time.Unix(0, to*int64(time.Millisecond))
+	}
+	if !query.From.IsZero() && !query.To.IsZero() && query.To.Before(query.From) {
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("to %d is before from %d", c.QueryInt64("to"), c.QueryInt64("from")), "")
+	}
+
+	labels, err := parseStateHistoryLabels(c.QueryStrings("labels"))
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+	query.Labels = labels
+
+	if err := srv.store.ListAlertStateHistory(c.Req.Context(), &query); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get state history")
+	}
+
+	history := make(apimodels.GettableStateHistory, 0, len(query.Result))
+	for _, e := range query.Result {
+		entry := apimodels.GettableStateHistoryEntry{
+			RuleUID:          e.RuleUID,
+			Labels:           e.Labels,
+			PreviousState:    string(e.PreviousState),
+			State:            string(e.State),
+			Error:            e.Error,
+			EvaluationString: e.EvaluationString,
+			EvaluatedAt:      e.EvaluatedAt,
+		}
+		if e.Values != "" {
+			if err := json.Unmarshal([]byte(e.Values), &entry.Values); err != nil {
+				srv.log.Warn("failed to unmarshal evaluation values of state history entry", "ruleUID", e.RuleUID, "id", e.ID, "err", err)
+			}
+		}
+		history = append(history, entry)
+	}
+
+	return response.JSON(http.StatusOK, history)
+}
+
+func (srv HistorySrv) RouteGetNotificationDeliveries(c *models.ReqContext) response.Response {
+	query := ngmodels.ListNotificationDeliveriesQuery{
+		OrgID:    c.OrgId,
+		Receiver: c.Query("receiver"),
+		RuleUID:  c.Query("ruleUID"),
+		Limit:    c.QueryInt("limit"),
+	}
+	if query.Limit <= 0 {
+		query.Limit = defaultNotificationDeliveriesLimit
+	}
+	if from := c.QueryInt64("from"); from > 0 {
+		query.From = time.Unix(0, from*int64(time.Millisecond))
+	}
+	if to := c.QueryInt64("to"); to > 0 {
+		query.To = time.Unix(0, to*int64(time.Millisecond))
+	}
+	if !query.From.IsZero() && !query.To.IsZero() && query.To.Before(query.From) {
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("to %d is before from %d", c.QueryInt64("to"), c.QueryInt64("from")), "")
+	}
+
+	if err := srv.notificationStore.ListNotificationDeliveries(c.Req.Context(), &query); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get notification deliveries")
+	}
+
+	deliveries := make(apimodels.GettableNotificationDeliveries, 0, len(query.Result))
+	for _, e := range query.Result {
+		deliveries = append(deliveries, apimodels.GettableNotificationDelivery{
+			Receiver:         e.Receiver,
+			Integration:      e.Integration,
+			IntegrationIndex: e.IntegrationIndex,
+			IntegrationUID:   e.IntegrationUID,
+			RuleUID:          e.RuleUID,
+			GroupKey:         e.GroupKey,
+			Alerts:           e.Alerts,
+			State:            string(e.State),
+			Error:            e.Error,
+			Attempt:          e.Attempt,
+			SentAt:           e.SentAt,
+		})
+	}
+
+	return response.JSON(http.StatusOK, deliveries)
+}
+
+// parseStateHistoryLabels parses labels in the format name=value.
+func parseStateHistoryLabels(params []string) (map[string]string, error) {
+	labels := make(map[string]string, len(params))
+	for _, p := range params {
+		parts := strings.SplitN(p, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid label %q: expected name=value", p)
+		}
+		labels[parts[0]] = parts[1]
+	}
+	return labels, nil
+}
@@ -38,6 +38,9 @@ func (srv RulerSrv) RouteDeleteNamespaceRulesConfig(c *models.ReqContext) respon
 
 	uids, err := srv.store.DeleteNamespaceAlertRules(c.SignedInUser.OrgId, namespace.Uid)
 	if err != nil {
+		if errors.Is(err, ngmodels.ErrAlertRuleHasDependents) {
+			return ErrResp(http.StatusConflict, err, "failed to delete namespace alert rules")
+		}
 		return ErrResp(http.StatusInternalServerError, err, "failed to delete namespace alert rules")
 	}
 
@@ -60,6 +63,8 @@ func (srv RulerSrv) RouteDeleteRuleGroupConfig(c *models.ReqContext) response.Re
 	if err != nil {
 		if errors.Is(err, ngmodels.ErrRuleGroupNamespaceNotFound) {
 			return ErrResp(http.StatusNotFound, err, "failed to delete rule group")
+		} else if errors.Is(err, ngmodels.ErrAlertRuleHasDependents) {
+			return ErrResp(http.StatusConflict, err, "failed to delete rule group")
 		}
 		return ErrResp(http.StatusInternalServerError, err, "failed to delete rule group")
 	}
@@ -93,8 +98,10 @@ func (srv RulerSrv) RouteGetNamespaceRulesConfig(c *models.ReqContext) response.
 		if !ok {
 			ruleGroupInterval := model.Duration(time.Duration(r.IntervalSeconds) * time.Second)
 			ruleGroupConfigs[r.RuleGroup] = apimodels.GettableRuleGroupConfig{
-				Name:     r.RuleGroup,
-				Interval: ruleGroupInterval,
+				Name:                 r.RuleGroup,
+				Interval:             ruleGroupInterval,
+				EvaluateSequentially: r.EvaluateSequentially,
+				IsPaused:             r.RuleGroupIsPaused,
 				Rules: []apimodels.GettableExtendedRuleNode{
 					toGettableExtendedRuleNode(*r, namespace.Id),
 				},
@@ -130,17 +137,22 @@ func (srv RulerSrv) RouteGetRulegGroupConfig(c *models.ReqContext) response.Resp
 	}
 
 	var ruleGroupInterval model.Duration
+	var evaluateSequentially, isPaused bool
 	ruleNodes := make([]apimodels.GettableExtendedRuleNode, 0, len(q.Result))
 	for _, r := range q.Result {
 		ruleGroupInterval = model.Duration(time.Duration(r.IntervalSeconds) * time.Second)
+		evaluateSequentially = r.EvaluateSequentially
+		isPaused = r.RuleGroupIsPaused
 		ruleNodes = append(ruleNodes, toGettableExtendedRuleNode(*r, namespace.Id))
 	}
 
 	result := apimodels.RuleGroupConfigResponse{
 		GettableRuleGroupConfig: apimodels.GettableRuleGroupConfig{
-			Name:     ruleGroup,
-			Interval: ruleGroupInterval,
-			Rules:    ruleNodes,
+			Name:                 ruleGroup,
+			Interval:             ruleGroupInterval,
+			Rules:                ruleNodes,
+			EvaluateSequentially: evaluateSequentially,
+			IsPaused:             isPaused,
 		},
 	}
 	return response.JSON(http.StatusAccepted, result)
@@ -190,8 +202,10 @@ func (srv RulerSrv) RouteGetRulesConfig(c *models.ReqContext) response.Response
 			ruleGroupInterval := model.Duration(time.Duration(r.IntervalSeconds) * time.Second)
 			configs[namespace] = make(map[string]apimodels.GettableRuleGroupConfig)
 			configs[namespace][r.RuleGroup] = apimodels.GettableRuleGroupConfig{
-				Name:     r.RuleGroup,
-				Interval: ruleGroupInterval,
+				Name:                 r.RuleGroup,
+				Interval:             ruleGroupInterval,
+				EvaluateSequentially: r.EvaluateSequentially,
+				IsPaused:             r.RuleGroupIsPaused,
 				Rules: []apimodels.GettableExtendedRuleNode{
 					toGettableExtendedRuleNode(*r, folder.Id),
 				},
@@ -201,8 +215,10 @@ func (srv RulerSrv) RouteGetRulesConfig(c *models.ReqContext) response.Response
 			if !ok {
 				ruleGroupInterval := model.Duration(time.Duration(r.IntervalSeconds) * time.Second)
 				configs[namespace][r.RuleGroup] = apimodels.GettableRuleGroupConfig{
-					Name:     r.RuleGroup,
-					Interval: ruleGroupInterval,
+					Name:                 r.RuleGroup,
+					Interval:             ruleGroupInterval,
+					EvaluateSequentially: r.EvaluateSequentially,
+					IsPaused:             r.RuleGroupIsPaused,
 					Rules: []apimodels.GettableExtendedRuleNode{
 						toGettableExtendedRuleNode(*r, folder.Id),
 					},
@@ -230,9 +246,39 @@ func (srv RulerSrv) RoutePostNameRulesConfig(c *models.ReqContext, ruleGroupConf
 		return toNamespaceErrorResponse(err)
 	}
 
+	return srv.updateAlertRuleGroup(c, namespace, ruleGroupConfig)
+}
+
+// updateAlertRuleGroup validates the rules of the rule group and replaces the rule group
+// of the namespace with them.
+func (srv RulerSrv) updateAlertRuleGroup(c *models.ReqContext, namespace *models.Folder, ruleGroupConfig apimodels.PostableRuleGroupConfig) response.Response {
+	alertRuleUIDs, resp := srv.validateAlertRuleGroup(c, ruleGroupConfig)
+	if resp != nil {
+		return resp
+	}
+
+	if err := srv.store.UpdateRuleGroup(store.UpdateRuleGroupCmd{
+		OrgID:           c.SignedInUser.OrgId,
+		NamespaceUID:    namespace.Uid,
+		RuleGroupConfig: ruleGroupConfig,
+	}); err != nil {
+		return toUpdateRuleGroupErrorResponse(err)
+	}
+
+	for uid := range alertRuleUIDs {
+		srv.manager.RemoveByRuleUID(c.OrgId, uid)
+	}
+
+	return response.JSON(http.StatusAccepted, util.DynMap{"message": "rule group updated successfully"})
+}
+
+// validateAlertRuleGroup validates the rules of the rule group, and checks the quota of alert rules
+// if the group has new rules. It returns the UIDs of the rules of the group that already exist,
+// or the error response if the rule group is not valid.
+func (srv RulerSrv) validateAlertRuleGroup(c *models.ReqContext, ruleGroupConfig apimodels.PostableRuleGroupConfig) (map[string]struct{}, response.Response) {
 	//TODO: Should this belong in alerting-api?
 	if ruleGroupConfig.Name == "" {
-		return ErrResp(http.StatusBadRequest, errors.New("rule group name is not valid"), "")
+		return nil, ErrResp(http.StatusBadRequest, errors.New("rule group name is not valid"), "")
 	}
 
 	alertRuleUIDs := make(map[string]struct{})
@@ -243,12 +289,12 @@ func (srv RulerSrv) RoutePostNameRulesConfig(c *models.ReqContext, ruleGroupConf
 			Data:      r.GrafanaManagedAlert.Data,
 		}
 		if err := validateCondition(cond, c.SignedInUser, c.SkipCache, srv.DatasourceCache); err != nil {
-			return ErrResp(http.StatusBadRequest, err, "failed to validate alert rule %q", r.GrafanaManagedAlert.Title)
+			return nil, ErrResp(http.StatusBadRequest, err, "failed to validate alert rule %q", r.GrafanaManagedAlert.Title)
 		}
 		if r.GrafanaManagedAlert.UID != "" {
 			_, ok := alertRuleUIDs[r.GrafanaManagedAlert.UID]
 			if ok {
-				return ErrResp(http.StatusBadRequest, fmt.Errorf("conflicting UID %q found", r.GrafanaManagedAlert.UID), "failed to validate alert rule %q", r.GrafanaManagedAlert.Title)
+				return nil, ErrResp(http.StatusBadRequest, fmt.Errorf("conflicting UID %q found", r.GrafanaManagedAlert.UID), "failed to validate alert rule %q", r.GrafanaManagedAlert.Title)
 			}
 			alertRuleUIDs[r.GrafanaManagedAlert.UID] = struct{}{}
 		}
@@ -262,31 +308,25 @@ func (srv RulerSrv) RoutePostNameRulesConfig(c *models.ReqContext, ruleGroupConf
 		// and rollback the transaction in case of violation
 		limitReached, err := srv.QuotaService.QuotaReached(c, "alert_rule")
 		if err != nil {
-			return ErrResp(http.StatusInternalServerError, err, "failed to get quota")
+			return nil, ErrResp(http.StatusInternalServerError, err, "failed to get quota")
 		}
 		if limitReached {
-			return ErrResp(http.StatusForbidden, errors.New("quota reached"), "")
+			return nil, ErrResp(http.StatusForbidden, errors.New("quota reached"), "")
 		}
 	}
 
-	if err := srv.store.UpdateRuleGroup(store.UpdateRuleGroupCmd{
-		OrgID:           c.SignedInUser.OrgId,
-		NamespaceUID:    namespace.Uid,
-		RuleGroupConfig: ruleGroupConfig,
-	}); err != nil {
-		if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
-			return ErrResp(http.StatusNotFound, err, "failed to update rule group")
-		} else if errors.Is(err, ngmodels.ErrAlertRuleFailedValidation) {
-			return ErrResp(http.StatusBadRequest, err, "failed to update rule group")
-		}
-		return ErrResp(http.StatusInternalServerError, err, "failed to update rule group")
-	}
+	return alertRuleUIDs, nil
+}
 
-	for uid := range alertRuleUIDs {
-		srv.manager.RemoveByRuleUID(c.OrgId, uid)
+func toUpdateRuleGroupErrorResponse(err error) response.Response {
+	if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
+		return ErrResp(http.StatusNotFound, err, "failed to update rule group")
+	} else if errors.Is(err, ngmodels.ErrAlertRuleFailedValidation) {
+		return ErrResp(http.StatusBadRequest, err, "failed to update rule group")
+	} else if errors.Is(err, ngmodels.ErrAlertRuleHasDependents) {
+		return ErrResp(http.StatusConflict, err, "failed to update rule group")
 	}
-
-	return response.JSON(http.StatusAccepted, util.DynMap{"message": "rule group updated successfully"})
+	return ErrResp(http.StatusInternalServerError, err, "failed to update rule group")
 }
 
 func toGettableExtendedRuleNode(r ngmodels.AlertRule, namespaceID int64) apimodels.GettableExtendedRuleNode {
@@ -306,6 +346,9 @@ func toGettableExtendedRuleNode(r ngmodels.AlertRule, namespaceID int64) apimode
 			RuleGroup:       r.RuleGroup,
 			NoDataState:     apimodels.NoDataState(r.NoDataState),
 			ExecErrState:    apimodels.ExecutionErrorState(r.ExecErrState),
+			IsPaused:        r.IsPaused,
+			Record:          r.Record,
+			DependsOn:       r.DependsOn,
 		},
 	}
 	gettableExtendedRuleNode.ApiRuleNode = &apimodels.ApiRuleNode{
@@ -1,11 +1,16 @@
 package api
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
+	"sort"
 	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -13,11 +18,16 @@ import (
 	"github.com/grafana/grafana/pkg/services/datasources"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tsdb"
 	"github.com/grafana/grafana/pkg/web"
 )
 
+// maxBacktestEvaluations is the maximum number of evaluations of an alert rule in a backtest.
+const maxBacktestEvaluations = 1000
+
 type TestingApiSrv struct {
 	*AlertingProxy
 	Cfg             *setting.Cfg
@@ -93,3 +103,137 @@ func (srv TestingApiSrv) RouteEvalQueries(c *models.ReqContext, cmd apimodels.Ev
 
 	return response.JSONStreaming(http.StatusOK, evalResults)
 }
+
+func (srv TestingApiSrv) RouteBacktestRule(c *models.ReqContext, cmd apimodels.BacktestConfig) response.Response {
+	interval := time.Duration(cmd.Interval)
+	if interval < time.Second || interval%time.Second != 0 {
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("interval %s must be a positive number of seconds", interval), "")
+	}
+	if !cmd.From.Before(cmd.To) {
+		return ErrResp(http.StatusBadRequest, errors.New("from must be before to"), "")
+	}
+	if cmd.To.After(timeNow()) {
+		return ErrResp(http.StatusBadRequest, errors.New("to must not be in the future"), "")
+	}
+	if n := cmd.To.Sub(cmd.From)/interval + 1; n > maxBacktestEvaluations {
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("the rule would be evaluated %d times, the maximum is %d", n, maxBacktestEvaluations), "")
+	}
+
+	rule := &ngmodels.AlertRule{
+		OrgID:           c.SignedInUser.OrgId,
+		Title:           cmd.Title,
+		Condition:       cmd.Condition,
+		Data:            cmd.Data,
+		IntervalSeconds: int64(interval.Seconds()),
+		For:             time.Duration(cmd.For),
+		Labels:          cmd.Labels,
+		NoDataState:     ngmodels.NoDataState(cmd.NoDataState),
+		ExecErrState:    ngmodels.ExecutionErrorState(cmd.ExecErrState),
+	}
+	if rule.NoDataState == "" {
+		rule.NoDataState = ngmodels.NoData
+	}
+	if rule.ExecErrState == "" {
+		rule.ExecErrState = ngmodels.AlertingErrState
+	}
+	if !rule.NoDataState.IsValid() {
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("unknown no data state %q", rule.NoDataState), "")
+	}
+	if !rule.ExecErrState.IsValid() {
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("unknown execution error state %q", rule.ExecErrState), "")
+	}
+
+	cond := ngmodels.Condition{
+		Condition: rule.Condition,
+		OrgID:     rule.OrgID,
+		Data:      rule.Data,
+	}
+	if len(cond.Data) == 0 {
+		return ErrResp(http.StatusBadRequest, errors.New("no queries or expressions are found"), "invalid condition")
+	}
+	if err := validateCondition(cond, c.SignedInUser, c.SkipCache, srv.DatasourceCache); err != nil {
+		return ErrResp(http.StatusBadRequest, err, "invalid condition")
+	}
+
+	appURL, err := url.Parse(srv.Cfg.AppURL)
+	if err != nil {
+		appURL = nil
+	}
+	evaluator := eval.Evaluator{Cfg: srv.Cfg, Log: srv.log}
+	// the queries of templates would run at the current time rather than at the time of the evaluations
+	manager := state.NewInMemoryManager(srv.log, appURL, state.NewNoopTemplateQuerier())
+
+	result, err := backtestRule(c.Req.Context(), manager, rule, cmd.From, cmd.To, func(now time.Time) (eval.Results, error) {
+		return evaluator.ConditionEval(&cond, now, srv.DataService)
+	})
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "failed to evaluate the rule")
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// backtestRule evaluates the rule at its interval from the start to the end of the time range, and
+// returns the periods during which its alert instances stay in the same state. An instance keeps its
+// state until the manager considers it stale if it is missing from an evaluation.
+func backtestRule(ctx context.Context, manager *state.Manager, rule *ngmodels.AlertRule, from, to time.Time, evaluate func(time.Time) (eval.Results, error)) (apimodels.BacktestResult, error) {
+	interval := time.Duration(rule.IntervalSeconds) * time.Second
+	result := apimodels.BacktestResult{Instances: []apimodels.BacktestInstance{}}
+	instances := make(map[string]int)
+
+	// closePeriod ends the current period of the instance, if it has one.
+	closePeriod := func(i int, end time.Time) {
+		periods := result.Instances[i].Periods
+		if n := len(periods); n > 0 && periods[n-1].End.IsZero() {
+			periods[n-1].End = end
+		}
+	}
+
+	for now := from; !now.After(to); now = now.Add(interval) {
+		results, err := evaluate(now)
+		if err != nil {
+			return apimodels.BacktestResult{}, fmt.Errorf("failed to evaluate the rule at %s: %w", now.Format(time.RFC3339), err)
+		}
+		manager.ProcessEvalResults(ctx, rule, results)
+		result.Evaluations++
+
+		current := make(map[int]struct{})
+		for _, s := range manager.GetStatesForRuleUID(rule.OrgID, rule.UID) {
+			i, ok := instances[s.CacheId]
+			if !ok {
+				labels := s.Labels.Copy()
+				// the rule is not saved, so it has neither a UID nor a namespace
+				delete(labels, ngmodels.RuleUIDLabel)
+				delete(labels, ngmodels.NamespaceUIDLabel)
+				result.Instances = append(result.Instances, apimodels.BacktestInstance{Labels: labels})
+				i = len(result.Instances) - 1
+				instances[s.CacheId] = i
+			}
+			current[i] = struct{}{}
+
+			periods := result.Instances[i].Periods
+			if n := len(periods); n > 0 && periods[n-1].End.IsZero() && periods[n-1].State == s.State.String() {
+				continue
+			}
+			closePeriod(i, now)
+			result.Instances[i].Periods = append(result.Instances[i].Periods, apimodels.BacktestStatePeriod{
+				State: s.State.String(),
+				Start: now,
+			})
+		}
+
+		// instances removed from the manager as stale
+		for i := range result.Instances {
+			if _, ok := current[i]; !ok {
+				closePeriod(i, now)
+			}
+		}
+	}
+
+	for i := range result.Instances {
+		closePeriod(i, to)
+	}
+	sort.Slice(result.Instances, func(i, j int) bool {
+		return data.Labels(result.Instances[i].Labels).String() < data.Labels(result.Instances[j].Labels).String()
+	})
+	return result, nil
+}
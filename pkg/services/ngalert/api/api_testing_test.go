@@ -0,0 +1,90 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+func TestBacktestRule(t *testing.T) {
+	from := time.Unix(0, 0).UTC()
+	at := func(minutes int) time.Time {
+		return from.Add(time.Duration(minutes) * time.Minute)
+	}
+
+	rule := &ngmodels.AlertRule{
+		OrgID:           1,
+		Title:           "backtest",
+		IntervalSeconds: 60,
+		For:             2 * time.Minute,
+		Labels:          map[string]string{"team": "a"},
+		NoDataState:     ngmodels.NoData,
+		ExecErrState:    ngmodels.AlertingErrState,
+	}
+
+	t.Run("returns the state periods of every alert instance", func(t *testing.T) {
+		// instance a starts alerting at the first minute and stops at the fifth,
+		// instance b is alerting at the first evaluation only.
+		states := map[string][]eval.State{
+			"a": {eval.Normal, eval.Alerting, eval.Alerting, eval.Alerting, eval.Alerting, eval.Normal, eval.Normal},
+			"b": {eval.Alerting},
+		}
+		evaluate := func(now time.Time) (eval.Results, error) {
+			i := int(now.Sub(from) / time.Minute)
+			var results eval.Results
+			for instance, s := range states {
+				if i < len(s) {
+					results = append(results, eval.Result{
+						Instance:    data.Labels{"instance": instance},
+						State:       s[i],
+						EvaluatedAt: now,
+					})
+				}
+			}
+			return results, nil
+		}
+
+		manager := state.NewInMemoryManager(log.New("test"), nil, nil)
+		result, err := backtestRule(context.Background(), manager, rule, from, at(6), evaluate)
+		require.NoError(t, err)
+		require.Equal(t, apimodels.BacktestResult{
+			Evaluations: 7,
+			Instances: []apimodels.BacktestInstance{
+				{
+					Labels: map[string]string{"alertname": "backtest", "team": "a", "instance": "a"},
+					Periods: []apimodels.BacktestStatePeriod{
+						{State: "Normal", Start: at(0), End: at(1)},
+						{State: "Pending", Start: at(1), End: at(4)},
+						{State: "Alerting", Start: at(4), End: at(5)},
+						{State: "Normal", Start: at(5), End: at(6)},
+					},
+				},
+				{
+					// the instance is stale after two intervals without results
+					Labels: map[string]string{"alertname": "backtest", "team": "a", "instance": "b"},
+					Periods: []apimodels.BacktestStatePeriod{
+						{State: "Pending", Start: at(0), End: at(3)},
+					},
+				},
+			},
+		}, result)
+	})
+
+	t.Run("fails if an evaluation fails", func(t *testing.T) {
+		manager := state.NewInMemoryManager(log.New("test"), nil, nil)
+		_, err := backtestRule(context.Background(), manager, rule, from, at(6), func(now time.Time) (eval.Results, error) {
+			return nil, errors.New("boom")
+		})
+		require.EqualError(t, err, "failed to evaluate the rule at 1970-01-01T00:00:00Z: boom")
+	})
+}
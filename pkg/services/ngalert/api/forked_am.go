@@ -155,3 +155,12 @@ func (am *ForkedAMSvc) RoutePostTestReceivers(ctx *models.ReqContext, body apimo
 
 	return s.RoutePostTestReceivers(ctx, body)
 }
+
+func (am *ForkedAMSvc) RoutePostTestRoutes(ctx *models.ReqContext, body apimodels.TestRoutesConfig) response.Response {
+	s, err := am.getService(ctx)
+	if err != nil {
+		return ErrResp(400, err, "")
+	}
+
+	return s.RoutePostTestRoutes(ctx, body)
+}
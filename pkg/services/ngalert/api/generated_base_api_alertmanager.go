@@ -32,6 +32,7 @@ type AlertmanagerApiService interface {
 	RoutePostAMAlerts(*models.ReqContext, apimodels.PostableAlerts) response.Response
 	RoutePostAlertingConfig(*models.ReqContext, apimodels.PostableUserConfig) response.Response
 	RoutePostTestReceivers(*models.ReqContext, apimodels.TestReceiversConfigParams) response.Response
+	RoutePostTestRoutes(*models.ReqContext, apimodels.TestRoutesConfig) response.Response
 }
 
 func (api *API) RegisterAlertmanagerApiEndpoints(srv AlertmanagerApiService, m *metrics.API) {
@@ -148,5 +149,15 @@ func (api *API) RegisterAlertmanagerApiEndpoints(srv AlertmanagerApiService, m *
 				m,
 			),
 		)
+		group.Post(
+			toMacaronPath("/api/alertmanager/{Recipient}/config/api/v1/routes/test"),
+			binding.Bind(apimodels.TestRoutesConfig{}),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/alertmanager/{Recipient}/config/api/v1/routes/test",
+				srv.RoutePostTestRoutes,
+				m,
+			),
+		)
 	}, middleware.ReqSignedIn)
 }
@@ -0,0 +1,45 @@
+/*Package api contains base API implementation of unified alerting
+ *
+ *Generated by: Swagger Codegen (https://github.com/swagger-api/swagger-codegen.git)
+ *
+ *Do not manually edit these files, please find ngalert/api/swagger-codegen/ for commands on how to generate them.
+ */
+package api
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+)
+
+type ConvertApiService interface {
+	RouteGetConvertPrometheusRules(*models.ReqContext) response.Response
+	RoutePostConvertPrometheusRules(*models.ReqContext) response.Response
+}
+
+func (api *API) RegisterConvertApiEndpoints(srv ConvertApiService, m *metrics.API) {
+	api.RouteRegister.Group("", func(group routing.RouteRegister) {
+		group.Get(
+			toMacaronPath("/api/ruler/grafana/api/v1/export/prometheus/{Namespace}"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/ruler/grafana/api/v1/export/prometheus/{Namespace}",
+				srv.RouteGetConvertPrometheusRules,
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/ruler/grafana/api/v1/import/prometheus/{Namespace}"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/ruler/grafana/api/v1/import/prometheus/{Namespace}",
+				srv.RoutePostConvertPrometheusRules,
+				m,
+			),
+		)
+	}, middleware.ReqSignedIn)
+}
@@ -20,6 +20,7 @@ import (
 )
 
 type TestingApiService interface {
+	RouteBacktestRule(*models.ReqContext, apimodels.BacktestConfig) response.Response
 	RouteEvalQueries(*models.ReqContext, apimodels.EvalQueriesPayload) response.Response
 	RouteTestRuleConfig(*models.ReqContext, apimodels.TestRulePayload) response.Response
 }
@@ -36,6 +37,16 @@ func (api *API) RegisterTestingApiEndpoints(srv TestingApiService, m *metrics.AP
 				m,
 			),
 		)
+		group.Post(
+			toMacaronPath("/api/v1/rule/backtest"),
+			binding.Bind(apimodels.BacktestConfig{}),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/rule/backtest",
+				srv.RouteBacktestRule,
+				m,
+			),
+		)
 		group.Post(
 			toMacaronPath("/api/v1/rule/test/{Recipient}"),
 			binding.Bind(apimodels.TestRulePayload{}),
@@ -91,6 +91,19 @@ import (
 //       408: Failure
 //       409: AlertManagerNotReady
 
+// swagger:route POST /api/alertmanager/{Recipient}/config/api/v1/routes/test alertmanager RoutePostTestRoutes
+//
+// Test which notification policies, receivers and inhibition rules apply to an alert with the given labels,
+// with the current configuration or with the given one, without saving it.
+//
+//     Responses:
+//
+//       200: TestRoutesResult
+//       400: ValidationError
+//       403: PermissionDenied
+//       404: AlertManagerNotFound
+//       409: AlertManagerNotReady
+
 // swagger:route GET /api/alertmanager/{Recipient}/api/v2/silences alertmanager RouteGetSilences
 //
 // get silences
@@ -165,6 +178,51 @@ type TestReceiverConfigResult struct {
 	Error  string `json:"error,omitempty"`
 }
 
+// swagger:parameters RoutePostTestRoutes
+type TestRoutesConfigParams struct {
+	// in:body
+	Body TestRoutesConfig
+}
+
+// swagger:model
+type TestRoutesConfig struct {
+	// The labels of the alert to route.
+	Labels map[string]string `json:"labels"`
+	// The configuration to test. The current configuration is used if it is not set.
+	AlertmanagerConfig *PostableApiAlertingConfig `json:"alertmanager_config,omitempty"`
+}
+
+// swagger:model
+type TestRoutesResult struct {
+	// The notification policies matching the labels, in the order the alert is sent to their receivers.
+	Routes []TestRouteResult `json:"routes"`
+	// The inhibition rules the alert is a target of.
+	Inhibitions []TestRouteInhibition `json:"inhibitions"`
+}
+
+// swagger:model
+type TestRouteResult struct {
+	// The index of each nested route from the root route to the matching route.
+	Path []int `json:"path"`
+	// The matchers of each route from the root route to the matching route.
+	Matchers          [][]string `json:"matchers"`
+	Receiver          string     `json:"receiver"`
+	GroupBy           []string   `json:"group_by"`
+	GroupWait         string     `json:"group_wait"`
+	GroupInterval     string     `json:"group_interval"`
+	RepeatInterval    string     `json:"repeat_interval"`
+	MuteTimeIntervals []string   `json:"mute_time_intervals,omitempty"`
+}
+
+// swagger:model
+type TestRouteInhibition struct {
+	SourceMatchers []string `json:"source_matchers"`
+	TargetMatchers []string `json:"target_matchers"`
+	Equal          []string `json:"equal,omitempty"`
+	// Active is true if a firing alert currently inhibits the alert.
+	Active bool `json:"active"`
+}
+
 // swagger:parameters RouteCreateSilence
 type CreateSilenceParams struct {
 	// in:body
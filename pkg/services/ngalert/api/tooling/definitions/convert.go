@@ -0,0 +1,58 @@
+package definitions
+
+import "github.com/prometheus/common/model"
+
+// swagger:route POST /api/ruler/grafana/api/v1/import/prometheus/{Namespace} convert RoutePostConvertPrometheusRules
+//
+// Import the rule groups of a Prometheus rules file as Grafana managed rules querying a Prometheus datasource.
+//
+// Existing rule groups of the namespace with the same name are replaced.
+//
+//     Consumes:
+//     - application/yaml
+//
+//     Responses:
+//       202: Ack
+//       400: ValidationError
+
+// swagger:route GET /api/ruler/grafana/api/v1/export/prometheus/{Namespace} convert RouteGetConvertPrometheusRules
+//
+// Export the Grafana managed rules of a namespace as a Prometheus rules file.
+//
+//     Produces:
+//     - application/yaml
+//
+//     Responses:
+//       200: PrometheusRulesFile
+//       400: ValidationError
+
+// swagger:parameters RoutePostConvertPrometheusRules
+type ImportPrometheusRulesParams struct {
+	// in: path
+	Namespace string
+	// The UID of the Prometheus datasource the imported rules query
+	// in: query
+	// required: true
+	DatasourceUID string `json:"datasourceUID"`
+	// in: body
+	Body PrometheusRulesFile
+}
+
+// swagger:parameters RouteGetConvertPrometheusRules
+type ExportPrometheusRulesParams struct {
+	// in: path
+	Namespace string
+}
+
+// PrometheusRulesFile is a Prometheus rules file.
+// swagger:model
+type PrometheusRulesFile struct {
+	Groups []PrometheusRuleGroup `yaml:"groups" json:"groups"`
+}
+
+// swagger:model
+type PrometheusRuleGroup struct {
+	Name     string         `yaml:"name" json:"name"`
+	Interval model.Duration `yaml:"interval,omitempty" json:"interval,omitempty"`
+	Rules    []ApiRuleNode  `yaml:"rules" json:"rules"`
+}
@@ -107,6 +107,12 @@ type PostableRuleGroupConfig struct {
 	Name     string                     `yaml:"name" json:"name"`
 	Interval model.Duration             `yaml:"interval,omitempty" json:"interval,omitempty"`
 	Rules    []PostableExtendedRuleNode `yaml:"rules" json:"rules"`
+	// EvaluateSequentially evaluates the rules of the group one after the other, in order,
+	// instead of independently. Only Grafana managed rule groups support it.
+	EvaluateSequentially bool `yaml:"evaluate_sequentially,omitempty" json:"evaluate_sequentially,omitempty"`
+	// IsPaused pauses the evaluation of all the rules of the group, whether they are paused or not.
+	// Only Grafana managed rule groups support it.
+	IsPaused bool `yaml:"is_paused,omitempty" json:"is_paused,omitempty"`
 }
 
 func (c *PostableRuleGroupConfig) UnmarshalJSON(b []byte) error {
@@ -145,6 +151,14 @@ func (c *PostableRuleGroupConfig) validate() error {
 	if hasGrafRules && hasLotexRules {
 		return fmt.Errorf("cannot mix Grafana & Prometheus style rules")
 	}
+
+	if hasLotexRules && c.EvaluateSequentially {
+		return fmt.Errorf("only Grafana managed rules can be evaluated sequentially")
+	}
+
+	if hasLotexRules && c.IsPaused {
+		return fmt.Errorf("only Grafana managed rule groups can be paused")
+	}
 	return nil
 }
 
@@ -153,6 +167,12 @@ type GettableRuleGroupConfig struct {
 	Name     string                     `yaml:"name" json:"name"`
 	Interval model.Duration             `yaml:"interval,omitempty" json:"interval,omitempty"`
 	Rules    []GettableExtendedRuleNode `yaml:"rules" json:"rules"`
+	// EvaluateSequentially evaluates the rules of the group one after the other, in order,
+	// instead of independently. Only Grafana managed rule groups support it.
+	EvaluateSequentially bool `yaml:"evaluate_sequentially,omitempty" json:"evaluate_sequentially,omitempty"`
+	// IsPaused pauses the evaluation of all the rules of the group, whether they are paused or not.
+	// Only Grafana managed rule groups support it.
+	IsPaused bool `yaml:"is_paused,omitempty" json:"is_paused,omitempty"`
 }
 
 func (c *GettableRuleGroupConfig) UnmarshalJSON(b []byte) error {
@@ -287,42 +307,52 @@ func (n *GettableExtendedRuleNode) validate() error {
 type NoDataState string
 
 const (
-	Alerting NoDataState = "Alerting"
-	NoData   NoDataState = "NoData"
-	OK       NoDataState = "OK"
+	Alerting      NoDataState = "Alerting"
+	NoData        NoDataState = "NoData"
+	OK            NoDataState = "OK"
+	KeepLastState NoDataState = "KeepLastState"
 )
 
 // swagger:enum ExecutionErrorState
 type ExecutionErrorState string
 
 const (
-	AlertingErrState ExecutionErrorState = "Alerting"
+	AlertingErrState      ExecutionErrorState = "Alerting"
+	ErrorErrState         ExecutionErrorState = "Error"
+	OkErrState            ExecutionErrorState = "OK"
+	KeepLastStateErrState ExecutionErrorState = "KeepLastState"
 )
 
 // swagger:model
 type PostableGrafanaRule struct {
-	Title        string              `json:"title" yaml:"title"`
-	Condition    string              `json:"condition" yaml:"condition"`
-	Data         []models.AlertQuery `json:"data" yaml:"data"`
-	UID          string              `json:"uid" yaml:"uid"`
-	NoDataState  NoDataState         `json:"no_data_state" yaml:"no_data_state"`
-	ExecErrState ExecutionErrorState `json:"exec_err_state" yaml:"exec_err_state"`
+	Title        string                  `json:"title" yaml:"title"`
+	Condition    string                  `json:"condition" yaml:"condition"`
+	Data         []models.AlertQuery     `json:"data" yaml:"data"`
+	UID          string                  `json:"uid" yaml:"uid"`
+	NoDataState  NoDataState             `json:"no_data_state" yaml:"no_data_state"`
+	ExecErrState ExecutionErrorState     `json:"exec_err_state" yaml:"exec_err_state"`
+	IsPaused     bool                    `json:"is_paused" yaml:"is_paused"`
+	Record       string                  `json:"record,omitempty" yaml:"record,omitempty"`
+	DependsOn    []models.RuleDependency `json:"depends_on,omitempty" yaml:"depends_on,omitempty"`
 }
 
 // swagger:model
 type GettableGrafanaRule struct {
-	ID              int64               `json:"id" yaml:"id"`
-	OrgID           int64               `json:"orgId" yaml:"orgId"`
-	Title           string              `json:"title" yaml:"title"`
-	Condition       string              `json:"condition" yaml:"condition"`
-	Data            []models.AlertQuery `json:"data" yaml:"data"`
-	Updated         time.Time           `json:"updated" yaml:"updated"`
-	IntervalSeconds int64               `json:"intervalSeconds" yaml:"intervalSeconds"`
-	Version         int64               `json:"version" yaml:"version"`
-	UID             string              `json:"uid" yaml:"uid"`
-	NamespaceUID    string              `json:"namespace_uid" yaml:"namespace_uid"`
-	NamespaceID     int64               `json:"namespace_id" yaml:"namespace_id"`
-	RuleGroup       string              `json:"rule_group" yaml:"rule_group"`
-	NoDataState     NoDataState         `json:"no_data_state" yaml:"no_data_state"`
-	ExecErrState    ExecutionErrorState `json:"exec_err_state" yaml:"exec_err_state"`
+	ID              int64                   `json:"id" yaml:"id"`
+	OrgID           int64                   `json:"orgId" yaml:"orgId"`
+	Title           string                  `json:"title" yaml:"title"`
+	Condition       string                  `json:"condition" yaml:"condition"`
+	Data            []models.AlertQuery     `json:"data" yaml:"data"`
+	Updated         time.Time               `json:"updated" yaml:"updated"`
+	IntervalSeconds int64                   `json:"intervalSeconds" yaml:"intervalSeconds"`
+	Version         int64                   `json:"version" yaml:"version"`
+	UID             string                  `json:"uid" yaml:"uid"`
+	NamespaceUID    string                  `json:"namespace_uid" yaml:"namespace_uid"`
+	NamespaceID     int64                   `json:"namespace_id" yaml:"namespace_id"`
+	RuleGroup       string                  `json:"rule_group" yaml:"rule_group"`
+	NoDataState     NoDataState             `json:"no_data_state" yaml:"no_data_state"`
+	ExecErrState    ExecutionErrorState     `json:"exec_err_state" yaml:"exec_err_state"`
+	IsPaused        bool                    `json:"is_paused" yaml:"is_paused"`
+	Record          string                  `json:"record,omitempty" yaml:"record,omitempty"`
+	DependsOn       []models.RuleDependency `json:"depends_on,omitempty" yaml:"depends_on,omitempty"`
 }
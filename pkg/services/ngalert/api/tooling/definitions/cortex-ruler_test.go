@@ -149,6 +149,63 @@ func Test_Rule_Group_Marshaling(t *testing.T) {
 			},
 			err: true,
 		},
+		{
+			desc: "success grafana evaluated sequentially",
+			input: PostableRuleGroupConfig{
+				Name:                 "foo",
+				Interval:             0,
+				EvaluateSequentially: true,
+				Rules: []PostableExtendedRuleNode{
+					{
+						GrafanaManagedAlert: &PostableGrafanaRule{},
+					},
+					{
+						GrafanaManagedAlert: &PostableGrafanaRule{},
+					},
+				},
+			},
+		},
+		{
+			desc: "failure lotex evaluated sequentially",
+			input: PostableRuleGroupConfig{
+				Name:                 "foo",
+				Interval:             0,
+				EvaluateSequentially: true,
+				Rules: []PostableExtendedRuleNode{
+					{
+						ApiRuleNode: &ApiRuleNode{},
+					},
+				},
+			},
+			err: true,
+		},
+		{
+			desc: "success grafana paused",
+			input: PostableRuleGroupConfig{
+				Name:     "foo",
+				Interval: 0,
+				IsPaused: true,
+				Rules: []PostableExtendedRuleNode{
+					{
+						GrafanaManagedAlert: &PostableGrafanaRule{},
+					},
+				},
+			},
+		},
+		{
+			desc: "failure lotex paused",
+			input: PostableRuleGroupConfig{
+				Name:     "foo",
+				Interval: 0,
+				IsPaused: true,
+				Rules: []PostableExtendedRuleNode{
+					{
+						ApiRuleNode: &ApiRuleNode{},
+					},
+				},
+			},
+			err: true,
+		},
 	} {
 		t.Run(tc.desc, func(t *testing.T) {
 			encoded, err := json.Marshal(tc.input)
@@ -0,0 +1,124 @@
+package definitions
+
+import "time"
+
+// swagger:route GET /api/v1/ngalert/history history RouteGetStateHistory
+//
+// Get the state transitions of the alert instances of the user's organization, most recent first.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: GettableStateHistory
+//       400: ValidationError
+
+// swagger:route GET /api/v1/ngalert/history/notifications history RouteGetNotificationDeliveries
+//
+// Get the notifications sent to the contact points of the user's organization, most recent first.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: GettableNotificationDeliveries
+//       400: ValidationError
+
+// swagger:parameters RouteGetStateHistory
+type StateHistoryParams struct {
+	// The UID of the alert rule
+	// in: query
+	// required: false
+	RuleUID string `json:"ruleUID"`
+
+	// A list of labels in the format name=value the alert instances must have
+	// in: query
+	// required: false
+	Labels []string `json:"labels"`
+
+	// Epoch timestamp in milliseconds of the earliest transition
+	// in: query
+	// required: false
+	From int64 `json:"from"`
+
+	// Epoch timestamp in milliseconds of the latest transition
+	// in: query
+	// required: false
+	To int64 `json:"to"`
+
+	// The maximum number of transitions
+	// in: query
+	// required: false
+	// default: 100
+	Limit int `json:"limit"`
+}
+
+// swagger:model
+type GettableStateHistory []GettableStateHistoryEntry
+
+// swagger:model
+type GettableStateHistoryEntry struct {
+	RuleUID          string                           `json:"ruleUID"`
+	Labels           map[string]string                `json:"labels"`
+	PreviousState    string                           `json:"previousState"`
+	State            string                           `json:"state"`
+	Error            string                           `json:"error,omitempty"`
+	EvaluationString string                           `json:"evaluationString"`
+	Values           map[string]StateHistoryEvalValue `json:"values"`
+	EvaluatedAt      time.Time                        `json:"evaluatedAt"`
+}
+
+// StateHistoryEvalValue is the labels and value of a reduce or math expression in the evaluation.
+// swagger:model
+type StateHistoryEvalValue struct {
+	Labels map[string]string `json:"labels"`
+	Value  *float64          `json:"value"`
+}
+
+// swagger:parameters RouteGetNotificationDeliveries
+type NotificationDeliveriesParams struct {
+	// The name of the receiver
+	// in: query
+	// required: false
+	Receiver string `json:"receiver"`
+
+	// The UID of the alert rule
+	// in: query
+	// required: false
+	RuleUID string `json:"ruleUID"`
+
+	// Epoch timestamp in milliseconds of the earliest notification
+	// in: query
+	// required: false
+	From int64 `json:"from"`
+
+	// Epoch timestamp in milliseconds of the latest notification
+	// in: query
+	// required: false
+	To int64 `json:"to"`
+
+	// The maximum number of notifications
+	// in: query
+	// required: false
+	// default: 100
+	Limit int `json:"limit"`
+}
+
+// swagger:model
+type GettableNotificationDeliveries []GettableNotificationDelivery
+
+// GettableNotificationDelivery is an attempt to send the alerts of an alert rule to an integration of a receiver.
+// swagger:model
+type GettableNotificationDelivery struct {
+	Receiver         string    `json:"receiver"`
+	Integration      string    `json:"integration"`
+	IntegrationIndex int       `json:"integrationIndex"`
+	IntegrationUID   string    `json:"integrationUID"`
+	RuleUID          string    `json:"ruleUID"`
+	GroupKey         string    `json:"groupKey"`
+	Alerts           int       `json:"alerts"`
+	State            string    `json:"state"`
+	Error            string    `json:"error,omitempty"`
+	Attempt          int       `json:"attempt"`
+	SentAt           time.Time `json:"sentAt"`
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/promql"
 )
 
@@ -38,6 +39,20 @@ import (
 //     Responses:
 //       200: EvalQueriesResponse
 
+// swagger:route Post /api/v1/rule/backtest testing RouteBacktestRule
+//
+// Replay the evaluations of a Grafana managed alert rule over a past time range.
+//
+//     Consumes:
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: BacktestResult
+//       400: ValidationError
+
 // swagger:parameters RouteTestReceiverConfig
 type TestReceiverRequest struct {
 	// in:body
@@ -70,6 +85,54 @@ type EvalQueriesPayload struct {
 	Now  time.Time           `json:"now"`
 }
 
+// swagger:parameters RouteBacktestRule
+type BacktestRequest struct {
+	// in:body
+	Body BacktestConfig
+}
+
+// swagger:model
+type BacktestConfig struct {
+	// The start of the time range
+	From time.Time `json:"from"`
+	// The end of the time range
+	To time.Time `json:"to"`
+	// The evaluation interval of the alert rule
+	Interval model.Duration `json:"interval"`
+
+	Title        string              `json:"title"`
+	Condition    string              `json:"condition"`
+	Data         []models.AlertQuery `json:"data"`
+	For          model.Duration      `json:"for,omitempty"`
+	Labels       map[string]string   `json:"labels,omitempty"`
+	NoDataState  NoDataState         `json:"no_data_state"`
+	ExecErrState ExecutionErrorState `json:"exec_err_state"`
+}
+
+// swagger:model
+type BacktestResult struct {
+	// The number of evaluations of the alert rule
+	Evaluations int                `json:"evaluations"`
+	Instances   []BacktestInstance `json:"instances"`
+}
+
+// BacktestInstance is the state timeline of an alert instance.
+// swagger:model
+type BacktestInstance struct {
+	Labels  map[string]string     `json:"labels"`
+	Periods []BacktestStatePeriod `json:"periods"`
+}
+
+// BacktestStatePeriod is a period during which an alert instance stays in the same state.
+// swagger:model
+type BacktestStatePeriod struct {
+	State string `json:"state"`
+	// The time of the first evaluation in the state
+	Start time.Time `json:"start"`
+	// The time of the first evaluation in another state, or the end of the time range
+	End time.Time `json:"end"`
+}
+
 func (p *TestRulePayload) UnmarshalJSON(b []byte) error {
 	type plain TestRulePayload
 	if err := json.Unmarshal(b, (*plain)(p)); err != nil {
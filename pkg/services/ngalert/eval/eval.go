@@ -101,10 +101,15 @@ const (
 	// Error is the eval state for an alert rule condition
 	// that evaluated to Error.
 	Error
+
+	// Inhibited is the state for an alert instance that is
+	// Alerting while an alert of a rule its alert rule depends
+	// on is firing. Evaluations do not emit results with this state.
+	Inhibited
 )
 
 func (s State) String() string {
-	return [...]string{"Normal", "Alerting", "Pending", "NoData", "Error"}[s]
+	return [...]string{"Normal", "Alerting", "Pending", "NoData", "Error", "Inhibited"}[s]
 }
 
 // AlertExecCtx is the context provided for executing an alert condition.
@@ -445,7 +450,12 @@ func (e *Evaluator) ConditionEval(condition *models.Condition, now time.Time, da
 
 // QueriesAndExpressionsEval executes queries and expressions and returns the result.
 func (e *Evaluator) QueriesAndExpressionsEval(orgID int64, data []models.AlertQuery, now time.Time, dataService *tsdb.Service) (*backend.QueryDataResponse, error) {
-	alertCtx, cancelFn := context.WithTimeout(context.Background(), e.Cfg.UnifiedAlerting.EvaluationTimeout)
+	return e.QueriesAndExpressionsEvalCtx(context.Background(), orgID, data, now, dataService)
+}
+
+// QueriesAndExpressionsEvalCtx executes queries and expressions within the context and returns the result.
+func (e *Evaluator) QueriesAndExpressionsEvalCtx(ctx context.Context, orgID int64, data []models.AlertQuery, now time.Time, dataService *tsdb.Service) (*backend.QueryDataResponse, error) {
+	alertCtx, cancelFn := context.WithTimeout(ctx, e.Cfg.UnifiedAlerting.EvaluationTimeout)
 	defer cancelFn()
 
 	alertExecCtx := AlertExecCtx{OrgID: orgID, Ctx: alertCtx, ExpressionsEnabled: e.Cfg.ExpressionsEnabled, Log: e.Log}
@@ -45,10 +45,13 @@ type NGAlert struct {
 }
 
 type Scheduler struct {
-	Registerer   prometheus.Registerer
-	EvalTotal    *prometheus.CounterVec
-	EvalFailures *prometheus.CounterVec
-	EvalDuration *prometheus.SummaryVec
+	Registerer              prometheus.Registerer
+	EvalTotal               *prometheus.CounterVec
+	EvalFailures            *prometheus.CounterVec
+	EvalDuration            *prometheus.SummaryVec
+	EvalLag                 *prometheus.SummaryVec
+	DatasourceEvalsInFlight *prometheus.GaugeVec
+	GroupEvalDuration       *prometheus.SummaryVec
 }
 
 type MultiOrgAlertmanager struct {
@@ -152,6 +155,35 @@ func newSchedulerMetrics(r prometheus.Registerer) *Scheduler {
 			},
 			[]string{"org"},
 		),
+		EvalLag: promauto.With(r).NewSummaryVec(
+			prometheus.SummaryOpts{
+				Namespace:  Namespace,
+				Subsystem:  Subsystem,
+				Name:       "rule_evaluation_lag_seconds",
+				Help:       "The time between the scheduling of a rule evaluation and its start.",
+				Objectives: map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001},
+			},
+			[]string{"org"},
+		),
+		DatasourceEvalsInFlight: promauto.With(r).NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "datasource_rule_evaluations_in_flight",
+				Help:      "The number of rule evaluations querying a datasource.",
+			},
+			[]string{"datasource_uid"},
+		),
+		GroupEvalDuration: promauto.With(r).NewSummaryVec(
+			prometheus.SummaryOpts{
+				Namespace:  Namespace,
+				Subsystem:  Subsystem,
+				Name:       "rule_group_evaluation_duration_seconds",
+				Help:       "The duration for the rules of a sequentially evaluated rule group to execute.",
+				Objectives: map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001},
+			},
+			[]string{"org"},
+		),
 	}
 }
 
@@ -19,6 +19,8 @@ var (
 	ErrAlertRuleFailedValidation = errors.New("invalid alert rule")
 	// ErrAlertRuleUniqueConstraintViolation
 	ErrAlertRuleUniqueConstraintViolation = errors.New("a conflicting alert rule is found: rule title under the same organisation and folder should be unique")
+	// ErrAlertRuleHasDependents is an error for deleting an alert rule other alert rules depend on.
+	ErrAlertRuleHasDependents = errors.New("other alert rules depend on the alert rule")
 )
 
 type NoDataState string
@@ -28,11 +30,21 @@ func (noDataState NoDataState) String() string {
 }
 
 const (
-	Alerting NoDataState = "Alerting"
-	NoData   NoDataState = "NoData"
-	OK       NoDataState = "OK"
+	Alerting      NoDataState = "Alerting"
+	NoData        NoDataState = "NoData"
+	OK            NoDataState = "OK"
+	KeepLastState NoDataState = "KeepLastState"
 )
 
+// IsValid returns true if the no data state is one of the known states.
+func (noDataState NoDataState) IsValid() bool {
+	switch noDataState {
+	case Alerting, NoData, OK, KeepLastState:
+		return true
+	}
+	return false
+}
+
 type ExecutionErrorState string
 
 func (executionErrorState ExecutionErrorState) String() string {
@@ -40,9 +52,21 @@ func (executionErrorState ExecutionErrorState) String() string {
 }
 
 const (
-	AlertingErrState ExecutionErrorState = "Alerting"
+	AlertingErrState      ExecutionErrorState = "Alerting"
+	ErrorErrState         ExecutionErrorState = "Error"
+	OkErrState            ExecutionErrorState = "OK"
+	KeepLastStateErrState ExecutionErrorState = "KeepLastState"
 )
 
+// IsValid returns true if the execution error state is one of the known states.
+func (executionErrorState ExecutionErrorState) IsValid() bool {
+	switch executionErrorState {
+	case AlertingErrState, ErrorErrState, OkErrState, KeepLastStateErrState:
+		return true
+	}
+	return false
+}
+
 const (
 	RuleUIDLabel      = "__alert_rule_uid__"
 	NamespaceUIDLabel = "__alert_rule_namespace_uid__"
@@ -69,6 +93,21 @@ type AlertRule struct {
 	RuleGroup       string
 	NoDataState     NoDataState
 	ExecErrState    ExecutionErrorState
+	// IsPaused is true if the alert rule must not be evaluated.
+	IsPaused bool
+	// Record is the metric name the result of the condition is written to.
+	// If set, the rule is a recording rule and does not produce alert states.
+	Record string
+	// RuleGroupIndex is the position of the rule in its rule group, starting at 1.
+	RuleGroupIndex int
+	// EvaluateSequentially is true if the rules of the rule group are evaluated one after the other,
+	// in the order of their index. It is the same for all the rules of the group.
+	EvaluateSequentially bool
+	// RuleGroupIsPaused is true if none of the rules of the rule group must be evaluated.
+	// It is the same for all the rules of the group.
+	RuleGroupIsPaused bool
+	// DependsOn are the rules whose firing alerts inhibit the alerts of the rule.
+	DependsOn []RuleDependency
 	// ideally this field should have been apimodels.ApiDuration
 	// but this is currently not possible because of circular dependencies
 	For         time.Duration
@@ -76,6 +115,28 @@ type AlertRule struct {
 	Labels      map[string]string
 }
 
+// RuleDependency is a rule another rule depends on: while an alert of the rule is firing,
+// the alerts of the dependent rule that would be firing are Inhibited instead.
+type RuleDependency struct {
+	// RuleUID is the UID of the rule, in the organisation of the dependent rule.
+	RuleUID string `json:"rule_uid" yaml:"rule_uid"`
+	// Equal are the labels that must have the same value in both alerts for an alert
+	// to be inhibited. If it is empty, any firing alert of the rule inhibits all the
+	// alerts of the dependent rule.
+	Equal []string `json:"equal,omitempty" yaml:"equal,omitempty"`
+}
+
+// Inhibits returns true if a firing alert of the rule with the source labels inhibits
+// the alert of the dependent rule with the target labels.
+func (d RuleDependency) Inhibits(source, target map[string]string) bool {
+	for _, name := range d.Equal {
+		if source[name] != target[name] {
+			return false
+		}
+	}
+	return true
+}
+
 // AlertRuleKey is the alert definition identifier
 type AlertRuleKey struct {
 	OrgID int64
@@ -91,6 +152,12 @@ func (alertRule *AlertRule) GetKey() AlertRuleKey {
 	return AlertRuleKey{OrgID: alertRule.OrgID, UID: alertRule.UID}
 }
 
+// IsRecordingRule returns true if the rule writes the result of its condition
+// to a remote write target instead of producing alert states.
+func (alertRule *AlertRule) IsRecordingRule() bool {
+	return alertRule.Record != ""
+}
+
 // PreSave sets default values and loads the updated model for each alert query.
 func (alertRule *AlertRule) PreSave(timeNow func() time.Time) error {
 	for i, q := range alertRule.Data {
@@ -115,13 +182,19 @@ type AlertRuleVersion struct {
 	RestoredFrom     int64
 	Version          int64
 
-	Created         time.Time
-	Title           string
-	Condition       string
-	Data            []AlertQuery
-	IntervalSeconds int64
-	NoDataState     NoDataState
-	ExecErrState    ExecutionErrorState
+	Created              time.Time
+	Title                string
+	Condition            string
+	Data                 []AlertQuery
+	IntervalSeconds      int64
+	NoDataState          NoDataState
+	ExecErrState         ExecutionErrorState
+	IsPaused             bool
+	Record               string
+	RuleGroupIndex       int
+	EvaluateSequentially bool
+	RuleGroupIsPaused    bool
+	DependsOn            []RuleDependency
 	// ideally this field should have been apimodels.ApiDuration
 	// but this is currently not possible because of circular dependencies
 	For         time.Duration
@@ -31,6 +31,8 @@ const (
 	InstanceStateNoData InstanceStateType = "NoData"
 	// InstanceStateError is for a erroring alert.
 	InstanceStateError InstanceStateType = "Error"
+	// InstanceStateInhibited is for a firing alert suppressed by an alert of a rule its rule depends on.
+	InstanceStateInhibited InstanceStateType = "Inhibited"
 )
 
 // IsValid checks that the value of InstanceStateType is a valid
@@ -40,7 +42,8 @@ func (i InstanceStateType) IsValid() bool {
 		i == InstanceStateNormal ||
 		i == InstanceStateNoData ||
 		i == InstanceStatePending ||
-		i == InstanceStateError
+		i == InstanceStateError ||
+		i == InstanceStateInhibited
 }
 
 // SaveAlertInstanceCommand is the query for saving a new alert instance.
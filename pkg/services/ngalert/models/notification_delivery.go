@@ -0,0 +1,156 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// NotificationDeliveryState is the outcome of an attempt to send a notification to an integration.
+type NotificationDeliveryState string
+
+const (
+	// NotificationDeliverySent is the state of notifications that were sent successfully.
+	NotificationDeliverySent NotificationDeliveryState = "Sent"
+	// NotificationDeliveryFailed is the state of notifications that failed and are queued to be retried.
+	NotificationDeliveryFailed NotificationDeliveryState = "Failed"
+	// NotificationDeliveryDropped is the state of notifications that failed and are not retried anymore.
+	NotificationDeliveryDropped NotificationDeliveryState = "Dropped"
+)
+
+// IsValid checks that the value of NotificationDeliveryState is a valid string.
+func (s NotificationDeliveryState) IsValid() bool {
+	return s == NotificationDeliverySent ||
+		s == NotificationDeliveryFailed ||
+		s == NotificationDeliveryDropped
+}
+
+// NotificationDeliveryEntry represents an attempt to send the alerts of a rule to an integration of a receiver.
+// A notification grouping the alerts of several rules has an entry for each of them.
+type NotificationDeliveryEntry struct {
+	ID               int64 `xorm:"pk autoincr 'id'"`
+	OrgID            int64 `xorm:"org_id"`
+	Receiver         string
+	Integration      string
+	IntegrationIndex int
+	IntegrationUID   string `xorm:"integration_uid"`
+	// RuleUID is empty for alerts that were not created by a Grafana managed rule.
+	RuleUID  string `xorm:"rule_uid"`
+	GroupKey string
+	Alerts   int
+	State    NotificationDeliveryState
+	Error    string `xorm:"error_message"`
+	// Attempt is 0 for notifications sent by the notification pipeline, and the number of the retry otherwise.
+	Attempt int
+	SentAt  time.Time
+}
+
+// SaveNotificationDeliveriesCommand is the command for saving the entries of an attempt to send a notification.
+type SaveNotificationDeliveriesCommand struct {
+	Entries []*NotificationDeliveryEntry
+}
+
+// ListNotificationDeliveriesQuery is the query for listing the notification deliveries
+// within an organisation, most recent first.
+type ListNotificationDeliveriesQuery struct {
+	OrgID    int64
+	Receiver string
+	RuleUID  string
+	From     time.Time
+	To       time.Time
+	Limit    int
+
+	Result []*NotificationDeliveryEntry
+}
+
+// DeleteExpiredNotificationDeliveriesCommand is the command for deleting the notification deliveries
+// that were sent before OlderThan.
+type DeleteExpiredNotificationDeliveriesCommand struct {
+	OlderThan time.Time
+
+	DeletedRows int64
+}
+
+// NotificationRetry is a notification that failed to be sent to an integration and is waiting to be retried.
+type NotificationRetry struct {
+	ID               int64 `xorm:"pk autoincr 'id'"`
+	OrgID            int64 `xorm:"org_id"`
+	Receiver         string
+	Integration      string
+	IntegrationIndex int
+	IntegrationUID   string `xorm:"integration_uid"`
+	GroupKey         string
+	// GroupLabels is the JSON encoded labels of the alert group of the notification.
+	GroupLabels string
+	// Alerts is the JSON encoded list of alerts of the notification.
+	Alerts        string
+	Attempts      int
+	Error         string `xorm:"error_message"`
+	NextAttemptAt time.Time
+	Created       time.Time
+}
+
+// EnqueueNotificationRetryCommand is the command for adding a failed notification to the retry queue.
+// The notification replaces the notification of the same alert group to the integration already
+// in the queue, if any, as it is more recent.
+type EnqueueNotificationRetryCommand struct {
+	OrgID            int64
+	Receiver         string
+	Integration      string
+	IntegrationIndex int
+	IntegrationUID   string
+	GroupKey         string
+	GroupLabels      string
+	Alerts           string
+	Error            string
+	NextAttemptAt    time.Time
+}
+
+// GetDueNotificationRetriesQuery is the query for the notifications of an organisation
+// that are due to be retried at Now, oldest first.
+type GetDueNotificationRetriesQuery struct {
+	OrgID int64
+	Now   time.Time
+	Limit int
+
+	Result []*NotificationRetry
+}
+
+// UpdateNotificationRetryCommand is the command for recording a failed retry of a notification.
+type UpdateNotificationRetryCommand struct {
+	ID            int64
+	Attempts      int
+	Error         string
+	NextAttemptAt time.Time
+}
+
+// DeleteNotificationRetryCommand is the command for removing a notification from the retry queue.
+type DeleteNotificationRetryCommand struct {
+	ID int64
+}
+
+// DeleteGroupNotificationRetriesCommand is the command for removing from the retry queue the
+// notifications of an alert group to an integration, once a more recent notification was sent.
+type DeleteGroupNotificationRetriesCommand struct {
+	OrgID          int64
+	Receiver       string
+	IntegrationUID string
+	GroupKey       string
+}
+
+// ValidateNotificationDeliveryEntry validates that the entry contains an organisation,
+// an integration and a valid state.
+func ValidateNotificationDeliveryEntry(entry *NotificationDeliveryEntry) error {
+	if entry.OrgID == 0 {
+		return fmt.Errorf("notification delivery entry is invalid due to missing organisation")
+	}
+
+	if entry.Receiver == "" || entry.Integration == "" {
+		return fmt.Errorf("notification delivery entry is invalid due to missing receiver or integration")
+	}
+
+	if !entry.State.IsValid() {
+		return fmt.Errorf("notification delivery entry is invalid because the state '%v' is invalid", entry.State)
+	}
+
+	return nil
+}
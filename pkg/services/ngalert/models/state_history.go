@@ -0,0 +1,79 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// AlertStateHistoryEntry represents a single state transition of an alert instance.
+type AlertStateHistoryEntry struct {
+	ID               int64  `xorm:"pk autoincr 'id'"`
+	RuleOrgID        int64  `xorm:"rule_org_id"`
+	RuleUID          string `xorm:"rule_uid"`
+	Labels           InstanceLabels
+	LabelsHash       string
+	PreviousState    InstanceStateType
+	State            InstanceStateType
+	Error            string `xorm:"error_message"`
+	EvaluationString string
+	// Values is the JSON encoded RefID, labels and value of each reduce and math expression of the evaluation.
+	Values      string `xorm:"eval_values"`
+	EvaluatedAt time.Time
+	Created     time.Time
+}
+
+// SaveAlertStateHistoryCommand is the command for saving a state transition of an alert instance.
+type SaveAlertStateHistoryCommand struct {
+	RuleOrgID        int64
+	RuleUID          string
+	Labels           InstanceLabels
+	PreviousState    InstanceStateType
+	State            InstanceStateType
+	Error            string
+	EvaluationString string
+	Values           string
+	EvaluatedAt      time.Time
+}
+
+// ListAlertStateHistoryQuery is the query for listing the state transitions of alert instances
+// within an organisation, most recent first. Labels only match instances that have all of them.
+type ListAlertStateHistoryQuery struct {
+	RuleOrgID int64
+	RuleUID   string
+	Labels    map[string]string
+	From      time.Time
+	To        time.Time
+	Limit     int
+
+	Result []*AlertStateHistoryEntry
+}
+
+// DeleteExpiredAlertStateHistoryCommand is the command for deleting the state transitions
+// that were evaluated before OlderThan.
+type DeleteExpiredAlertStateHistoryCommand struct {
+	OlderThan time.Time
+
+	DeletedRows int64
+}
+
+// ValidateAlertStateHistoryEntry validates that the entry contains an alert rule,
+// and valid states.
+func ValidateAlertStateHistoryEntry(entry *AlertStateHistoryEntry) error {
+	if entry.RuleOrgID == 0 {
+		return fmt.Errorf("alert state history entry is invalid due to missing alert rule organisation")
+	}
+
+	if entry.RuleUID == "" {
+		return fmt.Errorf("alert state history entry is invalid due to missing alert rule uid")
+	}
+
+	if !entry.PreviousState.IsValid() {
+		return fmt.Errorf("alert state history entry is invalid because the previous state '%v' is invalid", entry.PreviousState)
+	}
+
+	if !entry.State.IsValid() {
+		return fmt.Errorf("alert state history entry is invalid because the state '%v' is invalid", entry.State)
+	}
+
+	return nil
+}
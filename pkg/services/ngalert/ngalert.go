@@ -16,6 +16,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	"github.com/grafana/grafana/pkg/services/ngalert/recording"
 	"github.com/grafana/grafana/pkg/services/ngalert/schedule"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
@@ -128,6 +129,14 @@ func (ng *AlertNG) init() error {
 		AdminConfigPollInterval: ng.Cfg.UnifiedAlerting.AdminConfigPollInterval,
 		DisabledOrgs:            ng.Cfg.UnifiedAlerting.DisabledOrgs,
 		MinRuleInterval:         ng.getRuleMinInterval(),
+		EvaluationJitter:        ng.Cfg.UnifiedAlerting.EvaluationJitter,
+
+		MaxConcurrentEvaluationsPerDatasource: ng.Cfg.UnifiedAlerting.MaxConcurrentEvaluationsPerDatasource,
+	}
+
+	// recording rules are only evaluated if there is somewhere to write their results
+	if w := recording.NewRemoteWriter(ng.Cfg.UnifiedAlerting, log.New("ngalert.recording")); w != nil {
+		schedCfg.RecordingWriter = w
 	}
 
 	appUrl, err := url.Parse(ng.Cfg.AppURL)
@@ -135,7 +144,8 @@ func (ng *AlertNG) init() error {
 		ng.Log.Error("Failed to parse application URL. Continue without it.", "error", err)
 		appUrl = nil
 	}
-	stateManager := state.NewManager(ng.Log, ng.Metrics.GetStateMetrics(), appUrl, store, store)
+	templateQuerier := state.NewTemplateQuerier(schedCfg.Evaluator, ng.DataService)
+	stateManager := state.NewManager(ng.Log, ng.Metrics.GetStateMetrics(), appUrl, store, store, store, templateQuerier)
 	scheduler := schedule.NewScheduler(schedCfg, ng.DataService, appUrl, stateManager)
 
 	ng.stateManager = stateManager
@@ -151,6 +161,7 @@ func (ng *AlertNG) init() error {
 		QuotaService:         ng.QuotaService,
 		EncryptionService:    ng.EncryptionService,
 		InstanceStore:        store,
+		StateHistoryStore:    store,
 		RuleStore:            store,
 		AlertingStore:        store,
 		AdminConfigStore:     store,
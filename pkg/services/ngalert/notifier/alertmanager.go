@@ -118,6 +118,11 @@ type Alertmanager struct {
 	configHash      [16]byte
 	orgID           int64
 
+	// integrations and integrationUIDs are the integrations of the current configuration by receiver,
+	// used to retry the notifications of the retry queue.
+	integrations    map[string][]notify.Integration
+	integrationUIDs map[string][]string
+
 	decryptFn channels.GetDecryptedValueFn
 }
 
@@ -187,6 +192,12 @@ func newAlertmanager(orgID int64, cfg *setting.Cfg, store store.AlertingStore, k
 		return nil, fmt.Errorf("unable to initialize the alert provider component of alerting: %w", err)
 	}
 
+	am.wg.Add(1)
+	go func() {
+		defer am.wg.Done()
+		am.runNotificationRetries()
+	}()
+
 	return am, nil
 }
 
@@ -411,8 +422,9 @@ func (am *Alertmanager) applyConfig(cfg *apimodels.PostableUserConfig, rawConfig
 	meshStage := notify.NewGossipSettleStage(am.peer)
 	inhibitionStage := notify.NewMuteStage(am.inhibitor)
 	silencingStage := notify.NewMuteStage(am.silencer)
+	uids := integrationUIDs(cfg.AlertmanagerConfig.Receivers)
 	for name := range integrationsMap {
-		stage := am.createReceiverStage(name, integrationsMap[name], am.waitFunc, am.notificationLog)
+		stage := am.createReceiverStage(name, integrationsMap[name], uids[name], am.waitFunc, am.notificationLog)
 		routingStage[name] = notify.MultiStage{meshStage, silencingStage, inhibitionStage, stage}
 	}
 
@@ -433,6 +445,8 @@ func (am *Alertmanager) applyConfig(cfg *apimodels.PostableUserConfig, rawConfig
 
 	am.config = cfg
 	am.configHash = md5.Sum(rawConfig)
+	am.integrations = integrationsMap
+	am.integrationUIDs = uids
 
 	return nil
 }
@@ -520,6 +534,12 @@ func (am *Alertmanager) buildReceiverIntegration(r *apimodels.PostableGrafanaRec
 		n, err = channels.NewDingDingNotifier(cfg, tmpl)
 	case "kafka":
 		n, err = channels.NewKafkaNotifier(cfg, tmpl)
+	case "mqtt":
+		n, err = channels.NewMqttNotifier(cfg, tmpl, am.decryptFn)
+	case "amqp":
+		n, err = channels.NewAmqpNotifier(cfg, tmpl, am.decryptFn)
+	case "sns":
+		n, err = channels.NewSnsNotifier(cfg, tmpl, am.decryptFn)
 	case "webhook":
 		n, err = channels.NewWebHookNotifier(cfg, tmpl, am.decryptFn)
 	case "sensugo":
@@ -692,7 +712,8 @@ func (e AlertValidationError) Error() string {
 }
 
 // createReceiverStage creates a pipeline of stages for a receiver.
-func (am *Alertmanager) createReceiverStage(name string, integrations []notify.Integration, wait func() time.Duration, notificationLog notify.NotificationLog) notify.Stage {
+// uids are the UIDs of the Grafana managed receivers of the integrations, in the same order.
+func (am *Alertmanager) createReceiverStage(name string, integrations []notify.Integration, uids []string, wait func() time.Duration, notificationLog notify.NotificationLog) notify.Stage {
 	var fs notify.FanoutStage
 	for i := range integrations {
 		recv := &nflogpb.Receiver{
@@ -703,7 +724,11 @@ func (am *Alertmanager) createReceiverStage(name string, integrations []notify.I
 		var s notify.MultiStage
 		s = append(s, notify.NewWaitStage(wait))
 		s = append(s, notify.NewDedupStage(&integrations[i], notificationLog, recv))
-		s = append(s, notify.NewRetryStage(integrations[i], name, am.stageMetrics))
+		var uid string
+		if i < len(uids) {
+			uid = uids[i]
+		}
+		s = append(s, am.newDeliveryStage(name, integrations[i], uid, notify.NewRetryStage(integrations[i], name, am.stageMetrics)))
 		s = append(s, notify.NewSetNotifiesStage(notificationLog, recv))
 
 		fs = append(fs, s)
@@ -0,0 +1,172 @@
+package channels
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/streadway/amqp"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	old_notifiers "github.com/grafana/grafana/pkg/services/alerting/notifiers"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const amqpTimeout = 30 * time.Second
+
+// AmqpNotifier is responsible for publishing
+// alert notifications to an AMQP 0-9-1 broker, such as RabbitMQ.
+type AmqpNotifier struct {
+	old_notifiers.NotifierBase
+	URL         string
+	Exchange    string
+	RoutingKey  string
+	Message     string
+	ContentType string
+	Username    string
+	Password    string
+	Config      amqp.Config
+	orgID       int64
+	log         log.Logger
+	tmpl        *template.Template
+}
+
+// NewAmqpNotifier is the constructor function for the AMQP notifier.
+func NewAmqpNotifier(model *NotificationChannelConfig, t *template.Template, fn GetDecryptedValueFn) (*AmqpNotifier, error) {
+	if model.Settings == nil {
+		return nil, receiverInitError{Cfg: *model, Reason: "could not find settings property"}
+	}
+	rawURL := model.Settings.Get("url").MustString()
+	if rawURL == "" {
+		return nil, receiverInitError{Cfg: *model, Reason: "could not find URL property in settings"}
+	}
+	uri, err := amqp.ParseURI(rawURL)
+	if err != nil {
+		return nil, receiverInitError{Cfg: *model, Reason: "invalid URL", Err: err}
+	}
+	routingKey := model.Settings.Get("routingKey").MustString()
+	exchange := model.Settings.Get("exchange").MustString()
+	if routingKey == "" && exchange == "" {
+		return nil, receiverInitError{Cfg: *model, Reason: "could not find exchange or routing key property in settings"}
+	}
+	tlsConfig, err := newTLSConfig(readTLSSettings(model, fn), uri.Host)
+	if err != nil {
+		return nil, receiverInitError{Cfg: *model, Reason: "invalid TLS settings", Err: err}
+	}
+
+	message := model.Settings.Get("message").MustString()
+	contentType := "application/json"
+	if message != "" {
+		contentType = "text/plain"
+	}
+
+	username := model.Settings.Get("username").MustString()
+	password := fn(context.Background(), model.SecureSettings, "password", model.Settings.Get("password").MustString(), setting.SecretKey)
+	config := amqp.Config{
+		Heartbeat:       10 * time.Second,
+		TLSClientConfig: tlsConfig,
+		Dial:            amqp.DefaultDial(amqpTimeout),
+	}
+	// Without a username, the credentials of the URL are used.
+	if username != "" {
+		config.SASL = []amqp.Authentication{&amqp.PlainAuth{Username: username, Password: password}}
+	}
+
+	return &AmqpNotifier{
+		NotifierBase: old_notifiers.NewNotifierBase(&models.AlertNotification{
+			Uid:                   model.UID,
+			Name:                  model.Name,
+			Type:                  model.Type,
+			DisableResolveMessage: model.DisableResolveMessage,
+			Settings:              model.Settings,
+		}),
+		URL:         rawURL,
+		Exchange:    exchange,
+		RoutingKey:  routingKey,
+		Message:     message,
+		ContentType: model.Settings.Get("contentType").MustString(contentType),
+		Username:    username,
+		Password:    password,
+		Config:      config,
+		orgID:       model.OrgID,
+		log:         log.New("alerting.notifier.amqp"),
+		tmpl:        t,
+	}, nil
+}
+
+// Notify publishes the alert notification to the exchange.
+func (an *AmqpNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	payload, err := messagePayload(ctx, an.tmpl, as, an.Message, an.orgID, an.log)
+	if err != nil {
+		return false, err
+	}
+
+	var tmplErr error
+	tmpl, _ := TmplText(ctx, an.tmpl, as, an.log, &tmplErr)
+	routingKey := tmpl(an.RoutingKey)
+	if tmplErr != nil {
+		an.log.Debug("failed to template AMQP routing key", "err", tmplErr.Error())
+	}
+
+	msg := amqp.Publishing{
+		ContentType:  an.ContentType,
+		DeliveryMode: amqp.Persistent,
+		Timestamp:    time.Now(),
+		AppId:        "grafana",
+		Body:         payload,
+	}
+
+	an.log.Debug("Publishing to AMQP broker", "exchange", an.Exchange, "routingKey", routingKey)
+	if err := publishAMQP(ctx, an.URL, an.Config, an.Exchange, routingKey, msg); err != nil {
+		an.log.Error("Failed to publish notification to AMQP broker", "exchange", an.Exchange, "routingKey", routingKey, "err", err)
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (an *AmqpNotifier) SendResolved() bool {
+	return !an.GetDisableResolveMessage()
+}
+
+// publishAMQP connects to an AMQP broker and publishes a message, waiting for the broker to confirm it.
+// Stubbable by tests.
+var publishAMQP = func(ctx context.Context, url string, config amqp.Config, exchange, routingKey string, msg amqp.Publishing) error {
+	conn, err := amqp.DialConfig(url, config)
+	if err != nil {
+		return fmt.Errorf("failed to connect to AMQP broker: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	ch, err := conn.Channel()
+	if err != nil {
+		return fmt.Errorf("failed to open AMQP channel: %w", err)
+	}
+	defer func() { _ = ch.Close() }()
+
+	if err := ch.Confirm(false); err != nil {
+		return fmt.Errorf("failed to enable AMQP publisher confirms: %w", err)
+	}
+	confirms := ch.NotifyPublish(make(chan amqp.Confirmation, 1))
+
+	if err := ch.Publish(exchange, routingKey, false, false, msg); err != nil {
+		return fmt.Errorf("failed to publish AMQP message: %w", err)
+	}
+
+	select {
+	case confirm, ok := <-confirms:
+		if !ok {
+			return errors.New("AMQP channel closed before the message was confirmed")
+		}
+		if !confirm.Ack {
+			return errors.New("AMQP message was rejected by the broker")
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,161 @@
+package channels
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	old_notifiers "github.com/grafana/grafana/pkg/services/alerting/notifiers"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const mqttTimeout = 30 * time.Second
+
+// MqttNotifier is responsible for publishing
+// alert notifications to an MQTT broker.
+type MqttNotifier struct {
+	old_notifiers.NotifierBase
+	BrokerURL string
+	Topic     string
+	Message   string
+	QoS       byte
+	Retain    bool
+	ClientID  string
+	Username  string
+	Password  string
+	TLSConfig *tls.Config
+	orgID     int64
+	log       log.Logger
+	tmpl      *template.Template
+}
+
+// NewMqttNotifier is the constructor function for the MQTT notifier.
+func NewMqttNotifier(model *NotificationChannelConfig, t *template.Template, fn GetDecryptedValueFn) (*MqttNotifier, error) {
+	if model.Settings == nil {
+		return nil, receiverInitError{Cfg: *model, Reason: "could not find settings property"}
+	}
+	brokerURL := model.Settings.Get("brokerUrl").MustString()
+	if brokerURL == "" {
+		return nil, receiverInitError{Cfg: *model, Reason: "could not find broker URL property in settings"}
+	}
+	u, err := url.Parse(brokerURL)
+	if err != nil {
+		return nil, receiverInitError{Cfg: *model, Reason: "invalid broker URL", Err: err}
+	}
+	switch u.Scheme {
+	case "tcp", "mqtt", "ssl", "tls", "mqtts", "ws", "wss":
+	default:
+		return nil, receiverInitError{Cfg: *model, Reason: fmt.Sprintf("unsupported broker URL scheme %q", u.Scheme)}
+	}
+	topic := model.Settings.Get("topic").MustString()
+	if topic == "" {
+		return nil, receiverInitError{Cfg: *model, Reason: "could not find topic property in settings"}
+	}
+	qos, err := strconv.Atoi(model.Settings.Get("qos").MustString("0"))
+	if err != nil || qos < 0 || qos > 2 {
+		return nil, receiverInitError{Cfg: *model, Reason: "invalid QoS, must be 0, 1 or 2"}
+	}
+	tlsConfig, err := newTLSConfig(readTLSSettings(model, fn), u.Hostname())
+	if err != nil {
+		return nil, receiverInitError{Cfg: *model, Reason: "invalid TLS settings", Err: err}
+	}
+
+	return &MqttNotifier{
+		NotifierBase: old_notifiers.NewNotifierBase(&models.AlertNotification{
+			Uid:                   model.UID,
+			Name:                  model.Name,
+			Type:                  model.Type,
+			DisableResolveMessage: model.DisableResolveMessage,
+			Settings:              model.Settings,
+		}),
+		BrokerURL: brokerURL,
+		Topic:     topic,
+		Message:   model.Settings.Get("message").MustString(),
+		QoS:       byte(qos),
+		Retain:    model.Settings.Get("retain").MustBool(false),
+		ClientID:  model.Settings.Get("clientId").MustString(),
+		Username:  model.Settings.Get("username").MustString(),
+		Password:  fn(context.Background(), model.SecureSettings, "password", model.Settings.Get("password").MustString(), setting.SecretKey),
+		TLSConfig: tlsConfig,
+		orgID:     model.OrgID,
+		log:       log.New("alerting.notifier.mqtt"),
+		tmpl:      t,
+	}, nil
+}
+
+// Notify publishes the alert notification to the topic.
+func (mn *MqttNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	payload, err := messagePayload(ctx, mn.tmpl, as, mn.Message, mn.orgID, mn.log)
+	if err != nil {
+		return false, err
+	}
+
+	var tmplErr error
+	tmpl, _ := TmplText(ctx, mn.tmpl, as, mn.log, &tmplErr)
+	topic := tmpl(mn.Topic)
+	if tmplErr != nil {
+		mn.log.Debug("failed to template MQTT topic", "err", tmplErr.Error())
+	}
+
+	// Each notification uses its own connection, and brokers disconnect a client when
+	// another one connects with the same ID, so the client ID is unique by default.
+	clientID := mn.ClientID
+	if clientID == "" {
+		clientID = "grafana-" + util.GenerateShortUID()
+	}
+
+	opts := mqtt.NewClientOptions().
+		AddBroker(mn.BrokerURL).
+		SetClientID(clientID).
+		SetUsername(mn.Username).
+		SetPassword(mn.Password).
+		SetTLSConfig(mn.TLSConfig).
+		SetConnectTimeout(mqttTimeout).
+		SetAutoReconnect(false)
+
+	mn.log.Debug("Publishing to MQTT broker", "broker", mn.BrokerURL, "topic", topic)
+	if err := publishMQTT(ctx, opts, topic, mn.QoS, mn.Retain, payload); err != nil {
+		mn.log.Error("Failed to publish notification to MQTT broker", "broker", mn.BrokerURL, "topic", topic, "err", err)
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (mn *MqttNotifier) SendResolved() bool {
+	return !mn.GetDisableResolveMessage()
+}
+
+// publishMQTT connects to an MQTT broker and publishes a message.
+// Stubbable by tests.
+var publishMQTT = func(ctx context.Context, opts *mqtt.ClientOptions, topic string, qos byte, retain bool, payload []byte) error {
+	client := mqtt.NewClient(opts)
+	if err := waitMQTTToken(ctx, client.Connect()); err != nil {
+		return fmt.Errorf("failed to connect to MQTT broker: %w", err)
+	}
+	defer client.Disconnect(250)
+
+	if err := waitMQTTToken(ctx, client.Publish(topic, qos, retain, payload)); err != nil {
+		return fmt.Errorf("failed to publish MQTT message: %w", err)
+	}
+	return nil
+}
+
+func waitMQTTToken(ctx context.Context, token mqtt.Token) error {
+	select {
+	case <-token.Done():
+		return token.Error()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
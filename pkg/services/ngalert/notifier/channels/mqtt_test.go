@@ -0,0 +1,183 @@
+package channels
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"testing"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/encryption/ossencryption"
+)
+
+func TestMqttNotifier(t *testing.T) {
+	tmpl := templateForTests(t)
+
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	alerts := []*types.Alert{
+		{
+			Alert: model.Alert{
+				Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+				Annotations: model.LabelSet{"ann1": "annv1"},
+			},
+		},
+	}
+
+	cases := []struct {
+		name         string
+		settings     string
+		expBroker    string
+		expTopic     string
+		expQoS       byte
+		expRetain    bool
+		expUsername  string
+		expPassword  string
+		expClientID  string
+		expMsg       string
+		expInitError string
+		publishErr   error
+	}{
+		{
+			name: "Default config",
+			settings: `{
+				"brokerUrl": "tcp://localhost:1883",
+				"topic": "grafana/alerts"
+			}`,
+			expBroker: "tcp://localhost:1883",
+			expTopic:  "grafana/alerts",
+		}, {
+			name: "Custom config with templated topic and message",
+			settings: `{
+				"brokerUrl": "ssl://localhost:8883",
+				"topic": "grafana/{{ .CommonLabels.lbl1 }}",
+				"message": "{{ .Status }}: {{ .CommonLabels.alertname }}",
+				"qos": "1",
+				"retain": true,
+				"clientId": "grafana",
+				"username": "user",
+				"password": "secret"
+			}`,
+			expBroker:   "ssl://localhost:8883",
+			expTopic:    "grafana/val1",
+			expQoS:      1,
+			expRetain:   true,
+			expUsername: "user",
+			expPassword: "secret",
+			expClientID: "grafana",
+			expMsg:      "firing: alert1",
+		}, {
+			name: "Error when publishing",
+			settings: `{
+				"brokerUrl": "tcp://localhost:1883",
+				"topic": "grafana/alerts"
+			}`,
+			publishErr: errors.New("connection refused"),
+		}, {
+			name:         "Error in initing: missing broker URL",
+			settings:     `{"topic": "grafana/alerts"}`,
+			expInitError: `failed to validate receiver "MQTT" of type "mqtt": could not find broker URL property in settings`,
+		}, {
+			name:         "Error in initing: unsupported scheme",
+			settings:     `{"brokerUrl": "http://localhost:1883", "topic": "grafana/alerts"}`,
+			expInitError: `failed to validate receiver "MQTT" of type "mqtt": unsupported broker URL scheme "http"`,
+		}, {
+			name:         "Error in initing: missing topic",
+			settings:     `{"brokerUrl": "tcp://localhost:1883"}`,
+			expInitError: `failed to validate receiver "MQTT" of type "mqtt": could not find topic property in settings`,
+		}, {
+			name:         "Error in initing: invalid QoS",
+			settings:     `{"brokerUrl": "tcp://localhost:1883", "topic": "grafana/alerts", "qos": "3"}`,
+			expInitError: `failed to validate receiver "MQTT" of type "mqtt": invalid QoS, must be 0, 1 or 2`,
+		}, {
+			name:         "Error in initing: invalid CA certificate",
+			settings:     `{"brokerUrl": "ssl://localhost:8883", "topic": "grafana/alerts", "tlsCACert": "invalid"}`,
+			expInitError: `failed to validate receiver "MQTT" of type "mqtt": invalid TLS settings: failed to parse the CA certificate`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			settingsJSON, err := simplejson.NewJson([]byte(c.settings))
+			require.NoError(t, err)
+
+			m := &NotificationChannelConfig{
+				OrgID:    1,
+				Name:     "MQTT",
+				Type:     "mqtt",
+				Settings: settingsJSON,
+			}
+
+			decryptFn := ossencryption.ProvideService().GetDecryptedValue
+			mn, err := NewMqttNotifier(m, tmpl, decryptFn)
+			if c.expInitError != "" {
+				require.Error(t, err)
+				require.Equal(t, c.expInitError, err.Error())
+				return
+			}
+			require.NoError(t, err)
+
+			var (
+				opts          *mqtt.ClientOptions
+				topic         string
+				qos           byte
+				retain        bool
+				payload       []byte
+				origPublisher = publishMQTT
+			)
+			t.Cleanup(func() {
+				publishMQTT = origPublisher
+			})
+			publishMQTT = func(_ context.Context, o *mqtt.ClientOptions, tp string, q byte, r bool, p []byte) error {
+				opts, topic, qos, retain, payload = o, tp, q, r, p
+				return c.publishErr
+			}
+
+			ctx := notify.WithGroupKey(context.Background(), "alertname")
+			ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+			ok, err := mn.Notify(ctx, alerts...)
+			if c.publishErr != nil {
+				require.False(t, ok)
+				require.ErrorIs(t, err, c.publishErr)
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, ok)
+
+			require.Len(t, opts.Servers, 1)
+			require.Equal(t, c.expBroker, opts.Servers[0].String())
+			require.Equal(t, c.expUsername, opts.Username)
+			require.Equal(t, c.expPassword, opts.Password)
+			if c.expClientID != "" {
+				require.Equal(t, c.expClientID, opts.ClientID)
+			} else {
+				require.Regexp(t, "^grafana-", opts.ClientID)
+			}
+			require.Equal(t, c.expTopic, topic)
+			require.Equal(t, c.expQoS, qos)
+			require.Equal(t, c.expRetain, retain)
+
+			if c.expMsg != "" {
+				require.Equal(t, c.expMsg, string(payload))
+				return
+			}
+			// Without a message, the payload is the one of the webhook notifier.
+			var msg webhookMessage
+			require.NoError(t, json.Unmarshal(payload, &msg))
+			require.Equal(t, "1", msg.Version)
+			require.Equal(t, "alertname", msg.GroupKey)
+			require.Equal(t, int64(1), msg.OrgID)
+			require.Equal(t, "[FIRING:1]  (val1)", msg.Title)
+			require.Len(t, msg.Alerts, 1)
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package channels
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	v4 "github.com/aws/aws-sdk-go/aws/signer/v4"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	old_notifiers "github.com/grafana/grafana/pkg/services/alerting/notifiers"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	snsAPIVersion     = "2010-03-31"
+	snsContentType    = "application/x-www-form-urlencoded; charset=utf-8"
+	snsMaxSubjectSize = 100
+)
+
+// SnsNotifier is responsible for publishing alert notifications to an AWS SNS topic,
+// or to any service implementing the SNS Publish API, with requests signed with AWS Signature Version 4.
+type SnsNotifier struct {
+	old_notifiers.NotifierBase
+	Endpoint    string
+	Region      string
+	TopicARN    string
+	Subject     string
+	Message     string
+	Credentials *credentials.Credentials
+	orgID       int64
+	log         log.Logger
+	tmpl        *template.Template
+}
+
+// NewSnsNotifier is the constructor function for the SNS notifier.
+func NewSnsNotifier(model *NotificationChannelConfig, t *template.Template, fn GetDecryptedValueFn) (*SnsNotifier, error) {
+	if model.Settings == nil {
+		return nil, receiverInitError{Cfg: *model, Reason: "could not find settings property"}
+	}
+	topicARN := model.Settings.Get("topicArn").MustString()
+	if topicARN == "" {
+		return nil, receiverInitError{Cfg: *model, Reason: "could not find topic ARN property in settings"}
+	}
+	region := model.Settings.Get("region").MustString()
+	if region == "" {
+		// The region of AWS topics is part of their ARN.
+		if a, err := arn.Parse(topicARN); err == nil {
+			region = a.Region
+		}
+	}
+	if region == "" {
+		return nil, receiverInitError{Cfg: *model, Reason: "could not find region property in settings"}
+	}
+	endpoint := model.Settings.Get("endpoint").MustString(fmt.Sprintf("https://sns.%s.amazonaws.com/", region))
+	if _, err := url.Parse(endpoint); err != nil {
+		return nil, receiverInitError{Cfg: *model, Reason: "invalid endpoint", Err: err}
+	}
+
+	accessKey := model.Settings.Get("accessKey").MustString()
+	secretKey := fn(context.Background(), model.SecureSettings, "secretKey", model.Settings.Get("secretKey").MustString(), setting.SecretKey)
+	// The credentials of the Grafana server are never used, as contact points can
+	// send notifications to any endpoint.
+	if accessKey == "" || secretKey == "" {
+		return nil, receiverInitError{Cfg: *model, Reason: "both the access key and the secret key must be set"}
+	}
+	creds := credentials.NewStaticCredentials(accessKey, secretKey, "")
+
+	return &SnsNotifier{
+		NotifierBase: old_notifiers.NewNotifierBase(&models.AlertNotification{
+			Uid:                   model.UID,
+			Name:                  model.Name,
+			Type:                  model.Type,
+			DisableResolveMessage: model.DisableResolveMessage,
+			Settings:              model.Settings,
+		}),
+		Endpoint:    endpoint,
+		Region:      region,
+		TopicARN:    topicARN,
+		Subject:     model.Settings.Get("subject").MustString(`{{ template "default.title" . }}`),
+		Message:     model.Settings.Get("message").MustString(),
+		Credentials: creds,
+		orgID:       model.OrgID,
+		log:         log.New("alerting.notifier.sns"),
+		tmpl:        t,
+	}, nil
+}
+
+// Notify publishes the alert notification to the SNS topic.
+func (sn *SnsNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	payload, err := messagePayload(ctx, sn.tmpl, as, sn.Message, sn.orgID, sn.log)
+	if err != nil {
+		return false, err
+	}
+
+	var tmplErr error
+	tmpl, _ := TmplText(ctx, sn.tmpl, as, sn.log, &tmplErr)
+	subject := snsSubject(tmpl(sn.Subject))
+	if tmplErr != nil {
+		sn.log.Debug("failed to template SNS subject", "err", tmplErr.Error())
+	}
+
+	form := url.Values{}
+	form.Set("Action", "Publish")
+	form.Set("Version", snsAPIVersion)
+	form.Set("TopicArn", sn.TopicARN)
+	form.Set("Message", string(payload))
+	if subject != "" {
+		form.Set("Subject", subject)
+	}
+	body := form.Encode()
+
+	headers, err := sn.sign(body)
+	if err != nil {
+		return false, fmt.Errorf("failed to sign SNS request: %w", err)
+	}
+
+	cmd := &models.SendWebhookSync{
+		Url:         sn.Endpoint,
+		Body:        body,
+		HttpMethod:  http.MethodPost,
+		HttpHeader:  headers,
+		ContentType: snsContentType,
+	}
+
+	if err := bus.DispatchCtx(ctx, cmd); err != nil {
+		sn.log.Error("Failed to publish notification to SNS", "topic", sn.TopicARN, "error", err)
+		return false, err
+	}
+
+	return true, nil
+}
+
+// sign returns the headers that authenticate a Publish request with the body.
+func (sn *SnsNotifier) sign(body string) (map[string]string, error) {
+	req, err := http.NewRequest(http.MethodPost, sn.Endpoint, strings.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", snsContentType)
+
+	if _, err := v4.NewSigner(sn.Credentials).Sign(req, strings.NewReader(body), "sns", sn.Region, time.Now()); err != nil {
+		return nil, err
+	}
+
+	headers := make(map[string]string)
+	for _, h := range []string{"Authorization", "X-Amz-Date", "X-Amz-Security-Token"} {
+		if v := req.Header.Get(h); v != "" {
+			headers[h] = v
+		}
+	}
+	return headers, nil
+}
+
+func (sn *SnsNotifier) SendResolved() bool {
+	return !sn.GetDisableResolveMessage()
+}
+
+// snsSubject returns the subject as SNS accepts it: a single line of at most 100 characters.
+func snsSubject(subject string) string {
+	subject = strings.TrimSpace(strings.ReplaceAll(subject, "\n", " "))
+	if r := []rune(subject); len(r) > snsMaxSubjectSize {
+		subject = string(r[:snsMaxSubjectSize-1]) + "…"
+	}
+	return subject
+}
@@ -0,0 +1,154 @@
+package channels
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/encryption/ossencryption"
+)
+
+func TestSnsNotifier(t *testing.T) {
+	tmpl := templateForTests(t)
+
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	alerts := []*types.Alert{
+		{
+			Alert: model.Alert{
+				Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+				Annotations: model.LabelSet{"ann1": "annv1"},
+			},
+		},
+	}
+
+	cases := []struct {
+		name          string
+		settings      string
+		expURL        string
+		expCredential string
+		expSubject    string
+		expMsg        string
+		expInitError  string
+	}{
+		{
+			name: "Default config with the region of the topic ARN",
+			settings: `{
+				"topicArn": "arn:aws:sns:eu-west-1:123456789012:alerts",
+				"accessKey": "AKIDEXAMPLE",
+				"secretKey": "secret"
+			}`,
+			expURL:        "https://sns.eu-west-1.amazonaws.com/",
+			expCredential: "AKIDEXAMPLE/", // followed by the date
+			expSubject:    "[FIRING:1]  (val1)",
+		}, {
+			name: "Custom endpoint, subject and message",
+			settings: `{
+				"topicArn": "arn:aws:sns:us-east-1:000000000000:alerts",
+				"region": "us-east-1",
+				"endpoint": "http://localhost:4566/",
+				"accessKey": "test",
+				"secretKey": "test",
+				"subject": "{{ .Status }}: {{ .CommonLabels.alertname }}",
+				"message": "{{ len .Alerts }} alerts"
+			}`,
+			expURL:        "http://localhost:4566/",
+			expCredential: "test/",
+			expSubject:    "firing: alert1",
+			expMsg:        "1 alerts",
+		}, {
+			name:         "Error in initing: missing topic ARN",
+			settings:     `{"region": "us-east-1"}`,
+			expInitError: `failed to validate receiver "SNS" of type "sns": could not find topic ARN property in settings`,
+		}, {
+			name:         "Error in initing: missing region",
+			settings:     `{"topicArn": "alerts"}`,
+			expInitError: `failed to validate receiver "SNS" of type "sns": could not find region property in settings`,
+		}, {
+			name: "Error in initing: missing secret key",
+			settings: `{
+				"topicArn": "arn:aws:sns:us-east-1:123456789012:alerts",
+				"accessKey": "AKIDEXAMPLE"
+			}`,
+			expInitError: `failed to validate receiver "SNS" of type "sns": both the access key and the secret key must be set`,
+		}, {
+			name:         "Error in initing: missing keys",
+			settings:     `{"topicArn": "arn:aws:sns:us-east-1:123456789012:alerts"}`,
+			expInitError: `failed to validate receiver "SNS" of type "sns": both the access key and the secret key must be set`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			settingsJSON, err := simplejson.NewJson([]byte(c.settings))
+			require.NoError(t, err)
+
+			m := &NotificationChannelConfig{
+				OrgID:    1,
+				Name:     "SNS",
+				Type:     "sns",
+				Settings: settingsJSON,
+			}
+
+			decryptFn := ossencryption.ProvideService().GetDecryptedValue
+			sn, err := NewSnsNotifier(m, tmpl, decryptFn)
+			if c.expInitError != "" {
+				require.Error(t, err)
+				require.Equal(t, c.expInitError, err.Error())
+				return
+			}
+			require.NoError(t, err)
+
+			var webhook *models.SendWebhookSync
+			bus.AddHandlerCtx("test", func(ctx context.Context, cmd *models.SendWebhookSync) error {
+				webhook = cmd
+				return nil
+			})
+
+			ctx := notify.WithGroupKey(context.Background(), "alertname")
+			ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+			ok, err := sn.Notify(ctx, alerts...)
+			require.NoError(t, err)
+			require.True(t, ok)
+
+			require.Equal(t, c.expURL, webhook.Url)
+			require.Equal(t, "POST", webhook.HttpMethod)
+			require.Equal(t, snsContentType, webhook.ContentType)
+			require.True(t, strings.HasPrefix(webhook.HttpHeader["Authorization"], "AWS4-HMAC-SHA256 Credential="+c.expCredential))
+			require.Contains(t, webhook.HttpHeader["Authorization"], "/"+sn.Region+"/sns/aws4_request")
+			require.NotEmpty(t, webhook.HttpHeader["X-Amz-Date"])
+
+			form, err := url.ParseQuery(webhook.Body)
+			require.NoError(t, err)
+			require.Equal(t, "Publish", form.Get("Action"))
+			require.Equal(t, snsAPIVersion, form.Get("Version"))
+			require.Equal(t, sn.TopicARN, form.Get("TopicArn"))
+			require.Equal(t, c.expSubject, form.Get("Subject"))
+			if c.expMsg != "" {
+				require.Equal(t, c.expMsg, form.Get("Message"))
+			} else {
+				require.Contains(t, form.Get("Message"), `"groupKey":"alertname"`)
+			}
+		})
+	}
+}
+
+func TestSnsSubject(t *testing.T) {
+	require.Equal(t, "[FIRING:2] alert (val1)", snsSubject("[FIRING:2] alert (val1)\n"))
+	require.Equal(t, "first line second line", snsSubject("first line\nsecond line"))
+
+	long := snsSubject(strings.Repeat("a", 150))
+	require.Len(t, []rune(long), snsMaxSubjectSize)
+	require.True(t, strings.HasSuffix(long, "…"))
+}
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"context"
 	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -14,9 +16,12 @@ import (
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/util"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
 	"github.com/prometheus/common/model"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 const (
@@ -126,6 +131,67 @@ var sendHTTPRequest = func(ctx context.Context, url *url.URL, cfg httpCfg, logge
 	return respBody, nil
 }
 
+// tlsSettings are the TLS settings of the notifiers that connect to a message broker.
+type tlsSettings struct {
+	InsecureSkipVerify bool
+	CACert             string
+	ClientCert         string
+	ClientKey          string
+}
+
+// readTLSSettings reads the TLS settings of a notifier, the client key being a secure setting.
+func readTLSSettings(model *NotificationChannelConfig, fn GetDecryptedValueFn) tlsSettings {
+	return tlsSettings{
+		InsecureSkipVerify: model.Settings.Get("tlsSkipVerify").MustBool(false),
+		CACert:             model.Settings.Get("tlsCACert").MustString(),
+		ClientCert:         model.Settings.Get("tlsClientCert").MustString(),
+		ClientKey:          fn(context.Background(), model.SecureSettings, "tlsClientKey", model.Settings.Get("tlsClientKey").MustString(), setting.SecretKey),
+	}
+}
+
+// newTLSConfig returns the TLS configuration of the settings.
+func newTLSConfig(s tlsSettings, serverName string) (*tls.Config, error) {
+	cfg := &tls.Config{
+		ServerName:         serverName,
+		InsecureSkipVerify: s.InsecureSkipVerify,
+	}
+
+	if s.CACert != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(s.CACert)) {
+			return nil, errors.New("failed to parse the CA certificate")
+		}
+		cfg.RootCAs = pool
+	}
+
+	if s.ClientCert != "" || s.ClientKey != "" {
+		cert, err := tls.X509KeyPair([]byte(s.ClientCert), []byte(s.ClientKey))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
+
+// messagePayload returns the payload of a notifier that publishes to a message broker: the
+// templated message if there is one, and the JSON body of a webhook otherwise.
+func messagePayload(ctx context.Context, t *template.Template, as []*types.Alert, message string, orgID int64, logger log.Logger) ([]byte, error) {
+	if message == "" {
+		return webhookPayload(ctx, t, as, 0, orgID, logger)
+	}
+
+	var tmplErr error
+	tmpl, _ := TmplText(ctx, t, as, logger, &tmplErr)
+	payload := tmpl(message)
+	if tmplErr != nil {
+		logger.Debug("failed to template message", "err", tmplErr.Error())
+	}
+
+	return []byte(payload), nil
+}
+
 func joinUrlPath(base, additionalPath string, logger log.Logger) string {
 	u, err := url.Parse(base)
 	if err != nil {
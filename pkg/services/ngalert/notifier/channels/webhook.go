@@ -77,20 +77,43 @@ type webhookMessage struct {
 
 // Notify implements the Notifier interface.
 func (wn *WebhookNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
-	groupKey, err := notify.ExtractGroupKey(ctx)
+	as, numTruncated := truncateAlerts(wn.MaxAlerts, as)
+	body, err := webhookPayload(ctx, wn.tmpl, as, numTruncated, wn.orgID, wn.log)
 	if err != nil {
 		return false, err
 	}
 
-	as, numTruncated := truncateAlerts(wn.MaxAlerts, as)
+	cmd := &models.SendWebhookSync{
+		Url:        wn.URL,
+		User:       wn.User,
+		Password:   wn.Password,
+		Body:       string(body),
+		HttpMethod: wn.HTTPMethod,
+	}
+
+	if err := bus.DispatchCtx(ctx, cmd); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// webhookPayload returns the JSON body of a webhook for the alerts. It is also
+// the default payload of the notifiers that publish to a message broker.
+func webhookPayload(ctx context.Context, t *template.Template, as []*types.Alert, numTruncated int, orgID int64, logger log.Logger) ([]byte, error) {
+	groupKey, err := notify.ExtractGroupKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	var tmplErr error
-	tmpl, data := TmplText(ctx, wn.tmpl, as, wn.log, &tmplErr)
+	tmpl, data := TmplText(ctx, t, as, logger, &tmplErr)
 	msg := &webhookMessage{
 		Version:         "1",
 		ExtendedData:    data,
 		GroupKey:        groupKey.String(),
 		TruncatedAlerts: numTruncated,
-		OrgID:           wn.orgID,
+		OrgID:           orgID,
 		Title:           tmpl(`{{ template "default.title" . }}`),
 		Message:         tmpl(`{{ template "default.message" . }}`),
 	}
@@ -101,27 +124,10 @@ func (wn *WebhookNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool
 	}
 
 	if tmplErr != nil {
-		wn.log.Debug("failed to template webhook message", "err", tmplErr.Error())
-	}
-
-	body, err := json.Marshal(msg)
-	if err != nil {
-		return false, err
+		logger.Debug("failed to template webhook message", "err", tmplErr.Error())
 	}
 
-	cmd := &models.SendWebhookSync{
-		Url:        wn.URL,
-		User:       wn.User,
-		Password:   wn.Password,
-		Body:       string(body),
-		HttpMethod: wn.HTTPMethod,
-	}
-
-	if err := bus.DispatchCtx(ctx, cmd); err != nil {
-		return false, err
-	}
-
-	return true, nil
+	return json.Marshal(msg)
 }
 
 func truncateAlerts(maxAlerts int, alerts []*types.Alert) ([]*types.Alert, int) {
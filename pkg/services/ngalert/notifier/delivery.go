@@ -0,0 +1,313 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	// notify.Stage takes the logger of github.com/go-kit/log rather than github.com/go-kit/kit/log
+	gokit_log "github.com/go-kit/log"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+const (
+	// notificationRetryPollInterval is how often the retry queue is checked for notifications that are due.
+	notificationRetryPollInterval = 10 * time.Second
+	// notificationRetryBatchSize is the maximum number of notifications retried at each poll.
+	notificationRetryBatchSize = 100
+	// notificationRetryTimeout is the timeout of a single retry of a notification.
+	notificationRetryTimeout = time.Minute
+	// notificationRetryMaxBackoff caps the delay between two retries of a notification.
+	notificationRetryMaxBackoff = 6 * time.Hour
+)
+
+// deliveryStage records the outcome of sending a notification to an integration in the
+// notification delivery log. Notifications that failed are added to the retry queue,
+// which then owns them, so that they survive restarts. As a notification has the current
+// state of the alerts of its group, it supersedes the notification of the group in the queue.
+type deliveryStage struct {
+	am          *Alertmanager
+	receiver    string
+	integration notify.Integration
+	uid         string
+	next        notify.Stage
+}
+
+func (am *Alertmanager) newDeliveryStage(receiver string, integration notify.Integration, uid string, next notify.Stage) *deliveryStage {
+	return &deliveryStage{
+		am:          am,
+		receiver:    receiver,
+		integration: integration,
+		uid:         uid,
+		next:        next,
+	}
+}
+
+// Exec implements the notify.Stage interface.
+func (s *deliveryStage) Exec(ctx context.Context, l gokit_log.Logger, alerts ...*types.Alert) (context.Context, []*types.Alert, error) {
+	ctx, res, err := s.next.Exec(ctx, l, alerts...)
+	groupKey, _ := notify.GroupKey(ctx)
+
+	if err == nil {
+		s.am.logDelivery(s.receiver, s.integration, s.uid, groupKey, alerts, ngmodels.NotificationDeliverySent, nil, 0)
+		s.am.deleteGroupNotificationRetries(s.receiver, s.uid, groupKey)
+		return ctx, res, nil
+	}
+
+	if s.am.Settings.UnifiedAlerting.NotificationRetryMaxAttempts <= 0 {
+		s.am.logDelivery(s.receiver, s.integration, s.uid, groupKey, alerts, ngmodels.NotificationDeliveryDropped, err, 0)
+		return ctx, res, err
+	}
+
+	groupLabels, _ := notify.GroupLabels(ctx)
+	if qerr := s.am.enqueueNotificationRetry(s.receiver, s.integration, s.uid, groupKey, groupLabels, alerts, err); qerr != nil {
+		s.am.logger.Error("failed to add notification to the retry queue", "receiver", s.receiver, "integration", s.integration.Name(), "err", qerr)
+		s.am.logDelivery(s.receiver, s.integration, s.uid, groupKey, alerts, ngmodels.NotificationDeliveryDropped, err, 0)
+		return ctx, res, err
+	}
+
+	s.am.logDelivery(s.receiver, s.integration, s.uid, groupKey, alerts, ngmodels.NotificationDeliveryFailed, err, 0)
+	// The retry queue is now responsible for the notification, so it is recorded as sent
+	// in the notification log to not send it again at the next flush of the alert group.
+	return ctx, alerts, nil
+}
+
+// logDelivery saves an entry in the notification delivery log for each of the rules of the alerts.
+func (am *Alertmanager) logDelivery(receiver string, integration notify.Integration, uid, groupKey string, alerts []*types.Alert,
+	state ngmodels.NotificationDeliveryState, err error, attempt int) {
+	alertsPerRule := make(map[string]int)
+	var rules []string
+	for _, a := range alerts {
+		ruleUID := string(a.Labels[model.LabelName(ngmodels.RuleUIDLabel)])
+		if _, ok := alertsPerRule[ruleUID]; !ok {
+			rules = append(rules, ruleUID)
+		}
+		alertsPerRule[ruleUID]++
+	}
+
+	var errMsg string
+	if err != nil {
+		errMsg = err.Error()
+	}
+
+	now := time.Now()
+	cmd := ngmodels.SaveNotificationDeliveriesCommand{
+		Entries: make([]*ngmodels.NotificationDeliveryEntry, 0, len(rules)),
+	}
+	for _, ruleUID := range rules {
+		cmd.Entries = append(cmd.Entries, &ngmodels.NotificationDeliveryEntry{
+			OrgID:            am.orgID,
+			Receiver:         receiver,
+			Integration:      integration.Name(),
+			IntegrationIndex: integration.Index(),
+			IntegrationUID:   uid,
+			RuleUID:          ruleUID,
+			GroupKey:         groupKey,
+			Alerts:           alertsPerRule[ruleUID],
+			State:            state,
+			Error:            errMsg,
+			Attempt:          attempt,
+			SentAt:           now,
+		})
+	}
+
+	// The context of the notification might have expired, so it is not used to save the entries.
+	if err := am.Store.SaveNotificationDeliveries(context.Background(), &cmd); err != nil {
+		am.logger.Error("failed to save notification delivery", "receiver", receiver, "integration", integration.Name(), "err", err)
+	}
+}
+
+func (am *Alertmanager) enqueueNotificationRetry(receiver string, integration notify.Integration, uid, groupKey string,
+	groupLabels model.LabelSet, alerts []*types.Alert, notifyErr error) error {
+	rawAlerts, err := json.Marshal(alerts)
+	if err != nil {
+		return fmt.Errorf("failed to serialize alerts: %w", err)
+	}
+	rawGroupLabels, err := json.Marshal(groupLabels)
+	if err != nil {
+		return fmt.Errorf("failed to serialize group labels: %w", err)
+	}
+
+	return am.Store.EnqueueNotificationRetry(context.Background(), &ngmodels.EnqueueNotificationRetryCommand{
+		OrgID:            am.orgID,
+		Receiver:         receiver,
+		Integration:      integration.Name(),
+		IntegrationIndex: integration.Index(),
+		IntegrationUID:   uid,
+		GroupKey:         groupKey,
+		GroupLabels:      string(rawGroupLabels),
+		Alerts:           string(rawAlerts),
+		Error:            notifyErr.Error(),
+		NextAttemptAt:    time.Now().Add(am.notificationRetryBackoff(0)),
+	})
+}
+
+// notificationRetryBackoff returns the delay before the next retry of a notification
+// that failed the given number of retries.
+func (am *Alertmanager) notificationRetryBackoff(attempts int) time.Duration {
+	backoff := am.Settings.UnifiedAlerting.NotificationRetryInterval
+	for i := 0; i < attempts && backoff < notificationRetryMaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > notificationRetryMaxBackoff {
+		backoff = notificationRetryMaxBackoff
+	}
+	return backoff
+}
+
+// runNotificationRetries retries the notifications of the retry queue that are due, until the Alertmanager is stopped.
+func (am *Alertmanager) runNotificationRetries() {
+	ticker := time.NewTicker(notificationRetryPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			am.retryNotifications()
+		case <-am.stopc:
+			return
+		}
+	}
+}
+
+func (am *Alertmanager) retryNotifications() {
+	// In a cluster, the queue is shared through the database, so only the first peer retries notifications.
+	if !am.Ready() || am.peer.Position() != 0 {
+		return
+	}
+
+	query := ngmodels.GetDueNotificationRetriesQuery{
+		OrgID: am.orgID,
+		Now:   time.Now(),
+		Limit: notificationRetryBatchSize,
+	}
+	if err := am.Store.GetDueNotificationRetries(context.Background(), &query); err != nil {
+		am.logger.Error("failed to get the notifications to retry", "err", err)
+		return
+	}
+
+	for _, r := range query.Result {
+		am.retryNotification(r)
+	}
+}
+
+func (am *Alertmanager) retryNotification(r *ngmodels.NotificationRetry) {
+	logger := am.logger.New("receiver", r.Receiver, "integration", r.Integration, "attempt", r.Attempts+1)
+
+	integration, ok := am.integrationFor(r.Receiver, r.Integration, r.IntegrationUID)
+	if !ok {
+		logger.Warn("dropping notification as its integration does not exist anymore")
+		am.deleteNotificationRetry(r)
+		return
+	}
+
+	var alerts []*types.Alert
+	var groupLabels model.LabelSet
+	if err := json.Unmarshal([]byte(r.Alerts), &alerts); err != nil {
+		logger.Error("dropping notification as its alerts cannot be read", "err", err)
+		am.deleteNotificationRetry(r)
+		return
+	}
+	if err := json.Unmarshal([]byte(r.GroupLabels), &groupLabels); err != nil {
+		logger.Warn("failed to read group labels of notification", "err", err)
+	}
+
+	if !integration.SendResolved() {
+		firing := alerts[:0]
+		for _, a := range alerts {
+			if a.Status() != model.AlertResolved {
+				firing = append(firing, a)
+			}
+		}
+		alerts = firing
+	}
+	if len(alerts) == 0 {
+		am.deleteNotificationRetry(r)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), notificationRetryTimeout)
+	defer cancel()
+	ctx = notify.WithGroupKey(ctx, r.GroupKey)
+	ctx = notify.WithGroupLabels(ctx, groupLabels)
+	ctx = notify.WithReceiverName(ctx, r.Receiver)
+
+	attempt := r.Attempts + 1
+	_, err := integration.Notify(ctx, alerts...)
+	if err == nil {
+		logger.Debug("notification retry succeeded")
+		am.logDelivery(r.Receiver, integration, r.IntegrationUID, r.GroupKey, alerts, ngmodels.NotificationDeliverySent, nil, attempt)
+		am.deleteNotificationRetry(r)
+		return
+	}
+
+	if attempt >= am.Settings.UnifiedAlerting.NotificationRetryMaxAttempts {
+		logger.Error("dropping notification after its last retry failed", "err", err)
+		am.logDelivery(r.Receiver, integration, r.IntegrationUID, r.GroupKey, alerts, ngmodels.NotificationDeliveryDropped, err, attempt)
+		am.deleteNotificationRetry(r)
+		return
+	}
+
+	logger.Warn("notification retry failed", "err", err)
+	am.logDelivery(r.Receiver, integration, r.IntegrationUID, r.GroupKey, alerts, ngmodels.NotificationDeliveryFailed, err, attempt)
+	cmd := ngmodels.UpdateNotificationRetryCommand{
+		ID:            r.ID,
+		Attempts:      attempt,
+		Error:         err.Error(),
+		NextAttemptAt: time.Now().Add(am.notificationRetryBackoff(attempt)),
+	}
+	if err := am.Store.UpdateNotificationRetry(context.Background(), &cmd); err != nil {
+		logger.Error("failed to update notification in the retry queue", "err", err)
+	}
+}
+
+func (am *Alertmanager) deleteNotificationRetry(r *ngmodels.NotificationRetry) {
+	if err := am.Store.DeleteNotificationRetry(context.Background(), &ngmodels.DeleteNotificationRetryCommand{ID: r.ID}); err != nil {
+		am.logger.Error("failed to delete notification from the retry queue", "receiver", r.Receiver, "integration", r.Integration, "err", err)
+	}
+}
+
+// deleteGroupNotificationRetries removes from the retry queue the notification of the alert group
+// to the integration, so that it is not sent after the more recent notification that was sent.
+func (am *Alertmanager) deleteGroupNotificationRetries(receiver, uid, groupKey string) {
+	cmd := ngmodels.DeleteGroupNotificationRetriesCommand{
+		OrgID:          am.orgID,
+		Receiver:       receiver,
+		IntegrationUID: uid,
+		GroupKey:       groupKey,
+	}
+	if err := am.Store.DeleteGroupNotificationRetries(context.Background(), &cmd); err != nil {
+		am.logger.Error("failed to delete notifications from the retry queue", "receiver", receiver, "integration_uid", uid, "err", err)
+	}
+}
+
+// integrationFor returns the integration of the current configuration of the receiver with the given type
+// and the UID of its Grafana managed receiver.
+func (am *Alertmanager) integrationFor(receiver, integrationType, uid string) (notify.Integration, bool) {
+	am.reloadConfigMtx.RLock()
+	defer am.reloadConfigMtx.RUnlock()
+
+	integrations := am.integrations[receiver]
+	for i, u := range am.integrationUIDs[receiver] {
+		if uid != "" && u == uid && i < len(integrations) && integrations[i].Name() == integrationType {
+			return integrations[i], true
+		}
+	}
+	return notify.Integration{}, false
+}
+
+// integrationUIDs returns the UIDs of the Grafana managed receivers of each receiver, in the order of their integrations.
+func integrationUIDs(receivers []*apimodels.PostableApiReceiver) map[string][]string {
+	uids := make(map[string][]string, len(receivers))
+	for _, receiver := range receivers {
+		for _, r := range receiver.GrafanaManagedReceivers {
+			uids[receiver.Name] = append(uids[receiver.Name], r.UID)
+		}
+	}
+	return uids
+}
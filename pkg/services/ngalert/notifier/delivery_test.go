@@ -0,0 +1,159 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	gokit_log "github.com/go-kit/log"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type fakeNotifier struct {
+	err      error
+	notified int
+}
+
+func (n *fakeNotifier) Notify(_ context.Context, _ ...*types.Alert) (bool, error) {
+	n.notified++
+	return false, n.err
+}
+
+func (n *fakeNotifier) SendResolved() bool {
+	return true
+}
+
+func TestDeliveryStage(t *testing.T) {
+	configStore := &FakeConfigStore{configs: map[int64]*models.AlertConfiguration{}}
+	am := &Alertmanager{
+		Settings: &setting.Cfg{UnifiedAlerting: setting.UnifiedAlertingSettings{
+			NotificationRetryInterval:    time.Minute,
+			NotificationRetryMaxAttempts: 2,
+		}},
+		Store:  configStore,
+		logger: log.New("alertmanager-test"),
+		orgID:  1,
+	}
+
+	n := &fakeNotifier{err: errors.New("service unavailable")}
+	integration := notify.NewIntegration(n, n, "webhook", 0)
+	am.integrations = map[string][]notify.Integration{"team": {integration}}
+	am.integrationUIDs = map[string][]string{"team": {"integration-uid"}}
+
+	now := time.Now()
+	alerts := []*types.Alert{
+		{Alert: model.Alert{Labels: model.LabelSet{"alertname": "a", models.RuleUIDLabel: "rule-1"}, StartsAt: now}},
+		{Alert: model.Alert{Labels: model.LabelSet{"alertname": "b", models.RuleUIDLabel: "rule-1"}, StartsAt: now}},
+		{Alert: model.Alert{Labels: model.LabelSet{"alertname": "c", models.RuleUIDLabel: "rule-2"}, StartsAt: now}},
+	}
+	next := notify.StageFunc(func(ctx context.Context, _ gokit_log.Logger, alerts ...*types.Alert) (context.Context, []*types.Alert, error) {
+		_, err := integration.Notify(ctx, alerts...)
+		return ctx, alerts, err
+	})
+	stage := am.newDeliveryStage("team", integration, "integration-uid", next)
+	ctx := notify.WithGroupKey(context.Background(), "group-key")
+	ctx = notify.WithGroupLabels(ctx, model.LabelSet{"team": "a"})
+
+	// A failed notification is queued to be retried instead of failing the pipeline.
+	_, res, err := stage.Exec(ctx, gokit_log.NewNopLogger(), alerts...)
+	require.NoError(t, err)
+	require.Len(t, res, 3)
+	require.Len(t, configStore.retries, 1)
+	require.Equal(t, "group-key", configStore.retries[0].GroupKey)
+	require.Len(t, configStore.deliveries, 2)
+	for _, e := range configStore.deliveries {
+		require.Equal(t, models.NotificationDeliveryFailed, e.State)
+		require.Equal(t, "integration-uid", e.IntegrationUID)
+		require.Equal(t, "service unavailable", e.Error)
+	}
+	require.Equal(t, "rule-1", configStore.deliveries[0].RuleUID)
+	require.Equal(t, 2, configStore.deliveries[0].Alerts)
+	require.Equal(t, "rule-2", configStore.deliveries[1].RuleUID)
+	require.Equal(t, 1, configStore.deliveries[1].Alerts)
+
+	// A failed retry is rescheduled with a longer delay.
+	am.retryNotification(configStore.retries[0])
+	require.Equal(t, 2, n.notified)
+	require.Len(t, configStore.retries, 1)
+	require.Equal(t, 1, configStore.retries[0].Attempts)
+	require.True(t, configStore.retries[0].NextAttemptAt.After(now.Add(time.Minute)))
+
+	// The notification is dropped once the last retry failed.
+	am.retryNotification(configStore.retries[0])
+	require.Equal(t, 3, n.notified)
+	require.Len(t, configStore.retries, 0)
+	last := configStore.deliveries[len(configStore.deliveries)-1]
+	require.Equal(t, models.NotificationDeliveryDropped, last.State)
+	require.Equal(t, 2, last.Attempt)
+
+	// A successful retry removes the notification from the queue.
+	_, _, err = stage.Exec(ctx, gokit_log.NewNopLogger(), alerts...)
+	require.NoError(t, err)
+	require.Len(t, configStore.retries, 1)
+	n.err = nil
+	am.retryNotification(configStore.retries[0])
+	require.Len(t, configStore.retries, 0)
+	last = configStore.deliveries[len(configStore.deliveries)-1]
+	require.Equal(t, models.NotificationDeliverySent, last.State)
+	require.Equal(t, 1, last.Attempt)
+
+	// A more recent notification of the alert group replaces the notification in the queue,
+	// and removes it once it was sent.
+	n.err = errors.New("service unavailable")
+	_, _, err = stage.Exec(ctx, gokit_log.NewNopLogger(), alerts...)
+	require.NoError(t, err)
+	_, _, err = stage.Exec(ctx, gokit_log.NewNopLogger(), alerts[:1]...)
+	require.NoError(t, err)
+	require.Len(t, configStore.retries, 1)
+	require.Contains(t, configStore.retries[0].Alerts, `"alertname":"a"`)
+	require.NotContains(t, configStore.retries[0].Alerts, `"alertname":"b"`)
+	n.err = nil
+	_, _, err = stage.Exec(ctx, gokit_log.NewNopLogger(), alerts...)
+	require.NoError(t, err)
+	require.Len(t, configStore.retries, 0)
+
+	// A notification is retried with the integration with the same UID after the configuration is reloaded.
+	n.err = errors.New("service unavailable")
+	_, _, err = stage.Exec(ctx, gokit_log.NewNopLogger(), alerts...)
+	require.NoError(t, err)
+	other := &fakeNotifier{}
+	am.integrations = map[string][]notify.Integration{"team": {notify.NewIntegration(other, other, "webhook", 0), notify.NewIntegration(n, n, "webhook", 1)}}
+	am.integrationUIDs = map[string][]string{"team": {"other-uid", "integration-uid"}}
+	notified := n.notified
+	n.err = nil
+	am.retryNotification(configStore.retries[0])
+	require.Equal(t, 0, other.notified)
+	require.Equal(t, notified+1, n.notified)
+	require.Len(t, configStore.retries, 0)
+
+	// A notification of an integration that does not exist anymore is dropped.
+	n.err = errors.New("service unavailable")
+	_, _, err = stage.Exec(ctx, gokit_log.NewNopLogger(), alerts...)
+	require.NoError(t, err)
+	am.integrations = map[string][]notify.Integration{"team": {notify.NewIntegration(other, other, "webhook", 0)}}
+	am.integrationUIDs = map[string][]string{"team": {"other-uid"}}
+	am.retryNotification(configStore.retries[0])
+	require.Equal(t, 0, other.notified)
+	require.Len(t, configStore.retries, 0)
+}
+
+func TestNotificationRetryBackoff(t *testing.T) {
+	am := &Alertmanager{
+		Settings: &setting.Cfg{UnifiedAlerting: setting.UnifiedAlertingSettings{
+			NotificationRetryInterval: time.Minute,
+		}},
+	}
+
+	require.Equal(t, time.Minute, am.notificationRetryBackoff(0))
+	require.Equal(t, 2*time.Minute, am.notificationRetryBackoff(1))
+	require.Equal(t, 8*time.Minute, am.notificationRetryBackoff(3))
+	require.Equal(t, notificationRetryMaxBackoff, am.notificationRetryBackoff(100))
+}
@@ -0,0 +1,155 @@
+package notifier
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/prometheus/alertmanager/dispatch"
+	"github.com/prometheus/alertmanager/inhibit"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/common/model"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+var (
+	ErrNoLabels = errors.New("no labels")
+	ErrNoRoute  = errors.New("the configuration has no route")
+)
+
+// TestRoutes returns the routes, receivers and inhibition rules that apply to an alert with the given labels,
+// using the configuration of the request if there is one, and the current configuration otherwise.
+func (am *Alertmanager) TestRoutes(c apimodels.TestRoutesConfig) (apimodels.TestRoutesResult, error) {
+	if len(c.Labels) == 0 {
+		return apimodels.TestRoutesResult{}, ErrNoLabels
+	}
+
+	cfg := c.AlertmanagerConfig
+	if cfg == nil {
+		am.reloadConfigMtx.RLock()
+		if am.ready() {
+			cfg = &am.config.AlertmanagerConfig
+		}
+		am.reloadConfigMtx.RUnlock()
+	}
+	if cfg == nil || cfg.Route == nil {
+		return apimodels.TestRoutesResult{}, ErrNoRoute
+	}
+
+	lset := make(model.LabelSet, len(c.Labels))
+	for k, v := range c.Labels {
+		lset[model.LabelName(k)] = model.LabelValue(v)
+	}
+
+	root := dispatch.NewRoute(cfg.Route.AsAMRoute(), nil)
+	result := apimodels.TestRoutesResult{
+		Routes:      matchRoutes(root, lset, []int{}, nil),
+		Inhibitions: make([]apimodels.TestRouteInhibition, 0),
+	}
+
+	for _, r := range cfg.InhibitRules {
+		rule := inhibit.NewInhibitRule(r)
+		if !rule.TargetMatchers.Matches(lset) {
+			continue
+		}
+		inhibition := apimodels.TestRouteInhibition{
+			SourceMatchers: matcherStrings(rule.SourceMatchers),
+			TargetMatchers: matcherStrings(rule.TargetMatchers),
+			Active:         am.isInhibitedBy(rule, lset),
+		}
+		for _, ln := range r.Equal {
+			inhibition.Equal = append(inhibition.Equal, string(ln))
+		}
+		result.Inhibitions = append(result.Inhibitions, inhibition)
+	}
+
+	return result, nil
+}
+
+// matchRoutes returns the routes of the tree that match the labels, as dispatch.Route.Match does,
+// along with their path and matchers from the root route.
+func matchRoutes(r *dispatch.Route, lset model.LabelSet, path []int, matchers [][]string) []apimodels.TestRouteResult {
+	if !r.Matchers.Matches(lset) {
+		return nil
+	}
+
+	matchers = append(append([][]string{}, matchers...), matcherStrings(r.Matchers))
+
+	var all []apimodels.TestRouteResult
+	for i, cr := range r.Routes {
+		// Copy the path so that the routes do not share the same backing array.
+		childPath := append(append([]int{}, path...), i)
+		matches := matchRoutes(cr, lset, childPath, matchers)
+		all = append(all, matches...)
+
+		if matches != nil && !cr.Continue {
+			break
+		}
+	}
+
+	if len(all) == 0 {
+		all = append(all, newTestRouteResult(r, path, matchers))
+	}
+
+	return all
+}
+
+func newTestRouteResult(r *dispatch.Route, path []int, matchers [][]string) apimodels.TestRouteResult {
+	result := apimodels.TestRouteResult{
+		Path:              path,
+		Matchers:          matchers,
+		Receiver:          r.RouteOpts.Receiver,
+		GroupWait:         model.Duration(r.RouteOpts.GroupWait).String(),
+		GroupInterval:     model.Duration(r.RouteOpts.GroupInterval).String(),
+		RepeatInterval:    model.Duration(r.RouteOpts.RepeatInterval).String(),
+		MuteTimeIntervals: r.RouteOpts.MuteTimeIntervals,
+	}
+
+	if r.RouteOpts.GroupByAll {
+		result.GroupBy = []string{"..."}
+	} else {
+		result.GroupBy = make([]string, 0, len(r.RouteOpts.GroupBy))
+		for ln := range r.RouteOpts.GroupBy {
+			result.GroupBy = append(result.GroupBy, string(ln))
+		}
+		sort.Strings(result.GroupBy)
+	}
+
+	return result
+}
+
+// isInhibitedBy returns true if a firing alert is a source of the inhibition rule for the labels.
+func (am *Alertmanager) isInhibitedBy(rule *inhibit.InhibitRule, lset model.LabelSet) bool {
+	it := am.alerts.GetPending()
+	defer it.Close()
+
+	fp := lset.Fingerprint()
+	for a := range it.Next() {
+		// An alert does not inhibit itself.
+		if a.Resolved() || a.Labels.Fingerprint() == fp || !rule.SourceMatchers.Matches(a.Labels) {
+			continue
+		}
+		if hasEqualLabels(rule.Equal, a.Labels, lset) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func hasEqualLabels(equal map[model.LabelName]struct{}, source, target model.LabelSet) bool {
+	for ln := range equal {
+		if source[ln] != target[ln] {
+			return false
+		}
+	}
+	return true
+}
+
+func matcherStrings(ms labels.Matchers) []string {
+	result := make([]string, 0, len(ms))
+	for _, m := range ms {
+		result = append(result, m.String())
+	}
+	return result
+}
@@ -0,0 +1,145 @@
+package notifier
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+const testRoutesConfig = `{
+	"alertmanager_config": {
+		"route": {
+			"receiver": "default",
+			"group_by": ["alertname"],
+			"routes": [{
+				"receiver": "team-a",
+				"object_matchers": [["team", "=", "a"]],
+				"group_wait": "10s",
+				"continue": true
+			}, {
+				"receiver": "team-a-critical",
+				"object_matchers": [["team", "=", "a"], ["severity", "=", "critical"]]
+			}, {
+				"receiver": "team-b",
+				"object_matchers": [["team", "=", "b"]],
+				"routes": [{
+					"receiver": "team-b-db",
+					"object_matchers": [["service", "=", "db"]],
+					"group_by": ["..."],
+					"mute_time_intervals": ["weekends"]
+				}]
+			}]
+		},
+		"inhibit_rules": [{
+			"source_matchers": ["severity=\"critical\""],
+			"target_matchers": ["severity=\"warning\""],
+			"equal": ["team"]
+		}],
+		"receivers": [
+			{"name": "default", "grafana_managed_receiver_configs": [{"name": "default", "type": "email", "settings": {"addresses": "default@example.com"}}]},
+			{"name": "team-a", "grafana_managed_receiver_configs": [{"name": "team-a", "type": "email", "settings": {"addresses": "a@example.com"}}]},
+			{"name": "team-a-critical", "grafana_managed_receiver_configs": [{"name": "team-a-critical", "type": "email", "settings": {"addresses": "a@example.com"}}]},
+			{"name": "team-b", "grafana_managed_receiver_configs": [{"name": "team-b", "type": "email", "settings": {"addresses": "b@example.com"}}]},
+			{"name": "team-b-db", "grafana_managed_receiver_configs": [{"name": "team-b-db", "type": "email", "settings": {"addresses": "b@example.com"}}]}
+		]
+	}
+}`
+
+func TestTestRoutes(t *testing.T) {
+	am := setupAMTest(t)
+
+	cfg, err := Load([]byte(testRoutesConfig))
+	require.NoError(t, err)
+
+	t.Run("no labels", func(t *testing.T) {
+		_, err := am.TestRoutes(apimodels.TestRoutesConfig{AlertmanagerConfig: &cfg.AlertmanagerConfig})
+		require.ErrorIs(t, err, ErrNoLabels)
+	})
+
+	t.Run("no configuration", func(t *testing.T) {
+		_, err := am.TestRoutes(apimodels.TestRoutesConfig{Labels: map[string]string{"team": "a"}})
+		require.ErrorIs(t, err, ErrNoRoute)
+	})
+
+	t.Run("default route", func(t *testing.T) {
+		result, err := am.TestRoutes(apimodels.TestRoutesConfig{
+			Labels:             map[string]string{"team": "c"},
+			AlertmanagerConfig: &cfg.AlertmanagerConfig,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []apimodels.TestRouteResult{{
+			Path:           []int{},
+			Matchers:       [][]string{{}},
+			Receiver:       "default",
+			GroupBy:        []string{"alertname"},
+			GroupWait:      "30s",
+			GroupInterval:  "5m",
+			RepeatInterval: "4h",
+		}}, result.Routes)
+		require.Empty(t, result.Inhibitions)
+	})
+
+	t.Run("continue to the next routes", func(t *testing.T) {
+		result, err := am.TestRoutes(apimodels.TestRoutesConfig{
+			Labels:             map[string]string{"team": "a", "severity": "critical"},
+			AlertmanagerConfig: &cfg.AlertmanagerConfig,
+		})
+		require.NoError(t, err)
+		require.Len(t, result.Routes, 2)
+		require.Equal(t, "team-a", result.Routes[0].Receiver)
+		require.Equal(t, []int{0}, result.Routes[0].Path)
+		require.Equal(t, "10s", result.Routes[0].GroupWait)
+		require.Equal(t, "team-a-critical", result.Routes[1].Receiver)
+		require.Equal(t, []int{1}, result.Routes[1].Path)
+		// The matchers of a route are sorted.
+		require.Equal(t, [][]string{{}, {`severity="critical"`, `team="a"`}}, result.Routes[1].Matchers)
+	})
+
+	t.Run("nested route and inhibition", func(t *testing.T) {
+		labels := map[string]string{"team": "b", "service": "db", "severity": "warning"}
+		result, err := am.TestRoutes(apimodels.TestRoutesConfig{
+			Labels:             labels,
+			AlertmanagerConfig: &cfg.AlertmanagerConfig,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []apimodels.TestRouteResult{{
+			Path:              []int{2, 0},
+			Matchers:          [][]string{{}, {`team="b"`}, {`service="db"`}},
+			Receiver:          "team-b-db",
+			GroupBy:           []string{"..."},
+			GroupWait:         "30s",
+			GroupInterval:     "5m",
+			RepeatInterval:    "4h",
+			MuteTimeIntervals: []string{"weekends"},
+		}}, result.Routes)
+		require.Equal(t, []apimodels.TestRouteInhibition{{
+			SourceMatchers: []string{`severity="critical"`},
+			TargetMatchers: []string{`severity="warning"`},
+			Equal:          []string{"team"},
+			Active:         false,
+		}}, result.Inhibitions)
+
+		// A firing alert of another team does not inhibit the alert, but one of the same team does.
+		for _, team := range []string{"a", "b"} {
+			require.NoError(t, am.PutAlerts(apimodels.PostableAlerts{PostableAlerts: []models.PostableAlert{{
+				Alert: models.Alert{
+					Labels: models.LabelSet{"alertname": "source", "team": team, "severity": "critical"},
+				},
+				StartsAt: strfmt.DateTime(time.Now()),
+			}}}))
+
+			result, err = am.TestRoutes(apimodels.TestRoutesConfig{
+				Labels:             labels,
+				AlertmanagerConfig: &cfg.AlertmanagerConfig,
+			})
+			require.NoError(t, err)
+			require.Len(t, result.Inhibitions, 1)
+			require.Equal(t, team == "b", result.Inhibitions[0].Active)
+		}
+	})
+}
@@ -13,6 +13,11 @@ import (
 
 type FakeConfigStore struct {
 	configs map[int64]*models.AlertConfiguration
+
+	mtx         sync.Mutex
+	deliveries  []*models.NotificationDeliveryEntry
+	retries     []*models.NotificationRetry
+	lastRetryID int64
 }
 
 func (f *FakeConfigStore) GetAllLatestAlertmanagerConfiguration(context.Context) ([]*models.AlertConfiguration, error) {
@@ -59,6 +64,102 @@ func (f *FakeConfigStore) SaveAlertmanagerConfigurationWithCallback(cmd *models.
 	return nil
 }
 
+func (f *FakeConfigStore) SaveNotificationDeliveries(_ context.Context, cmd *models.SaveNotificationDeliveriesCommand) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.deliveries = append(f.deliveries, cmd.Entries...)
+	return nil
+}
+
+func (f *FakeConfigStore) ListNotificationDeliveries(_ context.Context, query *models.ListNotificationDeliveriesQuery) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	for _, e := range f.deliveries {
+		if e.OrgID == query.OrgID && (query.Receiver == "" || e.Receiver == query.Receiver) && (query.RuleUID == "" || e.RuleUID == query.RuleUID) {
+			query.Result = append(query.Result, e)
+		}
+	}
+	return nil
+}
+
+func (f *FakeConfigStore) DeleteExpiredNotificationDeliveries(context.Context, *models.DeleteExpiredNotificationDeliveriesCommand) error {
+	return nil
+}
+
+func (f *FakeConfigStore) EnqueueNotificationRetry(_ context.Context, cmd *models.EnqueueNotificationRetryCommand) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.deleteGroupNotificationRetries(cmd.OrgID, cmd.Receiver, cmd.IntegrationUID, cmd.GroupKey)
+	f.lastRetryID++
+	f.retries = append(f.retries, &models.NotificationRetry{
+		ID:               f.lastRetryID,
+		OrgID:            cmd.OrgID,
+		Receiver:         cmd.Receiver,
+		Integration:      cmd.Integration,
+		IntegrationIndex: cmd.IntegrationIndex,
+		IntegrationUID:   cmd.IntegrationUID,
+		GroupKey:         cmd.GroupKey,
+		GroupLabels:      cmd.GroupLabels,
+		Alerts:           cmd.Alerts,
+		Error:            cmd.Error,
+		NextAttemptAt:    cmd.NextAttemptAt,
+	})
+	return nil
+}
+
+func (f *FakeConfigStore) GetDueNotificationRetries(_ context.Context, query *models.GetDueNotificationRetriesQuery) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	for _, r := range f.retries {
+		if r.OrgID == query.OrgID && !r.NextAttemptAt.After(query.Now) {
+			query.Result = append(query.Result, r)
+		}
+	}
+	return nil
+}
+
+func (f *FakeConfigStore) UpdateNotificationRetry(_ context.Context, cmd *models.UpdateNotificationRetryCommand) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	for _, r := range f.retries {
+		if r.ID == cmd.ID {
+			r.Attempts = cmd.Attempts
+			r.Error = cmd.Error
+			r.NextAttemptAt = cmd.NextAttemptAt
+		}
+	}
+	return nil
+}
+
+func (f *FakeConfigStore) DeleteNotificationRetry(_ context.Context, cmd *models.DeleteNotificationRetryCommand) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	for i, r := range f.retries {
+		if r.ID == cmd.ID {
+			f.retries = append(f.retries[:i], f.retries[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (f *FakeConfigStore) DeleteGroupNotificationRetries(_ context.Context, cmd *models.DeleteGroupNotificationRetriesCommand) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.deleteGroupNotificationRetries(cmd.OrgID, cmd.Receiver, cmd.IntegrationUID, cmd.GroupKey)
+	return nil
+}
+
+func (f *FakeConfigStore) deleteGroupNotificationRetries(orgID int64, receiver, integrationUID, groupKey string) {
+	retries := f.retries[:0]
+	for _, r := range f.retries {
+		if r.OrgID != orgID || r.Receiver != receiver || r.IntegrationUID != integrationUID || r.GroupKey != groupKey {
+			retries = append(retries, r)
+		}
+	}
+	f.retries = retries
+}
+
 type FakeOrgStore struct {
 	orgs []int64
 }
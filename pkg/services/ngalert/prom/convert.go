@@ -0,0 +1,306 @@
+// Package prom converts between Grafana managed alert rules and Prometheus rules files.
+package prom
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/promql/parser"
+
+	"github.com/grafana/grafana/pkg/expr"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+const (
+	queryRefID     = "A"
+	reduceRefID    = "B"
+	conditionRefID = "C"
+
+	// defaultInterval is the default evaluation interval of Prometheus.
+	defaultInterval = model.Duration(time.Minute)
+	// queryTimeRange is the relative time range of the imported queries.
+	// Only its end matters for instant queries.
+	queryTimeRange = 10 * time.Minute
+)
+
+// presenceExpression returns the math expression that is 1 for every series of refID.
+// Prometheus alerts fire for each series returned by the expression, whatever its value is.
+func presenceExpression(refID string) string {
+	return fmt.Sprintf("is_number($%[1]s) || is_nan($%[1]s) || is_inf($%[1]s)", refID)
+}
+
+// ToGrafanaRuleGroups converts the rule groups of a Prometheus rules file to Grafana
+// managed rule groups whose rules query the datasource with the given UID.
+// The rule groups replace the rule groups with the same name of the namespace, whose rules
+// are given as existing: a rule keeps the UID of the existing rule of its group with the same
+// title, so that importing a file again updates the rules rather than creating new ones.
+// Alert rules get a number suffix if their title is already used in the file or by a rule of
+// another rule group of the namespace, as titles must be unique in a namespace.
+func ToGrafanaRuleGroups(file apimodels.PrometheusRulesFile, datasourceUID string, existing []*ngmodels.AlertRule) ([]apimodels.PostableRuleGroupConfig, error) {
+	groupNames := make(map[string]struct{}, len(file.Groups))
+	for _, g := range file.Groups {
+		if g.Name == "" {
+			return nil, errors.New("rule group name must not be empty")
+		}
+		if _, ok := groupNames[g.Name]; ok {
+			return nil, fmt.Errorf("rule group %q is defined more than once", g.Name)
+		}
+		groupNames[g.Name] = struct{}{}
+	}
+
+	titles := make(map[string]struct{})
+	existingUIDs := make(map[string]map[string]string)
+	for _, r := range existing {
+		if _, ok := groupNames[r.RuleGroup]; !ok {
+			// the rule is not replaced
+			titles[r.Title] = struct{}{}
+			continue
+		}
+		if existingUIDs[r.RuleGroup] == nil {
+			existingUIDs[r.RuleGroup] = make(map[string]string)
+		}
+		existingUIDs[r.RuleGroup][r.Title] = r.UID
+	}
+
+	result := make([]apimodels.PostableRuleGroupConfig, 0, len(file.Groups))
+	for _, g := range file.Groups {
+		group := apimodels.PostableRuleGroupConfig{
+			Name:     g.Name,
+			Interval: g.Interval,
+		}
+		if group.Interval == 0 {
+			group.Interval = defaultInterval
+		}
+
+		for i, r := range g.Rules {
+			rule, err := toGrafanaRule(r, datasourceUID)
+			if err != nil {
+				return nil, fmt.Errorf("invalid rule %d of rule group %q: %w", i+1, g.Name, err)
+			}
+
+			title := uniqueTitle(rule.GrafanaManagedAlert.Title, titles)
+			rule.GrafanaManagedAlert.Title = title
+			rule.GrafanaManagedAlert.UID = existingUIDs[g.Name][title]
+			group.Rules = append(group.Rules, rule)
+		}
+		result = append(result, group)
+	}
+	return result, nil
+}
+
+// uniqueTitle returns the title, with a number suffix if it is already used, and adds it to the used titles.
+func uniqueTitle(title string, used map[string]struct{}) string {
+	unique := title
+	for n := 2; ; n++ {
+		if _, ok := used[unique]; !ok {
+			used[unique] = struct{}{}
+			return unique
+		}
+		unique = fmt.Sprintf("%s (%d)", title, n)
+	}
+}
+
+func toGrafanaRule(r apimodels.ApiRuleNode, datasourceUID string) (apimodels.PostableExtendedRuleNode, error) {
+	switch {
+	case r.Alert != "" && r.Record != "":
+		return apimodels.PostableExtendedRuleNode{}, errors.New("only one of alert and record can be set")
+	case r.Alert == "" && r.Record == "":
+		return apimodels.PostableExtendedRuleNode{}, errors.New("one of alert or record must be set")
+	case r.Record != "" && !model.IsValidMetricName(model.LabelValue(r.Record)):
+		return apimodels.PostableExtendedRuleNode{}, fmt.Errorf("record %q is not a valid metric name", r.Record)
+	case r.Record != "" && r.For != 0:
+		return apimodels.PostableExtendedRuleNode{}, errors.New("for cannot be set for a recording rule")
+	case r.Expr == "":
+		return apimodels.PostableExtendedRuleNode{}, errors.New("expr must be set")
+	}
+	if _, err := parser.ParseExpr(r.Expr); err != nil {
+		return apimodels.PostableExtendedRuleNode{}, fmt.Errorf("invalid expr: %w", err)
+	}
+
+	data := []ngmodels.AlertQuery{
+		{
+			RefID:         queryRefID,
+			DatasourceUID: datasourceUID,
+			RelativeTimeRange: ngmodels.RelativeTimeRange{
+				From: ngmodels.Duration(queryTimeRange),
+				To:   0,
+			},
+			Model: mustMarshal(map[string]interface{}{
+				"refId":   queryRefID,
+				"expr":    r.Expr,
+				"instant": true,
+				"range":   false,
+			}),
+		},
+		expressionQuery(reduceRefID, map[string]interface{}{
+			"type":       "reduce",
+			"reducer":    "last",
+			"expression": queryRefID,
+		}),
+	}
+
+	rule := apimodels.PostableExtendedRuleNode{
+		ApiRuleNode: &apimodels.ApiRuleNode{
+			Labels: r.Labels,
+		},
+		GrafanaManagedAlert: &apimodels.PostableGrafanaRule{
+			NoDataState:  apimodels.OK,
+			ExecErrState: apimodels.ErrorErrState,
+		},
+	}
+
+	if r.Record != "" {
+		rule.GrafanaManagedAlert.Title = r.Record
+		rule.GrafanaManagedAlert.Record = r.Record
+		rule.GrafanaManagedAlert.Condition = reduceRefID
+	} else {
+		data = append(data, expressionQuery(conditionRefID, map[string]interface{}{
+			"type":       "math",
+			"expression": presenceExpression(reduceRefID),
+		}))
+		rule.GrafanaManagedAlert.Title = r.Alert
+		rule.GrafanaManagedAlert.Condition = conditionRefID
+		rule.ApiRuleNode.For = r.For
+		rule.ApiRuleNode.Annotations = r.Annotations
+	}
+	rule.GrafanaManagedAlert.Data = data
+
+	return rule, nil
+}
+
+func expressionQuery(refID string, m map[string]interface{}) ngmodels.AlertQuery {
+	m["refId"] = refID
+	m["datasourceUid"] = expr.DatasourceUID
+	return ngmodels.AlertQuery{
+		RefID:         refID,
+		DatasourceUID: expr.DatasourceUID,
+		Model:         mustMarshal(m),
+	}
+}
+
+func mustMarshal(v map[string]interface{}) json.RawMessage {
+	b, err := json.Marshal(v)
+	if err != nil {
+		// maps of strings and booleans can always be marshalled
+		panic(err)
+	}
+	return b
+}
+
+// SkippedRule is a Grafana managed rule that cannot be converted to a Prometheus rule.
+type SkippedRule struct {
+	Title     string
+	RuleGroup string
+	Err       error
+}
+
+// FromAlertRules converts Grafana managed alert rules to a Prometheus rules file.
+// Only the rules that are shaped like the imported ones can be converted: their condition
+// must be a query of a Prometheus datasource, possibly reduced to its last value and,
+// for alert rules, checked for the presence of series. The other rules are skipped.
+func FromAlertRules(rules []*ngmodels.AlertRule) (apimodels.PrometheusRulesFile, []SkippedRule) {
+	var skipped []SkippedRule
+	groups := make(map[string]*apimodels.PrometheusRuleGroup)
+	for _, r := range rules {
+		node, err := toPrometheusRule(r)
+		if err != nil {
+			skipped = append(skipped, SkippedRule{Title: r.Title, RuleGroup: r.RuleGroup, Err: err})
+			continue
+		}
+
+		group, ok := groups[r.RuleGroup]
+		if !ok {
+			group = &apimodels.PrometheusRuleGroup{
+				Name:     r.RuleGroup,
+				Interval: model.Duration(time.Duration(r.IntervalSeconds) * time.Second),
+			}
+			groups[r.RuleGroup] = group
+		}
+		group.Rules = append(group.Rules, node)
+	}
+
+	file := apimodels.PrometheusRulesFile{Groups: make([]apimodels.PrometheusRuleGroup, 0, len(groups))}
+	for _, g := range groups {
+		file.Groups = append(file.Groups, *g)
+	}
+	sort.Slice(file.Groups, func(i, j int) bool {
+		return file.Groups[i].Name < file.Groups[j].Name
+	})
+	return file, skipped
+}
+
+func toPrometheusRule(r *ngmodels.AlertRule) (apimodels.ApiRuleNode, error) {
+	queries := make(map[string]ngmodels.AlertQuery, len(r.Data))
+	for _, q := range r.Data {
+		queries[q.RefID] = q
+	}
+
+	promExpr, presence, err := resolveExpression(queries, r.Condition, 0)
+	if err != nil {
+		return apimodels.ApiRuleNode{}, err
+	}
+
+	if r.IsRecordingRule() {
+		return apimodels.ApiRuleNode{
+			Record: r.Record,
+			Expr:   promExpr,
+			Labels: r.Labels,
+		}, nil
+	}
+
+	if !presence {
+		return apimodels.ApiRuleNode{}, errors.New("alert rules must fire for every series of the query")
+	}
+	return apimodels.ApiRuleNode{
+		Alert:       r.Title,
+		Expr:        promExpr,
+		For:         model.Duration(r.For),
+		Labels:      r.Labels,
+		Annotations: r.Annotations,
+	}, nil
+}
+
+// resolveExpression returns the Prometheus expression refID is computed from, and whether
+// the presence of its series is checked on the way.
+func resolveExpression(queries map[string]ngmodels.AlertQuery, refID string, depth int) (string, bool, error) {
+	q, ok := queries[refID]
+	if !ok || depth > len(queries) {
+		return "", false, fmt.Errorf("query %s not found", refID)
+	}
+
+	var m map[string]interface{}
+	if err := json.Unmarshal(q.Model, &m); err != nil {
+		return "", false, fmt.Errorf("failed to unmarshal the model of query %s: %w", refID, err)
+	}
+
+	if isExpression, _ := q.IsExpression(); !isExpression {
+		promExpr, _ := m["expr"].(string)
+		if promExpr == "" {
+			return "", false, fmt.Errorf("query %s has no Prometheus expression", refID)
+		}
+		return promExpr, false, nil
+	}
+
+	expression, _ := m["expression"].(string)
+	switch m["type"] {
+	case "reduce":
+		if m["reducer"] != "last" {
+			return "", false, fmt.Errorf("reduce expression %s must use the last reducer", refID)
+		}
+		return resolveExpression(queries, strings.TrimPrefix(expression, "$"), depth+1)
+	case "math":
+		for ref := range queries {
+			if expression == presenceExpression(ref) {
+				promExpr, _, err := resolveExpression(queries, ref, depth+1)
+				return promExpr, true, err
+			}
+		}
+	}
+	return "", false, fmt.Errorf("expression %s has no Prometheus equivalent", refID)
+}
@@ -0,0 +1,254 @@
+package prom
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/expr"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+const rulesFile = `
+groups:
+  - name: node
+    interval: 30s
+    rules:
+      - record: instance:node_cpu:rate5m
+        expr: sum by (instance) (rate(node_cpu_seconds_total{mode!="idle"}[5m]))
+        labels:
+          team: infra
+      - alert: HighCPU
+        expr: instance:node_cpu:rate5m > 0.9
+        for: 5m
+        labels:
+          severity: warning
+        annotations:
+          summary: CPU usage of {{ $labels.instance }} is high
+  - name: availability
+    rules:
+      - alert: HighCPU
+        expr: up == 0
+`
+
+func parseRulesFile(t *testing.T, s string) apimodels.PrometheusRulesFile {
+	t.Helper()
+	var file apimodels.PrometheusRulesFile
+	require.NoError(t, yaml.Unmarshal([]byte(s), &file))
+	return file
+}
+
+// toAlertRules returns the alert rules the store saves for the rule groups.
+func toAlertRules(groups []apimodels.PostableRuleGroupConfig) []*ngmodels.AlertRule {
+	var rules []*ngmodels.AlertRule
+	for _, g := range groups {
+		for _, r := range g.Rules {
+			rules = append(rules, &ngmodels.AlertRule{
+				Title:           r.GrafanaManagedAlert.Title,
+				Condition:       r.GrafanaManagedAlert.Condition,
+				Data:            r.GrafanaManagedAlert.Data,
+				IntervalSeconds: int64(time.Duration(g.Interval).Seconds()),
+				RuleGroup:       g.Name,
+				NoDataState:     ngmodels.NoDataState(r.GrafanaManagedAlert.NoDataState),
+				ExecErrState:    ngmodels.ExecutionErrorState(r.GrafanaManagedAlert.ExecErrState),
+				Record:          r.GrafanaManagedAlert.Record,
+				For:             time.Duration(r.ApiRuleNode.For),
+				Labels:          r.ApiRuleNode.Labels,
+				Annotations:     r.ApiRuleNode.Annotations,
+			})
+		}
+	}
+	return rules
+}
+
+func TestToGrafanaRuleGroups(t *testing.T) {
+	groups, err := ToGrafanaRuleGroups(parseRulesFile(t, rulesFile), "prom-uid", nil)
+	require.NoError(t, err)
+	require.Len(t, groups, 2)
+
+	t.Run("group intervals default to one minute", func(t *testing.T) {
+		require.Equal(t, "node", groups[0].Name)
+		require.Equal(t, model.Duration(30*time.Second), groups[0].Interval)
+		require.Equal(t, "availability", groups[1].Name)
+		require.Equal(t, model.Duration(time.Minute), groups[1].Interval)
+	})
+
+	t.Run("recording rules record the last value of the query", func(t *testing.T) {
+		r := groups[0].Rules[0]
+		require.Equal(t, "instance:node_cpu:rate5m", r.GrafanaManagedAlert.Title)
+		require.Equal(t, "instance:node_cpu:rate5m", r.GrafanaManagedAlert.Record)
+		require.Equal(t, reduceRefID, r.GrafanaManagedAlert.Condition)
+		require.Equal(t, map[string]string{"team": "infra"}, r.ApiRuleNode.Labels)
+		require.Len(t, r.GrafanaManagedAlert.Data, 2)
+
+		query := r.GrafanaManagedAlert.Data[0]
+		require.Equal(t, "prom-uid", query.DatasourceUID)
+		require.Equal(t, ngmodels.Duration(10*time.Minute), query.RelativeTimeRange.From)
+		var m map[string]interface{}
+		require.NoError(t, json.Unmarshal(query.Model, &m))
+		require.Equal(t, `sum by (instance) (rate(node_cpu_seconds_total{mode!="idle"}[5m]))`, m["expr"])
+		require.Equal(t, true, m["instant"])
+
+		reduce := r.GrafanaManagedAlert.Data[1]
+		require.Equal(t, expr.DatasourceUID, reduce.DatasourceUID)
+		require.JSONEq(t, `{"refId":"B","datasourceUid":"-100","type":"reduce","reducer":"last","expression":"A"}`, string(reduce.Model))
+	})
+
+	t.Run("alert rules fire for every series of the query", func(t *testing.T) {
+		r := groups[0].Rules[1]
+		require.Equal(t, "HighCPU", r.GrafanaManagedAlert.Title)
+		require.Empty(t, r.GrafanaManagedAlert.Record)
+		require.Equal(t, conditionRefID, r.GrafanaManagedAlert.Condition)
+		require.Equal(t, apimodels.OK, r.GrafanaManagedAlert.NoDataState)
+		require.Equal(t, apimodels.ErrorErrState, r.GrafanaManagedAlert.ExecErrState)
+		require.Equal(t, model.Duration(5*time.Minute), r.ApiRuleNode.For)
+		require.Equal(t, map[string]string{"severity": "warning"}, r.ApiRuleNode.Labels)
+		require.Equal(t, map[string]string{"summary": "CPU usage of {{ $labels.instance }} is high"}, r.ApiRuleNode.Annotations)
+		require.Len(t, r.GrafanaManagedAlert.Data, 3)
+		require.JSONEq(t, `{"refId":"C","datasourceUid":"-100","type":"math","expression":"is_number($B) || is_nan($B) || is_inf($B)"}`, string(r.GrafanaManagedAlert.Data[2].Model))
+	})
+
+	t.Run("titles are unique across the file", func(t *testing.T) {
+		require.Equal(t, "HighCPU (2)", groups[1].Rules[0].GrafanaManagedAlert.Title)
+	})
+
+	t.Run("rules are new", func(t *testing.T) {
+		for _, g := range groups {
+			for _, r := range g.Rules {
+				require.Empty(t, r.GrafanaManagedAlert.UID)
+			}
+		}
+	})
+}
+
+func TestToGrafanaRuleGroupsExistingRules(t *testing.T) {
+	existing := []*ngmodels.AlertRule{
+		{UID: "uid-1", Title: "HighCPU", RuleGroup: "node"},
+		{UID: "uid-2", Title: "HighCPU (2)", RuleGroup: "availability"},
+		{UID: "uid-3", Title: "instance:node_cpu:rate5m", RuleGroup: "other"},
+		{UID: "uid-4", Title: "Removed", RuleGroup: "node"},
+	}
+
+	groups, err := ToGrafanaRuleGroups(parseRulesFile(t, rulesFile), "prom-uid", existing)
+	require.NoError(t, err)
+	require.Len(t, groups, 2)
+
+	t.Run("titles are unique across the rule groups of the namespace that are not replaced", func(t *testing.T) {
+		require.Equal(t, "instance:node_cpu:rate5m (2)", groups[0].Rules[0].GrafanaManagedAlert.Title)
+		require.Empty(t, groups[0].Rules[0].GrafanaManagedAlert.UID)
+	})
+
+	t.Run("rules keep the UID of the existing rule of their group with the same title", func(t *testing.T) {
+		require.Equal(t, "HighCPU", groups[0].Rules[1].GrafanaManagedAlert.Title)
+		require.Equal(t, "uid-1", groups[0].Rules[1].GrafanaManagedAlert.UID)
+		require.Equal(t, "HighCPU (2)", groups[1].Rules[0].GrafanaManagedAlert.Title)
+		require.Equal(t, "uid-2", groups[1].Rules[0].GrafanaManagedAlert.UID)
+	})
+}
+
+func TestToGrafanaRuleGroupsErrors(t *testing.T) {
+	testCases := []struct {
+		desc string
+		file string
+		err  string
+	}{
+		{
+			desc: "group without name",
+			file: "groups: [{rules: [{alert: a, expr: up}]}]",
+			err:  "rule group name must not be empty",
+		},
+		{
+			desc: "duplicated group",
+			file: "groups: [{name: a, rules: []}, {name: a, rules: []}]",
+			err:  `rule group "a" is defined more than once`,
+		},
+		{
+			desc: "rule with alert and record",
+			file: "groups: [{name: a, rules: [{alert: a, record: a, expr: up}]}]",
+			err:  `invalid rule 1 of rule group "a": only one of alert and record can be set`,
+		},
+		{
+			desc: "rule without alert or record",
+			file: "groups: [{name: a, rules: [{expr: up}]}]",
+			err:  `invalid rule 1 of rule group "a": one of alert or record must be set`,
+		},
+		{
+			desc: "invalid record",
+			file: "groups: [{name: a, rules: [{record: a-b, expr: up}]}]",
+			err:  `invalid rule 1 of rule group "a": record "a-b" is not a valid metric name`,
+		},
+		{
+			desc: "recording rule with for",
+			file: "groups: [{name: a, rules: [{record: a, expr: up, for: 1m}]}]",
+			err:  `invalid rule 1 of rule group "a": for cannot be set for a recording rule`,
+		},
+		{
+			desc: "invalid expr",
+			file: "groups: [{name: a, rules: [{alert: a, expr: 'sum('}]}]",
+			err:  `invalid rule 1 of rule group "a": invalid expr`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			_, err := ToGrafanaRuleGroups(parseRulesFile(t, tc.file), "prom-uid", nil)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.err)
+		})
+	}
+}
+
+func TestFromAlertRules(t *testing.T) {
+	t.Run("imported rules are exported as they were", func(t *testing.T) {
+		groups, err := ToGrafanaRuleGroups(parseRulesFile(t, rulesFile), "prom-uid", nil)
+		require.NoError(t, err)
+
+		file, skipped := FromAlertRules(toAlertRules(groups))
+		require.Empty(t, skipped)
+
+		// groups are sorted by name
+		expected := parseRulesFile(t, rulesFile)
+		expected.Groups[0], expected.Groups[1] = expected.Groups[1], expected.Groups[0]
+		expected.Groups[0].Interval = model.Duration(time.Minute)
+		expected.Groups[0].Rules[0].Alert = "HighCPU (2)"
+		require.Equal(t, expected, file)
+	})
+
+	t.Run("rules that do not query a Prometheus expression are skipped", func(t *testing.T) {
+		groups, err := ToGrafanaRuleGroups(parseRulesFile(t, "groups: [{name: a, rules: [{alert: a, expr: up}]}]"), "prom-uid", nil)
+		require.NoError(t, err)
+		rule := &ngmodels.AlertRule{
+			Title:     "classic",
+			RuleGroup: "a",
+			Condition: "A",
+			Data: []ngmodels.AlertQuery{{
+				RefID:         "A",
+				DatasourceUID: expr.DatasourceUID,
+				Model:         json.RawMessage(`{"type":"classic_conditions","conditions":[]}`),
+			}},
+		}
+
+		file, skipped := FromAlertRules(append(toAlertRules(groups), rule))
+		require.Len(t, file.Groups, 1)
+		require.Len(t, file.Groups[0].Rules, 1)
+		require.Equal(t, "a", file.Groups[0].Rules[0].Alert)
+		require.Len(t, skipped, 1)
+		require.Equal(t, "classic", skipped[0].Title)
+		require.EqualError(t, skipped[0].Err, "expression A has no Prometheus equivalent")
+	})
+
+	t.Run("alert rules must check the presence of series", func(t *testing.T) {
+		groups, err := ToGrafanaRuleGroups(parseRulesFile(t, "groups: [{name: a, rules: [{alert: a, expr: up}]}]"), "prom-uid", nil)
+		require.NoError(t, err)
+		rules := toAlertRules(groups)
+		rules[0].Condition = reduceRefID
+
+		_, skipped := FromAlertRules(rules)
+		require.Len(t, skipped, 1)
+		require.EqualError(t, skipped[0].Err, "alert rules must fire for every series of the query")
+	})
+}
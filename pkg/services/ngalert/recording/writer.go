@@ -0,0 +1,84 @@
+// Package recording writes the results of recording rules to a Prometheus remote write endpoint.
+package recording
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/live/remotewrite"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// Writer writes the result of the condition of a recording rule as series with the given metric name.
+type Writer interface {
+	Write(ctx context.Context, name string, labels map[string]string, frames data.Frames, now time.Time) error
+}
+
+// RemoteWriter is a Writer that sends series to a Prometheus remote write endpoint.
+type RemoteWriter struct {
+	url      string
+	user     string
+	password string
+
+	client *http.Client
+	log    log.Logger
+}
+
+// NewRemoteWriter returns a RemoteWriter for the recording rules remote write endpoint
+// of the configuration, or nil if there is none.
+func NewRemoteWriter(cfg setting.UnifiedAlertingSettings, logger log.Logger) *RemoteWriter {
+	if cfg.RecordingRulesRemoteWriteURL == "" {
+		return nil
+	}
+	return &RemoteWriter{
+		url:      cfg.RecordingRulesRemoteWriteURL,
+		user:     cfg.RecordingRulesRemoteWriteUser,
+		password: cfg.RecordingRulesRemoteWritePassword,
+		client:   &http.Client{Timeout: cfg.RecordingRulesRemoteWriteTimeout},
+		log:      logger,
+	}
+}
+
+// Write converts the frames to series named name with the given labels and sends them to the remote write endpoint.
+// Frames without time field, such as the result of a reduce expression, are written as a single sample at now.
+func (w *RemoteWriter) Write(ctx context.Context, name string, labels map[string]string, frames data.Frames, now time.Time) error {
+	series := remotewrite.TimeSeriesFromFramesWithName(name, labels, now, frames...)
+	if len(series) == 0 {
+		w.log.Debug("no series to write", "name", name)
+		return nil
+	}
+
+	body, err := remotewrite.TimeSeriesToBytes(series)
+	if err != nil {
+		return fmt.Errorf("failed to serialize series: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create remote write request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	if w.user != "" {
+		req.SetBasicAuth(w.user, w.password)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send remote write request: %w", err)
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("unexpected response status code from remote write endpoint: %d", resp.StatusCode)
+	}
+
+	w.log.Debug("series written to remote write endpoint", "name", name, "count", len(series))
+	return nil
+}
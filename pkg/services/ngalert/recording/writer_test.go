@@ -0,0 +1,84 @@
+package recording
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestNewRemoteWriter(t *testing.T) {
+	require.Nil(t, NewRemoteWriter(setting.UnifiedAlertingSettings{}, log.New("test")))
+	require.NotNil(t, NewRemoteWriter(setting.UnifiedAlertingSettings{RecordingRulesRemoteWriteURL: "http://localhost:9090/api/v1/write"}, log.New("test")))
+}
+
+func TestRemoteWriter_Write(t *testing.T) {
+	now := time.Unix(1000, 0)
+	value := 42.0
+	frames := data.Frames{
+		data.NewFrame("",
+			data.NewField("B", data.Labels{"instance": "a"}, []*float64{&value}),
+		),
+	}
+
+	t.Run("series are sent to the remote write endpoint", func(t *testing.T) {
+		var received prompb.WriteRequest
+		var user, password string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, password, _ = r.BasicAuth()
+			require.Equal(t, "snappy", r.Header.Get("Content-Encoding"))
+			compressed, err := ioutil.ReadAll(r.Body)
+			require.NoError(t, err)
+			b, err := snappy.Decode(nil, compressed)
+			require.NoError(t, err)
+			require.NoError(t, proto.Unmarshal(b, &received))
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		w := NewRemoteWriter(setting.UnifiedAlertingSettings{
+			RecordingRulesRemoteWriteURL:      server.URL,
+			RecordingRulesRemoteWriteUser:     "user",
+			RecordingRulesRemoteWritePassword: "password",
+			RecordingRulesRemoteWriteTimeout:  time.Second,
+		}, log.New("test"))
+
+		err := w.Write(context.Background(), "instance:value", map[string]string{"team": "a"}, frames, now)
+		require.NoError(t, err)
+		require.Equal(t, "user", user)
+		require.Equal(t, "password", password)
+		require.Len(t, received.Timeseries, 1)
+		require.Equal(t, []prompb.Label{
+			{Name: "instance", Value: "a"},
+			{Name: "team", Value: "a"},
+			{Name: "__name__", Value: "instance:value"},
+		}, received.Timeseries[0].Labels)
+		require.Equal(t, []prompb.Sample{{Value: 42, Timestamp: 1000000}}, received.Timeseries[0].Samples)
+	})
+
+	t.Run("an error is returned if the remote write endpoint fails", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		w := NewRemoteWriter(setting.UnifiedAlertingSettings{
+			RecordingRulesRemoteWriteURL:     server.URL,
+			RecordingRulesRemoteWriteTimeout: time.Second,
+		}, log.New("test"))
+
+		err := w.Write(context.Background(), "instance:value", nil, frames, now)
+		require.Error(t, err)
+	})
+}
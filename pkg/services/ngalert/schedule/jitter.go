@@ -0,0 +1,35 @@
+package schedule
+
+import (
+	"hash/fnv"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// jitterOffset returns the tick, between 0 and frequency-1, at which the rule is evaluated
+// within its interval of frequency ticks. The offset only depends on the rule and the
+// strategy, so it does not change between restarts.
+func jitterOffset(rule *models.AlertRule, strategy setting.EvaluationJitter, frequency int64) int64 {
+	if frequency <= 1 {
+		return 0
+	}
+
+	h := fnv.New64a()
+	switch strategy {
+	case setting.EvaluationJitterRule:
+		_, _ = h.Write([]byte(strconv.FormatInt(rule.OrgID, 10)))
+		_, _ = h.Write([]byte{0})
+		_, _ = h.Write([]byte(rule.UID))
+	case setting.EvaluationJitterGroup:
+		_, _ = h.Write([]byte(strconv.FormatInt(rule.OrgID, 10)))
+		_, _ = h.Write([]byte{0})
+		_, _ = h.Write([]byte(rule.NamespaceUID))
+		_, _ = h.Write([]byte{0})
+		_, _ = h.Write([]byte(rule.RuleGroup))
+	default:
+		return 0
+	}
+	return int64(h.Sum64() % uint64(frequency))
+}
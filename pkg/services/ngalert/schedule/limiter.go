@@ -0,0 +1,93 @@
+package schedule
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// datasourceLimiter limits the number of rule evaluations querying each datasource at the same time.
+type datasourceLimiter struct {
+	limit    int
+	inFlight *prometheus.GaugeVec
+
+	mtx   sync.Mutex
+	slots map[string]chan struct{}
+}
+
+// newDatasourceLimiter returns a limiter allowing limit evaluations per datasource at the same time.
+// Evaluations are not limited if limit is 0.
+func newDatasourceLimiter(limit int, inFlight *prometheus.GaugeVec) *datasourceLimiter {
+	return &datasourceLimiter{
+		limit:    limit,
+		inFlight: inFlight,
+		slots:    make(map[string]chan struct{}),
+	}
+}
+
+func (l *datasourceLimiter) slotsFor(uid string) chan struct{} {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	s, ok := l.slots[uid]
+	if !ok {
+		s = make(chan struct{}, l.limit)
+		l.slots[uid] = s
+	}
+	return s
+}
+
+// acquire blocks until a slot of each datasource is available, or the context is done.
+// Slots are acquired in the order of the sorted UIDs so that evaluations querying
+// several datasources cannot wait for each other. The returned function releases them.
+func (l *datasourceLimiter) acquire(ctx context.Context, uids []string) (func(), error) {
+	acquired := make([]string, 0, len(uids))
+	release := func() {
+		for _, uid := range acquired {
+			if l.limit > 0 {
+				<-l.slotsFor(uid)
+			}
+			if l.inFlight != nil {
+				l.inFlight.WithLabelValues(uid).Dec()
+			}
+		}
+	}
+
+	for _, uid := range uids {
+		if l.limit > 0 {
+			select {
+			case l.slotsFor(uid) <- struct{}{}:
+			case <-ctx.Done():
+				release()
+				return nil, ctx.Err()
+			}
+		}
+		if l.inFlight != nil {
+			l.inFlight.WithLabelValues(uid).Inc()
+		}
+		acquired = append(acquired, uid)
+	}
+	return release, nil
+}
+
+// datasourceUIDs returns the sorted UIDs of the datasources queried by the rule.
+func datasourceUIDs(rule *models.AlertRule) []string {
+	seen := make(map[string]struct{}, len(rule.Data))
+	uids := make([]string, 0, len(rule.Data))
+	for _, q := range rule.Data {
+		if isExpression, err := q.IsExpression(); err != nil || isExpression {
+			continue
+		}
+		if _, ok := seen[q.DatasourceUID]; ok {
+			continue
+		}
+		seen[q.DatasourceUID] = struct{}{}
+		uids = append(uids, q.DatasourceUID)
+	}
+	sort.Strings(uids)
+	return uids
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"sort"
 	"sync"
 	"time"
 
@@ -13,9 +14,11 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	"github.com/grafana/grafana/pkg/services/ngalert/recording"
 	"github.com/grafana/grafana/pkg/services/ngalert/sender"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tsdb"
 
 	"github.com/benbjohnson/clock"
@@ -86,6 +89,13 @@ type schedule struct {
 	adminConfigPollInterval time.Duration
 	disabledOrgs            map[int64]struct{}
 	minRuleInterval         time.Duration
+
+	// recordingWriter writes the results of recording rules, they are not evaluated if it is nil.
+	recordingWriter recording.Writer
+
+	// evaluationJitter spreads the evaluations of the rules that have the same interval.
+	evaluationJitter  setting.EvaluationJitter
+	datasourceLimiter *datasourceLimiter
 }
 
 // SchedulerCfg is the scheduler configuration.
@@ -106,6 +116,10 @@ type SchedulerCfg struct {
 	AdminConfigPollInterval time.Duration
 	DisabledOrgs            map[int64]struct{}
 	MinRuleInterval         time.Duration
+	RecordingWriter         recording.Writer
+	EvaluationJitter        setting.EvaluationJitter
+	// MaxConcurrentEvaluationsPerDatasource limits the evaluations querying a datasource at the same time, if not 0.
+	MaxConcurrentEvaluationsPerDatasource int
 }
 
 // NewScheduler returns a new schedule.
@@ -136,6 +150,9 @@ func NewScheduler(cfg SchedulerCfg, dataService *tsdb.Service, appURL *url.URL,
 		adminConfigPollInterval: cfg.AdminConfigPollInterval,
 		disabledOrgs:            cfg.DisabledOrgs,
 		minRuleInterval:         cfg.MinRuleInterval,
+		recordingWriter:         cfg.RecordingWriter,
+		evaluationJitter:        cfg.EvaluationJitter,
+		datasourceLimiter:       newDatasourceLimiter(cfg.MaxConcurrentEvaluationsPerDatasource, cfg.Metrics.DatasourceEvalsInFlight),
 	}
 	return &sch
 }
@@ -340,12 +357,10 @@ func (sch *schedule) ruleEvaluationLoop(ctx context.Context) error {
 			// so, at the end, the remaining registered alert rules are the deleted ones
 			registeredDefinitions := sch.registry.keyMap()
 
-			type readyToRunItem struct {
-				key      models.AlertRuleKey
-				ruleInfo alertRuleInfo
-			}
-
 			readyToRun := make([]readyToRunItem, 0)
+			// the rules of the groups evaluated sequentially are dispatched together
+			sequentialGroups := make(map[ruleGroupKey][]readyToRunItem)
+			sequentialGroupKeys := make([]ruleGroupKey, 0)
 			for _, item := range alertRules {
 				key := item.GetKey()
 				itemVersion := item.Version
@@ -362,6 +377,7 @@ func (sch *schedule) ruleEvaluationLoop(ctx context.Context) error {
 
 				if newRoutine && !invalidInterval {
 					dispatcherGroup.Go(func() error {
+						defer close(ruleInfo.stoppedCh)
 						return sch.ruleRoutine(ctx, key, ruleInfo.evalCh, ruleInfo.stopCh)
 					})
 				}
@@ -373,9 +389,31 @@ func (sch *schedule) ruleEvaluationLoop(ctx context.Context) error {
 					continue
 				}
 
+				// the routine of a paused alert rule is kept, and so is its state, but it is not evaluated
+				if item.IsPaused || item.RuleGroupIsPaused {
+					sch.log.Debug("alert rule is paused and will not be evaluated", "key", key, "rule_group_paused", item.RuleGroupIsPaused)
+					delete(registeredDefinitions, key)
+					continue
+				}
+
+				// the rules of a group evaluated sequentially must be ready at the same tick
+				jitter := sch.evaluationJitter
+				if item.EvaluateSequentially && jitter == setting.EvaluationJitterRule {
+					jitter = setting.EvaluationJitterGroup
+				}
+
 				itemFrequency := item.IntervalSeconds / int64(sch.baseInterval.Seconds())
-				if item.IntervalSeconds != 0 && tickNum%itemFrequency == 0 {
-					readyToRun = append(readyToRun, readyToRunItem{key: key, ruleInfo: ruleInfo})
+				if item.IntervalSeconds != 0 && tickNum%itemFrequency == jitterOffset(item, jitter, itemFrequency) {
+					ready := readyToRunItem{key: key, ruleInfo: ruleInfo, groupIndex: item.RuleGroupIndex}
+					if item.EvaluateSequentially {
+						groupKey := ruleGroupKey{orgID: item.OrgID, namespaceUID: item.NamespaceUID, ruleGroup: item.RuleGroup}
+						if _, ok := sequentialGroups[groupKey]; !ok {
+							sequentialGroupKeys = append(sequentialGroupKeys, groupKey)
+						}
+						sequentialGroups[groupKey] = append(sequentialGroups[groupKey], ready)
+					} else {
+						readyToRun = append(readyToRun, ready)
+					}
 				}
 
 				// remove the alert rule from the registered alert rules
@@ -383,15 +421,30 @@ func (sch *schedule) ruleEvaluationLoop(ctx context.Context) error {
 			}
 
 			var step int64 = 0
-			if len(readyToRun) > 0 {
-				step = sch.baseInterval.Nanoseconds() / int64(len(readyToRun))
+			if n := len(readyToRun) + len(sequentialGroupKeys); n > 0 {
+				step = sch.baseInterval.Nanoseconds() / int64(n)
 			}
 
 			for i := range readyToRun {
 				item := readyToRun[i]
 
 				time.AfterFunc(time.Duration(int64(i)*step), func() {
-					item.ruleInfo.evalCh <- &evalContext{now: tick, version: item.ruleInfo.version}
+					item.ruleInfo.evalCh <- &evalContext{now: tick, version: item.ruleInfo.version, scheduledAt: timeNow()}
+				})
+			}
+
+			for i, groupKey := range sequentialGroupKeys {
+				groupKey := groupKey
+				items := sequentialGroups[groupKey]
+				sort.Slice(items, func(a, b int) bool {
+					if items[a].groupIndex != items[b].groupIndex {
+						return items[a].groupIndex < items[b].groupIndex
+					}
+					return items[a].key.UID < items[b].key.UID
+				})
+
+				time.AfterFunc(time.Duration(int64(len(readyToRun)+i)*step), func() {
+					sch.evaluateSequentially(ctx, groupKey, tick, items)
 				})
 			}
 
@@ -433,12 +486,11 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key models.AlertRul
 		select {
 		case ctx := <-evalCh:
 			if evalRunning {
+				ctx.finish()
 				continue
 			}
 
 			evaluate := func(attempt int64) error {
-				start := timeNow()
-
 				// fetch latest alert rule version
 				if alertRule == nil || alertRule.Version < ctx.version {
 					q := models.GetAlertRuleByUIDQuery{OrgID: key.OrgID, UID: key.UID}
@@ -451,12 +503,28 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key models.AlertRul
 					sch.log.Debug("new alert rule version fetched", "title", alertRule.Title, "key", key, "version", alertRule.Version)
 				}
 
+				release, err := sch.datasourceLimiter.acquire(grafanaCtx, datasourceUIDs(alertRule))
+				if err != nil {
+					return err
+				}
+				if attempt == 0 {
+					sch.metrics.EvalLag.WithLabelValues(fmt.Sprint(alertRule.OrgID)).Observe(timeNow().Sub(ctx.scheduledAt).Seconds())
+				}
+				// the time spent waiting for the datasources is part of the lag, not of the evaluation
+				start := timeNow()
+
+				if alertRule.IsRecordingRule() {
+					defer release()
+					return sch.record(alertRule, ctx.now, attempt)
+				}
+
 				condition := models.Condition{
 					Condition: alertRule.Condition,
 					OrgID:     alertRule.OrgID,
 					Data:      alertRule.Data,
 				}
 				results, err := sch.evaluator.ConditionEval(&condition, ctx.now, sch.dataService)
+				release()
 				var (
 					end    = timeNow()
 					tenant = fmt.Sprint(alertRule.OrgID)
@@ -473,7 +541,7 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key models.AlertRul
 					return err
 				}
 
-				processedStates := sch.stateManager.ProcessEvalResults(context.Background(), alertRule, results)
+				processedStates := sch.stateManager.ProcessEvalResults(grafanaCtx, alertRule, results)
 				sch.saveAlertStates(processedStates)
 				alerts := FromAlertStateToPostableAlerts(processedStates, sch.stateManager, sch.appURL)
 
@@ -507,6 +575,7 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key models.AlertRul
 				evalRunning = true
 				defer func() {
 					evalRunning = false
+					ctx.finish()
 					sch.evalApplied(key, ctx.now)
 				}()
 
@@ -528,6 +597,73 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key models.AlertRul
 	}
 }
 
+// evaluateSequentially sends the evaluation of each rule of the group to its routine once the
+// evaluation of the previous rule is done, so that the rules are evaluated in order within the tick.
+// The rules whose routine has stopped, because they were deleted in the meantime, are skipped.
+func (sch *schedule) evaluateSequentially(ctx context.Context, groupKey ruleGroupKey, tick time.Time, items []readyToRunItem) {
+	start := timeNow()
+	for _, item := range items {
+		done := make(chan struct{})
+		select {
+		case item.ruleInfo.evalCh <- &evalContext{now: tick, version: item.ruleInfo.version, scheduledAt: timeNow(), done: done}:
+		case <-item.ruleInfo.stoppedCh:
+			sch.log.Debug("alert rule routine stopped, skipping its evaluation", "key", item.key, "group", groupKey.ruleGroup)
+			continue
+		case <-ctx.Done():
+			return
+		}
+
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return
+		}
+	}
+	sch.metrics.GroupEvalDuration.WithLabelValues(fmt.Sprint(groupKey.orgID)).Observe(timeNow().Sub(start).Seconds())
+}
+
+// record evaluates the queries and expressions of a recording rule and writes the result of its condition.
+func (sch *schedule) record(alertRule *models.AlertRule, now time.Time, attempt int64) error {
+	key := alertRule.GetKey()
+	if sch.recordingWriter == nil {
+		sch.log.Debug("recording rule will not be evaluated: no remote write endpoint is configured", "key", key)
+		return nil
+	}
+
+	start := timeNow()
+	resp, err := sch.evaluator.QueriesAndExpressionsEval(alertRule.OrgID, alertRule.Data, now, sch.dataService)
+	if err == nil {
+		res, ok := resp.Responses[alertRule.Condition]
+		switch {
+		case !ok:
+			err = fmt.Errorf("no result for condition %s", alertRule.Condition)
+		case res.Error != nil:
+			err = res.Error
+		}
+	}
+	var (
+		end    = timeNow()
+		tenant = fmt.Sprint(alertRule.OrgID)
+		dur    = end.Sub(start).Seconds()
+	)
+
+	sch.metrics.EvalTotal.WithLabelValues(tenant).Inc()
+	sch.metrics.EvalDuration.WithLabelValues(tenant).Observe(dur)
+	if err != nil {
+		sch.metrics.EvalFailures.WithLabelValues(tenant).Inc()
+		sch.log.Error("failed to evaluate recording rule", "title", alertRule.Title,
+			"key", key, "attempt", attempt, "now", now, "duration", end.Sub(start), "error", err)
+		return err
+	}
+
+	frames := resp.Responses[alertRule.Condition].Frames
+	if err := sch.recordingWriter.Write(context.Background(), alertRule.Record, alertRule.Labels, frames, now); err != nil {
+		sch.log.Error("failed to write the result of recording rule", "title", alertRule.Title, "key", key, "attempt", attempt, "record", alertRule.Record, "error", err)
+		return err
+	}
+	return nil
+}
+
 func (sch *schedule) saveAlertStates(states []*state.State) {
 	sch.log.Debug("saving alert states", "count", len(states))
 	for _, s := range states {
@@ -560,7 +696,7 @@ func (r *alertRuleRegistry) getOrCreateInfo(key models.AlertRuleKey, ruleVersion
 
 	info, ok := r.alertRuleInfo[key]
 	if !ok {
-		r.alertRuleInfo[key] = alertRuleInfo{evalCh: make(chan *evalContext), stopCh: make(chan struct{}), version: ruleVersion}
+		r.alertRuleInfo[key] = alertRuleInfo{evalCh: make(chan *evalContext), stopCh: make(chan struct{}), stoppedCh: make(chan struct{}), version: ruleVersion}
 		return r.alertRuleInfo[key]
 	}
 	info.version = ruleVersion
@@ -622,14 +758,41 @@ func (r *alertRuleRegistry) keyMap() map[models.AlertRuleKey]struct{} {
 }
 
 type alertRuleInfo struct {
-	evalCh  chan *evalContext
-	stopCh  chan struct{}
-	version int64
+	evalCh chan *evalContext
+	stopCh chan struct{}
+	// stoppedCh is closed once the routine of the rule has returned.
+	stoppedCh chan struct{}
+	version   int64
 }
 
 type evalContext struct {
 	now     time.Time
 	version int64
+	// scheduledAt is when the evaluation is sent to the routine of the rule.
+	scheduledAt time.Time
+	// done is closed once the evaluation is over, if it is not nil.
+	done chan struct{}
+}
+
+// finish signals that the evaluation is over.
+func (c *evalContext) finish() {
+	if c.done != nil {
+		close(c.done)
+	}
+}
+
+type readyToRunItem struct {
+	key      models.AlertRuleKey
+	ruleInfo alertRuleInfo
+	// groupIndex is the position of the rule in its rule group.
+	groupIndex int
+}
+
+// ruleGroupKey identifies a rule group.
+type ruleGroupKey struct {
+	orgID        int64
+	namespaceUID string
+	ruleGroup    string
 }
 
 // overrideCfg is only used on tests.
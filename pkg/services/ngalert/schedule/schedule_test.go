@@ -16,6 +16,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/schedule"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/services/ngalert/tests"
 
 	"github.com/benbjohnson/clock"
@@ -104,7 +105,7 @@ func TestWarmStateCache(t *testing.T) {
 		Metrics:                 testMetrics.GetSchedulerMetrics(),
 		AdminConfigPollInterval: 10 * time.Minute, // do not poll in unit tests.
 	}
-	st := state.NewManager(schedCfg.Logger, testMetrics.GetStateMetrics(), nil, dbstore, dbstore)
+	st := state.NewManager(schedCfg.Logger, testMetrics.GetStateMetrics(), nil, dbstore, dbstore, dbstore, nil)
 	st.Warm()
 
 	t.Run("instance cache has expected entries", func(t *testing.T) {
@@ -155,7 +156,7 @@ func TestAlertingTicker(t *testing.T) {
 			disabledOrgID: {},
 		},
 	}
-	st := state.NewManager(schedCfg.Logger, testMetrics.GetStateMetrics(), nil, dbstore, dbstore)
+	st := state.NewManager(schedCfg.Logger, testMetrics.GetStateMetrics(), nil, dbstore, dbstore, dbstore, nil)
 	appUrl := &url.URL{
 		Scheme: "http",
 		Host:   "localhost",
@@ -237,6 +238,52 @@ func TestAlertingTicker(t *testing.T) {
 		tick := advanceClock(t, mockedClock)
 		assertEvalRun(t, evalAppliedCh, tick, expectedAlertRulesEvaluated...)
 	})
+
+	// pause the alert rule with one second interval
+	paused := *alerts[2]
+	paused.IsPaused = true
+	err = dbstore.UpsertAlertRules([]store.UpsertRule{{Existing: alerts[2], New: paused}})
+	require.NoError(t, err)
+	t.Logf("alert rule: %v paused", alerts[2].GetKey())
+
+	expectedAlertRulesEvaluated = []models.AlertRuleKey{alerts[1].GetKey()}
+	t.Run(fmt.Sprintf("on 9th tick alert rules: %s should be evaluated", concatenate(expectedAlertRulesEvaluated)), func(t *testing.T) {
+		tick := advanceClock(t, mockedClock)
+		assertEvalRun(t, evalAppliedCh, tick, expectedAlertRulesEvaluated...)
+	})
+	t.Run("on 9th tick paused alert rules should not be stopped", func(t *testing.T) {
+		assertStopRun(t, stopAppliedCh)
+	})
+
+	// resume the alert rule with one second interval but pause its rule group
+	q := models.GetAlertRuleByUIDQuery{OrgID: mainOrgID, UID: alerts[2].UID}
+	require.NoError(t, dbstore.GetAlertRuleByUID(&q))
+	groupPaused := *q.Result
+	groupPaused.IsPaused = false
+	groupPaused.RuleGroupIsPaused = true
+	err = dbstore.UpsertAlertRules([]store.UpsertRule{{Existing: q.Result, New: groupPaused}})
+	require.NoError(t, err)
+	t.Logf("rule group of alert rule: %v paused", alerts[2].GetKey())
+
+	t.Run("on 10th tick alert rules of paused rule groups should not be evaluated", func(t *testing.T) {
+		tick := advanceClock(t, mockedClock)
+		assertEvalRun(t, evalAppliedCh, tick)
+	})
+
+	// resume the rule group
+	q = models.GetAlertRuleByUIDQuery{OrgID: mainOrgID, UID: alerts[2].UID}
+	require.NoError(t, dbstore.GetAlertRuleByUID(&q))
+	resumed := *q.Result
+	resumed.RuleGroupIsPaused = false
+	err = dbstore.UpsertAlertRules([]store.UpsertRule{{Existing: q.Result, New: resumed}})
+	require.NoError(t, err)
+	t.Logf("rule group of alert rule: %v resumed", alerts[2].GetKey())
+
+	expectedAlertRulesEvaluated = []models.AlertRuleKey{alerts[2].GetKey()}
+	t.Run(fmt.Sprintf("on 11th tick alert rules: %s should be evaluated", concatenate(expectedAlertRulesEvaluated)), func(t *testing.T) {
+		tick := advanceClock(t, mockedClock)
+		assertEvalRun(t, evalAppliedCh, tick, expectedAlertRulesEvaluated...)
+	})
 }
 
 func assertEvalRun(t *testing.T, ch <-chan evalAppliedInfo, tick time.Time, keys ...models.AlertRuleKey) {
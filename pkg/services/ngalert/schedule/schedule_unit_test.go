@@ -21,6 +21,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/require"
 )
@@ -231,6 +232,226 @@ func TestSendingToExternalAlertmanager_WithMultipleOrgs(t *testing.T) {
 	}, 10*time.Second, 200*time.Millisecond, "Alertmanager for org 1 and 2 were never removed")
 }
 
+func TestRecordingRule(t *testing.T) {
+	fakeRuleStore := newFakeRuleStore(t)
+	fakeInstanceStore := &fakeInstanceStore{}
+	fakeAdminConfigStore := newFakeAdminConfigStore(t)
+
+	// create a recording rule with one second interval
+	alertRule := CreateTestAlertRule(t, fakeRuleStore, 1, 1)
+	alertRule.Record = "test:recorded"
+	alertRule.Labels = map[string]string{"team": "alerting"}
+
+	sched, mockedClock := setupScheduler(t, fakeRuleStore, fakeInstanceStore, fakeAdminConfigStore)
+	writer := &fakeRecordingWriter{}
+	sched.recordingWriter = writer
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(func() {
+		cancel()
+	})
+	go func() {
+		err := sched.Run(ctx)
+		require.NoError(t, err)
+	}()
+
+	mockedClock.Add(2 * time.Second)
+
+	require.Eventually(t, func() bool {
+		return len(writer.Writes()) >= 1
+	}, 10*time.Second, 200*time.Millisecond)
+
+	write := writer.Writes()[0]
+	require.Equal(t, "test:recorded", write.name)
+	require.Equal(t, map[string]string{"team": "alerting"}, write.labels)
+	require.Len(t, write.frames, 1)
+
+	// recording rules do not produce alert states
+	require.Empty(t, sched.stateManager.GetStatesForRuleUID(alertRule.OrgID, alertRule.UID))
+}
+
+func TestJitterOffset(t *testing.T) {
+	rule := func(uid, group string) *models.AlertRule {
+		return &models.AlertRule{OrgID: 1, UID: uid, NamespaceUID: "namespace", RuleGroup: group}
+	}
+
+	t.Run("rules are not spread without jitter", func(t *testing.T) {
+		require.Equal(t, int64(0), jitterOffset(rule("a", "group"), setting.EvaluationJitterNone, 6))
+	})
+
+	t.Run("rules evaluated at every tick are not spread", func(t *testing.T) {
+		require.Equal(t, int64(0), jitterOffset(rule("a", "group"), setting.EvaluationJitterRule, 1))
+	})
+
+	t.Run("rules are spread over their interval", func(t *testing.T) {
+		offsets := make(map[int64]struct{})
+		for i := 0; i < 100; i++ {
+			offset := jitterOffset(rule(fmt.Sprintf("rule-%d", i), "group"), setting.EvaluationJitterRule, 6)
+			require.GreaterOrEqual(t, offset, int64(0))
+			require.Less(t, offset, int64(6))
+			// the offset of a rule does not change
+			require.Equal(t, offset, jitterOffset(rule(fmt.Sprintf("rule-%d", i), "group"), setting.EvaluationJitterRule, 6))
+			offsets[offset] = struct{}{}
+		}
+		require.Len(t, offsets, 6)
+	})
+
+	t.Run("the rules of a group have the same offset", func(t *testing.T) {
+		offsets := make(map[int64]struct{})
+		for i := 0; i < 100; i++ {
+			offsets[jitterOffset(rule(fmt.Sprintf("rule-%d", i), "group"), setting.EvaluationJitterGroup, 6)] = struct{}{}
+		}
+		require.Len(t, offsets, 1)
+	})
+}
+
+func TestDatasourceLimiter(t *testing.T) {
+	inFlight := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "in_flight"}, []string{"datasource_uid"})
+	limiter := newDatasourceLimiter(1, inFlight)
+
+	release, err := limiter.acquire(context.Background(), []string{"a", "b"})
+	require.NoError(t, err)
+	require.Equal(t, float64(1), testutil.ToFloat64(inFlight.WithLabelValues("a")))
+
+	t.Run("evaluations wait for a slot of every datasource", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		_, err := limiter.acquire(ctx, []string{"b"})
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+
+		// datasources without evaluations are not limited
+		releaseC, err := limiter.acquire(context.Background(), []string{"c"})
+		require.NoError(t, err)
+		releaseC()
+	})
+
+	t.Run("released slots can be acquired", func(t *testing.T) {
+		release()
+		require.Equal(t, float64(0), testutil.ToFloat64(inFlight.WithLabelValues("a")))
+
+		release, err := limiter.acquire(context.Background(), []string{"a", "b"})
+		require.NoError(t, err)
+		release()
+	})
+
+	t.Run("evaluations are not limited without a limit", func(t *testing.T) {
+		limiter := newDatasourceLimiter(0, nil)
+		for i := 0; i < 10; i++ {
+			_, err := limiter.acquire(context.Background(), []string{"a"})
+			require.NoError(t, err)
+		}
+	})
+}
+
+func TestDatasourceUIDs(t *testing.T) {
+	rule := &models.AlertRule{
+		Data: []models.AlertQuery{
+			{RefID: "A", DatasourceUID: "b", Model: json.RawMessage(`{}`)},
+			{RefID: "B", DatasourceUID: "a", Model: json.RawMessage(`{}`)},
+			{RefID: "C", DatasourceUID: "b", Model: json.RawMessage(`{}`)},
+			{RefID: "D", DatasourceUID: "-100", Model: json.RawMessage(`{"type":"math","expression":"$A"}`)},
+		},
+	}
+	require.Equal(t, []string{"a", "b"}, datasourceUIDs(rule))
+}
+
+func TestEvaluateSequentially(t *testing.T) {
+	m := metrics.NewNGAlert(prometheus.NewPedanticRegistry()).GetSchedulerMetrics()
+	sch := &schedule{metrics: m, log: log.New("ngalert schedule test")}
+	groupKey := ruleGroupKey{orgID: 1, namespaceUID: "namespace", ruleGroup: "group"}
+
+	items := make([]readyToRunItem, 0, 3)
+	for i := 1; i <= 3; i++ {
+		items = append(items, readyToRunItem{
+			key:        models.AlertRuleKey{OrgID: 1, UID: fmt.Sprintf("rule-%d", i)},
+			ruleInfo:   alertRuleInfo{evalCh: make(chan *evalContext), stoppedCh: make(chan struct{}), version: 1},
+			groupIndex: i,
+		})
+	}
+
+	t.Run("rules are evaluated once the previous rule is", func(t *testing.T) {
+		tick := time.Now()
+		done := make(chan struct{})
+		go func() {
+			sch.evaluateSequentially(context.Background(), groupKey, tick, items)
+			close(done)
+		}()
+
+		for i, item := range items {
+			var ctx *evalContext
+			select {
+			case ctx = <-item.ruleInfo.evalCh:
+			case <-time.After(time.Second):
+				t.Fatalf("rule %s was not evaluated", item.key)
+			}
+			require.Equal(t, tick, ctx.now)
+
+			// the next rule is not evaluated before the evaluation of the rule is over
+			if i+1 < len(items) {
+				select {
+				case <-items[i+1].ruleInfo.evalCh:
+					t.Fatalf("rule %s was evaluated before rule %s", items[i+1].key, item.key)
+				case <-time.After(50 * time.Millisecond):
+				}
+			}
+			ctx.finish()
+		}
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("the evaluation of the group is not over")
+		}
+		require.Equal(t, 1, testutil.CollectAndCount(m.GroupEvalDuration))
+	})
+
+	t.Run("rules whose routine stopped are skipped", func(t *testing.T) {
+		stopped := items[1]
+		stopped.ruleInfo.stoppedCh = make(chan struct{})
+		close(stopped.ruleInfo.stoppedCh)
+		items := []readyToRunItem{items[0], stopped, items[2]}
+
+		done := make(chan struct{})
+		go func() {
+			sch.evaluateSequentially(context.Background(), groupKey, time.Now(), items)
+			close(done)
+		}()
+
+		for _, item := range []readyToRunItem{items[0], items[2]} {
+			select {
+			case ctx := <-item.ruleInfo.evalCh:
+				ctx.finish()
+			case <-time.After(time.Second):
+				t.Fatalf("rule %s was not evaluated", item.key)
+			}
+		}
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("the evaluation of the group is not over")
+		}
+	})
+
+	t.Run("the evaluation of the group stops with the scheduler", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			sch.evaluateSequentially(ctx, groupKey, time.Now(), items)
+			close(done)
+		}()
+
+		<-items[0].ruleInfo.evalCh
+		cancel()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("the evaluation of the group did not stop")
+		}
+	})
+}
+
 func setupScheduler(t *testing.T, rs store.RuleStore, is store.InstanceStore, acs store.AdminConfigurationStore) (*schedule, *clock.Mock) {
 	t.Helper()
 
@@ -254,7 +475,7 @@ func setupScheduler(t *testing.T, rs store.RuleStore, is store.InstanceStore, ac
 		Metrics:                 m.GetSchedulerMetrics(),
 		AdminConfigPollInterval: 10 * time.Minute, // do not poll in unit tests.
 	}
-	st := state.NewManager(schedCfg.Logger, m.GetStateMetrics(), nil, rs, is)
+	st := state.NewManager(schedCfg.Logger, m.GetStateMetrics(), nil, rs, is, nil, nil)
 	appUrl := &url.URL{
 		Scheme: "http",
 		Host:   "localhost",
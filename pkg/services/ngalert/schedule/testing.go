@@ -10,6 +10,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	models2 "github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
@@ -118,6 +119,14 @@ func (f *fakeRuleStore) GetNamespaceByTitle(_ context.Context, _ string, _ int64
 }
 func (f *fakeRuleStore) GetOrgRuleGroups(_ *models.ListOrgRuleGroupsQuery) error { return nil }
 func (f *fakeRuleStore) UpsertAlertRules(_ []store.UpsertRule) error             { return nil }
+func (f *fakeRuleStore) UpdateRuleGroups(cmds []store.UpdateRuleGroupCmd) error {
+	for _, cmd := range cmds {
+		if err := f.UpdateRuleGroup(cmd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 func (f *fakeRuleStore) UpdateRuleGroup(cmd store.UpdateRuleGroupCmd) error {
 	f.mtx.Lock()
 	defer f.mtx.Unlock()
@@ -137,24 +146,30 @@ func (f *fakeRuleStore) UpdateRuleGroup(cmd store.UpdateRuleGroupCmd) error {
 	}
 
 	rules := []*models.AlertRule{}
-	for _, r := range cmd.RuleGroupConfig.Rules {
+	for idx, r := range cmd.RuleGroupConfig.Rules {
 		//TODO: Not sure why this is not being set properly, where is the code that sets this?
 		for i := range r.GrafanaManagedAlert.Data {
 			r.GrafanaManagedAlert.Data[i].DatasourceUID = "-100"
 		}
 
 		new := &models.AlertRule{
-			OrgID:           cmd.OrgID,
-			Title:           r.GrafanaManagedAlert.Title,
-			Condition:       r.GrafanaManagedAlert.Condition,
-			Data:            r.GrafanaManagedAlert.Data,
-			UID:             util.GenerateShortUID(),
-			IntervalSeconds: int64(time.Duration(cmd.RuleGroupConfig.Interval).Seconds()),
-			NamespaceUID:    cmd.NamespaceUID,
-			RuleGroup:       cmd.RuleGroupConfig.Name,
-			NoDataState:     models.NoDataState(r.GrafanaManagedAlert.NoDataState),
-			ExecErrState:    models.ExecutionErrorState(r.GrafanaManagedAlert.ExecErrState),
-			Version:         1,
+			OrgID:                cmd.OrgID,
+			Title:                r.GrafanaManagedAlert.Title,
+			Condition:            r.GrafanaManagedAlert.Condition,
+			Data:                 r.GrafanaManagedAlert.Data,
+			UID:                  util.GenerateShortUID(),
+			IntervalSeconds:      int64(time.Duration(cmd.RuleGroupConfig.Interval).Seconds()),
+			NamespaceUID:         cmd.NamespaceUID,
+			RuleGroup:            cmd.RuleGroupConfig.Name,
+			NoDataState:          models.NoDataState(r.GrafanaManagedAlert.NoDataState),
+			ExecErrState:         models.ExecutionErrorState(r.GrafanaManagedAlert.ExecErrState),
+			IsPaused:             r.GrafanaManagedAlert.IsPaused,
+			Record:               r.GrafanaManagedAlert.Record,
+			RuleGroupIndex:       idx + 1,
+			Version:              1,
+			EvaluateSequentially: cmd.RuleGroupConfig.EvaluateSequentially,
+			RuleGroupIsPaused:    cmd.RuleGroupConfig.IsPaused,
+			DependsOn:            r.GrafanaManagedAlert.DependsOn,
 		}
 
 		if r.ApiRuleNode != nil {
@@ -302,3 +317,29 @@ func (am *FakeExternalAlertmanager) Handler() func(w http.ResponseWriter, r *htt
 func (am *FakeExternalAlertmanager) Close() {
 	am.server.Close()
 }
+
+type fakeRecordingWrite struct {
+	name   string
+	labels map[string]string
+	frames data.Frames
+	now    time.Time
+}
+
+// fakeRecordingWriter records the series written by recording rules.
+type fakeRecordingWriter struct {
+	mtx    sync.Mutex
+	writes []fakeRecordingWrite
+}
+
+func (w *fakeRecordingWriter) Write(_ context.Context, name string, labels map[string]string, frames data.Frames, now time.Time) error {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	w.writes = append(w.writes, fakeRecordingWrite{name: name, labels: labels, frames: frames, now: now})
+	return nil
+}
+
+func (w *fakeRecordingWriter) Writes() []fakeRecordingWrite {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	return append([]fakeRecordingWrite{}, w.writes...)
+}
@@ -1,10 +1,12 @@
 package state
 
 import (
+	"context"
 	"fmt"
 	"net/url"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 
@@ -13,33 +15,40 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	ngModels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	prometheusModel "github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/promql"
 )
 
+// templateQueryTimeout is the timeout of the queries run by the templates of an alert instance.
+const templateQueryTimeout = 30 * time.Second
+
 type cache struct {
 	states      map[int64]map[string]map[string]*State // orgID > alertRuleUID > stateID > state
 	mtxStates   sync.RWMutex
 	log         log.Logger
 	metrics     *metrics.State
 	externalURL *url.URL
+	querier     TemplateQuerier
 }
 
-func newCache(logger log.Logger, metrics *metrics.State, externalURL *url.URL) *cache {
+func newCache(logger log.Logger, metrics *metrics.State, externalURL *url.URL, querier TemplateQuerier) *cache {
 	return &cache{
 		states:      make(map[int64]map[string]map[string]*State),
 		log:         logger,
 		metrics:     metrics,
 		externalURL: externalURL,
+		querier:     querier,
 	}
 }
 
-func (c *cache) getOrCreate(alertRule *ngModels.AlertRule, result eval.Result) *State {
-	c.mtxStates.Lock()
-	defer c.mtxStates.Unlock()
-
+func (c *cache) getOrCreate(ctx context.Context, alertRule *ngModels.AlertRule, result eval.Result) *State {
 	// clone the labels so we don't change eval.Result
 	labels := result.Instance.Copy()
 	attachRuleLabels(labels, alertRule)
-	ruleLabels, annotations := c.expandRuleLabelsAndAnnotations(alertRule, labels, result)
+	// templates can run queries, so they are expanded before the states are locked
+	ruleLabels, annotations := c.expandRuleLabelsAndAnnotations(ctx, alertRule, labels, result)
+
+	c.mtxStates.Lock()
+	defer c.mtxStates.Unlock()
 
 	// if duplicate labels exist, alertRule label will take precedence
 	lbs := mergeLabels(ruleLabels, result.Instance)
@@ -88,11 +97,21 @@ func attachRuleLabels(m map[string]string, alertRule *ngModels.AlertRule) {
 	m[prometheusModel.AlertNameLabel] = alertRule.Title
 }
 
-func (c *cache) expandRuleLabelsAndAnnotations(alertRule *ngModels.AlertRule, labels map[string]string, alertInstance eval.Result) (map[string]string, map[string]string) {
+func (c *cache) expandRuleLabelsAndAnnotations(ctx context.Context, alertRule *ngModels.AlertRule, labels map[string]string, alertInstance eval.Result) (map[string]string, map[string]string) {
+	ctx, cancel := context.WithTimeout(ctx, templateQueryTimeout)
+	defer cancel()
+
+	var query queryFunc
+	if c.querier != nil {
+		query = func(ctx context.Context, q string, ts time.Time) (promql.Vector, error) {
+			return c.querier.Query(ctx, alertRule, q, ts)
+		}
+	}
+
 	expand := func(original map[string]string) map[string]string {
 		expanded := make(map[string]string, len(original))
 		for k, v := range original {
-			ev, err := expandTemplate(alertRule.Title, v, labels, alertInstance, c.externalURL)
+			ev, err := expandTemplate(ctx, alertRule.Title, v, labels, alertInstance, c.externalURL, query)
 			expanded[k] = ev
 			if err != nil {
 				c.log.Error("error in expanding template", "name", k, "value", v, "err", err.Error())
@@ -169,11 +188,12 @@ func (c *cache) recordMetrics() {
 	// Set default values to zero such that gauges are reset
 	// after all values from a single state disappear.
 	ct := map[eval.State]int{
-		eval.Normal:   0,
-		eval.Alerting: 0,
-		eval.Pending:  0,
-		eval.NoData:   0,
-		eval.Error:    0,
+		eval.Normal:    0,
+		eval.Alerting:  0,
+		eval.Pending:   0,
+		eval.NoData:    0,
+		eval.Error:     0,
+		eval.Inhibited: 0,
 	}
 
 	for org, orgMap := range c.states {
@@ -2,6 +2,7 @@ package state
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/url"
 	"strconv"
@@ -31,23 +32,46 @@ type Manager struct {
 
 	ruleStore     store.RuleStore
 	instanceStore store.InstanceStore
+	historyStore  store.StateHistoryStore
+
+	// inMemory is true if the manager keeps the states in its cache only, without
+	// saving alert instances, state history or annotations.
+	inMemory bool
 }
 
-func NewManager(logger log.Logger, metrics *metrics.State, externalURL *url.URL, ruleStore store.RuleStore, instanceStore store.InstanceStore) *Manager {
+func NewManager(logger log.Logger, metrics *metrics.State, externalURL *url.URL, ruleStore store.RuleStore, instanceStore store.InstanceStore, historyStore store.StateHistoryStore, querier TemplateQuerier) *Manager {
 	manager := &Manager{
-		cache:         newCache(logger, metrics, externalURL),
+		cache:         newCache(logger, metrics, externalURL, querier),
 		quit:          make(chan struct{}),
 		ResendDelay:   ResendDelay, // TODO: make this configurable
 		log:           logger,
 		metrics:       metrics,
 		ruleStore:     ruleStore,
 		instanceStore: instanceStore,
+		historyStore:  historyStore,
 	}
 	go manager.recordMetrics()
 	return manager
 }
 
+// NewInMemoryManager returns a Manager that keeps the states in its cache only.
+// Evaluations of alert rules over a past time range can be replayed with it, as
+// it considers states stale relative to the time of the evaluations.
+func NewInMemoryManager(logger log.Logger, externalURL *url.URL, querier TemplateQuerier) *Manager {
+	return &Manager{
+		cache:       newCache(logger, nil, externalURL, querier),
+		quit:        make(chan struct{}),
+		ResendDelay: ResendDelay,
+		log:         logger,
+		inMemory:    true,
+	}
+}
+
 func (st *Manager) Close() {
+	if st.inMemory {
+		// in memory managers do not record metrics
+		return
+	}
 	st.quit <- struct{}{}
 }
 
@@ -116,8 +140,8 @@ func (st *Manager) Warm() {
 	}
 }
 
-func (st *Manager) getOrCreate(alertRule *ngModels.AlertRule, result eval.Result) *State {
-	return st.cache.getOrCreate(alertRule, result)
+func (st *Manager) getOrCreate(ctx context.Context, alertRule *ngModels.AlertRule, result eval.Result) *State {
+	return st.cache.getOrCreate(ctx, alertRule, result)
 }
 
 func (st *Manager) set(entry *State) {
@@ -141,19 +165,32 @@ func (st *Manager) RemoveByRuleUID(orgID int64, ruleUID string) {
 func (st *Manager) ProcessEvalResults(ctx context.Context, alertRule *ngModels.AlertRule, results eval.Results) []*State {
 	st.log.Debug("state manager processing evaluation results", "uid", alertRule.UID, "resultCount", len(results))
 	var states []*State
+	var transitions []*ngModels.SaveAlertStateHistoryCommand
 	processedResults := make(map[string]*State, len(results))
 	for _, result := range results {
-		s := st.setNextState(ctx, alertRule, result)
+		s, transition := st.setNextState(ctx, alertRule, result)
 		states = append(states, s)
 		processedResults[s.CacheId] = s
+		if transition != nil {
+			transitions = append(transitions, transition)
+		}
+	}
+	if len(transitions) > 0 {
+		// the transitions of an evaluation are saved together, before the next evaluation of the rule
+		st.saveStateHistory(ctx, alertRule, transitions)
 	}
-	st.staleResultsHandler(alertRule, processedResults)
+	now := time.Now()
+	if st.inMemory && len(results) > 0 {
+		now = results[0].EvaluatedAt
+	}
+	st.staleResultsHandler(now, alertRule, processedResults)
 	return states
 }
 
-// Set the current state based on evaluation results
-func (st *Manager) setNextState(ctx context.Context, alertRule *ngModels.AlertRule, result eval.Result) *State {
-	currentState := st.getOrCreate(alertRule, result)
+// Set the current state based on evaluation results. The state transition is returned
+// if the state changed, to be saved in the state history.
+func (st *Manager) setNextState(ctx context.Context, alertRule *ngModels.AlertRule, result eval.Result) (*State, *ngModels.SaveAlertStateHistoryCommand) {
+	currentState := st.getOrCreate(ctx, alertRule, result)
 
 	currentState.LastEvaluationTime = result.EvaluatedAt
 	currentState.EvaluationDuration = result.EvaluationDuration
@@ -166,6 +203,11 @@ func (st *Manager) setNextState(ctx context.Context, alertRule *ngModels.AlertRu
 	currentState.TrimResults(alertRule)
 	oldState := currentState.State
 
+	// an inhibited alert is firing, so its next state is the one of a firing alert
+	if currentState.State == eval.Inhibited {
+		currentState.State = eval.Alerting
+	}
+
 	st.log.Debug("setting alert state", "uid", alertRule.UID)
 	switch result.State {
 	case eval.Normal:
@@ -179,15 +221,44 @@ func (st *Manager) setNextState(ctx context.Context, alertRule *ngModels.AlertRu
 	case eval.Pending: // we do not emit results with this state
 	}
 
+	if currentState.State == eval.Alerting && st.isInhibited(alertRule, currentState) {
+		currentState.State = eval.Inhibited
+	}
+
 	// Set Resolved property so the scheduler knows to send a postable alert
-	// to Alertmanager.
-	currentState.Resolved = oldState == eval.Alerting && currentState.State == eval.Normal
+	// to Alertmanager. Inhibited alerts are not notified, so firing alerts that
+	// become inhibited are resolved in Alertmanager.
+	currentState.Resolved = oldState == eval.Alerting && (currentState.State == eval.Normal || currentState.State == eval.Inhibited)
+	if currentState.Resolved && currentState.State == eval.Inhibited {
+		currentState.EndsAt = result.EvaluatedAt
+	}
 
 	st.set(currentState)
+	var transition *ngModels.SaveAlertStateHistoryCommand
 	if oldState != currentState.State {
-		go st.createAlertAnnotation(ctx, currentState.State, alertRule, result, oldState)
+		transition = st.stateTransition(currentState, oldState, result)
+		if !st.inMemory {
+			go st.createAlertAnnotation(ctx, currentState.State, alertRule, result, oldState)
+		}
 	}
-	return currentState
+	return currentState, transition
+}
+
+// isInhibited returns true if an alert of a rule the alert rule depends on is firing
+// and has the labels the dependency requires to be equal to the ones of the state.
+func (st *Manager) isInhibited(alertRule *ngModels.AlertRule, s *State) bool {
+	for _, d := range alertRule.DependsOn {
+		for _, source := range st.GetStatesForRuleUID(alertRule.OrgID, d.RuleUID) {
+			// inhibited alerts are firing too, so they inhibit the alerts that depend on them
+			if source.State != eval.Alerting && source.State != eval.Inhibited {
+				continue
+			}
+			if d.Inhibits(source.Labels, s.Labels) {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 func (st *Manager) GetAll(orgID int64) []*State {
@@ -228,6 +299,8 @@ func translateInstanceState(state ngModels.InstanceStateType) eval.State {
 		return eval.Alerting
 	case state == ngModels.InstanceStateNormal:
 		return eval.Normal
+	case state == ngModels.InstanceStateInhibited:
+		return eval.Inhibited
 	default:
 		return eval.Error
 	}
@@ -278,13 +351,53 @@ func (st *Manager) createAlertAnnotation(ctx context.Context, new eval.State, al
 	}
 }
 
-func (st *Manager) staleResultsHandler(alertRule *ngModels.AlertRule, states map[string]*State) {
+// stateTransition returns the command that records the transition of the alert instance
+// from oldState to its current state in the state history, together with the evaluation
+// that caused it.
+func (st *Manager) stateTransition(s *State, oldState eval.State, result eval.Result) *ngModels.SaveAlertStateHistoryCommand {
+	if st.historyStore == nil {
+		return nil
+	}
+
+	values, err := json.Marshal(NewEvaluationValues(result.Values))
+	if err != nil {
+		st.log.Error("unable to marshal evaluation values for state history", "alertRuleUID", s.AlertRuleUID, "error", err.Error())
+		return nil
+	}
+
+	cmd := &ngModels.SaveAlertStateHistoryCommand{
+		RuleOrgID:        s.OrgID,
+		RuleUID:          s.AlertRuleUID,
+		Labels:           ngModels.InstanceLabels(s.Labels),
+		PreviousState:    ngModels.InstanceStateType(oldState.String()),
+		State:            ngModels.InstanceStateType(s.State.String()),
+		EvaluationString: result.EvaluationString,
+		Values:           string(values),
+		EvaluatedAt:      result.EvaluatedAt,
+	}
+	if result.Error != nil {
+		cmd.Error = result.Error.Error()
+	}
+	return cmd
+}
+
+// saveStateHistory saves the state transitions of an evaluation of the alert rule.
+func (st *Manager) saveStateHistory(ctx context.Context, alertRule *ngModels.AlertRule, transitions []*ngModels.SaveAlertStateHistoryCommand) {
+	if err := st.historyStore.SaveAlertStateHistory(ctx, transitions...); err != nil {
+		st.log.Error("unable to save state history", "alertRuleUID", alertRule.UID, "transitions", len(transitions), "error", err.Error())
+	}
+}
+
+func (st *Manager) staleResultsHandler(now time.Time, alertRule *ngModels.AlertRule, states map[string]*State) {
 	allStates := st.GetStatesForRuleUID(alertRule.OrgID, alertRule.UID)
 	for _, s := range allStates {
 		_, ok := states[s.CacheId]
-		if !ok && isItStale(s.LastEvaluationTime, alertRule.IntervalSeconds) {
+		if !ok && isItStale(now, s.LastEvaluationTime, alertRule.IntervalSeconds) {
 			st.log.Debug("removing stale state entry", "orgID", s.OrgID, "alertRuleUID", s.AlertRuleUID, "cacheID", s.CacheId)
 			st.cache.deleteEntry(s.OrgID, s.AlertRuleUID, s.CacheId)
+			if st.inMemory {
+				continue
+			}
 			ilbs := ngModels.InstanceLabels(s.Labels)
 			_, labelsHash, err := ilbs.StringAndHash()
 			if err != nil {
@@ -298,6 +411,6 @@ func (st *Manager) staleResultsHandler(alertRule *ngModels.AlertRule, states map
 	}
 }
 
-func isItStale(lastEval time.Time, intervalSeconds int64) bool {
-	return lastEval.Add(2 * time.Duration(intervalSeconds) * time.Second).Before(time.Now())
+func isItStale(now time.Time, lastEval time.Time, intervalSeconds int64) bool {
+	return lastEval.Add(2 * time.Duration(intervalSeconds) * time.Second).Before(now)
 }
@@ -37,8 +37,8 @@ type Evaluation struct {
 
 // EvaluationValue contains the labels and value for a RefID in an evaluation.
 type EvaluationValue struct {
-	Labels data.Labels
-	Value  *float64
+	Labels data.Labels `json:"labels"`
+	Value  *float64    `json:"value"`
 }
 
 // NewEvaluationValues returns the labels and values for each RefID in the capture.
@@ -86,17 +86,38 @@ func (a *State) resultAlerting(alertRule *ngModels.AlertRule, result eval.Result
 
 func (a *State) resultError(alertRule *ngModels.AlertRule, result eval.Result) {
 	a.Error = result.Error
+	switch alertRule.ExecErrState {
+	case ngModels.KeepLastStateErrState:
+		a.resultKeepLastState(alertRule, result)
+		return
+	case ngModels.OkErrState:
+		a.resultNormal(alertRule, result)
+		return
+	}
+
 	if a.StartsAt.IsZero() {
 		a.StartsAt = result.EvaluatedAt
 	}
 	a.setEndsAt(alertRule, result)
 
-	if alertRule.ExecErrState == ngModels.AlertingErrState {
+	switch alertRule.ExecErrState {
+	case ngModels.AlertingErrState:
 		a.State = eval.Alerting
+	case ngModels.ErrorErrState:
+		a.State = eval.Error
 	}
 }
 
 func (a *State) resultNoData(alertRule *ngModels.AlertRule, result eval.Result) {
+	switch alertRule.NoDataState {
+	case ngModels.KeepLastState:
+		a.resultKeepLastState(alertRule, result)
+		return
+	case ngModels.OK:
+		a.resultNormal(alertRule, result)
+		return
+	}
+
 	if a.StartsAt.IsZero() {
 		a.StartsAt = result.EvaluatedAt
 	}
@@ -107,17 +128,28 @@ func (a *State) resultNoData(alertRule *ngModels.AlertRule, result eval.Result)
 		a.State = eval.Alerting
 	case ngModels.NoData:
 		a.State = eval.NoData
-	case ngModels.OK:
-		a.State = eval.Normal
+	}
+}
+
+// resultKeepLastState leaves the state as it was before the evaluation. A firing
+// alert keeps firing, so its ending timestamp is moved forward as if the
+// evaluation had been alerting.
+func (a *State) resultKeepLastState(alertRule *ngModels.AlertRule, result eval.Result) {
+	if a.StartsAt.IsZero() {
+		a.StartsAt = result.EvaluatedAt
+	}
+	if a.State == eval.Alerting {
+		a.setEndsAt(alertRule, result)
 	}
 }
 
 func (a *State) NeedsSending(resendDelay time.Duration) bool {
-	if a.State != eval.Alerting && a.State != eval.Normal {
+	if a.State != eval.Alerting && a.State != eval.Normal && a.State != eval.Inhibited {
 		return false
 	}
 
-	if a.State == eval.Normal && !a.Resolved {
+	// normal and inhibited alerts are only sent once, to resolve the firing alert
+	if a.State != eval.Alerting && !a.Resolved {
 		return false
 	}
 	// if LastSentAt is before or equal to LastEvaluationTime + resendDelay, send again
@@ -4,6 +4,7 @@ import (
 	"context"
 	"math"
 	"net/url"
+	"reflect"
 	"strconv"
 	"time"
 
@@ -29,7 +30,15 @@ func (v templateCaptureValue) String() string {
 	return strconv.FormatFloat(v.Value, 'f', -1, 64)
 }
 
-func expandTemplate(name, text string, labels map[string]string, alertInstance eval.Result, externalURL *url.URL) (result string, resultErr error) {
+// queryFunc runs the instant queries of the query() template function.
+type queryFunc func(ctx context.Context, query string, ts time.Time) (promql.Vector, error)
+
+// noopQuery is the queryFunc used when there is nothing to run queries against.
+func noopQuery(context.Context, string, time.Time) (promql.Vector, error) {
+	return nil, nil
+}
+
+func expandTemplate(ctx context.Context, name, text string, labels map[string]string, alertInstance eval.Result, externalURL *url.URL, query queryFunc) (result string, resultErr error) {
 	name = "__alert_" + name
 	text = "{{- $labels := .Labels -}}{{- $values := .Values -}}{{- $value := .Value -}}" + text
 	data := struct {
@@ -42,24 +51,24 @@ func expandTemplate(name, text string, labels map[string]string, alertInstance e
 		Value:  alertInstance.EvaluationString,
 	}
 
+	if query == nil {
+		query = noopQuery
+	}
+
 	expander := template.NewTemplateExpander(
-		context.TODO(), // This context is only used with the `query()` function - which we don't support yet.
+		ctx, // This context is only used with the `query()` function.
 		text,
 		name,
 		data,
 		model.Time(timestamp.FromTime(alertInstance.EvaluatedAt)),
-		func(context.Context, string, time.Time) (promql.Vector, error) {
-			return nil, nil
-		},
+		template.QueryFunc(query),
 		externalURL,
 		[]string{"missingkey=error"},
 	)
 
 	expander.Funcs(text_template.FuncMap{
-		// These three functions are no-ops for now.
-		"strvalue": func(value templateCaptureValue) string {
-			return ""
-		},
+		"strvalue": strvalue,
+		// These two functions are no-ops for now.
 		"graphLink": func() string {
 			return ""
 		},
@@ -71,6 +80,27 @@ func expandTemplate(name, text string, labels map[string]string, alertInstance e
 	return expander.Expand()
 }
 
+// strvalue returns the __value__ label of a value in $values or of a sample returned by query().
+func strvalue(value interface{}) string {
+	if v, ok := value.(templateCaptureValue); ok {
+		return v.Labels["__value__"]
+	}
+
+	// the samples returned by query() are of a type that is not exported by the Prometheus template package
+	v := reflect.Indirect(reflect.ValueOf(value))
+	if v.Kind() != reflect.Struct {
+		return ""
+	}
+	f := v.FieldByName("Labels")
+	if !f.IsValid() {
+		return ""
+	}
+	if labels, ok := f.Interface().(map[string]string); ok {
+		return labels["__value__"]
+	}
+	return ""
+}
+
 func newTemplateCaptureValues(values map[string]eval.NumberValueCapture) map[string]templateCaptureValue {
 	m := make(map[string]templateCaptureValue)
 	for k, v := range values {
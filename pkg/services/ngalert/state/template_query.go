@@ -0,0 +1,191 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/pkg/timestamp"
+	"github.com/prometheus/prometheus/promql"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngModels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/tsdb"
+)
+
+// templateQueryRefID is the RefID of the queries run by the query() template function.
+const templateQueryRefID = "__template_query__"
+
+// TemplateQuerier runs the instant queries of the query() function in the label
+// and annotation templates of an alert rule.
+type TemplateQuerier interface {
+	Query(ctx context.Context, alertRule *ngModels.AlertRule, query string, ts time.Time) (promql.Vector, error)
+}
+
+// datasourceTemplateQuerier runs the queries of templates against the datasource
+// of the first query of the alert rule.
+type datasourceTemplateQuerier struct {
+	evaluator   eval.Evaluator
+	dataService *tsdb.Service
+}
+
+// NewTemplateQuerier returns a TemplateQuerier that runs the queries of templates through
+// the data service, against the datasource of the first query of the alert rule.
+func NewTemplateQuerier(evaluator eval.Evaluator, dataService *tsdb.Service) TemplateQuerier {
+	return &datasourceTemplateQuerier{evaluator: evaluator, dataService: dataService}
+}
+
+// ErrTemplateQueryDisabled is returned by the queries of templates where they cannot run.
+var ErrTemplateQueryDisabled = errors.New("query() is not supported when alert rules are not evaluated at the current time")
+
+// noopTemplateQuerier is a TemplateQuerier that runs no queries.
+type noopTemplateQuerier struct{}
+
+// NewNoopTemplateQuerier returns a TemplateQuerier whose queries fail with ErrTemplateQueryDisabled.
+// The queries of templates always run at the current time, so they cannot be used when alert rules
+// are evaluated in the past.
+func NewNoopTemplateQuerier() TemplateQuerier {
+	return noopTemplateQuerier{}
+}
+
+func (noopTemplateQuerier) Query(context.Context, *ngModels.AlertRule, string, time.Time) (promql.Vector, error) {
+	return nil, ErrTemplateQueryDisabled
+}
+
+func (q *datasourceTemplateQuerier) Query(ctx context.Context, alertRule *ngModels.AlertRule, query string, ts time.Time) (promql.Vector, error) {
+	alertQuery, err := templateAlertQuery(alertRule, query)
+	if err != nil {
+		return nil, err
+	}
+
+	// the query is a PromQL expression, so it can only run against Prometheus datasources
+	ds := &models.GetDataSourceQuery{OrgId: alertRule.OrgID, Uid: alertQuery.DatasourceUID}
+	if err := bus.Dispatch(ds); err != nil {
+		return nil, fmt.Errorf("failed to get datasource %s: %w", alertQuery.DatasourceUID, err)
+	}
+	if ds.Result.Type != models.DS_PROMETHEUS {
+		return nil, fmt.Errorf("query() is only supported for Prometheus datasources, datasource %s is of type %s", alertQuery.DatasourceUID, ds.Result.Type)
+	}
+
+	resp, err := q.evaluator.QueriesAndExpressionsEvalCtx(ctx, alertRule.OrgID, []ngModels.AlertQuery{alertQuery}, ts, q.dataService)
+	if err != nil {
+		return nil, err
+	}
+
+	res, ok := resp.Responses[templateQueryRefID]
+	if !ok {
+		return nil, nil
+	}
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	return framesToVector(res.Frames), nil
+}
+
+// templateAlertQuery returns an instant query with the expression query for the datasource
+// of the first query of the alert rule, keeping the other properties of its model.
+func templateAlertQuery(alertRule *ngModels.AlertRule, query string) (ngModels.AlertQuery, error) {
+	for _, q := range alertRule.Data {
+		if isExpression, err := q.IsExpression(); err != nil || isExpression {
+			continue
+		}
+
+		model := make(map[string]interface{})
+		if err := json.Unmarshal(q.Model, &model); err != nil {
+			return ngModels.AlertQuery{}, fmt.Errorf("failed to unmarshal query model: %w", err)
+		}
+		model["refId"] = templateQueryRefID
+		model["expr"] = query
+		model["instant"] = true
+		model["range"] = false
+
+		b, err := json.Marshal(model)
+		if err != nil {
+			return ngModels.AlertQuery{}, fmt.Errorf("failed to marshal query model: %w", err)
+		}
+
+		return ngModels.AlertQuery{
+			RefID:             templateQueryRefID,
+			QueryType:         q.QueryType,
+			RelativeTimeRange: q.RelativeTimeRange,
+			DatasourceUID:     q.DatasourceUID,
+			Model:             b,
+		}, nil
+	}
+	return ngModels.AlertQuery{}, errors.New("the alert rule has no datasource query to run the query against")
+}
+
+// framesToVector converts the numeric fields of frames to a vector with one sample per field.
+// The sample of a time series is its last value.
+func framesToVector(frames data.Frames) promql.Vector {
+	vector := promql.Vector{}
+	for _, frame := range frames {
+		timeIndex := -1
+		for i, field := range frame.Fields {
+			if field.Type().Time() {
+				timeIndex = i
+				break
+			}
+		}
+
+		for _, field := range frame.Fields {
+			if !field.Type().Numeric() {
+				continue
+			}
+			for i := field.Len() - 1; i >= 0; i-- {
+				v, ok := field.ConcreteAt(i)
+				if !ok {
+					continue
+				}
+				f, ok := sampleValue(v)
+				if !ok {
+					continue
+				}
+				var t int64
+				if timeIndex >= 0 {
+					if tv, ok := frame.Fields[timeIndex].ConcreteAt(i); ok {
+						t = timestamp.FromTime(tv.(time.Time))
+					}
+				}
+				vector = append(vector, promql.Sample{
+					Point:  promql.Point{T: t, V: f},
+					Metric: labels.FromMap(field.Labels),
+				})
+				break
+			}
+		}
+	}
+	return vector
+}
+
+func sampleValue(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	}
+	return 0, false
+}
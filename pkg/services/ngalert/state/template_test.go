@@ -1,12 +1,20 @@
 package state
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngModels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/promql"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	ptr "github.com/xorcare/pointer"
@@ -367,7 +375,7 @@ func TestExpandTemplate(t *testing.T) {
 		text:     "{{ query \"metric{__value__='a'}\" | sortByLabel }}",
 		expected: "",
 	}, {
-		name: "check that strvalue returns an empty string (for now)",
+		name: "check that strvalue returns the __value__ label",
 		text: "{{ $values.A | strvalue }}",
 		alertInstance: eval.Result{
 			Values: map[string]eval.NumberValueCapture{
@@ -377,7 +385,7 @@ func TestExpandTemplate(t *testing.T) {
 				},
 			},
 		},
-		expected: "",
+		expected: "foo",
 	}, {
 		name:     "check that safeHtml doesn't error or panic",
 		text:     "{{ \"<b>\" | safeHtml }}",
@@ -387,7 +395,7 @@ func TestExpandTemplate(t *testing.T) {
 
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
-			v, err := expandTemplate("test", c.text, c.labels, c.alertInstance, externalURL)
+			v, err := expandTemplate(context.Background(), "test", c.text, c.labels, c.alertInstance, externalURL, nil)
 			if c.expectedError != nil {
 				require.NotNil(t, err)
 				require.EqualError(t, c.expectedError, err.Error())
@@ -398,3 +406,129 @@ func TestExpandTemplate(t *testing.T) {
 		})
 	}
 }
+
+func TestExpandTemplateQuery(t *testing.T) {
+	query := func(_ context.Context, q string, _ time.Time) (promql.Vector, error) {
+		switch q {
+		case "topk(2, pod:cpu)":
+			return promql.Vector{
+				{Metric: labels.FromStrings("pod", "a"), Point: promql.Point{V: 0.9}},
+				{Metric: labels.FromStrings("pod", "b"), Point: promql.Point{V: 0.5}},
+			}, nil
+		case "version":
+			return promql.Vector{
+				{Metric: labels.FromStrings("__value__", "8.3.0")},
+			}, nil
+		}
+		return nil, errors.New("unexpected query")
+	}
+
+	cases := []struct {
+		name          string
+		text          string
+		expected      string
+		expectedError bool
+	}{{
+		name:     "query results can be ranged over",
+		text:     `{{ range query "topk(2, pod:cpu)" }}{{ .Labels.pod }}={{ .Value }} {{ end }}`,
+		expected: "a=0.9 b=0.5 ",
+	}, {
+		name:     "first, label and value work with query results",
+		text:     `{{ with query "topk(2, pod:cpu)" | first }}{{ label "pod" . }} {{ value . }}{{ end }}`,
+		expected: "a 0.9",
+	}, {
+		name:     "strvalue returns the __value__ label of query results",
+		text:     `{{ query "version" | first | strvalue }}`,
+		expected: "8.3.0",
+	}, {
+		name:          "query errors are returned",
+		text:          `{{ query "unknown" }}`,
+		expectedError: true,
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			v, err := expandTemplate(context.Background(), "test", c.text, nil, eval.Result{}, nil, query)
+			if c.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.expected, v)
+		})
+	}
+}
+
+func TestTemplateAlertQuery(t *testing.T) {
+	alertRule := &ngModels.AlertRule{
+		Data: []ngModels.AlertQuery{{
+			RefID:         "B",
+			DatasourceUID: "-100",
+			Model:         json.RawMessage(`{"type":"math","expression":"$A > 1"}`),
+		}, {
+			RefID:         "A",
+			DatasourceUID: "prometheus",
+			Model:         json.RawMessage(`{"refId":"A","expr":"up","interval":"1m"}`),
+		}},
+	}
+
+	q, err := templateAlertQuery(alertRule, "sum(up)")
+	require.NoError(t, err)
+	require.Equal(t, templateQueryRefID, q.RefID)
+	require.Equal(t, "prometheus", q.DatasourceUID)
+	require.JSONEq(t, `{"refId":"__template_query__","expr":"sum(up)","interval":"1m","instant":true,"range":false}`, string(q.Model))
+
+	_, err = templateAlertQuery(&ngModels.AlertRule{Data: alertRule.Data[:1]}, "sum(up)")
+	require.Error(t, err)
+}
+
+func TestDatasourceTemplateQuerierRejectsNonPrometheusDatasources(t *testing.T) {
+	bus.ClearBusHandlers()
+	defer bus.ClearBusHandlers()
+	bus.AddHandler("test", func(query *models.GetDataSourceQuery) error {
+		query.Result = &models.DataSource{Uid: query.Uid, Type: "loki"}
+		return nil
+	})
+
+	alertRule := &ngModels.AlertRule{
+		Data: []ngModels.AlertQuery{{
+			RefID:         "A",
+			DatasourceUID: "loki",
+			Model:         json.RawMessage(`{"refId":"A","expr":"{job=\"app\"}"}`),
+		}},
+	}
+
+	querier := NewTemplateQuerier(eval.Evaluator{}, nil)
+	_, err := querier.Query(context.Background(), alertRule, "sum(up)", time.Now())
+	require.EqualError(t, err, "query() is only supported for Prometheus datasources, datasource loki is of type loki")
+}
+
+func TestFramesToVector(t *testing.T) {
+	now := time.Unix(1000, 0)
+	frames := data.Frames{
+		data.NewFrame("",
+			data.NewField("Time", nil, []time.Time{now.Add(-time.Minute), now}),
+			data.NewField("Value", data.Labels{"pod": "a"}, []*float64{ptr.Float64(1), nil}),
+		),
+		data.NewFrame("",
+			data.NewField("Value", data.Labels{"pod": "b"}, []float64{2}),
+			data.NewField("Name", nil, []string{"b"}),
+		),
+	}
+
+	vector := framesToVector(frames)
+	require.Equal(t, promql.Vector{
+		{Metric: labels.FromStrings("pod", "a"), Point: promql.Point{T: now.Add(-time.Minute).UnixNano() / int64(time.Millisecond), V: 1}},
+		{Metric: labels.FromStrings("pod", "b"), Point: promql.Point{V: 2}},
+	}, vector)
+}
+
+func TestNoopTemplateQuerier(t *testing.T) {
+	querier := NewNoopTemplateQuerier()
+	query := func(ctx context.Context, q string, ts time.Time) (promql.Vector, error) {
+		return querier.Query(ctx, &ngModels.AlertRule{}, q, ts)
+	}
+
+	_, err := expandTemplate(context.Background(), "test", `{{ query "up" }}`, nil, eval.Result{}, nil, query)
+	require.ErrorIs(t, err, ErrTemplateQueryDisabled)
+}
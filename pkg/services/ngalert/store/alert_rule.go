@@ -18,6 +18,8 @@ import (
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/util"
+
+	prommodel "github.com/prometheus/common/model"
 )
 
 // AlertRuleMaxTitleLength is the maximum length of the alert rule title
@@ -53,6 +55,7 @@ type RuleStore interface {
 	GetOrgRuleGroups(query *ngmodels.ListOrgRuleGroupsQuery) error
 	UpsertAlertRules([]UpsertRule) error
 	UpdateRuleGroup(UpdateRuleGroupCmd) error
+	UpdateRuleGroups([]UpdateRuleGroupCmd) error
 }
 
 func getAlertRuleByUID(sess *sqlstore.DBSession, alertRuleUID string, orgID int64) (*ngmodels.AlertRule, error) {
@@ -71,23 +74,30 @@ func getAlertRuleByUID(sess *sqlstore.DBSession, alertRuleUID string, orgID int6
 // DeleteAlertRuleByUID is a handler for deleting an alert rule.
 func (st DBstore) DeleteAlertRuleByUID(orgID int64, ruleUID string) error {
 	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
-		_, err := sess.Exec("DELETE FROM alert_rule WHERE org_id = ? AND uid = ?", orgID, ruleUID)
-		if err != nil {
+		if err := deleteAlertRuleByUID(sess, orgID, ruleUID); err != nil {
 			return err
 		}
+		return checkRuleDependents(sess, orgID, []string{ruleUID})
+	})
+}
 
-		_, err = sess.Exec("DELETE FROM alert_rule_version WHERE rule_org_id = ? and rule_uid = ?", orgID, ruleUID)
+func deleteAlertRuleByUID(sess *sqlstore.DBSession, orgID int64, ruleUID string) error {
+	_, err := sess.Exec("DELETE FROM alert_rule WHERE org_id = ? AND uid = ?", orgID, ruleUID)
+	if err != nil {
+		return err
+	}
 
-		if err != nil {
-			return err
-		}
+	_, err = sess.Exec("DELETE FROM alert_rule_version WHERE rule_org_id = ? and rule_uid = ?", orgID, ruleUID)
 
-		_, err = sess.Exec("DELETE FROM alert_instance WHERE rule_org_id = ? AND rule_uid = ?", orgID, ruleUID)
-		if err != nil {
-			return err
-		}
-		return nil
-	})
+	if err != nil {
+		return err
+	}
+
+	_, err = sess.Exec("DELETE FROM alert_instance WHERE rule_org_id = ? AND rule_uid = ?", orgID, ruleUID)
+	if err != nil {
+		return err
+	}
+	return nil
 }
 
 // DeleteNamespaceAlertRules is a handler for deleting namespace alert rules. A list of deleted rule UIDs are returned.
@@ -117,7 +127,7 @@ func (st DBstore) DeleteNamespaceAlertRules(orgID int64, namespaceUID string) ([
 			return err
 		}
 
-		return nil
+		return checkRuleDependents(sess, orgID, ruleUIDs)
 	})
 	return ruleUIDs, err
 }
@@ -154,7 +164,7 @@ func (st DBstore) DeleteRuleGroupAlertRules(orgID int64, namespaceUID string, ru
 			return err
 		}
 
-		return nil
+		return checkRuleDependents(sess, orgID, ruleUIDs)
 	})
 
 	return ruleUIDs, err
@@ -187,134 +197,230 @@ func (st DBstore) GetAlertRuleByUID(query *ngmodels.GetAlertRuleByUIDQuery) erro
 // UpsertAlertRules is a handler for creating/updating alert rules.
 func (st DBstore) UpsertAlertRules(rules []UpsertRule) error {
 	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
-		newRules := make([]ngmodels.AlertRule, 0, len(rules))
-		ruleVersions := make([]ngmodels.AlertRuleVersion, 0, len(rules))
-		for _, r := range rules {
-			if r.Existing == nil && r.New.UID != "" {
-				// check by UID
-				existingAlertRule, err := getAlertRuleByUID(sess, r.New.UID, r.New.OrgID)
-				if err != nil {
-					if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
-						return fmt.Errorf("failed to get alert rule %s: %w", r.New.UID, err)
-					}
-					return err
-				}
-				r.Existing = existingAlertRule
-			}
+		return st.upsertAlertRules(sess, rules)
+	})
+}
 
-			var parentVersion int64
-			switch r.Existing {
-			case nil: // new rule
-				uid, err := GenerateNewAlertRuleUID(sess, r.New.OrgID, r.New.Title)
-				if err != nil {
-					return fmt.Errorf("failed to generate UID for alert rule %q: %w", r.New.Title, err)
+func (st DBstore) upsertAlertRules(sess *sqlstore.DBSession, rules []UpsertRule) error {
+	newRules := make([]ngmodels.AlertRule, 0, len(rules))
+	ruleVersions := make([]ngmodels.AlertRuleVersion, 0, len(rules))
+	for _, r := range rules {
+		if r.Existing == nil && r.New.UID != "" {
+			// check by UID
+			existingAlertRule, err := getAlertRuleByUID(sess, r.New.UID, r.New.OrgID)
+			if err != nil {
+				if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
+					return fmt.Errorf("failed to get alert rule %s: %w", r.New.UID, err)
 				}
-				r.New.UID = uid
+				return err
+			}
+			r.Existing = existingAlertRule
+		}
 
-				if r.New.IntervalSeconds == 0 {
-					r.New.IntervalSeconds = int64(st.DefaultInterval.Seconds())
-				}
+		var parentVersion int64
+		switch r.Existing {
+		case nil: // new rule
+			uid, err := GenerateNewAlertRuleUID(sess, r.New.OrgID, r.New.Title)
+			if err != nil {
+				return fmt.Errorf("failed to generate UID for alert rule %q: %w", r.New.Title, err)
+			}
+			r.New.UID = uid
 
-				r.New.Version = 1
+			if r.New.IntervalSeconds == 0 {
+				r.New.IntervalSeconds = int64(st.DefaultInterval.Seconds())
+			}
 
-				if r.New.NoDataState == "" {
-					// set default no data state
-					r.New.NoDataState = ngmodels.NoData
-				}
+			r.New.Version = 1
 
-				if r.New.ExecErrState == "" {
-					// set default error state
-					r.New.ExecErrState = ngmodels.AlertingErrState
-				}
+			if r.New.NoDataState == "" {
+				// set default no data state
+				r.New.NoDataState = ngmodels.NoData
+			}
 
-				if err := st.validateAlertRule(r.New); err != nil {
-					return err
-				}
+			if r.New.ExecErrState == "" {
+				// set default error state
+				r.New.ExecErrState = ngmodels.AlertingErrState
+			}
 
-				if err := (&r.New).PreSave(TimeNow); err != nil {
-					return err
-				}
+			if err := st.validateAlertRule(r.New); err != nil {
+				return err
+			}
 
-				newRules = append(newRules, r.New)
-			default:
-				// explicitly set the existing properties if missing
-				// do not rely on xorm
-				if r.New.Title == "" {
-					r.New.Title = r.Existing.Title
-				}
+			if err := (&r.New).PreSave(TimeNow); err != nil {
+				return err
+			}
 
-				if r.New.Condition == "" {
-					r.New.Condition = r.Existing.Condition
-				}
+			newRules = append(newRules, r.New)
+		default:
+			// explicitly set the existing properties if missing
+			// do not rely on xorm
+			if r.New.Title == "" {
+				r.New.Title = r.Existing.Title
+			}
 
-				if len(r.New.Data) == 0 {
-					r.New.Data = r.Existing.Data
-				}
+			if r.New.Condition == "" {
+				r.New.Condition = r.Existing.Condition
+			}
 
-				r.New.ID = r.Existing.ID
-				r.New.OrgID = r.Existing.OrgID
-				r.New.NamespaceUID = r.Existing.NamespaceUID
-				r.New.RuleGroup = r.Existing.RuleGroup
-				r.New.Version = r.Existing.Version + 1
+			if len(r.New.Data) == 0 {
+				r.New.Data = r.Existing.Data
+			}
 
-				if r.New.ExecErrState == "" {
-					r.New.ExecErrState = r.Existing.ExecErrState
-				}
+			r.New.ID = r.Existing.ID
+			r.New.OrgID = r.Existing.OrgID
+			r.New.NamespaceUID = r.Existing.NamespaceUID
+			r.New.RuleGroup = r.Existing.RuleGroup
+			r.New.Version = r.Existing.Version + 1
 
-				if r.New.NoDataState == "" {
-					r.New.NoDataState = r.Existing.NoDataState
-				}
+			if r.New.ExecErrState == "" {
+				r.New.ExecErrState = r.Existing.ExecErrState
+			}
 
-				if err := st.validateAlertRule(r.New); err != nil {
-					return err
-				}
+			if r.New.NoDataState == "" {
+				r.New.NoDataState = r.Existing.NoDataState
+			}
 
-				if err := (&r.New).PreSave(TimeNow); err != nil {
-					return err
-				}
+			if err := st.validateAlertRule(r.New); err != nil {
+				return err
+			}
 
-				// no way to update multiple rules at once
-				if _, err := sess.ID(r.Existing.ID).AllCols().Update(r.New); err != nil {
-					return fmt.Errorf("failed to update rule %s: %w", r.New.Title, err)
-				}
+			if err := (&r.New).PreSave(TimeNow); err != nil {
+				return err
+			}
 
-				parentVersion = r.Existing.Version
+			// no way to update multiple rules at once
+			if _, err := sess.ID(r.Existing.ID).AllCols().Update(r.New); err != nil {
+				return fmt.Errorf("failed to update rule %s: %w", r.New.Title, err)
 			}
 
-			ruleVersions = append(ruleVersions, ngmodels.AlertRuleVersion{
-				RuleOrgID:        r.New.OrgID,
-				RuleUID:          r.New.UID,
-				RuleNamespaceUID: r.New.NamespaceUID,
-				RuleGroup:        r.New.RuleGroup,
-				ParentVersion:    parentVersion,
-				Version:          r.New.Version,
-				Created:          r.New.Updated,
-				Condition:        r.New.Condition,
-				Title:            r.New.Title,
-				Data:             r.New.Data,
-				IntervalSeconds:  r.New.IntervalSeconds,
-				NoDataState:      r.New.NoDataState,
-				ExecErrState:     r.New.ExecErrState,
-				For:              r.New.For,
-				Annotations:      r.New.Annotations,
-				Labels:           r.New.Labels,
-			})
+			parentVersion = r.Existing.Version
+		}
+
+		ruleVersions = append(ruleVersions, ngmodels.AlertRuleVersion{
+			RuleOrgID:            r.New.OrgID,
+			RuleUID:              r.New.UID,
+			RuleNamespaceUID:     r.New.NamespaceUID,
+			RuleGroup:            r.New.RuleGroup,
+			ParentVersion:        parentVersion,
+			Version:              r.New.Version,
+			Created:              r.New.Updated,
+			Condition:            r.New.Condition,
+			Title:                r.New.Title,
+			Data:                 r.New.Data,
+			IntervalSeconds:      r.New.IntervalSeconds,
+			NoDataState:          r.New.NoDataState,
+			ExecErrState:         r.New.ExecErrState,
+			IsPaused:             r.New.IsPaused,
+			Record:               r.New.Record,
+			RuleGroupIndex:       r.New.RuleGroupIndex,
+			EvaluateSequentially: r.New.EvaluateSequentially,
+			RuleGroupIsPaused:    r.New.RuleGroupIsPaused,
+			DependsOn:            r.New.DependsOn,
+			For:                  r.New.For,
+			Annotations:          r.New.Annotations,
+			Labels:               r.New.Labels,
+		})
+	}
+
+	if len(newRules) > 0 {
+		if _, err := sess.Insert(&newRules); err != nil {
+			return fmt.Errorf("failed to create new rules: %w", err)
+		}
+	}
+
+	if len(ruleVersions) > 0 {
+		if _, err := sess.Insert(&ruleVersions); err != nil {
+			return fmt.Errorf("failed to create new rule versions: %w", err)
+		}
+	}
+
+	// the rules are saved first, so that the dependencies are checked against the saved rules
+	return checkRuleDependencies(sess, ruleVersions)
+}
+
+// checkRuleDependencies returns an error if a rule the saved rules depend on does not exist,
+// or if the dependencies of a saved rule form a cycle, in which the alerts of the rules would
+// inhibit each other forever.
+func checkRuleDependencies(sess *sqlstore.DBSession, saved []ngmodels.AlertRuleVersion) error {
+	dependenciesByOrg := make(map[int64]map[string][]ngmodels.RuleDependency)
+	for _, r := range saved {
+		if len(r.DependsOn) == 0 {
+			continue
+		}
+
+		dependencies, ok := dependenciesByOrg[r.RuleOrgID]
+		if !ok {
+			orgRules := make([]*ngmodels.AlertRule, 0)
+			if err := sess.SQL("SELECT * FROM alert_rule WHERE org_id = ?", r.RuleOrgID).Find(&orgRules); err != nil {
+				return err
+			}
+			dependencies = make(map[string][]ngmodels.RuleDependency, len(orgRules))
+			for _, orgRule := range orgRules {
+				dependencies[orgRule.UID] = orgRule.DependsOn
+			}
+			dependenciesByOrg[r.RuleOrgID] = dependencies
 		}
 
-		if len(newRules) > 0 {
-			if _, err := sess.Insert(&newRules); err != nil {
-				return fmt.Errorf("failed to create new rules: %w", err)
+		for _, d := range r.DependsOn {
+			if _, ok := dependencies[d.RuleUID]; !ok {
+				return fmt.Errorf("%w: the rule %s depends on the rule %s, which does not exist", ngmodels.ErrAlertRuleFailedValidation, r.RuleUID, d.RuleUID)
 			}
 		}
 
-		if len(ruleVersions) > 0 {
-			if _, err := sess.Insert(&ruleVersions); err != nil {
-				return fmt.Errorf("failed to create new rule versions: %w", err)
+		if cycle := dependencyCycle(dependencies, r.RuleUID); cycle != nil {
+			return fmt.Errorf("%w: the dependencies of the rule %s form a cycle: %s", ngmodels.ErrAlertRuleFailedValidation, r.RuleUID, strings.Join(cycle, " -> "))
+		}
+	}
+	return nil
+}
+
+// dependencyCycle returns the UIDs of the rules of a cycle of dependencies from and to the rule,
+// nil if there is none.
+func dependencyCycle(dependencies map[string][]ngmodels.RuleDependency, ruleUID string) []string {
+	visited := make(map[string]bool)
+	var visit func(path []string) []string
+	visit = func(path []string) []string {
+		for _, d := range dependencies[path[len(path)-1]] {
+			if d.RuleUID == ruleUID {
+				return append(path, ruleUID)
+			}
+			if visited[d.RuleUID] {
+				continue
+			}
+			visited[d.RuleUID] = true
+			if cycle := visit(append(path, d.RuleUID)); cycle != nil {
+				return cycle
 			}
 		}
+		return nil
+	}
+	return visit([]string{ruleUID})
+}
 
+// checkRuleDependents returns an error if a rule of the organization depends on one of the
+// deleted rules, so that rules are not deleted while other rules depend on them.
+func checkRuleDependents(sess *sqlstore.DBSession, orgID int64, deleted []string) error {
+	if len(deleted) == 0 {
 		return nil
-	})
+	}
+
+	deletedUIDs := make(map[string]struct{}, len(deleted))
+	for _, uid := range deleted {
+		deletedUIDs[uid] = struct{}{}
+	}
+
+	orgRules := make([]*ngmodels.AlertRule, 0)
+	if err := sess.SQL("SELECT * FROM alert_rule WHERE org_id = ?", orgID).Find(&orgRules); err != nil {
+		return err
+	}
+	for _, r := range orgRules {
+		for _, d := range r.DependsOn {
+			if _, ok := deletedUIDs[d.RuleUID]; ok {
+				return fmt.Errorf("%w: the rule %s depends on the rule %s", ngmodels.ErrAlertRuleHasDependents, r.UID, d.RuleUID)
+			}
+		}
+	}
+	return nil
 }
 
 // GetOrgAlertRules is a handler for retrieving alert rules of specific organisation.
@@ -342,7 +448,7 @@ func (st DBstore) GetOrgAlertRules(query *ngmodels.ListAlertRulesQuery) error {
 			}
 		}
 
-		q = fmt.Sprintf("%s ORDER BY id ASC", q)
+		q = fmt.Sprintf("%s ORDER BY rule_group_index ASC, id ASC", q)
 
 		if err := sess.SQL(q, params...).Find(&alertRules); err != nil {
 			return err
@@ -358,7 +464,7 @@ func (st DBstore) GetNamespaceAlertRules(query *ngmodels.ListNamespaceAlertRules
 	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
 		alertRules := make([]*ngmodels.AlertRule, 0)
 		// TODO rewrite using group by namespace_uid, rule_group
-		q := "SELECT * FROM alert_rule WHERE org_id = ? and namespace_uid = ?"
+		q := "SELECT * FROM alert_rule WHERE org_id = ? and namespace_uid = ? ORDER BY rule_group_index ASC, id ASC"
 		if err := sess.SQL(q, query.OrgID, query.NamespaceUID).Find(&alertRules); err != nil {
 			return err
 		}
@@ -383,6 +489,8 @@ func (st DBstore) GetRuleGroupAlertRules(query *ngmodels.ListRuleGroupAlertRules
 			}
 		}
 
+		q = fmt.Sprintf("%s ORDER BY rule_group_index ASC, id ASC", q)
+
 		alertRules := make([]*ngmodels.AlertRule, 0)
 		if err := sess.SQL(q, args...).Find(&alertRules); err != nil {
 			return err
@@ -438,12 +546,12 @@ func (st DBstore) GetNamespaceByTitle(ctx context.Context, namespace string, org
 	return folder, nil
 }
 
-// GetAlertRulesForScheduling returns alert rule info (identifier, interval, version state)
+// GetAlertRulesForScheduling returns alert rule info (identifier, group and position in it, interval, version state, paused)
 // that is useful for it's scheduling.
 func (st DBstore) GetAlertRulesForScheduling(query *ngmodels.ListAlertRulesQuery) error {
 	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
 		alerts := make([]*ngmodels.AlertRule, 0)
-		q := "SELECT uid, org_id, interval_seconds, version FROM alert_rule"
+		q := "SELECT uid, org_id, namespace_uid, rule_group, rule_group_index, evaluate_sequentially, rule_group_is_paused, interval_seconds, version, is_paused FROM alert_rule"
 		if len(query.ExcludeOrgs) > 0 {
 			q = fmt.Sprintf("%s WHERE org_id NOT IN (%s)", q, strings.Join(strings.Split(strings.Trim(fmt.Sprint(query.ExcludeOrgs), "[]"), " "), ","))
 		}
@@ -507,101 +615,180 @@ func (st DBstore) validateAlertRule(alertRule ngmodels.AlertRule) error {
 		return fmt.Errorf("%w: cannot have Panel ID without a Dashboard UID", ngmodels.ErrAlertRuleFailedValidation)
 	}
 
+	if !alertRule.NoDataState.IsValid() {
+		return fmt.Errorf("%w: unknown no data state %q", ngmodels.ErrAlertRuleFailedValidation, alertRule.NoDataState)
+	}
+
+	if !alertRule.ExecErrState.IsValid() {
+		return fmt.Errorf("%w: unknown execution error state %q", ngmodels.ErrAlertRuleFailedValidation, alertRule.ExecErrState)
+	}
+
+	if alertRule.IsRecordingRule() && !prommodel.IsValidMetricName(prommodel.LabelValue(alertRule.Record)) {
+		return fmt.Errorf("%w: record %q is not a valid metric name", ngmodels.ErrAlertRuleFailedValidation, alertRule.Record)
+	}
+
+	for _, d := range alertRule.DependsOn {
+		if d.RuleUID == "" {
+			return fmt.Errorf("%w: the UID of a rule the rule depends on is missing", ngmodels.ErrAlertRuleFailedValidation)
+		}
+		if d.RuleUID == alertRule.UID {
+			return fmt.Errorf("%w: the rule cannot depend on itself", ngmodels.ErrAlertRuleFailedValidation)
+		}
+	}
+
 	return nil
 }
 
 // UpdateRuleGroup creates new rules and updates and/or deletes existing rules
 func (st DBstore) UpdateRuleGroup(cmd UpdateRuleGroupCmd) error {
 	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
-		ruleGroup := cmd.RuleGroupConfig.Name
-		q := &ngmodels.ListRuleGroupAlertRulesQuery{
-			OrgID:        cmd.OrgID,
-			NamespaceUID: cmd.NamespaceUID,
-			RuleGroup:    ruleGroup,
+		return st.updateRuleGroup(sess, cmd)
+	})
+}
+
+// UpdateRuleGroups updates rule groups as UpdateRuleGroup does, in a single transaction,
+// so that either all of the rule groups are updated or none of them.
+func (st DBstore) UpdateRuleGroups(cmds []UpdateRuleGroupCmd) error {
+	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		// the rules removed from all the rule groups are deleted first, so that their titles
+		// can be used by the rules of any of the rule groups
+		deleted := make([][]string, 0, len(cmds))
+		for _, cmd := range cmds {
+			uids, err := deleteRemovedGroupRules(sess, cmd)
+			if err != nil {
+				return err
+			}
+			deleted = append(deleted, uids)
 		}
-		if err := st.GetRuleGroupAlertRules(q); err != nil {
-			return err
+		for i, cmd := range cmds {
+			if err := st.updateRuleGroup(sess, cmd); err != nil {
+				return err
+			}
+			if err := checkRuleDependents(sess, cmd.OrgID, deleted[i]); err != nil {
+				return err
+			}
 		}
-		existingGroupRules := q.Result
+		return nil
+	})
+}
 
-		existingGroupRulesUIDs := make(map[string]ngmodels.AlertRule, len(existingGroupRules))
-		for _, r := range existingGroupRules {
-			existingGroupRulesUIDs[r.UID] = *r
+// deleteRemovedGroupRules deletes the rules of the rule group of the command that are not
+// in the command, and returns their UIDs.
+func deleteRemovedGroupRules(sess *sqlstore.DBSession, cmd UpdateRuleGroupCmd) ([]string, error) {
+	var groupRuleUIDs []string
+	if err := sess.SQL("SELECT uid FROM alert_rule WHERE org_id = ? and namespace_uid = ? and rule_group = ?",
+		cmd.OrgID, cmd.NamespaceUID, cmd.RuleGroupConfig.Name).Find(&groupRuleUIDs); err != nil {
+		return nil, err
+	}
+
+	keptRuleUIDs := make(map[string]struct{}, len(cmd.RuleGroupConfig.Rules))
+	for _, r := range cmd.RuleGroupConfig.Rules {
+		if r.GrafanaManagedAlert != nil && r.GrafanaManagedAlert.UID != "" {
+			keptRuleUIDs[r.GrafanaManagedAlert.UID] = struct{}{}
 		}
+	}
 
-		upsertRules := make([]UpsertRule, 0)
-		for _, r := range cmd.RuleGroupConfig.Rules {
-			if r.GrafanaManagedAlert == nil {
-				continue
-			}
+	var deleted []string
+	for _, uid := range groupRuleUIDs {
+		if _, ok := keptRuleUIDs[uid]; ok {
+			continue
+		}
+		if err := deleteAlertRuleByUID(sess, cmd.OrgID, uid); err != nil {
+			return nil, err
+		}
+		deleted = append(deleted, uid)
+	}
+	return deleted, nil
+}
 
-			newAlertRule := ngmodels.AlertRule{
-				OrgID:           cmd.OrgID,
-				Title:           r.GrafanaManagedAlert.Title,
-				Condition:       r.GrafanaManagedAlert.Condition,
-				Data:            r.GrafanaManagedAlert.Data,
-				UID:             r.GrafanaManagedAlert.UID,
-				IntervalSeconds: int64(time.Duration(cmd.RuleGroupConfig.Interval).Seconds()),
-				NamespaceUID:    cmd.NamespaceUID,
-				RuleGroup:       ruleGroup,
-				NoDataState:     ngmodels.NoDataState(r.GrafanaManagedAlert.NoDataState),
-				ExecErrState:    ngmodels.ExecutionErrorState(r.GrafanaManagedAlert.ExecErrState),
-			}
+func (st DBstore) updateRuleGroup(sess *sqlstore.DBSession, cmd UpdateRuleGroupCmd) error {
+	// the rules removed from the rule group are deleted first, so that new rules can use their titles,
+	// and the rules that depend on them are checked once the rules of the group are saved
+	deleted, err := deleteRemovedGroupRules(sess, cmd)
+	if err != nil {
+		return err
+	}
 
-			if r.ApiRuleNode != nil {
-				newAlertRule.For = time.Duration(r.ApiRuleNode.For)
-				newAlertRule.Annotations = r.ApiRuleNode.Annotations
-				newAlertRule.Labels = r.ApiRuleNode.Labels
-			}
+	ruleGroup := cmd.RuleGroupConfig.Name
+	existingGroupRules := make([]*ngmodels.AlertRule, 0)
+	if err := sess.SQL("SELECT * FROM alert_rule WHERE org_id = ? and namespace_uid = ? and rule_group = ? ORDER BY rule_group_index ASC, id ASC",
+		cmd.OrgID, cmd.NamespaceUID, ruleGroup).Find(&existingGroupRules); err != nil {
+		return err
+	}
 
-			if s := newAlertRule.Annotations[ngmodels.DashboardUIDAnnotation]; s != "" {
-				newAlertRule.DashboardUID = &s
-			}
+	existingGroupRulesUIDs := make(map[string]ngmodels.AlertRule, len(existingGroupRules))
+	for _, r := range existingGroupRules {
+		existingGroupRulesUIDs[r.UID] = *r
+	}
 
-			if s := newAlertRule.Annotations[ngmodels.PanelIDAnnotation]; s != "" {
-				panelID, err := strconv.ParseInt(s, 10, 64)
-				if err != nil {
-					return fmt.Errorf("the %s annotation does not contain a valid Panel ID: %w", ngmodels.PanelIDAnnotation, err)
-				}
-				newAlertRule.PanelID = &panelID
-			}
+	upsertRules := make([]UpsertRule, 0)
+	for i, r := range cmd.RuleGroupConfig.Rules {
+		if r.GrafanaManagedAlert == nil {
+			continue
+		}
 
-			upsertRule := UpsertRule{
-				New: newAlertRule,
-			}
+		newAlertRule := ngmodels.AlertRule{
+			OrgID:                cmd.OrgID,
+			Title:                r.GrafanaManagedAlert.Title,
+			Condition:            r.GrafanaManagedAlert.Condition,
+			Data:                 r.GrafanaManagedAlert.Data,
+			UID:                  r.GrafanaManagedAlert.UID,
+			IntervalSeconds:      int64(time.Duration(cmd.RuleGroupConfig.Interval).Seconds()),
+			NamespaceUID:         cmd.NamespaceUID,
+			RuleGroup:            ruleGroup,
+			NoDataState:          ngmodels.NoDataState(r.GrafanaManagedAlert.NoDataState),
+			ExecErrState:         ngmodels.ExecutionErrorState(r.GrafanaManagedAlert.ExecErrState),
+			IsPaused:             r.GrafanaManagedAlert.IsPaused,
+			Record:               r.GrafanaManagedAlert.Record,
+			RuleGroupIndex:       i + 1,
+			EvaluateSequentially: cmd.RuleGroupConfig.EvaluateSequentially,
+			RuleGroupIsPaused:    cmd.RuleGroupConfig.IsPaused,
+			DependsOn:            r.GrafanaManagedAlert.DependsOn,
+		}
 
-			if existingGroupRule, ok := existingGroupRulesUIDs[r.GrafanaManagedAlert.UID]; ok {
-				upsertRule.Existing = &existingGroupRule
-				// remove the rule from existingGroupRulesUIDs
-				delete(existingGroupRulesUIDs, r.GrafanaManagedAlert.UID)
-			}
-			upsertRules = append(upsertRules, upsertRule)
+		if r.ApiRuleNode != nil {
+			newAlertRule.For = time.Duration(r.ApiRuleNode.For)
+			newAlertRule.Annotations = r.ApiRuleNode.Annotations
+			newAlertRule.Labels = r.ApiRuleNode.Labels
 		}
 
-		if err := st.UpsertAlertRules(upsertRules); err != nil {
-			if st.SQLStore.Dialect.IsUniqueConstraintViolation(err) {
-				return ngmodels.ErrAlertRuleUniqueConstraintViolation
-			}
-			return err
+		if s := newAlertRule.Annotations[ngmodels.DashboardUIDAnnotation]; s != "" {
+			newAlertRule.DashboardUID = &s
 		}
 
-		// delete instances for rules that will not be removed
-		for _, rule := range existingGroupRules {
-			if _, ok := existingGroupRulesUIDs[rule.UID]; !ok {
-				if err := st.DeleteAlertInstancesByRuleUID(cmd.OrgID, rule.UID); err != nil {
-					return err
-				}
+		if s := newAlertRule.Annotations[ngmodels.PanelIDAnnotation]; s != "" {
+			panelID, err := strconv.ParseInt(s, 10, 64)
+			if err != nil {
+				return fmt.Errorf("the %s annotation does not contain a valid Panel ID: %w", ngmodels.PanelIDAnnotation, err)
 			}
+			newAlertRule.PanelID = &panelID
 		}
 
-		// delete the remaining rules
-		for ruleUID := range existingGroupRulesUIDs {
-			if err := st.DeleteAlertRuleByUID(cmd.OrgID, ruleUID); err != nil {
-				return err
-			}
+		upsertRule := UpsertRule{
+			New: newAlertRule,
 		}
-		return nil
-	})
+
+		if existingGroupRule, ok := existingGroupRulesUIDs[r.GrafanaManagedAlert.UID]; ok {
+			upsertRule.Existing = &existingGroupRule
+		}
+		upsertRules = append(upsertRules, upsertRule)
+	}
+
+	if err := st.upsertAlertRules(sess, upsertRules); err != nil {
+		if st.SQLStore.Dialect.IsUniqueConstraintViolation(err) {
+			return ngmodels.ErrAlertRuleUniqueConstraintViolation
+		}
+		return err
+	}
+
+	// delete instances for the updated rules
+	for _, rule := range existingGroupRules {
+		if _, err := sess.Exec("DELETE FROM alert_instance WHERE rule_org_id = ? AND rule_uid = ?", cmd.OrgID, rule.UID); err != nil {
+			return err
+		}
+	}
+
+	return checkRuleDependents(sess, cmd.OrgID, deleted)
 }
 
 func (st DBstore) GetOrgRuleGroups(query *ngmodels.ListOrgRuleGroupsQuery) error {
@@ -0,0 +1,283 @@
+//go:build integration
+// +build integration
+
+package store_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/prom"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestAlertRuleDependencies(t *testing.T) {
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	const mainOrgID int64 = 1
+
+	alertRule1 := tests.CreateTestAlertRule(t, dbstore, 60, mainOrgID)
+	alertRule2 := tests.CreateTestAlertRule(t, dbstore, 60, mainOrgID)
+
+	setDependencies := func(rule *models.AlertRule, dependsOn ...string) error {
+		var dependencies []models.RuleDependency
+		for _, uid := range dependsOn {
+			dependencies = append(dependencies, models.RuleDependency{RuleUID: uid})
+		}
+		return dbstore.UpdateRuleGroup(store.UpdateRuleGroupCmd{
+			OrgID:        mainOrgID,
+			NamespaceUID: rule.NamespaceUID,
+			RuleGroupConfig: apimodels.PostableRuleGroupConfig{
+				Name:     rule.RuleGroup,
+				Interval: model.Duration(time.Duration(rule.IntervalSeconds) * time.Second),
+				Rules: []apimodels.PostableExtendedRuleNode{{
+					GrafanaManagedAlert: &apimodels.PostableGrafanaRule{
+						UID:       rule.UID,
+						DependsOn: dependencies,
+					},
+				}},
+			},
+		})
+	}
+
+	t.Run("a rule can depend on an existing rule", func(t *testing.T) {
+		require.NoError(t, setDependencies(alertRule2, alertRule1.UID))
+	})
+
+	t.Run("a rule cannot depend on a rule that does not exist", func(t *testing.T) {
+		err := setDependencies(alertRule1, "unknown")
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
+
+	t.Run("dependencies cannot form a cycle", func(t *testing.T) {
+		err := setDependencies(alertRule1, alertRule2.UID)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+
+		// the rule was not saved
+		q := models.GetAlertRuleByUIDQuery{OrgID: mainOrgID, UID: alertRule1.UID}
+		require.NoError(t, dbstore.GetAlertRuleByUID(&q))
+		require.Empty(t, q.Result.DependsOn)
+	})
+
+	ruleExists := func(rule *models.AlertRule) bool {
+		q := models.GetAlertRuleByUIDQuery{OrgID: mainOrgID, UID: rule.UID}
+		return dbstore.GetAlertRuleByUID(&q) == nil
+	}
+
+	t.Run("a rule group update cannot remove a rule other rules depend on", func(t *testing.T) {
+		err := dbstore.UpdateRuleGroup(store.UpdateRuleGroupCmd{
+			OrgID:        mainOrgID,
+			NamespaceUID: alertRule1.NamespaceUID,
+			RuleGroupConfig: apimodels.PostableRuleGroupConfig{
+				Name:     alertRule1.RuleGroup,
+				Interval: model.Duration(time.Duration(alertRule1.IntervalSeconds) * time.Second),
+			},
+		})
+		require.ErrorIs(t, err, models.ErrAlertRuleHasDependents)
+		require.True(t, ruleExists(alertRule1))
+	})
+
+	t.Run("a rule other rules depend on cannot be deleted", func(t *testing.T) {
+		err := dbstore.DeleteAlertRuleByUID(mainOrgID, alertRule1.UID)
+		require.ErrorIs(t, err, models.ErrAlertRuleHasDependents)
+
+		_, err = dbstore.DeleteRuleGroupAlertRules(mainOrgID, alertRule1.NamespaceUID, alertRule1.RuleGroup)
+		require.ErrorIs(t, err, models.ErrAlertRuleHasDependents)
+		require.True(t, ruleExists(alertRule1))
+	})
+
+	t.Run("a rule can be deleted with the rules that depend on it", func(t *testing.T) {
+		uids, err := dbstore.DeleteNamespaceAlertRules(mainOrgID, alertRule1.NamespaceUID)
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{alertRule1.UID, alertRule2.UID}, uids)
+	})
+}
+
+func TestUpdateRuleGroups(t *testing.T) {
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	const mainOrgID int64 = 1
+
+	alertRule1 := tests.CreateTestAlertRule(t, dbstore, 60, mainOrgID)
+	alertRule2 := tests.CreateTestAlertRule(t, dbstore, 60, mainOrgID)
+
+	updateCmd := func(rule *models.AlertRule, title string, dependsOn ...models.RuleDependency) store.UpdateRuleGroupCmd {
+		return store.UpdateRuleGroupCmd{
+			OrgID:        mainOrgID,
+			NamespaceUID: rule.NamespaceUID,
+			RuleGroupConfig: apimodels.PostableRuleGroupConfig{
+				Name:     rule.RuleGroup,
+				Interval: model.Duration(time.Duration(rule.IntervalSeconds) * time.Second),
+				Rules: []apimodels.PostableExtendedRuleNode{{
+					GrafanaManagedAlert: &apimodels.PostableGrafanaRule{
+						UID:       rule.UID,
+						Title:     title,
+						DependsOn: dependsOn,
+					},
+				}},
+			},
+		}
+	}
+
+	t.Run("no rule group is saved if one of them is invalid", func(t *testing.T) {
+		err := dbstore.UpdateRuleGroups([]store.UpdateRuleGroupCmd{
+			updateCmd(alertRule1, "updated 1"),
+			updateCmd(alertRule2, "updated 2", models.RuleDependency{RuleUID: "unknown"}),
+		})
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+
+		q := models.GetAlertRuleByUIDQuery{OrgID: mainOrgID, UID: alertRule1.UID}
+		require.NoError(t, dbstore.GetAlertRuleByUID(&q))
+		require.Equal(t, alertRule1.Title, q.Result.Title)
+	})
+
+	t.Run("all rule groups are saved", func(t *testing.T) {
+		err := dbstore.UpdateRuleGroups([]store.UpdateRuleGroupCmd{
+			updateCmd(alertRule1, "updated 1"),
+			updateCmd(alertRule2, "updated 2"),
+		})
+		require.NoError(t, err)
+
+		for i, rule := range []*models.AlertRule{alertRule1, alertRule2} {
+			q := models.GetAlertRuleByUIDQuery{OrgID: mainOrgID, UID: rule.UID}
+			require.NoError(t, dbstore.GetAlertRuleByUID(&q))
+			require.Equal(t, fmt.Sprintf("updated %d", i+1), q.Result.Title)
+		}
+	})
+}
+
+func TestUpdateRuleGroupsImportTwice(t *testing.T) {
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	const mainOrgID int64 = 1
+
+	// a rule of another rule group of the folder, which has the title of a rule of the file
+	otherRule := tests.CreateTestAlertRule(t, dbstore, 60, mainOrgID)
+
+	file := apimodels.PrometheusRulesFile{
+		Groups: []apimodels.PrometheusRuleGroup{{
+			Name: "imported",
+			Rules: []apimodels.ApiRuleNode{
+				{Alert: "InstanceDown", Expr: "up == 0"},
+				{Alert: otherRule.Title, Expr: "up == 1"},
+			},
+		}},
+	}
+
+	importFile := func() map[string]string {
+		q := models.ListNamespaceAlertRulesQuery{OrgID: mainOrgID, NamespaceUID: otherRule.NamespaceUID}
+		require.NoError(t, dbstore.GetNamespaceAlertRules(&q))
+
+		groups, err := prom.ToGrafanaRuleGroups(file, "prom-uid", q.Result)
+		require.NoError(t, err)
+		cmds := make([]store.UpdateRuleGroupCmd, 0, len(groups))
+		for _, g := range groups {
+			cmds = append(cmds, store.UpdateRuleGroupCmd{OrgID: mainOrgID, NamespaceUID: otherRule.NamespaceUID, RuleGroupConfig: g})
+		}
+		require.NoError(t, dbstore.UpdateRuleGroups(cmds))
+
+		imported := models.ListRuleGroupAlertRulesQuery{OrgID: mainOrgID, NamespaceUID: otherRule.NamespaceUID, RuleGroup: "imported"}
+		require.NoError(t, dbstore.GetRuleGroupAlertRules(&imported))
+		uids := make(map[string]string, len(imported.Result))
+		for _, r := range imported.Result {
+			uids[r.Title] = r.UID
+		}
+		return uids
+	}
+
+	first := importFile()
+	require.Len(t, first, 2)
+	require.Contains(t, first, "InstanceDown")
+	require.Contains(t, first, otherRule.Title+" (2)")
+
+	// the rules of the rule group are updated
+	second := importFile()
+	require.Equal(t, first, second)
+
+	q := models.GetAlertRuleByUIDQuery{OrgID: mainOrgID, UID: otherRule.UID}
+	require.NoError(t, dbstore.GetAlertRuleByUID(&q))
+	require.Equal(t, otherRule.RuleGroup, q.Result.RuleGroup)
+}
+
+func TestUpdateRuleGroupReplacedTitle(t *testing.T) {
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	const mainOrgID int64 = 1
+
+	alertRule := tests.CreateTestAlertRule(t, dbstore, 60, mainOrgID)
+
+	// the rule is replaced by a new rule with the same title
+	err := dbstore.UpdateRuleGroup(store.UpdateRuleGroupCmd{
+		OrgID:        mainOrgID,
+		NamespaceUID: alertRule.NamespaceUID,
+		RuleGroupConfig: apimodels.PostableRuleGroupConfig{
+			Name:     alertRule.RuleGroup,
+			Interval: model.Duration(time.Duration(alertRule.IntervalSeconds) * time.Second),
+			Rules: []apimodels.PostableExtendedRuleNode{{
+				GrafanaManagedAlert: &apimodels.PostableGrafanaRule{
+					Title:     alertRule.Title,
+					Condition: alertRule.Condition,
+					Data:      alertRule.Data,
+				},
+			}},
+		},
+	})
+	require.NoError(t, err)
+
+	q := models.ListRuleGroupAlertRulesQuery{OrgID: mainOrgID, NamespaceUID: alertRule.NamespaceUID, RuleGroup: alertRule.RuleGroup}
+	require.NoError(t, dbstore.GetRuleGroupAlertRules(&q))
+	require.Len(t, q.Result, 1)
+	require.Equal(t, alertRule.Title, q.Result[0].Title)
+	require.NotEqual(t, alertRule.UID, q.Result[0].UID)
+}
+
+func TestUpdateRuleGroupPaused(t *testing.T) {
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	const mainOrgID int64 = 1
+
+	alertRule := tests.CreateTestAlertRule(t, dbstore, 60, mainOrgID)
+
+	setGroupPaused := func(isPaused bool) {
+		err := dbstore.UpdateRuleGroup(store.UpdateRuleGroupCmd{
+			OrgID:        mainOrgID,
+			NamespaceUID: alertRule.NamespaceUID,
+			RuleGroupConfig: apimodels.PostableRuleGroupConfig{
+				Name:     alertRule.RuleGroup,
+				Interval: model.Duration(time.Duration(alertRule.IntervalSeconds) * time.Second),
+				IsPaused: isPaused,
+				Rules: []apimodels.PostableExtendedRuleNode{{
+					GrafanaManagedAlert: &apimodels.PostableGrafanaRule{UID: alertRule.UID},
+				}},
+			},
+		})
+		require.NoError(t, err)
+	}
+
+	getRule := func() *models.AlertRule {
+		q := models.ListAlertRulesQuery{}
+		require.NoError(t, dbstore.GetAlertRulesForScheduling(&q))
+		for _, r := range q.Result {
+			if r.UID == alertRule.UID {
+				return r
+			}
+		}
+		t.Fatalf("alert rule %s not found", alertRule.UID)
+		return nil
+	}
+
+	setGroupPaused(true)
+	rule := getRule()
+	require.True(t, rule.RuleGroupIsPaused)
+	require.False(t, rule.IsPaused)
+
+	setGroupPaused(false)
+	require.False(t, getRule().RuleGroupIsPaused)
+}
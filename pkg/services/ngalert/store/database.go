@@ -21,6 +21,7 @@ type AlertingStore interface {
 	GetAllLatestAlertmanagerConfiguration(ctx context.Context) ([]*models.AlertConfiguration, error)
 	SaveAlertmanagerConfiguration(*models.SaveAlertmanagerConfigurationCmd) error
 	SaveAlertmanagerConfigurationWithCallback(*models.SaveAlertmanagerConfigurationCmd, SaveCallback) error
+	NotificationDeliveryStore
 }
 
 // DBstore stores the alert definitions and instances in the database.
@@ -32,3 +33,9 @@ type DBstore struct {
 	SQLStore        *sqlstore.SQLStore
 	Logger          log.Logger
 }
+
+// ProvideDBstore returns a DBstore for the services that manage the data of
+// the unified alerting outside of it, such as the cleanup of expired entries.
+func ProvideDBstore(sqlStore *sqlstore.SQLStore) *DBstore {
+	return &DBstore{SQLStore: sqlStore, Logger: log.New("ngalert.store")}
+}
@@ -0,0 +1,173 @@
+package store
+
+import (
+	"context"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// NotificationDeliveryStore is the database interface for the notification delivery log
+// and the queue of notifications to retry.
+type NotificationDeliveryStore interface {
+	SaveNotificationDeliveries(ctx context.Context, cmd *models.SaveNotificationDeliveriesCommand) error
+	ListNotificationDeliveries(ctx context.Context, query *models.ListNotificationDeliveriesQuery) error
+	DeleteExpiredNotificationDeliveries(ctx context.Context, cmd *models.DeleteExpiredNotificationDeliveriesCommand) error
+	EnqueueNotificationRetry(ctx context.Context, cmd *models.EnqueueNotificationRetryCommand) error
+	GetDueNotificationRetries(ctx context.Context, query *models.GetDueNotificationRetriesQuery) error
+	UpdateNotificationRetry(ctx context.Context, cmd *models.UpdateNotificationRetryCommand) error
+	DeleteNotificationRetry(ctx context.Context, cmd *models.DeleteNotificationRetryCommand) error
+	DeleteGroupNotificationRetries(ctx context.Context, cmd *models.DeleteGroupNotificationRetriesCommand) error
+}
+
+// SaveNotificationDeliveries is a handler for saving the entries of an attempt to send a notification.
+func (st DBstore) SaveNotificationDeliveries(ctx context.Context, cmd *models.SaveNotificationDeliveriesCommand) error {
+	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		for _, entry := range cmd.Entries {
+			if err := models.ValidateNotificationDeliveryEntry(entry); err != nil {
+				return err
+			}
+
+			_, err := sess.Exec(`INSERT INTO alert_notification_delivery
+				(org_id, receiver, integration, integration_index, integration_uid, rule_uid, group_key, alerts, state, error_message, attempt, sent_at)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+				entry.OrgID, entry.Receiver, entry.Integration, entry.IntegrationIndex, entry.IntegrationUID, entry.RuleUID,
+				entry.GroupKey, entry.Alerts, entry.State, entry.Error, entry.Attempt, entry.SentAt.Unix())
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ListNotificationDeliveries is a handler for retrieving the notification deliveries
+// within a specific organisation based on various filters.
+func (st DBstore) ListNotificationDeliveries(ctx context.Context, query *models.ListNotificationDeliveriesQuery) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		entries := make([]*models.NotificationDeliveryEntry, 0)
+
+		s := strings.Builder{}
+		params := make([]interface{}, 0)
+
+		addToQuery := func(stmt string, p ...interface{}) {
+			s.WriteString(stmt)
+			params = append(params, p...)
+		}
+
+		addToQuery("SELECT * FROM alert_notification_delivery WHERE org_id = ?", query.OrgID)
+
+		if query.Receiver != "" {
+			addToQuery(` AND receiver = ?`, query.Receiver)
+		}
+
+		if query.RuleUID != "" {
+			addToQuery(` AND rule_uid = ?`, query.RuleUID)
+		}
+
+		if !query.From.IsZero() {
+			addToQuery(` AND sent_at >= ?`, query.From.Unix())
+		}
+
+		if !query.To.IsZero() {
+			addToQuery(` AND sent_at <= ?`, query.To.Unix())
+		}
+
+		addToQuery(` ORDER BY sent_at DESC, id DESC`)
+
+		if query.Limit > 0 {
+			addToQuery(` LIMIT ?`, query.Limit)
+		}
+
+		if err := sess.SQL(s.String(), params...).Find(&entries); err != nil {
+			return err
+		}
+
+		query.Result = entries
+		return nil
+	})
+}
+
+// DeleteExpiredNotificationDeliveries is a handler for deleting the notification deliveries
+// that were sent before the given time.
+func (st DBstore) DeleteExpiredNotificationDeliveries(ctx context.Context, cmd *models.DeleteExpiredNotificationDeliveriesCommand) error {
+	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		res, err := sess.Exec("DELETE FROM alert_notification_delivery WHERE sent_at < ?", cmd.OlderThan.Unix())
+		if err != nil {
+			return err
+		}
+		cmd.DeletedRows, err = res.RowsAffected()
+		return err
+	})
+}
+
+// EnqueueNotificationRetry is a handler for adding a failed notification to the retry queue,
+// in place of the notification of the same alert group to the integration already in the queue.
+func (st DBstore) EnqueueNotificationRetry(ctx context.Context, cmd *models.EnqueueNotificationRetryCommand) error {
+	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		if err := deleteGroupNotificationRetries(sess, cmd.OrgID, cmd.Receiver, cmd.IntegrationUID, cmd.GroupKey); err != nil {
+			return err
+		}
+
+		_, err := sess.Exec(`INSERT INTO alert_notification_retry
+			(org_id, receiver, integration, integration_index, integration_uid, group_key, group_labels, alerts, attempts, error_message, next_attempt_at, created)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			cmd.OrgID, cmd.Receiver, cmd.Integration, cmd.IntegrationIndex, cmd.IntegrationUID, cmd.GroupKey, cmd.GroupLabels, cmd.Alerts, 0, cmd.Error,
+			cmd.NextAttemptAt.Unix(), TimeNow().Unix())
+		return err
+	})
+}
+
+// GetDueNotificationRetries is a handler for retrieving the notifications of an organisation
+// that are due to be retried.
+func (st DBstore) GetDueNotificationRetries(ctx context.Context, query *models.GetDueNotificationRetriesQuery) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		retries := make([]*models.NotificationRetry, 0)
+
+		q := "SELECT * FROM alert_notification_retry WHERE org_id = ? AND next_attempt_at <= ? ORDER BY next_attempt_at ASC, id ASC"
+		params := []interface{}{query.OrgID, query.Now.Unix()}
+		if query.Limit > 0 {
+			q += " LIMIT ?"
+			params = append(params, query.Limit)
+		}
+
+		if err := sess.SQL(q, params...).Find(&retries); err != nil {
+			return err
+		}
+
+		query.Result = retries
+		return nil
+	})
+}
+
+// UpdateNotificationRetry is a handler for recording a failed retry of a notification.
+func (st DBstore) UpdateNotificationRetry(ctx context.Context, cmd *models.UpdateNotificationRetryCommand) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		_, err := sess.Exec("UPDATE alert_notification_retry SET attempts = ?, error_message = ?, next_attempt_at = ? WHERE id = ?",
+			cmd.Attempts, cmd.Error, cmd.NextAttemptAt.Unix(), cmd.ID)
+		return err
+	})
+}
+
+// DeleteNotificationRetry is a handler for removing a notification from the retry queue.
+func (st DBstore) DeleteNotificationRetry(ctx context.Context, cmd *models.DeleteNotificationRetryCommand) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		_, err := sess.Exec("DELETE FROM alert_notification_retry WHERE id = ?", cmd.ID)
+		return err
+	})
+}
+
+// DeleteGroupNotificationRetries is a handler for removing from the retry queue the notifications
+// of an alert group to an integration.
+func (st DBstore) DeleteGroupNotificationRetries(ctx context.Context, cmd *models.DeleteGroupNotificationRetriesCommand) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return deleteGroupNotificationRetries(sess, cmd.OrgID, cmd.Receiver, cmd.IntegrationUID, cmd.GroupKey)
+	})
+}
+
+func deleteGroupNotificationRetries(sess *sqlstore.DBSession, orgID int64, receiver, integrationUID, groupKey string) error {
+	_, err := sess.Exec("DELETE FROM alert_notification_retry WHERE org_id = ? AND receiver = ? AND integration_uid = ? AND group_key = ?",
+		orgID, receiver, integrationUID, groupKey)
+	return err
+}
@@ -0,0 +1,77 @@
+//go:build integration
+// +build integration
+
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestNotificationRetryQueue(t *testing.T) {
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	const mainOrgID int64 = 1
+
+	ctx := context.Background()
+	now := time.Now()
+	enqueue := func(integrationUID, groupKey, alerts string) {
+		err := dbstore.EnqueueNotificationRetry(ctx, &models.EnqueueNotificationRetryCommand{
+			OrgID:          mainOrgID,
+			Receiver:       "team",
+			Integration:    "webhook",
+			IntegrationUID: integrationUID,
+			GroupKey:       groupKey,
+			GroupLabels:    "{}",
+			Alerts:         alerts,
+			Error:          "service unavailable",
+			NextAttemptAt:  now.Add(-time.Minute),
+		})
+		require.NoError(t, err)
+	}
+	getRetries := func() []*models.NotificationRetry {
+		q := models.GetDueNotificationRetriesQuery{OrgID: mainOrgID, Now: now}
+		require.NoError(t, dbstore.GetDueNotificationRetries(ctx, &q))
+		return q.Result
+	}
+
+	enqueue("uid-1", "group-1", "first")
+	enqueue("uid-2", "group-1", "first")
+	enqueue("uid-1", "group-2", "first")
+
+	t.Run("a notification replaces the notification of the same alert group to the integration", func(t *testing.T) {
+		enqueue("uid-1", "group-1", "second")
+
+		retries := getRetries()
+		require.Len(t, retries, 3)
+		for _, r := range retries {
+			if r.IntegrationUID == "uid-1" && r.GroupKey == "group-1" {
+				require.Equal(t, "second", r.Alerts)
+			} else {
+				require.Equal(t, "first", r.Alerts)
+			}
+		}
+	})
+
+	t.Run("the notifications of an alert group to an integration are deleted", func(t *testing.T) {
+		err := dbstore.DeleteGroupNotificationRetries(ctx, &models.DeleteGroupNotificationRetriesCommand{
+			OrgID:          mainOrgID,
+			Receiver:       "team",
+			IntegrationUID: "uid-1",
+			GroupKey:       "group-1",
+		})
+		require.NoError(t, err)
+
+		retries := getRetries()
+		require.Len(t, retries, 2)
+		for _, r := range retries {
+			require.False(t, r.IntegrationUID == "uid-1" && r.GroupKey == "group-1")
+		}
+	})
+}
@@ -0,0 +1,151 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// listAlertStateHistoryLimit is the number of state transitions listed if the query has no limit.
+const listAlertStateHistoryLimit = 1000
+
+// StateHistoryStore is the database interface for the state transitions of alert instances.
+type StateHistoryStore interface {
+	SaveAlertStateHistory(ctx context.Context, cmds ...*models.SaveAlertStateHistoryCommand) error
+	ListAlertStateHistory(ctx context.Context, cmd *models.ListAlertStateHistoryQuery) error
+	DeleteExpiredAlertStateHistory(ctx context.Context, cmd *models.DeleteExpiredAlertStateHistoryCommand) error
+}
+
+// SaveAlertStateHistory is a handler for saving state transitions of alert instances.
+// All of them are saved in a single transaction.
+func (st DBstore) SaveAlertStateHistory(ctx context.Context, cmds ...*models.SaveAlertStateHistoryCommand) error {
+	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		for _, cmd := range cmds {
+			labelTupleJSON, labelsHash, err := cmd.Labels.StringAndHash()
+			if err != nil {
+				return err
+			}
+
+			entry := &models.AlertStateHistoryEntry{
+				RuleOrgID:        cmd.RuleOrgID,
+				RuleUID:          cmd.RuleUID,
+				Labels:           cmd.Labels,
+				LabelsHash:       labelsHash,
+				PreviousState:    cmd.PreviousState,
+				State:            cmd.State,
+				Error:            cmd.Error,
+				EvaluationString: cmd.EvaluationString,
+				Values:           cmd.Values,
+				EvaluatedAt:      cmd.EvaluatedAt,
+				Created:          TimeNow(),
+			}
+
+			if err := models.ValidateAlertStateHistoryEntry(entry); err != nil {
+				return err
+			}
+
+			if _, err := sess.Exec(`INSERT INTO alert_state_history
+				(rule_org_id, rule_uid, labels, labels_hash, previous_state, state, error_message, evaluation_string, eval_values, evaluated_at, created)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+				entry.RuleOrgID, entry.RuleUID, labelTupleJSON, entry.LabelsHash, entry.PreviousState, entry.State, entry.Error,
+				entry.EvaluationString, entry.Values, entry.EvaluatedAt.Unix(), entry.Created.Unix()); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ListAlertStateHistory is a handler for retrieving the state transitions of alert instances
+// within a specific organisation based on various filters.
+func (st DBstore) ListAlertStateHistory(ctx context.Context, cmd *models.ListAlertStateHistoryQuery) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		limit := cmd.Limit
+		if limit <= 0 {
+			limit = listAlertStateHistoryLimit
+		}
+
+		s := strings.Builder{}
+		params := make([]interface{}, 0)
+
+		addToQuery := func(stmt string, p ...interface{}) {
+			s.WriteString(stmt)
+			params = append(params, p...)
+		}
+
+		addToQuery("SELECT * FROM alert_state_history WHERE rule_org_id = ?", cmd.RuleOrgID)
+
+		if cmd.RuleUID != "" {
+			addToQuery(` AND rule_uid = ?`, cmd.RuleUID)
+		}
+
+		if !cmd.From.IsZero() {
+			addToQuery(` AND evaluated_at >= ?`, cmd.From.Unix())
+		}
+
+		if !cmd.To.IsZero() {
+			addToQuery(` AND evaluated_at <= ?`, cmd.To.Unix())
+		}
+
+		// Labels are stored as a JSON list of name and value pairs, so an entry with a label
+		// contains the JSON of its pair.
+		for name, value := range cmd.Labels {
+			pair, err := json.Marshal([2]string{name, value})
+			if err != nil {
+				return err
+			}
+			addToQuery(` AND labels LIKE ?`, "%"+string(pair)+"%")
+		}
+
+		addToQuery(` ORDER BY evaluated_at DESC, id DESC LIMIT ? OFFSET ?`)
+
+		// LIKE patterns can match more entries than the labels if the values contain wildcards,
+		// so the entries are matched again, and the next page is read until the limit is reached.
+		result := make([]*models.AlertStateHistoryEntry, 0)
+		for offset := 0; ; offset += limit {
+			entries := make([]*models.AlertStateHistoryEntry, 0)
+			if err := sess.SQL(s.String(), append(params, limit, offset)...).Find(&entries); err != nil {
+				return err
+			}
+			for _, e := range entries {
+				if labelsMatch(e.Labels, cmd.Labels) {
+					result = append(result, e)
+				}
+				if len(result) == limit {
+					break
+				}
+			}
+			if len(result) == limit || len(entries) < limit {
+				break
+			}
+		}
+
+		cmd.Result = result
+		return nil
+	})
+}
+
+// DeleteExpiredAlertStateHistory is a handler for deleting the state transitions
+// of alert instances that were evaluated before the given time.
+func (st DBstore) DeleteExpiredAlertStateHistory(ctx context.Context, cmd *models.DeleteExpiredAlertStateHistoryCommand) error {
+	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		res, err := sess.Exec("DELETE FROM alert_state_history WHERE evaluated_at < ?", cmd.OlderThan.Unix())
+		if err != nil {
+			return err
+		}
+		cmd.DeletedRows, err = res.RowsAffected()
+		return err
+	})
+}
+
+func labelsMatch(labels models.InstanceLabels, matchers map[string]string) bool {
+	for k, v := range matchers {
+		if lv, ok := labels[k]; !ok || lv != v {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,114 @@
+//go:build integration
+// +build integration
+
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlertStateHistoryOperations(t *testing.T) {
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	const mainOrgID int64 = 1
+
+	ctx := context.Background()
+	alertRule1 := tests.CreateTestAlertRule(t, dbstore, 60, mainOrgID)
+	alertRule2 := tests.CreateTestAlertRule(t, dbstore, 60, mainOrgID)
+
+	now := time.Unix(1000, 0).UTC()
+	save := func(rule *models.AlertRule, labels models.InstanceLabels, previous, state models.InstanceStateType, evaluatedAt time.Time) {
+		err := dbstore.SaveAlertStateHistory(ctx, &models.SaveAlertStateHistoryCommand{
+			RuleOrgID:        rule.OrgID,
+			RuleUID:          rule.UID,
+			Labels:           labels,
+			PreviousState:    previous,
+			State:            state,
+			EvaluationString: "[ var='B' labels={} value=1 ]",
+			Values:           `{"B":{"labels":null,"value":1}}`,
+			EvaluatedAt:      evaluatedAt,
+		})
+		require.NoError(t, err)
+	}
+
+	save(alertRule1, models.InstanceLabels{"host": "a"}, models.InstanceStateNormal, models.InstanceStatePending, now.Add(-3*time.Minute))
+	save(alertRule1, models.InstanceLabels{"host": "a"}, models.InstanceStatePending, models.InstanceStateFiring, now.Add(-2*time.Minute))
+	save(alertRule1, models.InstanceLabels{"host": "b"}, models.InstanceStateNormal, models.InstanceStateFiring, now.Add(-time.Minute))
+	save(alertRule2, models.InstanceLabels{"host": "a"}, models.InstanceStateNormal, models.InstanceStateError, now)
+
+	t.Run("can list state history of a rule, most recent first", func(t *testing.T) {
+		q := &models.ListAlertStateHistoryQuery{RuleOrgID: mainOrgID, RuleUID: alertRule1.UID}
+		require.NoError(t, dbstore.ListAlertStateHistory(ctx, q))
+		require.Len(t, q.Result, 3)
+		require.Equal(t, models.InstanceLabels{"host": "b"}, q.Result[0].Labels)
+		require.Equal(t, models.InstanceStateFiring, q.Result[1].State)
+		require.Equal(t, models.InstanceStatePending, q.Result[1].PreviousState)
+		require.Equal(t, `{"B":{"labels":null,"value":1}}`, q.Result[1].Values)
+		require.Equal(t, now.Add(-2*time.Minute), q.Result[1].EvaluatedAt.UTC())
+	})
+
+	t.Run("can filter state history by labels, time range and limit", func(t *testing.T) {
+		q := &models.ListAlertStateHistoryQuery{RuleOrgID: mainOrgID, Labels: map[string]string{"host": "a"}}
+		require.NoError(t, dbstore.ListAlertStateHistory(ctx, q))
+		require.Len(t, q.Result, 3)
+
+		q = &models.ListAlertStateHistoryQuery{RuleOrgID: mainOrgID, Labels: map[string]string{"host": "a"}, Limit: 2}
+		require.NoError(t, dbstore.ListAlertStateHistory(ctx, q))
+		require.Len(t, q.Result, 2)
+		require.Equal(t, alertRule2.UID, q.Result[0].RuleUID)
+
+		q = &models.ListAlertStateHistoryQuery{RuleOrgID: mainOrgID, From: now.Add(-2 * time.Minute), To: now.Add(-time.Minute)}
+		require.NoError(t, dbstore.ListAlertStateHistory(ctx, q))
+		require.Len(t, q.Result, 2)
+	})
+
+	t.Run("labels with wildcards only match equal values", func(t *testing.T) {
+		q := &models.ListAlertStateHistoryQuery{RuleOrgID: mainOrgID, Labels: map[string]string{"host": "_"}, Limit: 1}
+		require.NoError(t, dbstore.ListAlertStateHistory(ctx, q))
+		require.Len(t, q.Result, 0)
+	})
+
+	t.Run("state history of other organisations is not listed", func(t *testing.T) {
+		q := &models.ListAlertStateHistoryQuery{RuleOrgID: mainOrgID + 1}
+		require.NoError(t, dbstore.ListAlertStateHistory(ctx, q))
+		require.Len(t, q.Result, 0)
+	})
+
+	t.Run("can delete expired state history", func(t *testing.T) {
+		cmd := &models.DeleteExpiredAlertStateHistoryCommand{OlderThan: now.Add(-90 * time.Second)}
+		require.NoError(t, dbstore.DeleteExpiredAlertStateHistory(ctx, cmd))
+		require.Equal(t, int64(2), cmd.DeletedRows)
+
+		q := &models.ListAlertStateHistoryQuery{RuleOrgID: mainOrgID}
+		require.NoError(t, dbstore.ListAlertStateHistory(ctx, q))
+		require.Len(t, q.Result, 2)
+	})
+
+	t.Run("invalid states are not saved", func(t *testing.T) {
+		err := dbstore.SaveAlertStateHistory(ctx, &models.SaveAlertStateHistoryCommand{
+			RuleOrgID:     mainOrgID,
+			RuleUID:       alertRule1.UID,
+			PreviousState: models.InstanceStateNormal,
+			State:         models.InstanceStateFiring,
+			EvaluatedAt:   now,
+		}, &models.SaveAlertStateHistoryCommand{
+			RuleOrgID:     mainOrgID,
+			RuleUID:       alertRule1.UID,
+			PreviousState: models.InstanceStateNormal,
+			State:         "Unknown",
+		})
+		require.Error(t, err)
+
+		// the transitions are saved in a single transaction
+		q := &models.ListAlertStateHistoryQuery{RuleOrgID: mainOrgID}
+		require.NoError(t, dbstore.ListAlertStateHistory(ctx, q))
+		require.Len(t, q.Result, 2)
+	})
+}
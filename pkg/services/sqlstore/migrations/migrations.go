@@ -55,6 +55,7 @@ func (*OSSMigrations) AddMigration(mg *Migrator) {
 	addSecretsMigration(mg)
 	addKVStoreMigrations(mg)
 	ualert.AddDashboardUIDPanelIDMigration(mg)
+	addLivePipelineMigrations(mg)
 }
 
 func addMigrationLogMigrations(mg *Migrator) {
@@ -23,6 +23,7 @@ type alertRule struct {
 	ExecErrState    string
 	For             duration
 	Updated         time.Time
+	IsPaused        bool
 	Annotations     map[string]string
 	Labels          map[string]string // (Labels are not Created in the migration)
 }
@@ -43,6 +44,7 @@ type alertRuleVersion struct {
 	IntervalSeconds int64
 	NoDataState     string
 	ExecErrState    string
+	IsPaused        bool
 	// ideally this field should have been apimodels.ApiDuration
 	// but this is currently not possible because of circular dependencies
 	For         duration
@@ -67,6 +69,7 @@ func (a *alertRule) makeVersion() *alertRuleVersion {
 		IntervalSeconds: a.IntervalSeconds,
 		NoDataState:     a.NoDataState,
 		ExecErrState:    a.ExecErrState,
+		IsPaused:        a.IsPaused,
 		For:             a.For,
 		Annotations:     a.Annotations,
 		Labels:          map[string]string{},
@@ -110,6 +113,7 @@ func (m *migration) makeAlertRule(cond condition, da dashAlert, folderUID string
 		Updated:         time.Now().UTC(),
 		Annotations:     annotations,
 		Labels:          lbls,
+		IsPaused:        da.State == "paused",
 	}
 
 	var err error
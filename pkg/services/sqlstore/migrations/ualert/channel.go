@@ -22,6 +22,7 @@ type notificationChannel struct {
 	Type                  string           `xorm:"type"`
 	DisableResolveMessage bool             `xorm:"disable_resolve_message"`
 	IsDefault             bool             `xorm:"is_default"`
+	SendReminder          bool             `xorm:"send_reminder"`
 	Settings              *simplejson.Json `xorm:"settings"`
 	SecureSettings        SecureJsonData   `xorm:"secure_settings"`
 }
@@ -41,6 +42,7 @@ func (m *migration) getNotificationChannelMap() (channelsPerOrg, defaultChannels
 		type,
 		disable_resolve_message,
 		is_default,
+		send_reminder,
 		settings,
 		secure_settings
 	FROM
@@ -107,10 +109,11 @@ func (m *migration) updateReceiverAndRoute(allChannels channelsPerOrg, defaultCh
 
 func (m *migration) makeReceiverAndRoute(ruleUid string, orgID int64, channelUids []interface{}, defaultChannels []*notificationChannel, allChannels map[interface{}]*notificationChannel) (*PostableApiReceiver, *Route, error) {
 	portedChannels := []*PostableGrafanaReceiver{}
+	migratedChannels := []*notificationChannel{}
 	var receiver *PostableApiReceiver
 
 	addChannel := func(c *notificationChannel) error {
-		if c.Type == "hipchat" || c.Type == "sensu" {
+		if isDiscontinuedChannelType(c.Type) {
 			m.mg.Logger.Error("alert migration error: discontinued notification channel found", "type", c.Type, "name", c.Name, "uid", c.Uid)
 			return nil
 		}
@@ -139,6 +142,7 @@ func (m *migration) makeReceiverAndRoute(ruleUid string, orgID int64, channelUid
 				_, err := url.Parse(u)
 				if err != nil {
 					m.mg.Logger.Warn("slack notification channel had invalid URL, removing", "name", c.Name, "uid", c.Uid, "org", c.OrgID)
+					m.report.addChannelProblem(c, "the Slack URL is invalid and would be removed")
 					delete(decryptedSecureSettings, "url")
 				}
 			}
@@ -152,6 +156,7 @@ func (m *migration) makeReceiverAndRoute(ruleUid string, orgID int64, channelUid
 			Settings:              settings,
 			SecureSettings:        decryptedSecureSettings,
 		})
+		migratedChannels = append(migratedChannels, c)
 
 		return nil
 	}
@@ -217,6 +222,9 @@ func (m *migration) makeReceiverAndRoute(ruleUid string, orgID int64, channelUid
 		}
 
 		m.portedChannelGroupsPerOrg[orgID][chanKey] = receiverName
+		for _, c := range migratedChannels {
+			m.report.addChannelReceiver(c, receiverName)
+		}
 		receiver = &PostableApiReceiver{
 			Name:                    receiverName,
 			GrafanaManagedReceivers: portedChannels,
@@ -300,7 +308,7 @@ func (m *migration) addUnmigratedChannels(orgID int64, amConfigs *PostableUserCo
 		if ok {
 			continue
 		}
-		if c.Type == "hipchat" || c.Type == "sensu" {
+		if isDiscontinuedChannelType(c.Type) {
 			m.mg.Logger.Error("alert migration error: discontinued notification channel found", "type", c.Type, "name", c.Name, "uid", c.Uid)
 			continue
 		}
@@ -323,6 +331,7 @@ func (m *migration) addUnmigratedChannels(orgID int64, amConfigs *PostableUserCo
 			Settings:              settings,
 			SecureSettings:        decryptedSecureSettings,
 		})
+		m.report.addChannelReceiver(c, receiver.Name)
 	}
 	receiver.GrafanaManagedReceivers = portedChannels
 	if len(portedChannels) > 0 {
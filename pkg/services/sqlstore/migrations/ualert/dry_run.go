@@ -0,0 +1,271 @@
+package ualert
+
+import (
+	"fmt"
+	"sort"
+
+	"xorm.io/xorm"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+// DryRunReport describes what the migration of the dashboard alerts and notification
+// channels to unified alerting would do, without doing it.
+type DryRunReport struct {
+	Alerts   []*AlertReport   `json:"alerts"`
+	Channels []*ChannelReport `json:"channels"`
+
+	channels       map[*notificationChannel]*ChannelReport
+	generalFolders map[int64]*dashboard // Org -> general folder that would be created.
+	ruleTitles     map[ruleTitleKey]struct{}
+}
+
+// AlertReport describes the migration of a dashboard alert.
+type AlertReport struct {
+	ID           int64       `json:"id"`
+	OrgID        int64       `json:"orgId"`
+	DashboardUID string      `json:"dashboardUid"`
+	PanelID      int64       `json:"panelId"`
+	Name         string      `json:"name"`
+	Rule         *RuleReport `json:"rule,omitempty"`
+	// Problems are the features of the alert that would be lost or changed.
+	Problems []string `json:"problems,omitempty"`
+	// Error is set when the alert could not be migrated. It would fail the migration.
+	Error string `json:"error,omitempty"`
+
+	ruleUID string
+}
+
+// RuleReport describes the alert rule a dashboard alert would become.
+type RuleReport struct {
+	Title           string `json:"title"`
+	RuleGroup       string `json:"ruleGroup"`
+	Folder          string `json:"folder"`
+	NewFolder       bool   `json:"newFolder"`
+	IntervalSeconds int64  `json:"intervalSeconds"`
+	For             string `json:"for"`
+	NoDataState     string `json:"noDataState"`
+	ExecErrState    string `json:"execErrState"`
+	Silenced        bool   `json:"silenced"`
+	Receiver        string `json:"receiver,omitempty"`
+}
+
+// ChannelReport describes the migration of a notification channel.
+type ChannelReport struct {
+	ID        int64  `json:"id"`
+	OrgID     int64  `json:"orgId"`
+	UID       string `json:"uid"`
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	IsDefault bool   `json:"isDefault"`
+	// Receivers are the contact points the channel would be part of.
+	Receivers []string `json:"receivers,omitempty"`
+	Problems  []string `json:"problems,omitempty"`
+}
+
+type ruleTitleKey struct {
+	orgID        int64
+	namespaceUID string
+	title        string
+}
+
+// DryRun runs the migration of the dashboard alerts and notification channels to
+// unified alerting without writing anything, and reports what it would do.
+// Unlike the migration, it does not stop at the first alert that cannot be migrated.
+func DryRun(sess *xorm.Session, dialect migrator.Dialect) (*DryRunReport, error) {
+	m := newMigration()
+	m.report = &DryRunReport{
+		Alerts:         make([]*AlertReport, 0),
+		Channels:       make([]*ChannelReport, 0),
+		channels:       make(map[*notificationChannel]*ChannelReport),
+		generalFolders: make(map[int64]*dashboard),
+		ruleTitles:     make(map[ruleTitleKey]struct{}),
+	}
+
+	mg := &migrator.Migrator{
+		Dialect: dialect,
+		Logger:  log.New("ualert.dryrun"),
+	}
+	if err := m.Exec(sess, mg); err != nil {
+		return nil, err
+	}
+	return m.report, nil
+}
+
+func (r *DryRunReport) addAlert(da dashAlert) *AlertReport {
+	if r == nil {
+		return nil
+	}
+	ar := &AlertReport{
+		ID:           da.Id,
+		OrgID:        da.OrgId,
+		DashboardUID: da.DashboardUID,
+		PanelID:      da.PanelId,
+		Name:         da.Name,
+	}
+	r.Alerts = append(r.Alerts, ar)
+	return ar
+}
+
+func (r *DryRunReport) addChannels(allChannels channelsPerOrg) {
+	if r == nil {
+		return
+	}
+	for _, channels := range allChannels {
+		for _, c := range channels {
+			// Channels are mapped both by ID and UID.
+			if _, ok := r.channels[c]; ok {
+				continue
+			}
+			cr := &ChannelReport{
+				ID:        c.ID,
+				OrgID:     c.OrgID,
+				UID:       c.Uid,
+				Name:      c.Name,
+				Type:      c.Type,
+				IsDefault: c.IsDefault,
+				Problems:  channelProblems(c),
+			}
+			r.channels[c] = cr
+			r.Channels = append(r.Channels, cr)
+		}
+	}
+	sort.Slice(r.Channels, func(i, j int) bool {
+		if r.Channels[i].OrgID != r.Channels[j].OrgID {
+			return r.Channels[i].OrgID < r.Channels[j].OrgID
+		}
+		return r.Channels[i].ID < r.Channels[j].ID
+	})
+}
+
+func (r *DryRunReport) addChannelReceiver(c *notificationChannel, receiver string) {
+	if r == nil {
+		return
+	}
+	if cr, ok := r.channels[c]; ok {
+		cr.Receivers = append(cr.Receivers, receiver)
+	}
+}
+
+func (r *DryRunReport) addChannelProblem(c *notificationChannel, problem string) {
+	if r == nil {
+		return
+	}
+	if cr, ok := r.channels[c]; ok {
+		cr.Problems = append(cr.Problems, problem)
+	}
+}
+
+// resolveReceivers sets the receiver of the rules from the routes of the Alertmanager
+// configurations. Rules without a route of their own go to the root route.
+func (r *DryRunReport) resolveReceivers(amConfigs amConfigsPerOrg) {
+	if r == nil {
+		return
+	}
+	for _, ar := range r.Alerts {
+		amConfig, ok := amConfigs[ar.OrgID]
+		if ar.Rule == nil || !ok {
+			continue
+		}
+		root := amConfig.AlertmanagerConfig.Route
+		ar.Rule.Receiver = root.Receiver
+		n, v := getLabelForRouteMatching(ar.ruleUID)
+		for _, route := range root.Routes {
+			for _, mat := range route.Matchers {
+				if mat.Name == n && mat.Value == v {
+					ar.Rule.Receiver = route.Receiver
+				}
+			}
+		}
+	}
+	sort.SliceStable(r.Alerts, func(i, j int) bool {
+		if r.Alerts[i].OrgID != r.Alerts[j].OrgID {
+			return r.Alerts[i].OrgID < r.Alerts[j].OrgID
+		}
+		return r.Alerts[i].ID < r.Alerts[j].ID
+	})
+}
+
+func (ar *AlertReport) addProblem(format string, args ...interface{}) {
+	if ar == nil {
+		return
+	}
+	ar.Problems = append(ar.Problems, fmt.Sprintf(format, args...))
+}
+
+func (ar *AlertReport) setRule(rule *alertRule, folder dashboard) {
+	if ar == nil {
+		return
+	}
+	ar.ruleUID = rule.UID
+	ar.Rule = &RuleReport{
+		Title:           rule.Title,
+		RuleGroup:       rule.RuleGroup,
+		Folder:          folder.Title,
+		NewFolder:       folder.Id == 0, // Folders are not saved in a dry run.
+		IntervalSeconds: rule.IntervalSeconds,
+		For:             rule.For.String(),
+		NoDataState:     rule.NoDataState,
+		ExecErrState:    rule.ExecErrState,
+		Silenced:        rule.IsPaused,
+	}
+}
+
+// isDryRun returns whether the migration only reports what it would do.
+func (m *migration) isDryRun() bool {
+	return m.report != nil
+}
+
+// dryRunRuleTitle returns the title the rule would get when inserted, that is renamed
+// if there is already a rule with the same title in the folder.
+func (m *migration) dryRunRuleTitle(rule *alertRule) (string, bool) {
+	key := ruleTitleKey{orgID: rule.OrgID, namespaceUID: rule.NamespaceUID, title: rule.Title}
+	if _, ok := m.report.ruleTitles[key]; !ok {
+		m.report.ruleTitles[key] = struct{}{}
+		return rule.Title, false
+	}
+	return rule.Title + fmt.Sprintf(" %v", rule.UID), true
+}
+
+func isDiscontinuedChannelType(t string) bool {
+	return t == "hipchat" || t == "sensu"
+}
+
+// channelProblems returns the features of a notification channel that unified alerting does not support.
+func channelProblems(c *notificationChannel) []string {
+	var problems []string
+	if isDiscontinuedChannelType(c.Type) {
+		problems = append(problems, fmt.Sprintf("the %s notification channel is discontinued and would not be migrated", c.Type))
+	}
+	if c.SendReminder {
+		problems = append(problems, "reminders are not supported, notifications are repeated according to the repeat interval of the notification policy")
+	}
+	if c.Settings != nil && c.Settings.Get("uploadImage").MustBool(false) {
+		problems = append(problems, "images of the panel are not supported and would not be sent")
+	}
+	return problems
+}
+
+// conditionProblems returns the features of the conditions of a dashboard alert that
+// the classic condition expression does not support.
+func conditionProblems(set dashAlertSettings, orgID int64, dsUIDMap dsUIDLookup) []string {
+	var problems []string
+	for i, cond := range set.Conditions {
+		if dsUIDMap.GetUID(orgID, cond.Query.DatasourceID) == "" {
+			problems = append(problems, fmt.Sprintf("the data source %d of condition %d does not exist", cond.Query.DatasourceID, i+1))
+		}
+		switch cond.Reducer.Type {
+		case "avg", "sum", "min", "max", "count", "last", "median",
+			"diff", "diff_abs", "percent_diff", "percent_diff_abs", "count_non_null":
+		default:
+			problems = append(problems, fmt.Sprintf("the reducer %q of condition %d is not supported", cond.Reducer.Type, i+1))
+		}
+		switch cond.Evaluator.Type {
+		case "gt", "lt", "within_range", "outside_range", "no_value":
+		default:
+			problems = append(problems, fmt.Sprintf("the evaluator %q of condition %d is not supported", cond.Evaluator.Type, i+1))
+		}
+	}
+	return problems
+}
@@ -0,0 +1,47 @@
+package ualert
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+func Test_conditionProblems(t *testing.T) {
+	cond := func(dsID int64, reducer, evaluator string) dashAlertCondition {
+		var c dashAlertCondition
+		c.Query.DatasourceID = dsID
+		c.Reducer.Type = reducer
+		c.Evaluator.Type = evaluator
+		return c
+	}
+	dsUIDMap := dsUIDLookup{{1, 1}: "prom", {2, 2}: "loki"}
+
+	require.Empty(t, conditionProblems(dashAlertSettings{
+		Conditions: []dashAlertCondition{cond(1, "avg", "gt"), cond(1, "count_non_null", "no_value")},
+	}, 1, dsUIDMap))
+
+	require.Equal(t, []string{
+		"the data source 1 of condition 1 does not exist",
+		`the reducer "" of condition 2 is not supported`,
+		`the evaluator "eq" of condition 2 is not supported`,
+	}, conditionProblems(dashAlertSettings{
+		Conditions: []dashAlertCondition{cond(1, "avg", "gt"), cond(2, "", "eq")},
+	}, 2, dsUIDMap))
+}
+
+func Test_channelProblems(t *testing.T) {
+	require.Empty(t, channelProblems(&notificationChannel{Type: "email", Settings: simplejson.New()}))
+	require.Empty(t, channelProblems(&notificationChannel{Type: "email", Settings: simplejson.NewFromAny(map[string]interface{}{"uploadImage": false})}))
+
+	require.Equal(t, []string{
+		"the sensu notification channel is discontinued and would not be migrated",
+		"reminders are not supported, notifications are repeated according to the repeat interval of the notification policy",
+		"images of the panel are not supported and would not be sent",
+	}, channelProblems(&notificationChannel{
+		Type:         "sensu",
+		SendReminder: true,
+		Settings:     simplejson.NewFromAny(map[string]interface{}{"uploadImage": true}),
+	}))
+}
@@ -43,6 +43,12 @@ type dashboardAcl struct {
 // getOrCreateGeneralFolder returns the general folder under the specific organisation
 // If the general folder does not exist it creates it.
 func (m *migration) getOrCreateGeneralFolder(orgID int64) (*dashboard, error) {
+	if m.isDryRun() {
+		// the folder is not saved in a dry run, so it must be remembered
+		if folder, ok := m.report.generalFolders[orgID]; ok {
+			return folder, nil
+		}
+	}
 	// there is a unique constraint on org_id, folder_id, title
 	// there are no nested folders so the parent folder id is always 0
 	dashboard := dashboard{OrgId: orgID, FolderId: 0, Title: GENERAL_FOLDER}
@@ -55,6 +61,9 @@ func (m *migration) getOrCreateGeneralFolder(orgID int64) (*dashboard, error) {
 		if err != nil {
 			return nil, err
 		}
+		if m.isDryRun() {
+			m.report.generalFolders[orgID] = result
+		}
 
 		return result, nil
 	}
@@ -105,6 +114,9 @@ func (m *migration) createFolder(orgID int64, title string) (*dashboard, error)
 	dash.CreatedBy = FOLDER_CREATED_BY
 	dash.Updated = time.Now()
 	dash.UpdatedBy = FOLDER_CREATED_BY
+	if m.isDryRun() {
+		return dash, nil
+	}
 	metrics.MApiDashboardInsert.Inc()
 
 	if _, err = m.sess.Insert(dash); err != nil {
@@ -22,6 +22,12 @@ func AddTablesMigrations(mg *migrator.Migrator) {
 
 	// Create Admin Configuration
 	AddAlertAdminConfigMigrations(mg)
+
+	// Create alert_state_history table
+	AddAlertStateHistoryMigrations(mg)
+
+	// Create alert_notification_delivery and alert_notification_retry tables
+	AddAlertNotificationDeliveryMigrations(mg)
 }
 
 // AddAlertDefinitionMigrations should not be modified.
@@ -231,6 +237,30 @@ func AddAlertRuleMigrations(mg *migrator.Migrator, defaultIntervalSeconds int64)
 			Cols: []string{"org_id", "dashboard_uid", "panel_id"},
 		},
 	))
+
+	mg.AddMigration("add is_paused column to alert_rule", migrator.NewAddColumnMigration(alertRule, &migrator.Column{
+		Name: "is_paused", Type: migrator.DB_Bool, Nullable: false, Default: "0",
+	}))
+
+	mg.AddMigration("add record column to alert_rule", migrator.NewAddColumnMigration(alertRule, &migrator.Column{
+		Name: "record", Type: migrator.DB_NVarchar, Length: 190, Nullable: false, Default: "''",
+	}))
+
+	mg.AddMigration("add rule_group_index column to alert_rule", migrator.NewAddColumnMigration(alertRule, &migrator.Column{
+		Name: "rule_group_index", Type: migrator.DB_Int, Nullable: false, Default: "0",
+	}))
+
+	mg.AddMigration("add evaluate_sequentially column to alert_rule", migrator.NewAddColumnMigration(alertRule, &migrator.Column{
+		Name: "evaluate_sequentially", Type: migrator.DB_Bool, Nullable: false, Default: "0",
+	}))
+
+	mg.AddMigration("add depends_on column to alert_rule", migrator.NewAddColumnMigration(alertRule, &migrator.Column{
+		Name: "depends_on", Type: migrator.DB_Text, Nullable: true,
+	}))
+
+	mg.AddMigration("add rule_group_is_paused column to alert_rule", migrator.NewAddColumnMigration(alertRule, &migrator.Column{
+		Name: "rule_group_is_paused", Type: migrator.DB_Bool, Nullable: false, Default: "0",
+	}))
 }
 
 func AddAlertRuleVersionMigrations(mg *migrator.Migrator) {
@@ -274,6 +304,30 @@ func AddAlertRuleVersionMigrations(mg *migrator.Migrator) {
 
 	// add labels column
 	mg.AddMigration("add column labels to alert_rule_version", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{Name: "labels", Type: migrator.DB_Text, Nullable: true}))
+
+	mg.AddMigration("add is_paused column to alert_rule_version", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{
+		Name: "is_paused", Type: migrator.DB_Bool, Nullable: false, Default: "0",
+	}))
+
+	mg.AddMigration("add record column to alert_rule_version", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{
+		Name: "record", Type: migrator.DB_NVarchar, Length: 190, Nullable: false, Default: "''",
+	}))
+
+	mg.AddMigration("add rule_group_index column to alert_rule_version", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{
+		Name: "rule_group_index", Type: migrator.DB_Int, Nullable: false, Default: "0",
+	}))
+
+	mg.AddMigration("add evaluate_sequentially column to alert_rule_version", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{
+		Name: "evaluate_sequentially", Type: migrator.DB_Bool, Nullable: false, Default: "0",
+	}))
+
+	mg.AddMigration("add depends_on column to alert_rule_version", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{
+		Name: "depends_on", Type: migrator.DB_Text, Nullable: true,
+	}))
+
+	mg.AddMigration("add rule_group_is_paused column to alert_rule_version", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{
+		Name: "rule_group_is_paused", Type: migrator.DB_Bool, Nullable: false, Default: "0",
+	}))
 }
 
 func AddAlertmanagerConfigMigrations(mg *migrator.Migrator) {
@@ -324,3 +378,94 @@ func AddAlertAdminConfigMigrations(mg *migrator.Migrator) {
 	mg.AddMigration("create_ngalert_configuration_table", migrator.NewAddTableMigration(adminConfiguration))
 	mg.AddMigration("add index in ngalert_configuration on org_id column", migrator.NewAddIndexMigration(adminConfiguration, adminConfiguration.Indices[0]))
 }
+
+func AddAlertStateHistoryMigrations(mg *migrator.Migrator) {
+	alertStateHistory := migrator.Table{
+		Name: "alert_state_history",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "rule_org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "rule_uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "labels", Type: migrator.DB_Text, Nullable: false},
+			{Name: "labels_hash", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "previous_state", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "state", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "error_message", Type: migrator.DB_Text, Nullable: true},
+			{Name: "evaluation_string", Type: migrator.DB_Text, Nullable: true},
+			{Name: "eval_values", Type: migrator.DB_Text, Nullable: true},
+			{Name: "evaluated_at", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "created", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"rule_org_id", "rule_uid", "evaluated_at"}, Type: migrator.IndexType},
+			{Cols: []string{"rule_org_id", "evaluated_at"}, Type: migrator.IndexType},
+			{Cols: []string{"evaluated_at"}, Type: migrator.IndexType},
+		},
+	}
+
+	mg.AddMigration("create alert_state_history table", migrator.NewAddTableMigration(alertStateHistory))
+	mg.AddMigration("add index in alert_state_history on rule_org_id, rule_uid and evaluated_at columns", migrator.NewAddIndexMigration(alertStateHistory, alertStateHistory.Indices[0]))
+	mg.AddMigration("add index in alert_state_history on rule_org_id and evaluated_at columns", migrator.NewAddIndexMigration(alertStateHistory, alertStateHistory.Indices[1]))
+	mg.AddMigration("add index in alert_state_history on evaluated_at column", migrator.NewAddIndexMigration(alertStateHistory, alertStateHistory.Indices[2]))
+}
+
+func AddAlertNotificationDeliveryMigrations(mg *migrator.Migrator) {
+	notificationDelivery := migrator.Table{
+		Name: "alert_notification_delivery",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "receiver", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "integration", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "integration_index", Type: migrator.DB_Int, Nullable: false},
+			{Name: "integration_uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "rule_uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "group_key", Type: migrator.DB_Text, Nullable: false},
+			{Name: "alerts", Type: migrator.DB_Int, Nullable: false},
+			{Name: "state", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "error_message", Type: migrator.DB_Text, Nullable: true},
+			{Name: "attempt", Type: migrator.DB_Int, Nullable: false},
+			{Name: "sent_at", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "receiver", "sent_at"}, Type: migrator.IndexType},
+			{Cols: []string{"org_id", "rule_uid", "sent_at"}, Type: migrator.IndexType},
+			{Cols: []string{"org_id", "sent_at"}, Type: migrator.IndexType},
+			{Cols: []string{"sent_at"}, Type: migrator.IndexType},
+		},
+	}
+
+	mg.AddMigration("create alert_notification_delivery table", migrator.NewAddTableMigration(notificationDelivery))
+	mg.AddMigration("add index in alert_notification_delivery on org_id, receiver and sent_at columns", migrator.NewAddIndexMigration(notificationDelivery, notificationDelivery.Indices[0]))
+	mg.AddMigration("add index in alert_notification_delivery on org_id, rule_uid and sent_at columns", migrator.NewAddIndexMigration(notificationDelivery, notificationDelivery.Indices[1]))
+	mg.AddMigration("add index in alert_notification_delivery on org_id and sent_at columns", migrator.NewAddIndexMigration(notificationDelivery, notificationDelivery.Indices[2]))
+	mg.AddMigration("add index in alert_notification_delivery on sent_at column", migrator.NewAddIndexMigration(notificationDelivery, notificationDelivery.Indices[3]))
+
+	notificationRetry := migrator.Table{
+		Name: "alert_notification_retry",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "receiver", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "integration", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "integration_index", Type: migrator.DB_Int, Nullable: false},
+			{Name: "group_key", Type: migrator.DB_Text, Nullable: false},
+			{Name: "group_labels", Type: migrator.DB_Text, Nullable: false},
+			{Name: "alerts", Type: migrator.DB_MediumText, Nullable: false},
+			{Name: "attempts", Type: migrator.DB_Int, Nullable: false},
+			{Name: "error_message", Type: migrator.DB_Text, Nullable: true},
+			{Name: "next_attempt_at", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "created", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "next_attempt_at"}, Type: migrator.IndexType},
+		},
+	}
+
+	mg.AddMigration("create alert_notification_retry table", migrator.NewAddTableMigration(notificationRetry))
+	mg.AddMigration("add index in alert_notification_retry on org_id and next_attempt_at columns", migrator.NewAddIndexMigration(notificationRetry, notificationRetry.Indices[0]))
+
+	mg.AddMigration("add integration_uid column to alert_notification_retry", migrator.NewAddColumnMigration(notificationRetry, &migrator.Column{
+		Name: "integration_uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false, Default: "''",
+	}))
+}
@@ -61,12 +61,7 @@ func AddDashAlertMigration(mg *migrator.Migrator) {
 		if err != nil {
 			mg.Logger.Error("alert migration error: could not clear alert migration for removing data", "error", err)
 		}
-		mg.AddMigration(migTitle, &migration{
-			seenChannelUIDs:           make(map[string]struct{}),
-			migratedChannelsPerOrg:    make(map[int64]map[*notificationChannel]struct{}),
-			portedChannelGroupsPerOrg: make(map[int64]map[string]string),
-			silences:                  make(map[int64][]*pb.MeshSilence),
-		})
+		mg.AddMigration(migTitle, newMigration())
 	case !mg.Cfg.UnifiedAlerting.Enabled && migrationRun:
 		// Remove the migration entry that creates unified alerting data. This is so when the feature
 		// flag is enabled in the future the migration "move dashboard alerts to unified alerting" will be run again.
@@ -211,6 +206,18 @@ type migration struct {
 	silences                  map[int64][]*pb.MeshSilence
 	portedChannelGroupsPerOrg map[int64]map[string]string // Org -> Channel group key -> receiver name.
 	lastReceiverID            int                         // For the auto generated receivers.
+
+	// report is set for a dry run, which records what the migration would do instead of writing it.
+	report *DryRunReport
+}
+
+func newMigration() *migration {
+	return &migration{
+		seenChannelUIDs:           make(map[string]struct{}),
+		migratedChannelsPerOrg:    make(map[int64]map[*notificationChannel]struct{}),
+		portedChannelGroupsPerOrg: make(map[int64]map[string]string),
+		silences:                  make(map[int64][]*pb.MeshSilence),
+	}
 }
 
 func (m *migration) SQL(dialect migrator.Dialect) string {
@@ -244,6 +251,8 @@ func (m *migration) Exec(sess *xorm.Session, mg *migrator.Migrator) error {
 		return err
 	}
 
+	m.report.addChannels(allChannelsPerOrg)
+
 	amConfigPerOrg := make(amConfigsPerOrg, len(allChannelsPerOrg))
 	err = m.addDefaultChannels(amConfigPerOrg, allChannelsPerOrg, defaultChannelsPerOrg)
 	if err != nil {
@@ -251,56 +260,109 @@ func (m *migration) Exec(sess *xorm.Session, mg *migrator.Migrator) error {
 	}
 
 	for _, da := range dashAlerts {
-		newCond, err := transConditions(*da.ParsedSettings, da.OrgId, dsIDMap)
+		da.DashboardUID = dashIDMap[[2]int64{da.OrgId, da.DashboardId}]
+		ar := m.report.addAlert(da)
+		if err := m.migrateAlert(da, ar, dsIDMap, allChannelsPerOrg, defaultChannelsPerOrg, amConfigPerOrg); err != nil {
+			if !m.isDryRun() {
+				return err
+			}
+			ar.Error = err.Error()
+		}
+	}
+
+	for orgID, amConfig := range amConfigPerOrg {
+		// Create a separate receiver for all the unmigrated channels.
+		err = m.addUnmigratedChannels(orgID, amConfig, allChannelsPerOrg[orgID], defaultChannelsPerOrg[orgID])
 		if err != nil {
 			return err
 		}
 
-		da.DashboardUID = dashIDMap[[2]int64{da.OrgId, da.DashboardId}]
+		if m.isDryRun() {
+			continue
+		}
 
-		// get dashboard
-		dash := dashboard{}
-		exists, err := m.sess.Where("org_id=? AND uid=?", da.OrgId, da.DashboardUID).Get(&dash)
-		if err != nil {
-			return MigrationError{
-				Err:     fmt.Errorf("failed to get dashboard %s under organisation %d: %w", da.DashboardUID, da.OrgId, err),
-				AlertId: da.Id,
-			}
+		if err := m.writeAlertmanagerConfig(orgID, amConfig, allChannelsPerOrg[orgID]); err != nil {
+			return err
 		}
-		if !exists {
+
+		if err := m.writeSilencesFile(orgID); err != nil {
+			m.mg.Logger.Error("alert migration error: failed to write silence file", "err", err)
+		}
+	}
+
+	m.report.resolveReceivers(amConfigPerOrg)
+
+	return nil
+}
+
+// migrateAlert creates the alert rule of a dashboard alert, and the receiver and route
+// of its notification channels in the Alertmanager configuration of its organisation.
+func (m *migration) migrateAlert(da dashAlert, ar *AlertReport, dsIDMap dsUIDLookup, allChannelsPerOrg channelsPerOrg, defaultChannelsPerOrg defaultChannelsPerOrg, amConfigPerOrg amConfigsPerOrg) error {
+	for _, p := range conditionProblems(*da.ParsedSettings, da.OrgId, dsIDMap) {
+		ar.addProblem("%s", p)
+	}
+	for _, id := range extractChannelIDs(da) {
+		c, ok := allChannelsPerOrg[da.OrgId][id]
+		switch {
+		case !ok:
+			ar.addProblem("the notification channel %v does not exist and would be ignored", id)
+		case isDiscontinuedChannelType(c.Type):
+			ar.addProblem("the %s notification channel %q is discontinued and would not be notified", c.Type, c.Name)
+		}
+	}
+	if interval := ruleAdjustInterval(da.Frequency); interval != da.Frequency {
+		ar.addProblem("the evaluation interval of %ds would be changed to %ds", da.Frequency, interval)
+	}
+
+	newCond, err := transConditions(*da.ParsedSettings, da.OrgId, dsIDMap)
+	if err != nil {
+		return err
+	}
+
+	// get dashboard
+	dash := dashboard{}
+	exists, err := m.sess.Where("org_id=? AND uid=?", da.OrgId, da.DashboardUID).Get(&dash)
+	if err != nil {
+		return MigrationError{
+			Err:     fmt.Errorf("failed to get dashboard %s under organisation %d: %w", da.DashboardUID, da.OrgId, err),
+			AlertId: da.Id,
+		}
+	}
+	if !exists {
+		return MigrationError{
+			Err:     fmt.Errorf("dashboard with UID %v under organisation %d not found: %w", da.DashboardUID, da.OrgId, err),
+			AlertId: da.Id,
+		}
+	}
+
+	// get folder if exists
+	folder, err := m.getFolder(dash, da)
+	if err != nil {
+		return MigrationError{
+			Err:     err,
+			AlertId: da.Id,
+		}
+	}
+
+	switch {
+	case dash.HasAcl:
+		// create folder and assign the permissions of the dashboard (included default and inherited)
+		ptr, err := m.createFolder(dash.OrgId, fmt.Sprintf(DASHBOARD_FOLDER, getMigrationString(da)))
+		if err != nil {
 			return MigrationError{
-				Err:     fmt.Errorf("dashboard with UID %v under organisation %d not found: %w", da.DashboardUID, da.OrgId, err),
+				Err:     fmt.Errorf("failed to create folder: %w", err),
 				AlertId: da.Id,
 			}
 		}
-
-		// get folder if exists
-		folder, err := m.getFolder(dash, da)
+		folder = *ptr
+		permissions, err := m.getACL(dash.OrgId, dash.Id)
 		if err != nil {
 			return MigrationError{
-				Err:     err,
+				Err:     fmt.Errorf("failed to get dashboard %d under organisation %d permissions: %w", dash.Id, dash.OrgId, err),
 				AlertId: da.Id,
 			}
 		}
-
-		switch {
-		case dash.HasAcl:
-			// create folder and assign the permissions of the dashboard (included default and inherited)
-			ptr, err := m.createFolder(dash.OrgId, fmt.Sprintf(DASHBOARD_FOLDER, getMigrationString(da)))
-			if err != nil {
-				return MigrationError{
-					Err:     fmt.Errorf("failed to create folder: %w", err),
-					AlertId: da.Id,
-				}
-			}
-			folder = *ptr
-			permissions, err := m.getACL(dash.OrgId, dash.Id)
-			if err != nil {
-				return MigrationError{
-					Err:     fmt.Errorf("failed to get dashboard %d under organisation %d permissions: %w", dash.Id, dash.OrgId, err),
-					AlertId: da.Id,
-				}
-			}
+		if !m.isDryRun() {
 			err = m.setACL(folder.OrgId, folder.Id, permissions)
 			if err != nil {
 				return MigrationError{
@@ -308,85 +370,75 @@ func (m *migration) Exec(sess *xorm.Session, mg *migrator.Migrator) error {
 					AlertId: da.Id,
 				}
 			}
-		case dash.FolderId > 0:
-			// link the new rule to the existing folder
-		default:
-			// get or create general folder
-			ptr, err := m.getOrCreateGeneralFolder(dash.OrgId)
-			if err != nil {
-				return MigrationError{
-					Err:     fmt.Errorf("failed to get or create general folder under organisation %d: %w", dash.OrgId, err),
-					AlertId: da.Id,
-				}
-			}
-			// No need to assign default permissions to general folder
-			// because they are included to the query result if it's a folder with no permissions
-			// https://github.com/grafana/grafana/blob/076e2ce06a6ecf15804423fcc8dca1b620a321e5/pkg/services/sqlstore/dashboard_acl.go#L109
-			folder = *ptr
 		}
-
-		if folder.Uid == "" {
+	case dash.FolderId > 0:
+		// link the new rule to the existing folder
+	default:
+		// get or create general folder
+		ptr, err := m.getOrCreateGeneralFolder(dash.OrgId)
+		if err != nil {
 			return MigrationError{
-				Err:     fmt.Errorf("empty folder identifier"),
+				Err:     fmt.Errorf("failed to get or create general folder under organisation %d: %w", dash.OrgId, err),
 				AlertId: da.Id,
 			}
 		}
-		rule, err := m.makeAlertRule(*newCond, da, folder.Uid)
-		if err != nil {
-			return err
-		}
+		// No need to assign default permissions to general folder
+		// because they are included to the query result if it's a folder with no permissions
+		// https://github.com/grafana/grafana/blob/076e2ce06a6ecf15804423fcc8dca1b620a321e5/pkg/services/sqlstore/dashboard_acl.go#L109
+		folder = *ptr
+	}
 
-		if _, ok := amConfigPerOrg[rule.OrgID]; !ok {
-			m.mg.Logger.Info("no configuration found", "org", rule.OrgID)
-		} else {
-			if err := m.updateReceiverAndRoute(allChannelsPerOrg, defaultChannelsPerOrg, da, rule, amConfigPerOrg[rule.OrgID]); err != nil {
-				return err
-			}
+	if folder.Uid == "" {
+		return MigrationError{
+			Err:     fmt.Errorf("empty folder identifier"),
+			AlertId: da.Id,
 		}
+	}
+	rule, err := m.makeAlertRule(*newCond, da, folder.Uid)
+	if err != nil {
+		return err
+	}
 
-		if strings.HasPrefix(mg.Dialect.DriverName(), migrator.Postgres) {
-			err = mg.InTransaction(func(sess *xorm.Session) error {
-				_, err = sess.Insert(rule)
-				return err
-			})
-		} else {
-			_, err = m.sess.Insert(rule)
+	if _, ok := amConfigPerOrg[rule.OrgID]; !ok {
+		m.mg.Logger.Info("no configuration found", "org", rule.OrgID)
+	} else {
+		if err := m.updateReceiverAndRoute(allChannelsPerOrg, defaultChannelsPerOrg, da, rule, amConfigPerOrg[rule.OrgID]); err != nil {
+			return err
 		}
-		if err != nil {
-			// TODO better error handling, if constraint
-			rule.Title += fmt.Sprintf(" %v", rule.UID)
-			rule.RuleGroup += fmt.Sprintf(" %v", rule.UID)
+	}
 
-			_, err = m.sess.Insert(rule)
-			if err != nil {
-				return err
-			}
+	if m.isDryRun() {
+		if title, renamed := m.dryRunRuleTitle(rule); renamed {
+			ar.addProblem("another alert in the folder %q has the same name, the rule would be renamed to %q", folder.Title, title)
+			rule.RuleGroup += strings.TrimPrefix(title, rule.Title)
+			rule.Title = title
 		}
+		ar.setRule(rule, folder)
+		return nil
+	}
 
-		// create entry in alert_rule_version
-		_, err = m.sess.Insert(rule.makeVersion())
-		if err != nil {
+	if strings.HasPrefix(m.mg.Dialect.DriverName(), migrator.Postgres) {
+		err = m.mg.InTransaction(func(sess *xorm.Session) error {
+			_, err = sess.Insert(rule)
 			return err
-		}
+		})
+	} else {
+		_, err = m.sess.Insert(rule)
 	}
+	if err != nil {
+		// TODO better error handling, if constraint
+		rule.Title += fmt.Sprintf(" %v", rule.UID)
+		rule.RuleGroup += fmt.Sprintf(" %v", rule.UID)
 
-	for orgID, amConfig := range amConfigPerOrg {
-		// Create a separate receiver for all the unmigrated channels.
-		err = m.addUnmigratedChannels(orgID, amConfig, allChannelsPerOrg[orgID], defaultChannelsPerOrg[orgID])
+		_, err = m.sess.Insert(rule)
 		if err != nil {
 			return err
 		}
-
-		if err := m.writeAlertmanagerConfig(orgID, amConfig, allChannelsPerOrg[orgID]); err != nil {
-			return err
-		}
-
-		if err := m.writeSilencesFile(orgID); err != nil {
-			m.mg.Logger.Error("alert migration error: failed to write silence file", "err", err)
-		}
 	}
 
-	return nil
+	// create entry in alert_rule_version
+	_, err = m.sess.Insert(rule.makeVersion())
+	return err
 }
 
 func (m *migration) writeAlertmanagerConfig(orgID int64, amConfig *PostableUserConfig, allChannels map[interface{}]*notificationChannel) error {
@@ -473,6 +525,11 @@ func (m *rmMigration) Exec(sess *xorm.Session, mg *migrator.Migrator) error {
 		return err
 	}
 
+	_, err = sess.Exec("delete from alert_state_history")
+	if err != nil {
+		return err
+	}
+
 	exists, err := sess.IsTableExist("kv_store")
 	if err != nil {
 		return err
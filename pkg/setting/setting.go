@@ -404,6 +404,34 @@ type Cfg struct {
 	// LiveAllowedOrigins is a set of origins accepted by Live. If not provided
 	// then Live uses AppURL as the only allowed origin.
 	LiveAllowedOrigins []string
+	// LiveManagedStreamHistorySize is the maximum number of frames kept in the
+	// history of each managed stream channel. 0 disables the history.
+	LiveManagedStreamHistorySize int
+	// LiveManagedStreamHistoryMaxAge is the maximum age of the frames kept in the
+	// history of each managed stream channel. 0 means no limit.
+	LiveManagedStreamHistoryMaxAge time.Duration
+	// LiveMQTTBrokerURL is the address of an MQTT broker to receive Live data from.
+	// Zero value disables MQTT ingestion.
+	LiveMQTTBrokerURL string
+	// LiveMQTTClientID is the client ID of this Grafana instance, unique by
+	// default since the broker disconnects clients sharing an ID.
+	LiveMQTTClientID string
+	LiveMQTTUsername string
+	LiveMQTTPassword string
+	// TLS options of the connection to the MQTT broker.
+	LiveMQTTTLSSkipVerify     bool
+	LiveMQTTTLSCACertPath     string
+	LiveMQTTTLSClientCertPath string
+	LiveMQTTTLSClientKeyPath  string
+	// LiveMQTTQoS is the QoS level of the subscriptions to the MQTT broker.
+	LiveMQTTQoS byte
+	// LiveMQTTSharedGroup is the MQTT shared subscription group of Grafana
+	// instances, so that each message is received by a single instance.
+	LiveMQTTSharedGroup string
+	// LiveMQTTOrgID is the organization the data received over MQTT belongs to.
+	LiveMQTTOrgID int64
+	// LiveMQTTTopicRules map MQTT topics to Live channels.
+	LiveMQTTTopicRules []LiveMQTTTopicRule
 
 	// Grafana.com URL
 	GrafanaComURL string
@@ -1514,6 +1542,16 @@ func (cfg *Cfg) readLiveSettings(iniFile *ini.File) error {
 	}
 	cfg.LiveHAEngineAddress = section.Key("ha_engine_address").MustString("127.0.0.1:6379")
 
+	cfg.LiveManagedStreamHistorySize = section.Key("managed_stream_history_size").MustInt(0)
+	if cfg.LiveManagedStreamHistorySize < 0 {
+		return fmt.Errorf("unexpected value %d for [live] managed_stream_history_size", cfg.LiveManagedStreamHistorySize)
+	}
+	historyMaxAge, err := gtime.ParseDuration(section.Key("managed_stream_history_max_age").MustString("5m"))
+	if err != nil {
+		return fmt.Errorf("invalid value for [live] managed_stream_history_max_age: %w", err)
+	}
+	cfg.LiveManagedStreamHistoryMaxAge = historyMaxAge
+
 	var originPatterns []string
 	allowedOrigins := section.Key("allowed_origins").MustString("")
 	for _, originPattern := range strings.Split(allowedOrigins, ",") {
@@ -1523,10 +1561,62 @@ func (cfg *Cfg) readLiveSettings(iniFile *ini.File) error {
 		}
 		originPatterns = append(originPatterns, originPattern)
 	}
-	_, err := GetAllowedOriginGlobs(originPatterns)
+	_, err = GetAllowedOriginGlobs(originPatterns)
 	if err != nil {
 		return err
 	}
 	cfg.LiveAllowedOrigins = originPatterns
+
+	cfg.LiveMQTTBrokerURL = section.Key("mqtt_broker_url").MustString("")
+	cfg.LiveMQTTClientID = section.Key("mqtt_client_id").MustString("")
+	if cfg.LiveMQTTClientID == "" {
+		cfg.LiveMQTTClientID = "grafana-" + util.GenerateShortUID()
+	}
+	cfg.LiveMQTTUsername = section.Key("mqtt_username").MustString("")
+	cfg.LiveMQTTPassword = section.Key("mqtt_password").MustString("")
+	cfg.LiveMQTTTLSSkipVerify = section.Key("mqtt_tls_skip_verify").MustBool(false)
+	cfg.LiveMQTTTLSCACertPath = section.Key("mqtt_tls_ca_cert_path").MustString("")
+	cfg.LiveMQTTTLSClientCertPath = section.Key("mqtt_tls_client_cert_path").MustString("")
+	cfg.LiveMQTTTLSClientKeyPath = section.Key("mqtt_tls_client_key_path").MustString("")
+	if (cfg.LiveMQTTTLSClientCertPath == "") != (cfg.LiveMQTTTLSClientKeyPath == "") {
+		return fmt.Errorf("[live] mqtt_tls_client_cert_path and mqtt_tls_client_key_path must be set together")
+	}
+	qos := section.Key("mqtt_qos").MustInt(0)
+	if qos < 0 || qos > 2 {
+		return fmt.Errorf("unexpected value %d for [live] mqtt_qos", qos)
+	}
+	cfg.LiveMQTTQoS = byte(qos)
+	cfg.LiveMQTTSharedGroup = section.Key("mqtt_shared_group").MustString("")
+	cfg.LiveMQTTOrgID = section.Key("mqtt_org_id").MustInt64(1)
+	topicRules, err := parseLiveMQTTTopicRules(section.Key("mqtt_topic_rules").MustString(""))
+	if err != nil {
+		return fmt.Errorf("invalid value for [live] mqtt_topic_rules: %w", err)
+	}
+	cfg.LiveMQTTTopicRules = topicRules
 	return nil
 }
+
+// LiveMQTTTopicRule maps the MQTT topics matching a topic pattern to a Live
+// channel. Both are Live channel rule patterns, the channel pattern can use
+// the parameters of the topic pattern.
+type LiveMQTTTopicRule struct {
+	Topic   string
+	Channel string
+}
+
+// parseLiveMQTTTopicRules parses comma-separated rules made of a topic pattern
+// and a channel pattern separated by whitespace.
+func parseLiveMQTTTopicRules(s string) ([]LiveMQTTTopicRule, error) {
+	var rules []LiveMQTTTopicRule
+	for _, r := range strings.Split(s, ",") {
+		parts := strings.Fields(r)
+		if len(parts) == 0 {
+			continue
+		}
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("rule %q must be a topic pattern and a channel pattern separated by a space", strings.TrimSpace(r))
+		}
+		rules = append(rules, LiveMQTTTopicRule{Topic: parts[0], Channel: parts[1]})
+	}
+	return rules, nil
+}
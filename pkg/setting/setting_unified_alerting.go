@@ -2,6 +2,7 @@ package setting
 
 import (
 	"errors"
+	"fmt"
 	"strconv"
 	"strings"
 	"time"
@@ -47,6 +48,24 @@ const (
 	schedulerDefaultMaxAttempts             = 3
 	schedulerDefaultLegacyMinInterval       = 1
 	schedulerDefaultMinInterval             = 10 * time.Second
+	stateHistoryDefaultRetention            = 30 * 24 * time.Hour
+	recordingRulesDefaultRemoteWriteTimeout = 10 * time.Second
+	notificationLogDefaultRetention         = 30 * 24 * time.Hour
+	notificationRetryDefaultInterval        = time.Minute
+	notificationRetryDefaultMaxAttempts     = 10
+)
+
+// EvaluationJitter is the strategy used to spread the evaluations of the rules that have the same interval.
+type EvaluationJitter string
+
+const (
+	// EvaluationJitterNone evaluates the rules that have the same interval at the same tick.
+	EvaluationJitterNone EvaluationJitter = "none"
+	// EvaluationJitterRule spreads the evaluations of rules by the hash of their UID.
+	EvaluationJitterRule EvaluationJitter = "rule"
+	// EvaluationJitterGroup spreads the evaluations of rule groups by the hash of their name,
+	// so the rules of a group are evaluated at the same tick.
+	EvaluationJitterGroup EvaluationJitter = "group"
 )
 
 type UnifiedAlertingSettings struct {
@@ -65,6 +84,21 @@ type UnifiedAlertingSettings struct {
 	DefaultConfiguration           string
 	Enabled                        bool
 	DisabledOrgs                   map[int64]struct{}
+	StateHistoryRetention          time.Duration
+	// RecordingRulesRemoteWriteURL is the Prometheus remote write endpoint the series of recording rules are written to.
+	RecordingRulesRemoteWriteURL      string
+	RecordingRulesRemoteWriteUser     string
+	RecordingRulesRemoteWritePassword string
+	RecordingRulesRemoteWriteTimeout  time.Duration
+	EvaluationJitter                  EvaluationJitter
+	// MaxConcurrentEvaluationsPerDatasource is the maximum number of evaluations querying a datasource
+	// at the same time. There is no limit if it is 0.
+	MaxConcurrentEvaluationsPerDatasource int
+	NotificationLogRetention              time.Duration
+	// NotificationRetryInterval is the delay before the first retry of a failed notification.
+	// It doubles after each failed retry.
+	NotificationRetryInterval    time.Duration
+	NotificationRetryMaxAttempts int
 }
 
 // ReadUnifiedAlertingSettings reads both the `unified_alerting` and `alerting` sections of the configuration while preferring configuration the `alerting` section.
@@ -174,6 +208,46 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 	}
 	uaCfg.MinInterval = uaMinInterval
 
+	uaCfg.StateHistoryRetention, err = gtime.ParseDuration(valueAsString(ua, "state_history_retention", stateHistoryDefaultRetention.String()))
+	if err != nil {
+		return err
+	}
+
+	uaCfg.RecordingRulesRemoteWriteURL = valueAsString(ua, "recording_rules_remote_write_url", "")
+	uaCfg.RecordingRulesRemoteWriteUser = valueAsString(ua, "recording_rules_remote_write_user", "")
+	uaCfg.RecordingRulesRemoteWritePassword = valueAsString(ua, "recording_rules_remote_write_password", "")
+	uaCfg.RecordingRulesRemoteWriteTimeout, err = gtime.ParseDuration(valueAsString(ua, "recording_rules_remote_write_timeout", recordingRulesDefaultRemoteWriteTimeout.String()))
+	if err != nil {
+		return err
+	}
+
+	uaCfg.EvaluationJitter = EvaluationJitter(valueAsString(ua, "evaluation_jitter", string(EvaluationJitterNone)))
+	switch uaCfg.EvaluationJitter {
+	case EvaluationJitterNone, EvaluationJitterRule, EvaluationJitterGroup:
+	default:
+		return fmt.Errorf("invalid evaluation_jitter %q: must be one of none, rule or group", uaCfg.EvaluationJitter)
+	}
+
+	uaCfg.MaxConcurrentEvaluationsPerDatasource = ua.Key("max_concurrent_evaluations_per_datasource").MustInt(0)
+	if uaCfg.MaxConcurrentEvaluationsPerDatasource < 0 {
+		return fmt.Errorf("invalid max_concurrent_evaluations_per_datasource %d: must not be negative", uaCfg.MaxConcurrentEvaluationsPerDatasource)
+	}
+
+	uaCfg.NotificationLogRetention, err = gtime.ParseDuration(valueAsString(ua, "notification_log_retention", notificationLogDefaultRetention.String()))
+	if err != nil {
+		return err
+	}
+
+	uaCfg.NotificationRetryInterval, err = gtime.ParseDuration(valueAsString(ua, "notification_retry_interval", notificationRetryDefaultInterval.String()))
+	if err != nil {
+		return err
+	}
+	if uaCfg.NotificationRetryInterval <= 0 {
+		return fmt.Errorf("invalid notification_retry_interval %s: must be positive", uaCfg.NotificationRetryInterval)
+	}
+
+	uaCfg.NotificationRetryMaxAttempts = ua.Key("notification_retry_max_attempts").MustInt(notificationRetryDefaultMaxAttempts)
+
 	cfg.UnifiedAlerting = uaCfg
 	return nil
 }
@@ -24,6 +24,22 @@ func TestCfg_ReadUnifiedAlertingSettings(t *testing.T) {
 		require.Len(t, cfg.UnifiedAlerting.HAPeers, 0)
 		require.Equal(t, 200*time.Millisecond, cfg.UnifiedAlerting.HAGossipInterval)
 		require.Equal(t, 60*time.Second, cfg.UnifiedAlerting.HAPushPullInterval)
+		require.Equal(t, EvaluationJitterNone, cfg.UnifiedAlerting.EvaluationJitter)
+		require.Equal(t, 0, cfg.UnifiedAlerting.MaxConcurrentEvaluationsPerDatasource)
+		require.Equal(t, 30*24*time.Hour, cfg.UnifiedAlerting.NotificationLogRetention)
+		require.Equal(t, time.Minute, cfg.UnifiedAlerting.NotificationRetryInterval)
+		require.Equal(t, 10, cfg.UnifiedAlerting.NotificationRetryMaxAttempts)
+	}
+
+	// With an unknown evaluation jitter, it fails.
+	{
+		s, err := cfg.Raw.NewSection("unified_alerting")
+		require.NoError(t, err)
+		_, err = s.NewKey("evaluation_jitter", "random")
+		require.NoError(t, err)
+
+		require.EqualError(t, cfg.ReadUnifiedAlertingSettings(cfg.Raw), `invalid evaluation_jitter "random": must be one of none, rule or group`)
+		s.DeleteKey("evaluation_jitter")
 	}
 
 	// With peers set, it correctly parses them.
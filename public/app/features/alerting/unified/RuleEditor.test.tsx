@@ -224,6 +224,7 @@ describe('RuleEditor', () => {
             data: getDefaultQueries(),
             exec_err_state: 'Alerting',
             no_data_state: 'NoData',
+            is_paused: false,
             title: 'my great new rule',
           },
         },
@@ -404,6 +405,7 @@ describe('RuleEditor', () => {
             data: getDefaultQueries(),
             exec_err_state: 'Alerting',
             no_data_state: 'NoData',
+            is_paused: false,
             title: 'my great new rule',
           },
         },
@@ -13,6 +13,9 @@ import {
   Receiver,
   TestReceiversPayload,
   TestReceiversResult,
+  TestRoutesPayload,
+  TestRoutesResult,
+  AlertmanagerConfig,
 } from 'app/plugins/datasource/alertmanager/types';
 import { getDatasourceAPIId, GRAFANA_RULES_SOURCE_NAME } from '../utils/datasource';
 
@@ -188,6 +191,28 @@ export async function testReceivers(alertManagerSourceName: string, receivers: R
   }
 }
 
+export async function testRoutes(
+  alertManagerSourceName: string,
+  labels: Record<string, string>,
+  config?: AlertmanagerConfig
+): Promise<TestRoutesResult> {
+  const data: TestRoutesPayload = {
+    labels,
+    alertmanager_config: config,
+  };
+  const result = await lastValueFrom(
+    getBackendSrv().fetch<TestRoutesResult>({
+      method: 'POST',
+      data,
+      url: `/api/alertmanager/${getDatasourceAPIId(alertManagerSourceName)}/config/api/v1/routes/test`,
+      showErrorAlert: false,
+      showSuccessAlert: false,
+    })
+  );
+
+  return result.data;
+}
+
 function escapeQuotes(value: string): string {
   return value.replace(/"/g, '\\"');
 }
@@ -6,20 +6,24 @@ import React, { FC, useMemo } from 'react';
 
 type Props = Omit<SelectBaseProps<GrafanaAlertStateDecision>, 'options'> & {
   includeNoData: boolean;
+  includeError: boolean;
 };
 
 const options: SelectableValue[] = [
   { value: GrafanaAlertStateDecision.Alerting, label: 'Alerting' },
   { value: GrafanaAlertStateDecision.NoData, label: 'No Data' },
   { value: GrafanaAlertStateDecision.OK, label: 'OK' },
+  { value: GrafanaAlertStateDecision.KeepLastState, label: 'Keep Last State' },
+  { value: GrafanaAlertStateDecision.Error, label: 'Error' },
 ];
 
-export const GrafanaAlertStatePicker: FC<Props> = ({ includeNoData, ...props }) => {
+export const GrafanaAlertStatePicker: FC<Props> = ({ includeNoData, includeError, ...props }) => {
   const opts = useMemo(() => {
-    if (includeNoData) {
-      return options;
-    }
-    return options.filter((opt) => opt.value !== GrafanaAlertStateDecision.NoData);
-  }, [includeNoData]);
+    return options.filter(
+      (opt) =>
+        (includeNoData || opt.value !== GrafanaAlertStateDecision.NoData) &&
+        (includeError || opt.value !== GrafanaAlertStateDecision.Error)
+    );
+  }, [includeNoData, includeError]);
   return <Select menuShouldPortal options={opts} {...props} />;
 };
@@ -1,7 +1,7 @@
 import React, { FC, useState } from 'react';
 import { css } from '@emotion/css';
 import { parseDuration, durationToMilliseconds, GrafanaTheme2 } from '@grafana/data';
-import { Field, InlineLabel, Input, InputControl, useStyles2 } from '@grafana/ui';
+import { Field, InlineLabel, Input, InputControl, Switch, useStyles2 } from '@grafana/ui';
 import { useFormContext, RegisterOptions } from 'react-hook-form';
 import { RuleFormValues } from '../../types/rule-form';
 import { positiveDurationValidationPattern, durationValidationPattern } from '../../utils/time';
@@ -99,6 +99,7 @@ export const GrafanaConditionsStep: FC = () => {
                   {...field}
                   width={42}
                   includeNoData={true}
+                  includeError={false}
                   onChange={(value) => onChange(value?.value)}
                 />
               )}
@@ -112,6 +113,7 @@ export const GrafanaConditionsStep: FC = () => {
                   {...field}
                   width={42}
                   includeNoData={false}
+                  includeError={true}
                   onChange={(value) => onChange(value?.value)}
                 />
               )}
@@ -120,6 +122,9 @@ export const GrafanaConditionsStep: FC = () => {
           </Field>
         </>
       )}
+      <Field label="Pause evaluation" description="A paused rule is not evaluated and keeps its current state.">
+        <Switch {...register('isPaused')} />
+      </Field>
       <PreviewRule />
     </RuleEditorSection>
   );
@@ -337,12 +337,16 @@ async function saveGrafanaRule(values: RuleFormValues, existing?: RuleWithLocati
         await deleteRule(freshExisting);
         // if same folder, repost the group with updated rule
       } else {
-        const uid = (freshExisting.rule as RulerGrafanaRuleDTO).grafana_alert.uid!;
+        const { uid, depends_on } = (freshExisting.rule as RulerGrafanaRuleDTO).grafana_alert;
         formRule.grafana_alert.uid = uid;
+        // dependencies cannot be edited in the form yet, keep the existing ones
+        formRule.grafana_alert.depends_on = depends_on;
         await setRulerRuleGroup(GRAFANA_RULES_SOURCE_NAME, freshExisting.namespace, {
           name: freshExisting.group.name,
           interval: evaluateEvery,
           rules: [formRule],
+          evaluate_sequentially: freshExisting.group.evaluate_sequentially,
+          is_paused: freshExisting.group.is_paused,
         });
         return { uid };
       }
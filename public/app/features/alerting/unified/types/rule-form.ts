@@ -20,6 +20,7 @@ export interface RuleFormValues {
   condition: string | null; // refId of the query that gets alerted on
   noDataState: GrafanaAlertStateDecision;
   execErrState: GrafanaAlertStateDecision;
+  isPaused: boolean;
   folder: { title: string; id: number } | null;
   evaluateEvery: string;
   evaluateFor: string;
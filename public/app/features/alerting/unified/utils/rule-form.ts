@@ -48,6 +48,7 @@ export const getDefaultFormValues = (): RuleFormValues =>
     condition: '',
     noDataState: GrafanaAlertStateDecision.NoData,
     execErrState: GrafanaAlertStateDecision.Alerting,
+    isPaused: false,
     evaluateEvery: '1m',
     evaluateFor: '5m',
 
@@ -84,7 +85,7 @@ function listifyLabelsOrAnnotations(item: Labels | Annotations | undefined): Arr
 }
 
 export function formValuesToRulerGrafanaRuleDTO(values: RuleFormValues): PostableRuleGrafanaRuleDTO {
-  const { name, condition, noDataState, execErrState, evaluateFor, queries } = values;
+  const { name, condition, noDataState, execErrState, isPaused, evaluateFor, queries } = values;
   if (condition) {
     return {
       grafana_alert: {
@@ -93,6 +94,7 @@ export function formValuesToRulerGrafanaRuleDTO(values: RuleFormValues): Postabl
         no_data_state: noDataState,
         exec_err_state: execErrState,
         data: queries,
+        is_paused: isPaused,
       },
       for: evaluateFor,
       annotations: arrayToRecord(values.annotations || []),
@@ -117,6 +119,7 @@ export function rulerRuleToFormValues(ruleWithLocation: RuleWithLocation): RuleF
         evaluateEvery: group.interval || defaultFormValues.evaluateEvery,
         noDataState: ga.no_data_state,
         execErrState: ga.exec_err_state,
+        isPaused: !!ga.is_paused,
         queries: ga.data,
         condition: ga.condition,
         annotations: listifyLabelsOrAnnotations(rule.annotations),
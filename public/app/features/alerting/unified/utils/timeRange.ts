@@ -28,9 +28,11 @@ const getReferencedIds = (model: ExpressionQuery, queries: AlertQuery[]): string
     case ExpressionQueryType.classic:
       return getReferencedIdsForClassicCondition(model);
     case ExpressionQueryType.math:
+    case ExpressionQueryType.sql:
       return getReferencedIdsForMath(model, queries);
     case ExpressionQueryType.resample:
     case ExpressionQueryType.reduce:
+    case ExpressionQueryType.threshold:
       return getReferencedIdsForReduce(model);
   }
 };
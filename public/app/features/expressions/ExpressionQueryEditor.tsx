@@ -5,6 +5,8 @@ import { Resample } from './components/Resample';
 import { Reduce } from './components/Reduce';
 import { Math } from './components/Math';
 import { ClassicConditions } from './components/ClassicConditions';
+import { Threshold } from './components/Threshold';
+import { SQL } from './components/SQL';
 import { getDefaults } from './utils/expressionTypes';
 import { ExpressionQuery, ExpressionQueryType, gelTypes } from './types';
 
@@ -34,6 +36,12 @@ export class ExpressionQueryEditor extends PureComponent<Props> {
 
       case ExpressionQueryType.classic:
         return <ClassicConditions onChange={onChange} query={query} refIds={refIds} />;
+
+      case ExpressionQueryType.threshold:
+        return <Threshold onChange={onChange} query={query} labelWidth={labelWidth} refIds={refIds} />;
+
+      case ExpressionQueryType.sql:
+        return <SQL onChange={onChange} query={query} labelWidth={labelWidth} />;
     }
   }
 
@@ -12,7 +12,9 @@ interface Props {
 const mathPlaceholder =
   'Math operations on one more queries, you reference the query by ${refId} ie. $A, $B, $C etc\n' +
   'Example: $A + $B\n' +
-  'Available functions: abs(), log(), nan(), inf(), null()';
+  'Available functions: abs(), log(), log10(), log2(), exp(), sqrt(), round(), ceil(), floor(), sign(), clamp(), ' +
+  'clamp_min(), clamp_max(), is_nan(), is_inf(), is_null(), is_number(), if(), nan(), inf(), null()\n' +
+  'Series functions: rate(), increase(), delta(), deriv(), moving_avg(), shift(), offset(), cumulative_sum()';
 
 export const Math: FC<Props> = ({ labelWidth, onChange, query }) => {
   const onExpressionChange = (event: ChangeEvent<HTMLTextAreaElement>) => {
@@ -1,7 +1,7 @@
 import React, { FC } from 'react';
 import { SelectableValue } from '@grafana/data';
 import { InlineField, InlineFieldRow, Select } from '@grafana/ui';
-import { ExpressionQuery, reducerTypes } from '../types';
+import { ExpressionQuery, ReducerMode, reducerModes, reducerTypes } from '../types';
 
 interface Props {
   labelWidth: number;
@@ -12,6 +12,7 @@ interface Props {
 
 export const Reduce: FC<Props> = ({ labelWidth, onChange, refIds, query }) => {
   const reducer = reducerTypes.find((o) => o.value === query.reducer);
+  const mode = reducerModes.find((o) => o.value === (query.settings?.mode ?? ReducerMode.Strict));
 
   const onRefIdChange = (value: SelectableValue<string>) => {
     onChange({ ...query, expression: value.value });
@@ -21,6 +22,10 @@ export const Reduce: FC<Props> = ({ labelWidth, onChange, refIds, query }) => {
     onChange({ ...query, reducer: value.value });
   };
 
+  const onSelectMode = (value: SelectableValue<ReducerMode>) => {
+    onChange({ ...query, settings: { ...query.settings, mode: value.value } });
+  };
+
   return (
     <InlineFieldRow>
       <InlineField label="Function" labelWidth={labelWidth}>
@@ -29,6 +34,9 @@ export const Reduce: FC<Props> = ({ labelWidth, onChange, refIds, query }) => {
       <InlineField label="Input" labelWidth={labelWidth}>
         <Select menuShouldPortal onChange={onRefIdChange} options={refIds} value={query.expression} width={20} />
       </InlineField>
+      <InlineField label="Mode" labelWidth={labelWidth}>
+        <Select menuShouldPortal onChange={onSelectMode} options={reducerModes} value={mode} width={25} />
+      </InlineField>
     </InlineFieldRow>
   );
 };
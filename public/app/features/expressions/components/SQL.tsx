@@ -0,0 +1,34 @@
+import { InlineField, TextArea } from '@grafana/ui';
+import { css } from '@emotion/css';
+import React, { ChangeEvent, FC } from 'react';
+import { ExpressionQuery } from '../types';
+
+interface Props {
+  labelWidth: number;
+  query: ExpressionQuery;
+  onChange: (query: ExpressionQuery) => void;
+}
+
+const sqlPlaceholder =
+  'SQL query over the results of other queries, you reference a query by its refId as a table ie. A, B, C etc\n' +
+  'Example: SELECT A.host, A.value - B.value AS diff FROM A JOIN B ON A.host = B.host WHERE A.value > 0\n' +
+  'Supported clauses: SELECT [DISTINCT], FROM, [LEFT] JOIN ... ON, WHERE, GROUP BY, HAVING, ORDER BY, LIMIT\n' +
+  'Available functions: COUNT(), SUM(), AVG(), MIN(), MAX(), ABS(), ROUND(), CEIL(), FLOOR(), LOWER(), UPPER(), COALESCE()';
+
+export const SQL: FC<Props> = ({ labelWidth, onChange, query }) => {
+  const onExpressionChange = (event: ChangeEvent<HTMLTextAreaElement>) => {
+    onChange({ ...query, expression: event.target.value });
+  };
+
+  return (
+    <InlineField
+      label="Query"
+      labelWidth={labelWidth}
+      className={css`
+        align-items: baseline;
+      `}
+    >
+      <TextArea value={query.expression} onChange={onExpressionChange} rows={6} placeholder={sqlPlaceholder} />
+    </InlineField>
+  );
+};
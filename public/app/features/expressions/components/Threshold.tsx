@@ -0,0 +1,70 @@
+import React, { FC, FormEvent } from 'react';
+import { SelectableValue } from '@grafana/data';
+import { InlineField, InlineFieldRow, Input, Select } from '@grafana/ui';
+import { EvalFunction } from '../../alerting/state/alertDef';
+import { ExpressionQuery, thresholdFunctions } from '../types';
+
+interface Props {
+  labelWidth: number;
+  refIds: Array<SelectableValue<string>>;
+  query: ExpressionQuery;
+  onChange: (query: ExpressionQuery) => void;
+}
+
+export const Threshold: FC<Props> = ({ labelWidth, onChange, refIds, query }) => {
+  const condition = query.conditions![0];
+  const thresholdFunction = thresholdFunctions.find((fn) => fn.value === condition.evaluator.type);
+
+  const onRefIdChange = (value: SelectableValue<string>) => {
+    onChange({ ...query, expression: value.value });
+  };
+
+  const onEvalFunctionChange = (value: SelectableValue<EvalFunction>) => {
+    onChange({
+      ...query,
+      conditions: [{ ...condition, evaluator: { ...condition.evaluator, type: value.value! } }],
+    });
+  };
+
+  const onEvaluateValueChange = (event: FormEvent<HTMLInputElement>, index: number) => {
+    const newParams = [...condition.evaluator.params];
+    newParams[index] = parseFloat(event.currentTarget.value);
+
+    onChange({
+      ...query,
+      conditions: [{ ...condition, evaluator: { ...condition.evaluator, params: newParams } }],
+    });
+  };
+
+  const isRange =
+    condition.evaluator.type === EvalFunction.IsWithinRange || condition.evaluator.type === EvalFunction.IsOutsideRange;
+
+  return (
+    <InlineFieldRow>
+      <InlineField label="Input" labelWidth={labelWidth}>
+        <Select menuShouldPortal onChange={onRefIdChange} options={refIds} value={query.expression} width={20} />
+      </InlineField>
+      <Select
+        menuShouldPortal
+        options={thresholdFunctions}
+        onChange={onEvalFunctionChange}
+        value={thresholdFunction}
+        width={20}
+      />
+      <Input
+        type="number"
+        width={10}
+        onChange={(event) => onEvaluateValueChange(event, 0)}
+        defaultValue={condition.evaluator.params[0]}
+      />
+      {isRange && (
+        <Input
+          type="number"
+          width={10}
+          onChange={(event) => onEvaluateValueChange(event, 1)}
+          defaultValue={condition.evaluator.params[1]}
+        />
+      )}
+    </InlineFieldRow>
+  );
+};
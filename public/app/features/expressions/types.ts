@@ -6,6 +6,8 @@ export enum ExpressionQueryType {
   reduce = 'reduce',
   resample = 'resample',
   classic = 'classic_conditions',
+  threshold = 'threshold',
+  sql = 'sql',
 }
 
 export const gelTypes: Array<SelectableValue<ExpressionQueryType>> = [
@@ -13,6 +15,15 @@ export const gelTypes: Array<SelectableValue<ExpressionQueryType>> = [
   { value: ExpressionQueryType.reduce, label: 'Reduce' },
   { value: ExpressionQueryType.resample, label: 'Resample' },
   { value: ExpressionQueryType.classic, label: 'Classic condition' },
+  { value: ExpressionQueryType.threshold, label: 'Threshold' },
+  { value: ExpressionQueryType.sql, label: 'SQL' },
+];
+
+export const thresholdFunctions: Array<SelectableValue<EvalFunction>> = [
+  { value: EvalFunction.IsAbove, label: 'Is above' },
+  { value: EvalFunction.IsBelow, label: 'Is below' },
+  { value: EvalFunction.IsWithinRange, label: 'Is within range' },
+  { value: EvalFunction.IsOutsideRange, label: 'Is outside range' },
 ];
 
 export const reducerTypes: Array<SelectableValue<string>> = [
@@ -21,6 +32,32 @@ export const reducerTypes: Array<SelectableValue<string>> = [
   { value: ReducerID.mean, label: 'Mean', description: 'Get the average value' },
   { value: ReducerID.sum, label: 'Sum', description: 'Get the sum of all values' },
   { value: ReducerID.count, label: 'Count', description: 'Get the number of values' },
+  { value: 'count_non_null', label: 'Count non-null', description: 'Get the number of non-null values' },
+  { value: ReducerID.last, label: 'Last', description: 'Get the last value' },
+  { value: 'median', label: 'Median', description: 'Get the median value' },
+  { value: 'p90', label: '90th percentile', description: 'Get the 90th percentile value' },
+  { value: 'p95', label: '95th percentile', description: 'Get the 95th percentile value' },
+  { value: 'p99', label: '99th percentile', description: 'Get the 99th percentile value' },
+  { value: 'stddev', label: 'Standard deviation', description: 'Get the standard deviation of all values' },
+  { value: ReducerID.range, label: 'Range', description: 'Get the difference between the maximum and minimum value' },
+];
+
+export enum ReducerMode {
+  Strict = '', // backend API wants an empty string to represent "strict" mode
+  DropNonNumbers = 'dropNN',
+}
+
+export const reducerModes: Array<SelectableValue<ReducerMode>> = [
+  {
+    value: ReducerMode.Strict,
+    label: 'Strict',
+    description: 'Result can be NaN if series contains non-numeric data',
+  },
+  {
+    value: ReducerMode.DropNonNumbers,
+    label: 'Drop Non-numeric Values',
+    description: 'Drop NaN and null values from input series before reducing',
+  },
 ];
 
 export const downsamplingTypes: Array<SelectableValue<string>> = [
@@ -48,6 +85,11 @@ export interface ExpressionQuery extends DataQuery {
   downsampler?: string;
   upsampler?: string;
   conditions?: ClassicCondition[];
+  settings?: ExpressionQuerySettings;
+}
+
+export interface ExpressionQuerySettings {
+  mode?: ReducerMode;
 }
 export interface ClassicCondition {
   evaluator: {
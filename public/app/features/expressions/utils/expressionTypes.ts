@@ -29,6 +29,13 @@ export const getDefaults = (query: ExpressionQuery) => {
       }
       break;
 
+    case ExpressionQueryType.threshold:
+      if (!query.conditions) {
+        query.conditions = [defaultCondition];
+      }
+      query.reducer = undefined;
+      break;
+
     default:
       query.reducer = undefined;
   }
@@ -23,6 +23,7 @@ export interface RuleSettings {
 
 export interface Rule {
   pattern: string;
+  version?: number;
   settings: RuleSettings;
 }
 
@@ -254,6 +254,34 @@ export interface TestReceiversResult {
   receivers: TestReceiversResultReceiver[];
 }
 
+export interface TestRoutesPayload {
+  labels: Record<string, string>;
+  alertmanager_config?: AlertmanagerConfig;
+}
+
+export interface TestRouteResult {
+  path: number[];
+  matchers: string[][];
+  receiver: string;
+  group_by: string[];
+  group_wait: string;
+  group_interval: string;
+  repeat_interval: string;
+  mute_time_intervals?: string[];
+}
+
+export interface TestRouteInhibition {
+  source_matchers: string[];
+  target_matchers: string[];
+  equal?: string[];
+  active: boolean;
+}
+
+export interface TestRoutesResult {
+  routes: TestRouteResult[];
+  inhibitions: TestRouteInhibition[];
+}
+
 export enum AlertManagerImplementation {
   cortex = 'cortex',
   prometheus = 'prometheus',
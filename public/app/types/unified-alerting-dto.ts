@@ -17,6 +17,7 @@ export enum GrafanaAlertState {
   Pending = 'Pending',
   NoData = 'NoData',
   Error = 'Error',
+  Inhibited = 'Inhibited',
 }
 
 export enum PromRuleType {
@@ -99,6 +100,7 @@ export enum GrafanaAlertStateDecision {
   NoData = 'NoData',
   KeepLastState = 'KeepLastState',
   OK = 'OK',
+  Error = 'Error',
 }
 
 interface AlertDataQuery extends DataQuery {
@@ -121,6 +123,14 @@ export interface PostableGrafanaRuleDefinition {
   no_data_state: GrafanaAlertStateDecision;
   exec_err_state: GrafanaAlertStateDecision;
   data: AlertQuery[];
+  is_paused?: boolean;
+  record?: string;
+  depends_on?: RuleDependency[];
+}
+
+export interface RuleDependency {
+  rule_uid: string;
+  equal?: string[];
 }
 export interface GrafanaRuleDefinition extends PostableGrafanaRuleDefinition {
   uid: string;
@@ -150,6 +160,8 @@ export type RulerRuleGroupDTO<R = RulerRuleDTO> = {
   name: string;
   interval?: string;
   rules: R[];
+  evaluate_sequentially?: boolean;
+  is_paused?: boolean;
 };
 
 export type PostableRulerRuleGroupDTO = RulerRuleGroupDTO<PostableRuleDTO>;